@@ -9,9 +9,17 @@ import (
 	"github.com/emer/emergent/erand"
 	"github.com/emer/etable/minmax"
 	"github.com/goki/gosl/slbool"
+	"github.com/goki/ki/kit"
 	"github.com/goki/mat32"
 )
 
+//go:generate stringer -type=GeKinds
+
+var KiT_GeKinds = kit.Enums.AddEnum(GeKindsN, kit.NotBitFlag, nil)
+
+func (ev GeKinds) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *GeKinds) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
 ///////////////////////////////////////////////////////////////////////
 //  act.go contains the activation params and functions for axon
 
@@ -30,19 +38,18 @@ import (
 // Implements a basic thresholded Vm model, and optionally
 // the AdEx adaptive exponential function (adapt is KNaAdapt)
 type SpikeParams struct {
-	Thr      float32     `def:"0.5" desc:"threshold value Theta (Q) for firing output activation (.5 is more accurate value based on AdEx biological parameters and normalization"`
-	VmR      float32     `def:"0.3" desc:"post-spiking membrane potential to reset to, produces refractory effect if lower than VmInit -- 0.3 is apropriate biologically-based value for AdEx (Brette & Gurstner, 2005) parameters.  See also RTau"`
-	Tr       int32       `min:"1" def:"3" desc:"post-spiking explicit refractory period, in cycles -- prevents Vm updating for this number of cycles post firing -- Vm is reduced in exponential steps over this period according to RTau, being fixed at Tr to VmR exactly"`
-	RTau     float32     `def:"1.6667" desc:"time constant for decaying Vm down to VmR -- at end of Tr it is set to VmR exactly -- this provides a more realistic shape of the post-spiking Vm which is only relevant for more realistic channels that key off of Vm -- does not otherwise affect standard computation"`
-	Exp      slbool.Bool `def:"true" desc:"if true, turn on exponential excitatory current that drives Vm rapidly upward for spiking as it gets past its nominal firing threshold (Thr) -- nicely captures the Hodgkin Huxley dynamics of Na and K channels -- uses Brette & Gurstner 2005 AdEx formulation"`
-	ExpSlope float32     `viewif:"Exp" def:"0.02" desc:"slope in Vm (2 mV = .02 in normalized units) for extra exponential excitatory current that drives Vm rapidly upward for spiking as it gets past its nominal firing threshold (Thr) -- nicely captures the Hodgkin Huxley dynamics of Na and K channels -- uses Brette & Gurstner 2005 AdEx formulation"`
-	ExpThr   float32     `viewif:"Exp" def:"0.9" desc:"membrane potential threshold for actually triggering a spike when using the exponential mechanism"`
-	MaxHz    float32     `def:"180" min:"1" desc:"for translating spiking interval (rate) into rate-code activation equivalent, what is the maximum firing rate associated with a maximum activation value of 1"`
-	ISITau   float32     `def:"5" min:"1" desc:"constant for integrating the spiking interval in estimating spiking rate"`
-	ISIDt    float32     `view:"-" desc:"rate = 1 / tau"`
-	RDt      float32     `view:"-" desc:"rate = 1 / tau"`
-
-	pad int32
+	Thr       float32     `def:"0.5" desc:"threshold value Theta (Q) for firing output activation (.5 is more accurate value based on AdEx biological parameters and normalization"`
+	VmR       float32     `def:"0.3" desc:"post-spiking membrane potential to reset to, produces refractory effect if lower than VmInit -- 0.3 is apropriate biologically-based value for AdEx (Brette & Gurstner, 2005) parameters.  See also RTau"`
+	Tr        int32       `min:"1" def:"3" desc:"post-spiking explicit refractory period, in cycles -- prevents Vm updating for this number of cycles post firing -- Vm is reduced in exponential steps over this period according to RTau, being fixed at Tr to VmR exactly"`
+	RTau      float32     `def:"1.6667" desc:"time constant for decaying Vm down to VmR -- at end of Tr it is set to VmR exactly -- this provides a more realistic shape of the post-spiking Vm which is only relevant for more realistic channels that key off of Vm -- does not otherwise affect standard computation"`
+	Exp       slbool.Bool `def:"true" desc:"if true, turn on exponential excitatory current that drives Vm rapidly upward for spiking as it gets past its nominal firing threshold (Thr) -- nicely captures the Hodgkin Huxley dynamics of Na and K channels -- uses Brette & Gurstner 2005 AdEx formulation"`
+	ExpSlope  float32     `viewif:"Exp" def:"0.02" desc:"slope in Vm (2 mV = .02 in normalized units) for extra exponential excitatory current that drives Vm rapidly upward for spiking as it gets past its nominal firing threshold (Thr) -- nicely captures the Hodgkin Huxley dynamics of Na and K channels -- uses Brette & Gurstner 2005 AdEx formulation"`
+	ExpThr    float32     `viewif:"Exp" def:"0.9" desc:"membrane potential threshold for actually triggering a spike when using the exponential mechanism"`
+	MaxHz     float32     `def:"180" min:"1" desc:"for translating spiking interval (rate) into rate-code activation equivalent, what is the maximum firing rate associated with a maximum activation value of 1"`
+	ISITau    float32     `def:"5" min:"1" desc:"constant for integrating the spiking interval in estimating spiking rate"`
+	ISIDt     float32     `view:"-" desc:"rate = 1 / tau"`
+	RDt       float32     `view:"-" desc:"rate = 1 / tau"`
+	MaxRateHz float32     `def:"0" min:"0" desc:"if > 0, maximum firing rate in Hz (assuming 1 msec per cycle), which is enforced by extending the refractory period Tr as needed so that the interspike interval can never be shorter than 1 / MaxRateHz -- keeps rates physiologically plausible under strong drive, e.g., from clamped inputs"`
 }
 
 func (sk *SpikeParams) Defaults() {
@@ -55,6 +62,7 @@ func (sk *SpikeParams) Defaults() {
 	sk.ExpThr = 0.9
 	sk.MaxHz = 180
 	sk.ISITau = 5
+	sk.MaxRateHz = 0
 	sk.Update()
 }
 
@@ -66,6 +74,24 @@ func (sk *SpikeParams) Update() {
 	sk.RDt = 1 / sk.RTau
 }
 
+// RefractCycs returns the effective refractory period in cycles,
+// which is Tr extended as needed so that the minimum interspike interval
+// (refractory period + spiking cycle) is consistent with MaxRateHz.
+func (sk *SpikeParams) RefractCycs() int32 {
+	if sk.MaxRateHz <= 0 {
+		return sk.Tr
+	}
+	isi := 1000 / sk.MaxRateHz
+	minISI := int32(isi)
+	if float32(minISI) < isi {
+		minISI++
+	}
+	if minISI-1 > sk.Tr {
+		return minISI - 1
+	}
+	return sk.Tr
+}
+
 // ActToISI compute spiking interval from a given rate-coded activation,
 // based on time increment (.001 = 1msec default), Act.Dt.Integ
 func (sk *SpikeParams) ActToISI(act, timeInc, integ float32) float32 {
@@ -181,10 +207,13 @@ func (ai *ActInitParams) GetGiBase(rnd erand.Rand) float32 {
 // DecayParams control the decay of activation state in the DecayState function
 // called in NewState when a new state is to be processed.
 type DecayParams struct {
-	Act   float32     `def:"0,0.2,0.5,1" max:"1" min:"0" desc:"proportion to decay most activation state variables toward initial values at start of every ThetaCycle (except those controlled separately below) -- if 1 it is effectively equivalent to full clear, resetting other derived values.  ISI is reset every AlphaCycle to get a fresh sample of activations (doesn't affect direct computation -- only readout)."`
-	Glong float32     `def:"0,0.6" max:"1" min:"0" desc:"proportion to decay long-lasting conductances, NMDA and GABA, and also the dendritic membrane potential -- when using random stimulus order, it is important to decay this significantly to allow a fresh start -- but set Act to 0 to enable ongoing activity to keep neurons in their sensitive regime."`
-	AHP   float32     `def:"0" max:"1" min:"0" desc:"decay of afterhyperpolarization currents, including mAHP, sAHP, and KNa -- has a separate decay because often useful to have this not decay at all even if decay is on."`
-	OnRew slbool.Bool `desc:"decay layer at end of ThetaCycle when there is a global reward -- true by default for PTPred, PTMaint and PFC Super layers"`
+	Act    float32     `def:"0,0.2,0.5,1" max:"1" min:"0" desc:"proportion to decay most activation state variables toward initial values at start of every ThetaCycle (except those controlled separately below) -- if 1 it is effectively equivalent to full clear, resetting other derived values.  ISI is reset every AlphaCycle to get a fresh sample of activations (doesn't affect direct computation -- only readout)."`
+	Glong  float32     `def:"0,0.6" max:"1" min:"0" desc:"proportion to decay long-lasting conductances, NMDA and GABA, and also the dendritic membrane potential -- when using random stimulus order, it is important to decay this significantly to allow a fresh start -- but set Act to 0 to enable ongoing activity to keep neurons in their sensitive regime."`
+	AHP    float32     `def:"0" max:"1" min:"0" desc:"decay of afterhyperpolarization currents, including mAHP, sAHP, and KNa -- has a separate decay because often useful to have this not decay at all even if decay is on."`
+	OnRew  slbool.Bool `desc:"decay layer at end of ThetaCycle when there is a global reward -- true by default for PTPred, PTMaint and PFC Super layers"`
+	OnGate slbool.Bool `desc:"decay layer at end of ThetaCycle when there is VS BG gating, as indicated by Context.PVLV.VSMatrix.JustGated -- for resetting state upon action selection"`
+
+	pad, pad1, pad2 float32
 }
 
 func (ai *DecayParams) Update() {
@@ -199,9 +228,26 @@ func (ai *DecayParams) Defaults() {
 //////////////////////////////////////////////////////////////////////////////////////
 //  DtParams
 
+// GeKinds are the kinds of time course of the excitatory synaptic
+// conductance GeSyn in response to each input spike, for DtParams.GeKind.
+type GeKinds int32
+
+const (
+	// GeExp has an instantaneous rise and single-exponential decay
+	// with time constant GeTau.
+	GeExp GeKinds = iota
+
+	// GeAlpha is an alpha-function-like difference of exponentials, with
+	// a gradual rise with time constant GeRiseTau and decay with GeTau,
+	// having the same total conductance per spike as GeExp.
+	GeAlpha
+
+	GeKindsN
+)
+
 // DtParams are time and rate constants for temporal derivatives in Axon (Vm, G)
 type DtParams struct {
-	Integ       float32 `def:"1,0.5" min:"0" desc:"overall rate constant for numerical integration, for all equations at the unit level -- all time constants are specified in millisecond units, with one cycle = 1 msec -- if you instead want to make one cycle = 2 msec, you can do this globally by setting this integ value to 2 (etc).  However, stability issues will likely arise if you go too high.  For improved numerical stability, you may even need to reduce this value to 0.5 or possibly even lower (typically however this is not necessary).  MUST also coordinate this with network.time_inc variable to ensure that global network.time reflects simulated time accurately"`
+	Integ       float32 `def:"1,0.5" min:"0" desc:"overall rate constant for numerical integration, for all equations at the unit level -- all time constants are specified in millisecond units, with one cycle = 1 msec -- if you instead want to make one cycle = 2 msec, you can do this globally by setting this integ value to 2 (etc).  However, stability issues will likely arise if you go too high.  For improved numerical stability, you may even need to reduce this value to 0.5 or possibly even lower (typically however this is not necessary).  MUST also coordinate this with Context.TimePerCycle to ensure that Context.Time reflects simulated time accurately -- see Network.CheckDtConsistency"`
 	VmTau       float32 `def:"2.81" min:"1" desc:"membrane potential time constant in cycles, which should be milliseconds typically (tau is roughly how long it takes for value to change significantly -- 1.4x the half-life) -- reflects the capacitance of the neuron in principle -- biological default for AdEx spiking model C = 281 pF = 2.81 normalized"`
 	VmDendTau   float32 `def:"5" min:"1" desc:"dendritic membrane potential time constant in cycles, which should be milliseconds typically (tau is roughly how long it takes for value to change significantly -- 1.4x the half-life) -- reflects the capacitance of the neuron in principle -- biological default for AdEx spiking model C = 281 pF = 2.81 normalized"`
 	VmSteps     int32   `def:"2" min:"1" desc:"number of integration steps to take in computing new Vm value -- this is the one computation that can be most numerically unstable so taking multiple steps with proportionally smaller dt is beneficial"`
@@ -210,6 +256,8 @@ type DtParams struct {
 	IntTau      float32 `def:"40" min:"1" desc:"time constant for integrating values over timescale of an individual input state (e.g., roughly 200 msec -- theta cycle), used in computing ActInt, GeInt from Ge, and GiInt from GiSyn -- this is used for scoring performance, not for learning, in cycles, which should be milliseconds typically (tau is roughly how long it takes for value to change significantly -- 1.4x the half-life), "`
 	LongAvgTau  float32 `def:"20" min:"1" desc:"time constant for integrating slower long-time-scale averages, such as nrn.ActAvg, Pool.ActsMAvg, ActsPAvg -- computed in NewState when a new input state is present (i.e., not msec but in units of a theta cycle) (tau is roughly how long it takes for value to change significantly) -- set lower for smaller models"`
 	MaxCycStart int32   `def:"50" min:"0" desc:"cycle to start updating the SpkMaxCa, SpkMax values within a theta cycle -- early cycles often reflect prior state"`
+	GeKind      GeKinds `desc:"time course of the excitatory synaptic conductance GeSyn in response to each input spike: GeExp has an instantaneous rise and exponential decay, while GeAlpha has a more realistic gradual rise as well, for timing-sensitive models"`
+	GeRiseTau   float32 `viewif:"GeKind=GeAlpha" def:"2" min:"1" desc:"time constant for the rise of the excitatory conductance for GeAlpha, which must be less than GeTau -- larger values produce a slower rise and a later, lower peak"`
 
 	VmDt      float32 `view:"-" json:"-" xml:"-" desc:"nominal rate = Integ / tau"`
 	VmDendDt  float32 `view:"-" json:"-" xml:"-" desc:"nominal rate = Integ / tau"`
@@ -218,6 +266,8 @@ type DtParams struct {
 	GiDt      float32 `view:"-" json:"-" xml:"-" desc:"rate = Integ / tau"`
 	IntDt     float32 `view:"-" json:"-" xml:"-" desc:"rate = Integ / tau"`
 	LongAvgDt float32 `view:"-" json:"-" xml:"-" desc:"rate = 1 / tau"`
+	GeRiseDt  float32 `view:"-" json:"-" xml:"-" desc:"rate = Integ / tau"`
+	GeAlphaNm float32 `view:"-" json:"-" xml:"-" desc:"normalization factor for GeAlpha = GeTau / (GeTau - GeRiseTau)"`
 }
 
 func (dp *DtParams) Update() {
@@ -231,6 +281,11 @@ func (dp *DtParams) Update() {
 	dp.GiDt = dp.Integ / dp.GiTau
 	dp.IntDt = dp.Integ / dp.IntTau
 	dp.LongAvgDt = 1 / dp.LongAvgTau
+	if dp.GeRiseTau >= dp.GeTau {
+		dp.GeRiseTau = 0.5 * dp.GeTau
+	}
+	dp.GeRiseDt = dp.Integ / dp.GeRiseTau
+	dp.GeAlphaNm = dp.GeTau / (dp.GeTau - dp.GeRiseTau)
 }
 
 func (dp *DtParams) Defaults() {
@@ -243,6 +298,7 @@ func (dp *DtParams) Defaults() {
 	dp.IntTau = 40
 	dp.LongAvgTau = 20
 	dp.MaxCycStart = 50
+	dp.GeRiseTau = 2
 	dp.Update()
 }
 
@@ -251,6 +307,21 @@ func (dp *DtParams) GeSynFmRaw(geSyn, geRaw float32) float32 {
 	return geSyn + geRaw - dp.GeDt*geSyn
 }
 
+// GeRiseFmRaw integrates the rise component of a GeAlpha synaptic
+// conductance from raw spiking using GeRiseTau
+func (dp *DtParams) GeRiseFmRaw(geRise, geRaw float32) float32 {
+	return geRise + geRaw - dp.GeRiseDt*geRise
+}
+
+// GeSynAlpha returns the GeAlpha synaptic conductance from the decay
+// component integrated by GeSynFmRaw and the rise component integrated
+// by GeRiseFmRaw from the same raw spiking.  This difference of
+// exponentials has the same steady-state value as GeSynFmRaw
+// (see GeSynFmRawSteady).
+func (dp *DtParams) GeSynAlpha(geDecay, geRise float32) float32 {
+	return dp.GeAlphaNm * (geDecay - geRise)
+}
+
 // GeSynFmRawSteady returns the steady-state GeSyn that would result from
 // receiving a steady increment of GeRaw every time step = raw * GeTau.
 // dSyn = Raw - dt*Syn; solve for dSyn = 0 to get steady state:
@@ -297,21 +368,37 @@ func (dp *DtParams) AvgVarUpdt(avg, vr *float32, val float32) {
 // SpikeNoiseParams parameterizes background spiking activity impinging on the neuron,
 // simulated using a poisson spiking process.
 type SpikeNoiseParams struct {
-	On   slbool.Bool `desc:"add noise simulating background spiking levels"`
-	GeHz float32     `viewif:"On" def:"100" desc:"mean frequency of excitatory spikes -- typically 50Hz but multiple inputs increase rate -- poisson lambda parameter, also the variance"`
-	Ge   float32     `viewif:"On" min:"0" desc:"excitatory conductance per spike -- .001 has minimal impact, .01 can be strong, and .15 is needed to influence timing of clamped inputs"`
-	GiHz float32     `viewif:"On" def:"200" desc:"mean frequency of inhibitory spikes -- typically 100Hz fast spiking but multiple inputs increase rate -- poisson lambda parameter, also the variance"`
-	Gi   float32     `viewif:"On" min:"0" desc:"excitatory conductance per spike -- .001 has minimal impact, .01 can be strong, and .15 is needed to influence timing of clamped inputs"`
-
-	GeExpInt float32 `view:"-" json:"-" xml:"-" desc:"Exp(-Interval) which is the threshold for GeNoiseP as it is updated"`
-	GiExpInt float32 `view:"-" json:"-" xml:"-" desc:"Exp(-Interval) which is the threshold for GiNoiseP as it is updated"`
+	On       slbool.Bool `desc:"add noise simulating background spiking levels"`
+	GeHz     float32     `viewif:"On" def:"100" desc:"mean frequency of excitatory spikes -- typically 50Hz but multiple inputs increase rate -- poisson lambda parameter, also the variance"`
+	Ge       float32     `viewif:"On" min:"0" desc:"excitatory conductance per spike -- .001 has minimal impact, .01 can be strong, and .15 is needed to influence timing of clamped inputs"`
+	GiHz     float32     `viewif:"On" def:"200" desc:"mean frequency of inhibitory spikes -- typically 100Hz fast spiking but multiple inputs increase rate -- poisson lambda parameter, also the variance"`
+	Gi       float32     `viewif:"On" min:"0" desc:"excitatory conductance per spike -- .001 has minimal impact, .01 can be strong, and .15 is needed to influence timing of clamped inputs"`
+	CorrFrac float32     `viewif:"On" def:"0" min:"0" max:"1" desc:"fraction of the noise spikes that come from a source shared by all neurons in the same pool, with the remainder independent per neuron -- produces correlated noise across neurons in a pool, with a correlation of roughly CorrFrac"`
+
+	GeExpInt float32 `view:"-" json:"-" xml:"-" desc:"Exp(-Interval) which is the threshold for GeNoiseP as it is updated, for the independent portion of the noise"`
+	GiExpInt float32 `view:"-" json:"-" xml:"-" desc:"Exp(-Interval) which is the threshold for GiNoiseP as it is updated, for the independent portion of the noise"`
+	GeCorrP  float32 `view:"-" json:"-" xml:"-" desc:"per-cycle probability of a shared pool-level Ge noise spike = CorrFrac * GeHz / 1000"`
+	GiCorrP  float32 `view:"-" json:"-" xml:"-" desc:"per-cycle probability of a shared pool-level Gi noise spike = CorrFrac * GiHz / 1000"`
 
-	pad int32
+	pad, pad1 int32
 }
 
 func (an *SpikeNoiseParams) Update() {
-	an.GeExpInt = mat32.Exp(-1000.0 / an.GeHz)
-	an.GiExpInt = mat32.Exp(-1000.0 / an.GiHz)
+	if an.CorrFrac < 0 {
+		an.CorrFrac = 0
+	}
+	if an.CorrFrac > 1 {
+		an.CorrFrac = 1
+	}
+	if an.CorrFrac < 1 {
+		an.GeExpInt = mat32.Exp(-1000.0 / ((1 - an.CorrFrac) * an.GeHz))
+		an.GiExpInt = mat32.Exp(-1000.0 / ((1 - an.CorrFrac) * an.GiHz))
+	} else { // all noise comes from the shared source: PGe, PGi are off
+		an.GeExpInt = 0
+		an.GiExpInt = 0
+	}
+	an.GeCorrP = an.CorrFrac * an.GeHz / 1000.0
+	an.GiCorrP = an.CorrFrac * an.GiHz / 1000.0
 }
 
 func (an *SpikeNoiseParams) Defaults() {
@@ -325,6 +412,9 @@ func (an *SpikeNoiseParams) Defaults() {
 // PGe updates the GeNoiseP probability, multiplying a uniform random number [0-1]
 // and returns Ge from spiking if a spike is triggered
 func (an *SpikeNoiseParams) PGe(ctx *Context, p *float32, ni uint32) float32 {
+	if an.CorrFrac == 1 {
+		return 0
+	}
 	*p *= GetRandomNumber(ni, ctx.RandCtr, RandFunActPGe)
 	if *p <= an.GeExpInt {
 		*p = 1
@@ -333,9 +423,36 @@ func (an *SpikeNoiseParams) PGe(ctx *Context, p *float32, ni uint32) float32 {
 	return 0
 }
 
+// PGeCorr returns Ge from a spike in the shared noise source for given
+// pool index pi, which is the same for all neurons in the pool, if CorrFrac > 0
+func (an *SpikeNoiseParams) PGeCorr(ctx *Context, pi uint32) float32 {
+	if an.CorrFrac == 0 {
+		return 0
+	}
+	if GetRandomNumber(PoolRandIdx(pi), ctx.RandCtr, RandFunActPGeCorr) < an.GeCorrP {
+		return an.Ge
+	}
+	return 0
+}
+
+// PGiCorr returns Gi from a spike in the shared noise source for given
+// pool index pi, which is the same for all neurons in the pool, if CorrFrac > 0
+func (an *SpikeNoiseParams) PGiCorr(ctx *Context, pi uint32) float32 {
+	if an.CorrFrac == 0 {
+		return 0
+	}
+	if GetRandomNumber(PoolRandIdx(pi), ctx.RandCtr, RandFunActPGiCorr) < an.GiCorrP {
+		return an.Gi
+	}
+	return 0
+}
+
 // PGi updates the GiNoiseP probability, multiplying a uniform random number [0-1]
 // and returns Gi from spiking if a spike is triggered
 func (an *SpikeNoiseParams) PGi(ctx *Context, p *float32, ni uint32) float32 {
+	if an.CorrFrac == 1 {
+		return 0
+	}
 	*p *= GetRandomNumber(ni, ctx.RandCtr, RandFunActPGi)
 	if *p <= an.GiExpInt {
 		*p = 1
@@ -351,13 +468,15 @@ func (an *SpikeNoiseParams) PGi(ctx *Context, p *float32, ni uint32) float32 {
 // (like a current clamp) -- either adds or overwrites existing conductances.
 // Noise is added in either case.
 type ClampParams struct {
-	IsInput  slbool.Bool `inactive:"+" desc:"is this a clamped input layer?  set automatically based on layer type at initialization"`
-	IsTarget slbool.Bool `inactive:"+" desc:"is this a target layer?  set automatically based on layer type at initialization"`
-	Ge       float32     `def:"0.8,1.5" desc:"amount of Ge driven for clamping -- generally use 0.8 for Target layers, 1.5 for Input layers"`
-	Add      slbool.Bool `def:"false" view:"add external conductance on top of any existing -- generally this is not a good idea for target layers (creates a main effect that learning can never match), but may be ok for input layers"`
-	ErrThr   float32     `def:"0.5" desc:"threshold on neuron Act activity to count as active for computing error relative to target in PctErr method"`
+	IsInput    slbool.Bool `inactive:"+" desc:"is this a clamped input layer?  set automatically based on layer type at initialization"`
+	IsTarget   slbool.Bool `inactive:"+" desc:"is this a target layer?  set automatically based on layer type at initialization"`
+	Ge         float32     `def:"0.8,1.5" desc:"amount of Ge driven for clamping -- generally use 0.8 for Target layers, 1.5 for Input layers"`
+	Add        slbool.Bool `def:"false" view:"add external conductance on top of any existing -- generally this is not a good idea for target layers (creates a main effect that learning can never match), but may be ok for input layers"`
+	ErrThr     float32     `def:"0.5" desc:"threshold on neuron Act activity to count as active for computing error relative to target in PctErr method"`
+	RampCycles int32       `def:"0" min:"0" desc:"for Target layers, number of cycles at the start of the plus phase over which the clamping Ge ramps up linearly from RampStart * Ge to the full Ge value, so that the teaching signal fades in instead of stepping on -- 0 = no ramp"`
+	RampStart  float32     `def:"0" min:"0" max:"1" viewif:"RampCycles>0" desc:"starting proportion of the clamping Ge at the start of the plus phase, when RampCycles > 0"`
 
-	pad, pad1, pad2 float32
+	pad float32
 }
 
 func (cp *ClampParams) Update() {
@@ -366,6 +485,24 @@ func (cp *ClampParams) Update() {
 func (cp *ClampParams) Defaults() {
 	cp.Ge = 0.8
 	cp.ErrThr = 0.5
+	cp.RampCycles = 0
+	cp.RampStart = 0
+}
+
+// RampFact returns the multiplier on the clamping Ge for Target layers
+// in the plus phase, which ramps linearly from RampStart to 1
+// over the first RampCycles cycles of the plus phase.  Returns 1 otherwise.
+// Only a linear ramp is supported, because ClampParams is part of the
+// LayerParams shared with the GPU, which can only hold 32-bit scalar
+// fields (no slices or arrays) so a per-cycle ramp profile cannot be
+// stored here.  Arbitrary profiles can instead be produced on the CPU by
+// scaling the external input values applied each cycle, as the clamping
+// Ge is Ext * Ge.
+func (cp *ClampParams) RampFact(ctx *Context) float32 {
+	if cp.RampCycles <= 0 || cp.IsTarget.IsFalse() || ctx.PlusPhase.IsFalse() || ctx.PhaseCycle >= cp.RampCycles {
+		return 1
+	}
+	return cp.RampStart + (1-cp.RampStart)*float32(ctx.PhaseCycle)/float32(cp.RampCycles)
 }
 
 //////////////////////////////////////////////////////////////////////////////////////
@@ -581,6 +718,7 @@ func (ac *ActParams) DecayState(nrn *Neuron, decay, glong float32) {
 	// always reset these -- otherwise get insanely large values that take forever to update
 	nrn.ISIAvg = -1
 	nrn.ActInt = ac.Init.Act // start fresh
+	// note: SpkCnt is not decayed, as it counts actual spikes in the trial -- reset in NewState
 
 	if decay > 0 { // no-op for most, but not all..
 		nrn.Spike = 0
@@ -588,6 +726,7 @@ func (ac *ActParams) DecayState(nrn *Neuron, decay, glong float32) {
 		nrn.Act -= decay * (nrn.Act - ac.Init.Act)
 		nrn.ActInt -= decay * (nrn.ActInt - ac.Init.Act)
 		nrn.GeSyn -= decay * (nrn.GeSyn - nrn.GeBase)
+		nrn.GeRise -= decay * nrn.GeRise
 		nrn.Ge -= decay * (nrn.Ge - nrn.GeBase)
 		nrn.Gi -= decay * (nrn.Gi - nrn.GiBase)
 		nrn.Gk -= decay * nrn.Gk
@@ -611,6 +750,7 @@ func (ac *ActParams) DecayState(nrn *Neuron, decay, glong float32) {
 	nrn.GgabaB -= glong * nrn.GgabaB
 	nrn.GABAB -= glong * nrn.GABAB
 	nrn.GABABx -= glong * nrn.GABABx
+	nrn.GABABd -= glong * nrn.GABABd
 
 	nrn.GnmdaSyn -= glong * nrn.GnmdaSyn
 	nrn.Gnmda -= glong * nrn.Gnmda
@@ -645,13 +785,16 @@ func (ac *ActParams) DecayState(nrn *Neuron, decay, glong float32) {
 func (ac *ActParams) InitActs(rnd erand.Rand, nrn *Neuron) {
 	nrn.Spike = 0
 	nrn.Spiked = 0
+	nrn.SpkCnt = 0
 	nrn.ISI = -1
 	nrn.ISIAvg = -1
 	nrn.Act = ac.Init.Act
 	nrn.ActInt = ac.Init.Act
+	nrn.ActOut = 0
 	nrn.GeBase = ac.Init.GetGeBase(rnd)
 	nrn.GiBase = ac.Init.GetGiBase(rnd)
 	nrn.GeSyn = nrn.GeBase
+	nrn.GeRise = 0
 	nrn.Ge = nrn.GeBase
 	nrn.Gi = nrn.GiBase
 	nrn.Gk = 0
@@ -689,6 +832,7 @@ func (ac *ActParams) InitActs(rnd erand.Rand, nrn *Neuron) {
 	nrn.GgabaB = 0
 	nrn.GABAB = 0
 	nrn.GABABx = 0
+	nrn.GABABd = 0
 
 	nrn.Gvgcc = 0
 	nrn.VgccM = 0
@@ -798,14 +942,15 @@ func (ac *ActParams) GSkCaFmCa(nrn *Neuron) {
 // geExt is extra conductance to add to the final Ge value
 func (ac *ActParams) GeFmSyn(ctx *Context, ni uint32, nrn *Neuron, geSyn, geExt float32) {
 	nrn.GeExt = 0
+	clampGe := ac.Clamp.Ge * ac.Clamp.RampFact(ctx)
 	if ac.Clamp.Add.IsTrue() && nrn.HasFlag(NeuronHasExt) {
-		nrn.GeExt = nrn.Ext * ac.Clamp.Ge
+		nrn.GeExt = nrn.Ext * clampGe
 		geSyn += nrn.GeExt
 	}
 	geSyn = ac.Attn.ModVal(geSyn, nrn.Attn)
 
 	if ac.Clamp.Add.IsFalse() && nrn.HasFlag(NeuronHasExt) { // todo: this flag check is not working
-		geSyn = nrn.Ext * ac.Clamp.Ge
+		geSyn = nrn.Ext * clampGe
 		nrn.GeExt = geSyn
 		geExt = 0 // no extra in this case
 	}
@@ -823,6 +968,7 @@ func (ac *ActParams) GeNoise(ctx *Context, ni uint32, nrn *Neuron) {
 		return
 	}
 	ge := ac.Noise.PGe(ctx, &nrn.GeNoiseP, ni)
+	ge += ac.Noise.PGeCorr(ctx, nrn.SubPoolN)
 	nrn.GeNoise = ac.Dt.GeSynFmRaw(nrn.GeNoise, ge)
 	nrn.Ge += nrn.GeNoise
 }
@@ -833,6 +979,7 @@ func (ac *ActParams) GiNoise(ctx *Context, ni uint32, nrn *Neuron) {
 		return
 	}
 	gi := ac.Noise.PGi(ctx, &nrn.GiNoiseP, ni)
+	gi += ac.Noise.PGiCorr(ctx, nrn.SubPoolN)
 	nrn.GiNoise = ac.Dt.GiSynFmRaw(nrn.GiNoise, gi)
 }
 
@@ -875,10 +1022,18 @@ func (ac *ActParams) VmInteg(vm, dt, ge, gl, gi, gk float32, nvm, inet *float32)
 
 // VmFmG computes membrane potential Vm from conductances Ge, Gi, and Gk.
 func (ac *ActParams) VmFmG(nrn *Neuron) {
+	ac.VmFmGExt(nrn, 0)
+}
+
+// VmFmGExt computes membrane potential Vm from conductances Ge, Gi, and Gk,
+// plus given additional external current iext, e.g., from gap junctions,
+// which is applied outside of the refractory period.
+func (ac *ActParams) VmFmGExt(nrn *Neuron, iext float32) {
 	updtVm := true
+	tr := ac.Spike.RefractCycs()
 	// note: nrn.ISI has NOT yet been updated at this point: 0 right after spike, etc
 	// so it takes a full 3 time steps after spiking for Tr period
-	if ac.Spike.Tr > 0 && nrn.ISI >= 0 && nrn.ISI < float32(ac.Spike.Tr) {
+	if tr > 0 && nrn.ISI >= 0 && nrn.ISI < float32(tr) {
 		updtVm = false // don't update the spiking vm during refract
 	}
 
@@ -898,11 +1053,15 @@ func (ac *ActParams) VmFmG(nrn *Neuron) {
 			inet += expi
 			nvm = ac.VmFmInet(nvm, ac.Dt.VmDt, expi)
 		}
+		if iext != 0 {
+			inet += iext
+			nvm = ac.VmFmInet(nvm, ac.Dt.VmDt, iext)
+		}
 		nrn.Vm = nvm
 		nrn.Inet = inet
 	} else { // decay back to VmR
 		var dvm float32
-		if int32(nrn.ISI) == ac.Spike.Tr-1 {
+		if int32(nrn.ISI) == tr-1 {
 			dvm = (ac.Spike.VmR - nrn.Vm)
 		} else {
 			dvm = ac.Spike.RDt * (ac.Spike.VmR - nrn.Vm)
@@ -933,8 +1092,20 @@ func (ac *ActParams) SpikeFmVm(nrn *Neuron) {
 	} else {
 		thr = ac.Spike.Thr
 	}
-	if nrn.Vm >= thr {
+	thr += nrn.SpkThrOff
+	spiked := nrn.Vm >= thr
+	if spiked && ac.Spike.MaxRateHz > 0 && nrn.ISI >= 0 && nrn.ISI < float32(ac.Spike.RefractCycs()) {
+		spiked = false // still refractory
+	}
+	ac.SpikeISI(nrn, spiked)
+}
+
+// SpikeISI records whether the neuron spiked on this cycle in Spike,
+// updating the ISI-based values and the resulting Act rate activation.
+func (ac *ActParams) SpikeISI(nrn *Neuron, spiked bool) {
+	if spiked {
 		nrn.Spike = 1
+		nrn.SpkCnt += 1
 		if nrn.ISIAvg == -1 {
 			nrn.ISIAvg = -2
 		} else if nrn.ISI > 0 { // must have spiked to update
@@ -132,6 +132,15 @@ func (sc *SynComParams) ReadIdx(rnIdx uint32, cycTot int32, nRecvNeurs uint32) u
 	return sc.ReadOff(cycTot)*nRecvNeurs + rnIdx // delay is outer, neurs are inner -- should be faster?
 }
 
+// NegIdx returns the index into the separate buffer that accumulates the
+// magnitude of negative weight inputs for SWt.AllowNegative projections,
+// for given GBuf index bi from WriteIdx or ReadIdx.  This buffer follows
+// the DelLen * nRecvNeurs positive values, so the GBuf values are
+// always non-negative.
+func (sc *SynComParams) NegIdx(bi, nRecvNeurs uint32) uint32 {
+	return bi + sc.DelLen*nRecvNeurs
+}
+
 // FloatToIntFactor returns the factor used for converting float32
 // to int32 in GBuf encoding.  Because total G is constrained via
 // scaling factors to be around ~1, it is safe to use a factor that
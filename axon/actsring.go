@@ -0,0 +1,71 @@
+// Copyright (c) 2023, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package axon
+
+import (
+	"github.com/emer/etable/etensor"
+)
+
+// ActsRing records selected neuron variables for all neurons in the network
+// each cycle, into a fixed-size ring buffer of the most recent NRecs cycles,
+// overwriting the oldest records.  This provides a bounded-memory record of
+// recent activity that is independent of the GUI NetView.
+// Configure with Network.RecordActsRing.
+type ActsRing struct {
+	NRecs    int         `desc:"number of cycles to record -- 0 = off"`
+	NNeurons int         `desc:"number of neurons recorded per cycle"`
+	Vars     []string    `desc:"names of the neuron variables recorded"`
+	VarIdxs  []int       `view:"-" desc:"indexes of the Vars in the NeuronVars list"`
+	Idx      int         `desc:"ring buffer index where the next cycle will be recorded"`
+	N        int         `desc:"number of cycles recorded so far, up to NRecs"`
+	Vals     [][]float32 `view:"-" desc:"[Vars][NRecs][NNeurons] ring buffers of recorded values"`
+}
+
+// VarIdx returns the index of given variable in Vars, or -1 if not recorded.
+func (ar *ActsRing) VarIdx(varNm string) int {
+	for i, vn := range ar.Vars {
+		if vn == varNm {
+			return i
+		}
+	}
+	return -1
+}
+
+// Reset resets the record, without reallocating.
+func (ar *ActsRing) Reset() {
+	ar.Idx = 0
+	ar.N = 0
+}
+
+// Record records the current values of the variables for given neurons.
+func (ar *ActsRing) Record(neurs []Neuron) {
+	if ar.NRecs <= 0 || len(neurs) != ar.NNeurons {
+		return
+	}
+	st := ar.Idx * ar.NNeurons
+	for vi, vidx := range ar.VarIdxs {
+		vals := ar.Vals[vi][st : st+ar.NNeurons]
+		for ni := range neurs {
+			vals[ni] = neurs[ni].VarByIndex(vidx)
+		}
+	}
+	ar.Idx = (ar.Idx + 1) % ar.NRecs
+	if ar.N < ar.NRecs {
+		ar.N++
+	}
+}
+
+// Tensor returns the recorded values for variable at given index in Vars,
+// as a tensor of shape [N][NNeurons], ordered from oldest to most recent.
+func (ar *ActsRing) Tensor(vi int) *etensor.Float32 {
+	tsr := etensor.NewFloat32([]int{ar.N, ar.NNeurons}, nil, []string{"Rec", "Neuron"})
+	st := (ar.Idx - ar.N + ar.NRecs) % ar.NRecs
+	vals := ar.Vals[vi]
+	for r := 0; r < ar.N; r++ {
+		ri := (st + r) % ar.NRecs
+		copy(tsr.Values[r*ar.NNeurons:(r+1)*ar.NNeurons], vals[ri*ar.NNeurons:(ri+1)*ar.NNeurons])
+	}
+	return tsr
+}
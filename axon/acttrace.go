@@ -0,0 +1,79 @@
+// Copyright (c) 2023, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package axon
+
+import (
+	"math"
+)
+
+// ActTrace records the layer-average activity on each cycle in a ring
+// buffer of NCycles, for analyzing rhythmic dynamics in the activity
+// (see Layer.PhaseLockedActivity).  This is CPU-only state -- when running
+// on the GPU, the Neurons must be synced back each cycle and
+// Layer.RecordActTrace called.
+type ActTrace struct {
+	NCycles int       `desc:"number of recent cycles to record -- 0 = off"`
+	Idx     int       `desc:"ring buffer index where the next cycle will be recorded"`
+	N       int       `desc:"number of cycles recorded so far, up to NCycles"`
+	Vals    []float32 `view:"-" desc:"[NCycles] ring buffer of recorded layer-average activity"`
+}
+
+// Init allocates the buffer for given number of cycles,
+// and resets the recorded history.
+func (at *ActTrace) Init(ncycles int) {
+	at.NCycles = ncycles
+	at.Vals = make([]float32, ncycles)
+	at.Reset()
+}
+
+// Reset resets the recorded history, without reallocating.
+func (at *ActTrace) Reset() {
+	at.Idx = 0
+	at.N = 0
+}
+
+// Record records given value for the current cycle.
+func (at *ActTrace) Record(val float32) {
+	if at.NCycles <= 0 {
+		return
+	}
+	at.Vals[at.Idx] = val
+	at.Idx = (at.Idx + 1) % at.NCycles
+	if at.N < at.NCycles {
+		at.N++
+	}
+}
+
+// Fourier returns the amplitude and phase (in radians) of the Fourier
+// component at given frequency (cycles per unit of time), for the most
+// recent n recorded values (all recorded if n <= 0 or > N), with given
+// time step per record.  The mean is subtracted first, and the phase is
+// that of a cosine relative to the start of the window, such that
+// values of amp * cos(2 pi freq t + phase) are recovered exactly
+// for windows spanning whole periods.
+func (at *ActTrace) Fourier(freq float32, n int, dt float32) (amp, phase float32) {
+	if n <= 0 || n > at.N {
+		n = at.N
+	}
+	if n == 0 {
+		return
+	}
+	st := at.Idx - n + at.NCycles // oldest value in the window
+	mean := 0.0
+	for k := 0; k < n; k++ {
+		mean += float64(at.Vals[(st+k)%at.NCycles])
+	}
+	mean /= float64(n)
+	w := 2 * math.Pi * float64(freq) * float64(dt)
+	var re, im float64
+	for k := 0; k < n; k++ {
+		v := float64(at.Vals[(st+k)%at.NCycles]) - mean
+		re += v * math.Cos(w*float64(k))
+		im -= v * math.Sin(w*float64(k))
+	}
+	amp = float32(2 * math.Sqrt(re*re+im*im) / float64(n))
+	phase = float32(math.Atan2(im, re))
+	return
+}
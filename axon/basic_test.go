@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
 	"testing"
 
@@ -1082,3 +1083,60 @@ func TestSWtLinLearn(t *testing.T) {
 		t.Errorf("SPct: %g\t SWt should be 0.5 not: %g\n", spct, sy.SWt)
 	}
 }
+
+func TestSaveLoadParamSets(t *testing.T) {
+	sets := params.Sets{ParamSets[0],
+		{Name: "Fast", Desc: "faster learning", Sheets: params.Sheets{
+			"Network": &params.Sheet{
+				{Sel: "Prjn", Desc: "higher lrate",
+					Params: params.Params{
+						"Prjn.Learn.LRate.Base": "0.1",
+					}},
+			},
+		}},
+	}
+	fn := filepath.Join(t.TempDir(), "params.json")
+	if err := SaveParamSets(sets, fn); err != nil {
+		t.Fatal(err)
+	}
+	b1, _ := os.ReadFile(fn)
+	loaded, err := LoadParamSets(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 2 || loaded[1].Name != "Fast" || len(loaded[0].Sheets) != 2 {
+		t.Fatalf("LoadParamSets: did not load the saved sets: %v", loaded)
+	}
+	for _, sht := range []string{"Network", "InhibOff"} {
+		got := loaded[0].Sheets[sht]
+		want := sets[0].Sheets[sht]
+		if len(*got) != len(*want) {
+			t.Fatalf("sheet %s: got %d sels, want %d", sht, len(*got), len(*want))
+		}
+		for si, sel := range *want {
+			gsel := (*got)[si]
+			if gsel.Sel != sel.Sel || fmt.Sprint(gsel.Params) != fmt.Sprint(sel.Params) {
+				t.Errorf("sheet %s sel %d: got %v %v, want %v %v", sht, si, gsel.Sel, gsel.Params, sel.Sel, sel.Params)
+			}
+		}
+	}
+	if err := SaveParamSets(loaded, fn); err != nil {
+		t.Fatal(err)
+	}
+	b2, _ := os.ReadFile(fn)
+	if !bytes.Equal(b1, b2) {
+		t.Errorf("SaveParamSets: output not stable across round trip")
+	}
+
+	// loaded params apply the same as the originals
+	net := newTestNet()
+	net.ApplyParams(loaded[1].Sheets["Network"], false)
+	pj := net.AxonLayerByName("Hidden").RcvPrjns[0]
+	if pj.Params.Learn.LRate.Base != 0.1 {
+		t.Errorf("loaded params not applied: LRate.Base = %g", pj.Params.Learn.LRate.Base)
+	}
+
+	if _, err := LoadParamSets(filepath.Join(t.TempDir(), "none.json")); err == nil {
+		t.Errorf("LoadParamSets: expected error for missing file")
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2023, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package axon
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/emer/emergent/etime"
+	"github.com/emer/emergent/timer"
+)
+
+// BenchResult has the results of a Benchmark run, with the overall
+// throughput rates and the time spent in each function.
+type BenchResult struct {
+	GPU            bool               `desc:"whether the network was running on the GPU"`
+	Threads        string             `desc:"description of the threads used on the CPU"`
+	Trials         int                `desc:"number of trials run"`
+	Cycles         int                `desc:"total number of cycles run"`
+	Neurons        int                `desc:"number of neurons in the network"`
+	Synapses       int                `desc:"number of synapses in the network"`
+	Secs           float64            `desc:"total wall-clock time in seconds"`
+	CyclesPerSec   float64            `desc:"cycles per second"`
+	NeuronsPerSec  float64            `desc:"neuron updates per second: Neurons * CyclesPerSec"`
+	SynapsesPerSec float64            `desc:"synapse updates per second: Synapses * CyclesPerSec"`
+	FunSecs        map[string]float64 `desc:"seconds spent in each function, from the network FunTimes -- on the GPU, only aggregate times are available unless GPU.RecFunTimes is set"`
+}
+
+// String returns a report of the results
+func (br BenchResult) String() string {
+	var b strings.Builder
+	dev := "CPU " + br.Threads
+	if br.GPU {
+		dev = "GPU"
+	}
+	fmt.Fprintf(&b, "Benchmark: %s  Trials: %d  Cycles: %d  Neurons: %d  Synapses: %d  Secs: %.3f\n", dev, br.Trials, br.Cycles, br.Neurons, br.Synapses, br.Secs)
+	fmt.Fprintf(&b, "\tCycles/sec: %.1f  Neurons/sec: %.4g  Synapses/sec: %.4g\n", br.CyclesPerSec, br.NeuronsPerSec, br.SynapsesPerSec)
+	fnms := make([]string, 0, len(br.FunSecs))
+	for fn := range br.FunSecs {
+		fnms = append(fnms, fn)
+	}
+	sort.Strings(fnms)
+	for _, fn := range fnms {
+		fmt.Fprintf(&b, "\t%13s \t%7.3f\n", fn, br.FunSecs[fn])
+	}
+	return b.String()
+}
+
+// Benchmark runs given number of training trials on the network, each
+// with a full theta cycle of ctx.ThetaCycles cycles, including learning,
+// and returns the throughput rates and the time spent in each function
+// (using FunTimes), for consistently measuring the performance of the
+// network.  Runs on the GPU if it has been configured, otherwise on the
+// CPU using the current Threads.  The current external inputs are used
+// for all trials, so they should be applied first (see ApplyExts).
+// The state of the network is updated as in normal training,
+// and the FunTimes are reset at the start.
+func Benchmark(net *Network, ctx *Context, trials int) BenchResult {
+	recFuns := net.RecFunTimes
+	net.RecFunTimes = true
+	net.FunTimes = make(map[string]*timer.Time)
+	defer func() { net.RecFunTimes = recFuns }()
+
+	tmr := timer.Time{}
+	tmr.Start()
+	plusCyc := int(ctx.ThetaCycles) - 50
+	for trl := 0; trl < trials; trl++ {
+		net.NewState(ctx)
+		ctx.NewState(etime.Train)
+		for cyc := 0; cyc < int(ctx.ThetaCycles); cyc++ {
+			net.Cycle(ctx)
+			ctx.CycleInc()
+			if cyc == plusCyc-1 {
+				net.MinusPhase(ctx)
+				ctx.NewPhase(true)
+				net.PlusPhaseStart(ctx)
+			}
+		}
+		net.PlusPhase(ctx)
+		net.DWt(ctx)
+		net.WtFmDWt(ctx)
+	}
+	if net.GPU.On {
+		net.GPU.SyncStateFmGPU()
+	}
+	tmr.Stop()
+
+	br := BenchResult{GPU: net.GPU.On, Threads: net.Threads.String(), Trials: trials}
+	br.Cycles = trials * int(ctx.ThetaCycles)
+	br.Neurons = len(net.Neurons)
+	br.Synapses = len(net.Synapses)
+	br.Secs = tmr.TotalSecs()
+	if br.Secs > 0 {
+		br.CyclesPerSec = float64(br.Cycles) / br.Secs
+		br.NeuronsPerSec = float64(br.Neurons) * br.CyclesPerSec
+		br.SynapsesPerSec = float64(br.Synapses) * br.CyclesPerSec
+	}
+	br.FunSecs = make(map[string]float64, len(net.FunTimes))
+	for fn, ft := range net.FunTimes {
+		br.FunSecs[fn] = ft.TotalSecs()
+	}
+	return br
+}
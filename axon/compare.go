@@ -0,0 +1,206 @@
+// Copyright (c) 2023, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package axon
+
+import (
+	"log"
+	"math"
+	"sort"
+)
+
+// CompareRuns performs a two-sample, two-sided Welch's t-test comparing
+// the means of two sets of per-run values, e.g., the final performance
+// of each of several runs of two different parameter configurations.
+// Welch's test does not assume equal variances or equal numbers of runs.
+// Returns the t statistic, which is negative if the mean of a is less
+// than that of b, and the p value for the null hypothesis that the means
+// are the same.  Each set must have at least 2 values, else it returns
+// a t of 0 and a p of 1.
+func CompareRuns(a, b []float64) (tStat, pValue float64) {
+	na := float64(len(a))
+	nb := float64(len(b))
+	if na < 2 || nb < 2 {
+		log.Printf("CompareRuns: need at least 2 values in each set, have: %d, %d\n", len(a), len(b))
+		return 0, 1
+	}
+	ma, va := meanVar(a)
+	mb, vb := meanVar(b)
+	sa := va / na
+	sb := vb / nb
+	se2 := sa + sb
+	if se2 == 0 {
+		if ma == mb {
+			return 0, 1
+		}
+		return math.Copysign(math.Inf(1), ma-mb), 0
+	}
+	tStat = (ma - mb) / math.Sqrt(se2)
+	df := se2 * se2 / (sa*sa/(na-1) + sb*sb/(nb-1))
+	pValue = StudentTPValue(tStat, df)
+	return
+}
+
+// CompareRunsMannWhitney performs a two-sided Mann-Whitney U test (also
+// known as the Wilcoxon rank-sum test) comparing two sets of per-run
+// values, as a nonparametric alternative to CompareRuns that does not
+// assume normally distributed values, and is robust to outliers.
+// Returns the U statistic for a, which is the number of pairs in which
+// the a value is greater than the b value (with ties counting 1/2), and
+// the p value from the normal approximation with tie and continuity
+// corrections, which is reasonable for 8 or more values per set.
+// Each set must have at least 1 value, else it returns a U of 0 and a p of 1.
+func CompareRunsMannWhitney(a, b []float64) (uStat, pValue float64) {
+	na := len(a)
+	nb := len(b)
+	if na < 1 || nb < 1 {
+		log.Printf("CompareRunsMannWhitney: need at least 1 value in each set, have: %d, %d\n", na, nb)
+		return 0, 1
+	}
+	n := na + nb
+	type val struct {
+		v   float64
+		isA bool
+	}
+	vals := make([]val, 0, n)
+	for _, v := range a {
+		vals = append(vals, val{v, true})
+	}
+	for _, v := range b {
+		vals = append(vals, val{v, false})
+	}
+	sort.Slice(vals, func(i, j int) bool { return vals[i].v < vals[j].v })
+	rankA := 0.0
+	ties := 0.0 // sum of t^3 - t over tie groups
+	for i := 0; i < n; {
+		j := i + 1
+		for j < n && vals[j].v == vals[i].v {
+			j++
+		}
+		rank := 0.5 * float64(i+1+j) // average of ranks i+1..j
+		for k := i; k < j; k++ {
+			if vals[k].isA {
+				rankA += rank
+			}
+		}
+		t := float64(j - i)
+		ties += t*t*t - t
+		i = j
+	}
+	fa := float64(na)
+	fb := float64(nb)
+	fn := float64(n)
+	uStat = rankA - fa*(fa+1)/2
+	mu := fa * fb / 2
+	sig2 := fa * fb / 12 * ((fn + 1) - ties/(fn*(fn-1)))
+	if sig2 <= 0 { // all values the same
+		return uStat, 1
+	}
+	z := (math.Abs(uStat-mu) - 0.5) / math.Sqrt(sig2)
+	if z < 0 {
+		z = 0
+	}
+	pValue = math.Erfc(z / math.Sqrt2)
+	return
+}
+
+// StudentTPValue returns the two-sided p value for given t statistic
+// and degrees of freedom of the Student's t distribution, i.e., the
+// probability of a value at least as extreme as t under the null hypothesis.
+func StudentTPValue(t, df float64) float64 {
+	if df <= 0 || math.IsNaN(t) {
+		return math.NaN()
+	}
+	if math.IsInf(t, 0) {
+		return 0
+	}
+	return RegIncBeta(0.5*df, 0.5, df/(df+t*t))
+}
+
+// RegIncBeta returns the regularized incomplete beta function I_x(a, b),
+// for x in [0, 1] and a, b > 0, using the continued fraction expansion.
+func RegIncBeta(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	lga, _ := math.Lgamma(a)
+	lgb, _ := math.Lgamma(b)
+	lgab, _ := math.Lgamma(a + b)
+	bt := math.Exp(lgab - lga - lgb + a*math.Log(x) + b*math.Log(1-x))
+	if x < (a+1)/(a+b+2) {
+		return bt * betaContFrac(a, b, x) / a
+	}
+	return 1 - bt*betaContFrac(b, a, 1-x)/b
+}
+
+// betaContFrac evaluates the continued fraction for RegIncBeta,
+// using the modified Lentz method.
+func betaContFrac(a, b, x float64) float64 {
+	const (
+		maxIter = 300
+		eps     = 1.0e-14
+		tiny    = 1.0e-300
+	)
+	qab := a + b
+	qap := a + 1
+	qam := a - 1
+	c := 1.0
+	d := 1 - qab*x/qap
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m <= maxIter; m++ {
+		fm := float64(m)
+		m2 := 2 * fm
+		aa := fm * (b - fm) * x / ((qam + m2) * (a + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+		aa = -(a + fm) * (qab + fm) * x / ((a + m2) * (qap + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < eps {
+			break
+		}
+	}
+	return h
+}
+
+// meanVar returns the mean and the unbiased (n-1) sample variance of vals.
+func meanVar(vals []float64) (mean, vr float64) {
+	n := float64(len(vals))
+	for _, v := range vals {
+		mean += v
+	}
+	mean /= n
+	for _, v := range vals {
+		d := v - mean
+		vr += d * d
+	}
+	if n > 1 {
+		vr /= n - 1
+	}
+	return
+}
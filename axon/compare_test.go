@@ -0,0 +1,80 @@
+// Copyright (c) 2023, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package axon
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStudentTPValue(t *testing.T) {
+	for _, tv := range []float64{0.5, 1, 2.5, 10} {
+		// df = 1 is the Cauchy distribution
+		assert.InDelta(t, 1-2/math.Pi*math.Atan(tv), StudentTPValue(tv, 1), 1.0e-9)
+		assert.InDelta(t, 1-2/math.Pi*math.Atan(tv), StudentTPValue(-tv, 1), 1.0e-9)
+		// df = 2 has a closed form
+		assert.InDelta(t, 1-tv/math.Sqrt(2+tv*tv), StudentTPValue(tv, 2), 1.0e-9)
+	}
+	// large df approaches the normal distribution
+	assert.InDelta(t, math.Erfc(1.96/math.Sqrt2), StudentTPValue(1.96, 1.0e6), 1.0e-5)
+	assert.Equal(t, 1.0, StudentTPValue(0, 10))
+}
+
+func TestCompareRuns(t *testing.T) {
+	// known separation: b is a shifted by 2, with the same spread
+	a := []float64{0.9, 1.1, 1.0, 0.8, 1.2, 1.05, 0.95, 1.15, 0.85, 1.0}
+	b := make([]float64, len(a))
+	for i, v := range a {
+		b[i] = v + 2
+	}
+	tStat, p := CompareRuns(a, b)
+	assert.Less(t, tStat, -10.0)
+	assert.Less(t, p, 1.0e-6)
+	tStat2, p2 := CompareRuns(b, a)
+	assert.InDelta(t, -tStat, tStat2, 1.0e-9)
+	assert.InDelta(t, p, p2, 1.0e-12)
+
+	// interleaved samples: no separation
+	c := []float64{0.92, 1.08, 1.02, 0.82, 1.18, 1.03, 0.97, 1.12, 0.88, 0.99}
+	_, p = CompareRuns(a, c)
+	assert.Greater(t, p, 0.5)
+
+	// Welch test with unequal variances
+	tStat, p = CompareRuns([]float64{1, 2, 3, 4, 5}, []float64{2, 4, 6, 8, 10})
+	assert.InDelta(t, -3/math.Sqrt(2.5), tStat, 1.0e-9)
+	assert.InDelta(t, 0.107, p, 0.002)
+
+	tStat, p = CompareRuns([]float64{1}, b)
+	assert.Equal(t, 0.0, tStat)
+	assert.Equal(t, 1.0, p)
+}
+
+func TestCompareRunsMannWhitney(t *testing.T) {
+	a := []float64{0.9, 1.1, 1.0, 0.8, 1.2, 1.05, 0.95, 1.15, 0.85, 1.0}
+	b := make([]float64, len(a))
+	for i, v := range a {
+		b[i] = v + 2
+	}
+	u, p := CompareRunsMannWhitney(a, b)
+	assert.Equal(t, 0.0, u) // no a value exceeds any b value
+	assert.Less(t, p, 0.001)
+	u, _ = CompareRunsMannWhitney(b, a)
+	assert.Equal(t, float64(len(a)*len(b)), u)
+
+	// an outlier does not matter for ranks
+	bo := append([]float64{}, b...)
+	bo[0] = 1000
+	_, po := CompareRunsMannWhitney(a, bo)
+	assert.InDelta(t, p, po, 1.0e-12)
+
+	u, p = CompareRunsMannWhitney(a, a)
+	assert.Equal(t, float64(len(a)*len(a))/2, u)
+	assert.Equal(t, 1.0, p)
+
+	_, p = CompareRunsMannWhitney([]float64{1, 1, 1}, []float64{1, 1})
+	assert.Equal(t, 1.0, p)
+}
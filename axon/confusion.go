@@ -0,0 +1,84 @@
+// Copyright (c) 2023, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package axon
+
+import (
+	"log"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// ConfusionMatrix accumulates counts of predicted vs. target classes
+// for classification outputs, e.g., using Layer.ClassifyWinner for the
+// predicted class.  Counts are indexed as [Targ][Pred].
+type ConfusionMatrix struct {
+	NClasses int     `desc:"number of classes"`
+	Counts   []int64 `desc:"[NClasses][NClasses] counts, indexed as [Targ][Pred]"`
+}
+
+// Init initializes the matrix for given number of classes,
+// with all counts set to 0.
+func (cm *ConfusionMatrix) Init(nClasses int) {
+	cm.NClasses = nClasses
+	cm.Counts = make([]int64, nClasses*nClasses)
+}
+
+// Reset resets all counts to 0.
+func (cm *ConfusionMatrix) Reset() {
+	for i := range cm.Counts {
+		cm.Counts[i] = 0
+	}
+}
+
+// Incr increments the count for given predicted and target classes.
+// Out-of-range classes (e.g., -1 for no winner) are ignored, with a message.
+func (cm *ConfusionMatrix) Incr(pred, targ int) {
+	if pred < 0 || pred >= cm.NClasses || targ < 0 || targ >= cm.NClasses {
+		log.Printf("ConfusionMatrix Incr: pred: %d or targ: %d out of range for NClasses: %d\n", pred, targ, cm.NClasses)
+		return
+	}
+	cm.Counts[targ*cm.NClasses+pred]++
+}
+
+// Count returns the count for given predicted and target classes.
+func (cm *ConfusionMatrix) Count(pred, targ int) int64 {
+	return cm.Counts[targ*cm.NClasses+pred]
+}
+
+// ToTable returns the matrix as a table with one row per target class,
+// with columns: Targ = target class, N = total count for the target class,
+// and Pred = tensor of counts for each predicted class, along with
+// Prop = the proportion of the target class predicted as each class.
+func (cm *ConfusionMatrix) ToTable() *etable.Table {
+	nc := cm.NClasses
+	dt := &etable.Table{}
+	dt.SetMetaData("name", "ConfusionMatrix")
+	dt.SetMetaData("desc", "confusion matrix of predicted vs. target classes")
+	dt.SetFromSchema(etable.Schema{
+		{Name: "Targ", Type: etensor.INT64, CellShape: nil, DimNames: nil},
+		{Name: "N", Type: etensor.INT64, CellShape: nil, DimNames: nil},
+		{Name: "Pred", Type: etensor.INT64, CellShape: []int{nc}, DimNames: []string{"Pred"}},
+		{Name: "Prop", Type: etensor.FLOAT64, CellShape: []int{nc}, DimNames: []string{"Pred"}},
+	}, nc)
+	preds := dt.ColByName("Pred").(*etensor.Int64)
+	props := dt.ColByName("Prop").(*etensor.Float64)
+	for ti := 0; ti < nc; ti++ {
+		n := int64(0)
+		for pi := 0; pi < nc; pi++ {
+			c := cm.Count(pi, ti)
+			preds.Values[ti*nc+pi] = c
+			n += c
+		}
+		if n > 0 {
+			for pi := 0; pi < nc; pi++ {
+				props.Values[ti*nc+pi] = float64(cm.Count(pi, ti)) / float64(n)
+			}
+		}
+		dt.SetCellFloat("Targ", ti, float64(ti))
+		dt.SetCellFloat("N", ti, float64(n))
+	}
+	return dt
+}
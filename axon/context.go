@@ -73,8 +73,15 @@ func (ctx *Context) NewState(mode etime.Modes) {
 	}
 }
 
-// NewPhase resets PhaseCycle = 0 and sets the plus phase as specified
+// NewPhase resets PhaseCycle = 0 and sets the plus phase as specified,
+// updating the Phase counter: a minus phase is phase 0, and each plus
+// phase increments it (i.e., 1 for the standard minus-plus phases).
 func (ctx *Context) NewPhase(plusPhase bool) {
+	if plusPhase {
+		ctx.Phase++
+	} else {
+		ctx.Phase = 0
+	}
 	ctx.PhaseCycle = 0
 	ctx.PlusPhase.SetBool(plusPhase)
 }
@@ -111,8 +118,19 @@ func (ctx *Context) LHbDipResetFmSum() {
 
 //gosl: end context
 
-// Reset resets the counters all back to zero
+// Reset resets the counters all back to zero, along with the
+// neuromodulatory state and the PVLV drives and USs,
+// by calling ResetTiming, ResetNeuroMod, and ResetPVLVDrives.
 func (ctx *Context) Reset() {
+	ctx.ResetTiming()
+	ctx.ResetNeuroMod()
+	ctx.ResetPVLVDrives()
+}
+
+// ResetTiming resets the phase, cycle, time and trial counters
+// and the random number counter back to zero, leaving the
+// neuromodulatory and PVLV state intact.
+func (ctx *Context) ResetTiming() {
 	ctx.Phase = 0
 	ctx.PlusPhase.SetBool(false)
 	ctx.PhaseCycle = 0
@@ -126,9 +144,23 @@ func (ctx *Context) Reset() {
 		ctx.Defaults()
 	}
 	ctx.RandCtr.Reset()
+}
+
+// ResetNeuroMod resets the global neuromodulatory state (reward, DA, ACh etc)
+// back to zero.
+func (ctx *Context) ResetNeuroMod() {
 	ctx.NeuroMod.Init()
 }
 
+// ResetPVLVDrives resets the PVLV Drives to their baseline levels,
+// and the USs and Effort back to zero.  The learned VSPatch
+// expectations and other PVLV state are not affected.
+func (ctx *Context) ResetPVLVDrives() {
+	ctx.PVLV.Drive.ToBaseline()
+	ctx.PVLV.InitUS()
+	ctx.PVLV.Effort.Reset()
+}
+
 // NewContext returns a new Time struct with default parameters
 func NewContext() *Context {
 	ctx := &Context{}
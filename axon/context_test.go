@@ -0,0 +1,209 @@
+// Copyright (c) 2023, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package axon
+
+import (
+	"testing"
+
+	"github.com/emer/emergent/etime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextReset(t *testing.T) {
+	// returns a context with state in all the different domains
+	setup := func() *Context {
+		ctx := NewContext()
+		ctx.PVLV.Drive.NActive = 2
+		ctx.PVLV.Drive.Base.Set(0, 0.5)
+		ctx.NewState(etime.Train)
+		for cyc := 0; cyc < 10; cyc++ {
+			ctx.CycleInc()
+		}
+		ctx.NeuroMod.SetRew(1, true)
+		ctx.NeuroMod.DA = 0.5
+		ctx.PVLV.SetDrive(0, 0.9)
+		ctx.PVLV.SetPosUS(0, 1)
+		ctx.PVLV.Effort.AddEffort(2)
+		return ctx
+	}
+	timingReset := func(ctx *Context) bool {
+		return ctx.Cycle == 0 && ctx.CyclesTotal == 0 && ctx.Time == 0 && ctx.TrialsTotal == 0
+	}
+	neuroModReset := func(ctx *Context) bool {
+		return ctx.NeuroMod.Rew == 0 && ctx.NeuroMod.HasRew.IsFalse() && ctx.NeuroMod.DA == 0
+	}
+	drivesReset := func(ctx *Context) bool {
+		return ctx.PVLV.Drive.Drives.Get(0) == 0.5 && ctx.PVLV.USpos.Get(0) == 0 && ctx.PVLV.Effort.Raw == 0
+	}
+
+	ctx := setup()
+	assert.False(t, timingReset(ctx))
+	assert.False(t, neuroModReset(ctx))
+	assert.False(t, drivesReset(ctx))
+
+	ctx.ResetTiming()
+	assert.True(t, timingReset(ctx))
+	assert.False(t, neuroModReset(ctx))
+	assert.False(t, drivesReset(ctx))
+
+	ctx = setup()
+	ctx.ResetNeuroMod()
+	assert.False(t, timingReset(ctx))
+	assert.True(t, neuroModReset(ctx))
+	assert.False(t, drivesReset(ctx))
+
+	ctx = setup()
+	ctx.ResetPVLVDrives()
+	assert.False(t, timingReset(ctx))
+	assert.False(t, neuroModReset(ctx))
+	assert.True(t, drivesReset(ctx))
+
+	ctx = setup()
+	ctx.Reset()
+	assert.True(t, timingReset(ctx))
+	assert.True(t, neuroModReset(ctx))
+	assert.True(t, drivesReset(ctx))
+}
+
+func TestSetMixedUS(t *testing.T) {
+	ctx := NewContext()
+	pv := &ctx.PVLV
+	pv.Drive.NActive = 2
+	pv.Drive.NNegUSs = 2
+	pv.SetDrive(0, 1)
+	pv.Effort.Reset()
+	assert.Equal(t, float32(0), pv.VTA.PVnegDA) // default off
+	pv.VTA.PVnegDA = 1
+
+	mixedDA := func(posMag, negMag float32) float32 {
+		pv.InitUS()
+		pv.SetMixedUS(0, posMag, 1, negMag)
+		return pv.DA(0)
+	}
+	assert.InDelta(t, 1, mixedDA(1, 0), 1.0e-6)
+	assert.InDelta(t, -0.5, mixedDA(0, 0.5), 1.0e-6)
+	assert.InDelta(t, 0.6, mixedDA(1, 0.4), 1.0e-6)
+	assert.InDelta(t, -0.5, mixedDA(0.3, 0.8), 1.0e-6)
+	assert.Equal(t, float32(0.3), pv.USpos.Get(0))
+	assert.Equal(t, float32(0.8), pv.USneg.Get(1))
+	assert.True(t, pv.HasPosUS())
+	assert.True(t, pv.HasNegUS())
+
+	mixedDA(1, 0)
+	assert.False(t, pv.HasNegUS())
+
+	// balanced outcome nets out
+	assert.InDelta(t, 0, mixedDA(0.5, 0.5), 1.0e-6)
+
+	pv.VTA.Gain.PVneg = 0.5
+	assert.InDelta(t, 0.8, mixedDA(1, 0.4), 1.0e-6)
+
+	// default has no effect of negative USs on DA
+	pv.VTA.PVnegDA = 0
+	assert.InDelta(t, 1, mixedDA(1, 0.4), 1.0e-6)
+	assert.InDelta(t, 0, mixedDA(0, 0.5), 1.0e-6)
+}
+
+func TestOnPhaseStart(t *testing.T) {
+	net := createNetwork([]int{2, 2}, t)
+	ctx := NewContext()
+	var minusCycs, plusCycs []int32
+	plusOk := true
+	net.OnPhaseStart(0, func(ctx *Context) {
+		minusCycs = append(minusCycs, ctx.Cycle)
+	})
+	net.OnPhaseStart(1, func(ctx *Context) {
+		plusCycs = append(plusCycs, ctx.Cycle)
+		plusOk = plusOk && ctx.PlusPhase.IsTrue() && ctx.PhaseCycle == 0
+	})
+	runTrial := func(ctx *Context) {
+		net.NewState(ctx)
+		ctx.NewState(etime.Train)
+		ctx.NewPhase(false) // as in looper: no effect on the calls
+		for cyc := 0; cyc < 200; cyc++ {
+			net.Cycle(ctx)
+			ctx.CycleInc()
+			if cyc == 149 {
+				net.MinusPhase(ctx)
+				ctx.NewPhase(true)
+				net.PlusPhaseStart(ctx)
+			}
+		}
+		net.PlusPhase(ctx)
+	}
+	for trl := 0; trl < 2; trl++ {
+		runTrial(ctx)
+	}
+	assert.Equal(t, []int32{0, 0}, minusCycs)
+	assert.Equal(t, []int32{150, 150}, plusCycs)
+	assert.True(t, plusOk)
+	assert.Equal(t, int32(1), ctx.Phase)
+
+	// functions belong to the network, so a copied Context still gets them
+	cctx := *ctx
+	runTrial(&cctx)
+	assert.Equal(t, 3, len(minusCycs))
+	assert.Equal(t, 3, len(plusCycs))
+
+	net.ClearPhaseFuns()
+	runTrial(ctx)
+	assert.Equal(t, 3, len(minusCycs))
+	assert.Equal(t, 3, len(plusCycs))
+}
+
+func TestRecordGating(t *testing.T) {
+	ctx := NewContext()
+	pv := &ctx.PVLV
+	pv.Drive.NActive = 3
+	pv.SetDrive(1, 1)
+
+	var glog GatingLog
+	// trial 0: gating, no outcome
+	pv.VSMatrix.JustGated.SetBool(true)
+	pv.VSMatrix.HasGated.SetBool(true)
+	ctx.NeuroMod.DA = 0.2
+	glog = append(glog, ctx.RecordGating())
+
+	// trial 1: rewarded outcome
+	ctx.TrialsTotal++
+	pv.VSMatrix.JustGated.SetBool(false)
+	pv.SetPosUS(1, 0.8)
+	ctx.NeuroMod.HasRew.SetBool(true)
+	ctx.NeuroMod.Rew = 0.8
+	ctx.NeuroMod.RewPred = 0.3
+	ctx.NeuroMod.DA = 0.5
+	ctx.NeuroMod.ACh = 1
+	glog = append(glog, ctx.RecordGating())
+
+	gr := glog[0]
+	assert.Equal(t, "Train", gr.Mode)
+	assert.True(t, gr.JustGated)
+	assert.True(t, gr.HasGated)
+	assert.Equal(t, int32(-1), gr.PosUS)
+	assert.False(t, gr.HasRew)
+	assert.Equal(t, float32(0.2), gr.DA)
+
+	gr = glog[1]
+	assert.Equal(t, int32(1), gr.TrialsTotal)
+	assert.False(t, gr.JustGated)
+	assert.True(t, gr.HasGated)
+	assert.Equal(t, int32(1), gr.PosUS)
+	assert.Equal(t, pv.PosPV(), gr.PosPV)
+	assert.True(t, gr.HasRew)
+	assert.Equal(t, float32(0.8), gr.Rew)
+	assert.Equal(t, float32(0.3), gr.RewPred)
+	assert.Equal(t, float32(0.5), gr.DA)
+	assert.Equal(t, float32(1), gr.ACh)
+
+	dt := glog.ToTable()
+	assert.Equal(t, 2, dt.Rows)
+	assert.Equal(t, "Train", dt.CellString("Mode", 1))
+	assert.Equal(t, 1.0, dt.CellFloat("JustGated", 0))
+	assert.Equal(t, 0.0, dt.CellFloat("JustGated", 1))
+	assert.Equal(t, -1.0, dt.CellFloat("PosUS", 0))
+	assert.Equal(t, 1.0, dt.CellFloat("PosUS", 1))
+	assert.Equal(t, 1.0, dt.CellFloat("HasRew", 1))
+	assert.InDelta(t, 0.5, dt.CellFloat("DA", 1), 1.0e-6)
+}
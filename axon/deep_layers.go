@@ -5,18 +5,48 @@
 package axon
 
 import (
+	"sort"
+
+	"github.com/goki/ki/kit"
 	"github.com/goki/mat32"
 )
 
+//go:generate stringer -type=BurstThrModes
+
+var KiT_BurstThrModes = kit.Enums.AddEnum(BurstThrModesN, kit.NotBitFlag, nil)
+
+func (ev BurstThrModes) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *BurstThrModes) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
 //gosl: start deep_layers
 
+// BurstThrModes are ways of computing the threshold on CaSpkP
+// for the 5IB Burst activation in BurstParams.
+type BurstThrModes int32
+
+const (
+	// BurstThrAvgMax uses the MAX of the ThrRel relative distance between
+	// the layer average and maximum CaSpkP, and the ThrAbs absolute threshold.
+	BurstThrAvgMax BurstThrModes = iota
+
+	// BurstThrAbs uses the fixed ThrAbs absolute threshold.
+	BurstThrAbs
+
+	// BurstThrPctile uses the ThrPctile percentile of CaSpkP values within
+	// each pool, computed at the start of each plus phase, so that a roughly
+	// constant proportion of neurons burst.
+	BurstThrPctile
+
+	BurstThrModesN
+)
+
 // BurstParams determine how the 5IB Burst activation is computed from
 // CaSpkP integrated spiking values in Super layers -- thresholded.
 type BurstParams struct {
-	ThrRel float32 `max:"1" def:"0.1" desc:"Relative component of threshold on superficial activation value, below which it does not drive Burst (and above which, Burst = CaSpkP).  This is the distance between the average and maximum activation values within layer (e.g., 0 = average, 1 = max).  Overall effective threshold is MAX of relative and absolute thresholds."`
-	ThrAbs float32 `min:"0" max:"1" def:"0.1" desc:"Absolute component of threshold on superficial activation value, below which it does not drive Burst (and above which, Burst = CaSpkP).  Overall effective threshold is MAX of relative and absolute thresholds."`
-
-	pad, pad1 float32
+	ThrRel    float32       `viewif:"ThrMode=BurstThrAvgMax" max:"1" def:"0.1" desc:"Relative component of threshold on superficial activation value, below which it does not drive Burst (and above which, Burst = CaSpkP).  This is the distance between the average and maximum activation values within layer (e.g., 0 = average, 1 = max).  Overall effective threshold is MAX of relative and absolute thresholds."`
+	ThrAbs    float32       `min:"0" max:"1" def:"0.1" desc:"Absolute component of threshold on superficial activation value, below which it does not drive Burst (and above which, Burst = CaSpkP).  Overall effective threshold is MAX of relative and absolute thresholds for BurstThrAvgMax, and this is the only threshold for BurstThrAbs."`
+	ThrMode   BurstThrModes `desc:"how the burst threshold is computed"`
+	ThrPctile float32       `viewif:"ThrMode=BurstThrPctile" min:"0" max:"1" def:"0.8" desc:"percentile of CaSpkP values within each pool used as the threshold for BurstThrPctile, e.g., 0.8 means that roughly the top 20% of neurons burst"`
 }
 
 func (bp *BurstParams) Update() {
@@ -25,6 +55,8 @@ func (bp *BurstParams) Update() {
 func (bp *BurstParams) Defaults() {
 	bp.ThrRel = 0.1
 	bp.ThrAbs = 0.1
+	bp.ThrMode = BurstThrAvgMax
+	bp.ThrPctile = 0.8
 }
 
 // ThrFmAvgMax returns threshold from average and maximum values
@@ -34,6 +66,19 @@ func (bp *BurstParams) ThrFmAvgMax(avg, mx float32) float32 {
 	return thr
 }
 
+// Thr returns the burst threshold according to the ThrMode, given the
+// layer average and maximum CaSpkP values for BurstThrAvgMax, and the
+// pool percentile threshold (Pool.BurstThr) for BurstThrPctile.
+func (bp *BurstParams) Thr(avg, mx, pctile float32) float32 {
+	switch bp.ThrMode {
+	case BurstThrAbs:
+		return bp.ThrAbs
+	case BurstThrPctile:
+		return pctile
+	}
+	return bp.ThrFmAvgMax(avg, mx)
+}
+
 // CTParams control the CT corticothalamic neuron special behavior
 type CTParams struct {
 	GeGain   float32 `def:"0.8,1" desc:"gain factor for context excitatory input, which is constant as compared to the spiking input from other projections, so it must be downscaled accordingly.  This can make a difference and may need to be scaled up or down."`
@@ -173,3 +218,29 @@ func (ly *LayerParams) PulvDefaults() {
 func (ly *Layer) PulvPostBuild() {
 	ly.Params.Pulv.DriveLayIdx = ly.BuildConfigFindLayer("DriveLayName", true)
 }
+
+// BurstThrFmPctile computes the Pool.BurstThr threshold for each pool,
+// as the Burst.ThrPctile percentile of the current CaSpkP values of the
+// neurons in the pool, for Burst.ThrMode = BurstThrPctile.
+// Called at the start of the plus phase.
+func (ly *Layer) BurstThrFmPctile() {
+	var vals []float32
+	for pi := range ly.Pools {
+		pl := &ly.Pools[pi]
+		vals = vals[:0]
+		for ni := pl.StIdx; ni < pl.EdIdx; ni++ {
+			nrn := &ly.Neurons[ni]
+			if nrn.IsOff() {
+				continue
+			}
+			vals = append(vals, nrn.CaSpkP)
+		}
+		pl.BurstThr = 0
+		if len(vals) == 0 {
+			continue
+		}
+		sort.Slice(vals, func(i, j int) bool { return vals[i] < vals[j] })
+		idx := int(ly.Params.Burst.ThrPctile*float32(len(vals)-1) + 0.5)
+		pl.BurstThr = vals[idx]
+	}
+}
@@ -188,9 +188,12 @@ func (nt *Network) AddPTMaintLayer4D(name string, nPoolsY, nPoolsX, nNeurY, nNeu
 }
 
 // ConnectPTMaintSelf adds a Self (Lateral) projection within a PTMaintLayer,
-// which supports active maintenance, with a class of PTSelfMaint
+// which supports active maintenance, with classes of SelfMaint and PTSelfMaint.
+// Uses the SelfMaint defaults with a PrjnScale.Abs of 2 (see Layer.AddSelfMaint).
 func (nt *Network) ConnectPTMaintSelf(ly *Layer, pat prjn.Pattern) *Prjn {
-	return nt.LateralConnectLayer(ly, pat).SetClass("PTSelfMaint").(AxonPrjn).AsAxon()
+	pj := ly.AddSelfMaint(pat, 2)
+	pj.SetClass(pj.Cls + " PTSelfMaint")
+	return pj
 }
 
 // AddPTNotMaintLayer adds a PTNotMaintLayer of given size, for given
@@ -242,7 +245,7 @@ func (nt *Network) AddPTMaintThalForSuper(super, ct *Layer, suffix string, super
 	sthal.SetClass("SuperToThal")
 	thals.SetClass("ThalToSuper")
 	nt.ConnectLayers(super, pt, superToPT, ForwardPrjn).SetClass("SuperToPT")
-	nt.LateralConnectLayer(pt, ptSelf).SetClass("PTSelfMaint")
+	nt.ConnectPTMaintSelf(pt, ptSelf)
 	nt.ConnectLayers(ct, thal, ctToThal, ForwardPrjn).SetClass("CTtoThal")
 	return
 }
@@ -263,9 +266,12 @@ func (nt *Network) AddPTPredLayer4D(name string, nPoolsY, nPoolsX, nNeurY, nNeur
 }
 
 // ConnectPTPredSelf adds a Self (Lateral) projection within a PTPredLayer,
-// which supports active maintenance, with a class of PTSelfMaint
+// which supports active maintenance, with classes of SelfMaint and PTSelfMaint.
+// Uses the SelfMaint defaults with a PrjnScale.Abs of 2 (see Layer.AddSelfMaint).
 func (nt *Network) ConnectPTPredSelf(ly *Layer, pat prjn.Pattern) *Prjn {
-	return nt.LateralConnectLayer(ly, pat).SetClass("PTSelfMaint").(AxonPrjn).AsAxon()
+	pj := ly.AddSelfMaint(pat, 2)
+	pj.SetClass(pj.Cls + " PTSelfMaint")
+	return pj
 }
 
 // ConnectPTPredToPulv connects PTPred with given Pulv: PTPred -> Pulv is class PTPredToPulv,
@@ -0,0 +1,138 @@
+// Copyright (c) 2023, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package axon
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+
+	"github.com/emer/etable/etensor"
+)
+
+// NetDesc is a structural description of a network, listing its layers
+// and projections, as produced by Network.Describe.  It can be used by
+// external tools to reconstruct or visualize the architecture, or to diff
+// architectures, complementing the weights files.
+type NetDesc struct {
+	Name   string      `desc:"name of the network"`
+	Layers []LayerDesc `desc:"all layers in the network, in order"`
+	Prjns  []PrjnDesc  `desc:"all projections in the network, in order of receiving layer"`
+}
+
+// LayerDesc describes the structure of a layer, in NetDesc.
+type LayerDesc struct {
+	Name  string `desc:"name of the layer"`
+	Type  string `desc:"layer type, from LayerTypes"`
+	Shape []int  `desc:"shape of the layer"`
+	Class string `desc:"user-assigned class name(s), space separated"`
+	Off   bool   `json:",omitempty" desc:"layer is turned off"`
+}
+
+// PrjnDesc describes the structure of a projection, in NetDesc.
+type PrjnDesc struct {
+	Send    string `desc:"name of the sending layer"`
+	Recv    string `desc:"name of the receiving layer"`
+	Type    string `desc:"projection type, from PrjnTypes"`
+	Pattern string `desc:"name of the connectivity pattern, e.g., Full, OneToOne"`
+	Class   string `desc:"user-assigned class name(s), space separated"`
+	Off     bool   `json:",omitempty" desc:"projection is turned off"`
+}
+
+// NetDesc returns the structural description of the network,
+// which is encoded as JSON by Describe.
+func (nt *Network) NetDesc() *NetDesc {
+	nd := &NetDesc{Name: nt.Nm}
+	for _, ly := range nt.Layers {
+		nd.Layers = append(nd.Layers, LayerDesc{Name: ly.Nm, Type: ly.LayerType().String(), Shape: ly.Shp.Shp, Class: ly.Cls, Off: ly.Off})
+		for _, pj := range ly.RcvPrjns {
+			pat := ""
+			if pj.Pat != nil {
+				pat = pj.Pat.Name()
+			}
+			nd.Prjns = append(nd.Prjns, PrjnDesc{Send: pj.Send.Nm, Recv: ly.Nm, Type: pj.PrjnType().String(), Pattern: pat, Class: pj.Cls, Off: pj.Off})
+		}
+	}
+	return nd
+}
+
+// Describe returns a machine-readable JSON description of the network
+// architecture, listing each layer (name, type, shape, class) and each
+// projection (sending and receiving layer, type, pattern name, class),
+// as a NetDesc.  Can be called before or after Build.
+func (nt *Network) Describe() []byte {
+	b, err := json.MarshalIndent(nt.NetDesc(), "", "  ")
+	if err != nil {
+		log.Println(err)
+	}
+	return b
+}
+
+// Degree returns the average number of connections per receiving neuron
+// (convergence) and per sending neuron (divergence) in this projection,
+// from the RecvCon and SendCon connection counts.  Can be used to verify
+// that the prjn.Pattern produced the intended connectivity.
+func (pj *Prjn) Degree() (avgConvergence, avgDivergence float32) {
+	if n := len(pj.RecvCon); n > 0 {
+		sum := 0
+		for _, rc := range pj.RecvCon {
+			sum += int(rc.N)
+		}
+		avgConvergence = float32(sum) / float32(n)
+	}
+	if n := len(pj.SendCon); n > 0 {
+		sum := 0
+		for _, sc := range pj.SendCon {
+			sum += int(sc.N)
+		}
+		avgDivergence = float32(sum) / float32(n)
+	}
+	return
+}
+
+// DegreeDistribution returns histograms of the number of connections
+// per neuron, across all projections having the given class (which
+// includes the projection type name, e.g., ForwardPrjn), as a 2D tensor
+// with shape [2][MaxDegree+1], where row 0 has the number of receiving
+// neurons with each number of connections (convergence), and row 1 has
+// the number of sending neurons with each number of connections
+// (divergence).  Each projection is counted separately.
+// Must be called after Build.
+func (nt *Network) DegreeDistribution(prjnClass string) etensor.Tensor {
+	var pjs []*Prjn
+	maxN := 0
+	for _, ly := range nt.Layers {
+		for _, pj := range ly.RcvPrjns {
+			for _, pcl := range strings.Fields(pj.Class()) {
+				if pcl == prjnClass {
+					pjs = append(pjs, pj)
+					break
+				}
+			}
+		}
+	}
+	for _, pj := range pjs {
+		for _, rc := range pj.RecvCon {
+			if int(rc.N) > maxN {
+				maxN = int(rc.N)
+			}
+		}
+		for _, sc := range pj.SendCon {
+			if int(sc.N) > maxN {
+				maxN = int(sc.N)
+			}
+		}
+	}
+	hist := etensor.NewFloat32([]int{2, maxN + 1}, nil, []string{"RecvSend", "Degree"})
+	for _, pj := range pjs {
+		for _, rc := range pj.RecvCon {
+			hist.Values[int(rc.N)]++
+		}
+		for _, sc := range pj.SendCon {
+			hist.Values[maxN+1+int(sc.N)]++
+		}
+	}
+	return hist
+}
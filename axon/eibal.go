@@ -0,0 +1,70 @@
+// Copyright (c) 2023, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package axon
+
+// EIBalAcc accumulates the layer-average excitatory and inhibitory
+// conductances over trials, for characterizing the E/I balance of a layer
+// over an epoch (see Layer.EIBalanceEpoch).  The trial-level values are the
+// integrated GeInt and GiInt averages over neurons, recorded when On at the
+// end of each trial in PlusPhasePost, on the CPU, so this works the same
+// when running on the GPU.  Recording is off by default -- see
+// Layer.SetRecordEIBalance.  Reset at the start of each epoch via
+// Layer.ResetEIBalance.
+type EIBalAcc struct {
+	On    bool    `desc:"record the E/I balance at the end of each trial"`
+	N     int     `inactive:"+" desc:"number of trials accumulated since the last reset"`
+	GeSum float32 `inactive:"+" desc:"sum over trials of the average GeInt over neurons"`
+	GiSum float32 `inactive:"+" desc:"sum over trials of the average GiInt over neurons"`
+}
+
+// Reset resets the accumulated sums.
+func (eb *EIBalAcc) Reset() {
+	eb.N = 0
+	eb.GeSum = 0
+	eb.GiSum = 0
+}
+
+// Record adds the average GeInt and GiInt over given neurons to the sums, if On.
+func (eb *EIBalAcc) Record(neurs []Neuron) {
+	if !eb.On {
+		return
+	}
+	ge, gi, n := float32(0), float32(0), 0
+	for ni := range neurs {
+		nrn := &neurs[ni]
+		if nrn.IsOff() {
+			continue
+		}
+		ge += nrn.GeInt
+		gi += nrn.GiInt
+		n++
+	}
+	if n == 0 {
+		return
+	}
+	eb.GeSum += ge / float32(n)
+	eb.GiSum += gi / float32(n)
+	eb.N++
+}
+
+// Means returns the mean Ge and Gi over the accumulated trials,
+// and their ratio Ge / Gi (0 if Gi is 0).
+func (eb *EIBalAcc) Means() (meanGe, meanGi, ratio float32) {
+	if eb.N == 0 {
+		return
+	}
+	meanGe = eb.GeSum / float32(eb.N)
+	meanGi = eb.GiSum / float32(eb.N)
+	ratio = eiRatio(meanGe, meanGi)
+	return
+}
+
+// eiRatio returns ge / gi, or 0 if gi is 0.
+func eiRatio(ge, gi float32) float32 {
+	if gi == 0 {
+		return 0
+	}
+	return ge / gi
+}
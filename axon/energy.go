@@ -0,0 +1,55 @@
+// Copyright (c) 2023, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package axon
+
+// EnergyCost accumulates an estimate of the metabolic cost of activity in
+// a layer, for analyzing the tradeoff between performance and efficiency
+// (e.g., sparse coding saves energy).  The cost has two components:
+// the number of spikes, and the total synaptic conductance (Ge + Gi)
+// integrated over the trial.  When On, these are recorded at the end of
+// each trial in PlusPhasePost, on the CPU, so this works the same when
+// running on the GPU.  Recording is off by default -- see
+// Layer.SetRecordCost or Network.SetRecordCosts.  The totals accumulate
+// until reset, typically at the start of each epoch, via Layer.ResetCost
+// or Network.ResetCosts.
+type EnergyCost struct {
+	On        bool    `desc:"record the cost at the end of each trial"`
+	SpikeCost float32 `def:"1" min:"0" desc:"cost per spike"`
+	GCost     float32 `def:"0" min:"0" desc:"cost per unit of synaptic conductance (Ge + Gi) per cycle -- conductances are estimated from the integrated GeInt and GiInt values times the number of cycles in the trial"`
+	Spikes    float32 `inactive:"+" desc:"total number of spikes since the last reset"`
+	G         float32 `inactive:"+" desc:"total conductance integrated over cycles since the last reset"`
+}
+
+func (ec *EnergyCost) Defaults() {
+	ec.SpikeCost = 1
+	ec.GCost = 0
+}
+
+// Reset resets the accumulated totals.
+func (ec *EnergyCost) Reset() {
+	ec.Spikes = 0
+	ec.G = 0
+}
+
+// Record adds the spike counts and conductances of given neurons
+// over a trial of given number of cycles to the totals, if On.
+func (ec *EnergyCost) Record(neurs []Neuron, cycles int32) {
+	if !ec.On {
+		return
+	}
+	for ni := range neurs {
+		nrn := &neurs[ni]
+		if nrn.IsOff() {
+			continue
+		}
+		ec.Spikes += nrn.SpkCnt
+		ec.G += (nrn.GeInt + nrn.GiInt) * float32(cycles)
+	}
+}
+
+// Cost returns the total cost: Spikes * SpikeCost + G * GCost
+func (ec *EnergyCost) Cost() float32 {
+	return ec.Spikes*ec.SpikeCost + ec.G*ec.GCost
+}
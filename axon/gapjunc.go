@@ -0,0 +1,82 @@
+// Copyright (c) 2023, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package axon
+
+import (
+	"github.com/emer/emergent/prjn"
+)
+
+// GapJunctions implements electrical coupling among the neurons within
+// a layer via gap junctions, where each neuron receives a current of
+// Gbar times the sum of the Vm differences with each of its coupled
+// neurons every cycle, driving their Vm values together.  This is
+// CPU-only state -- see Layer.SetGapJunctions.
+type GapJunctions struct {
+	Gbar float32    `desc:"conductance of each gap junction, multiplying the Vm difference to get the coupling current"`
+	Cons [][]uint32 `view:"-" desc:"[Neurons][coupled] indexes of the neurons coupled to each neuron"`
+	Igap []float32  `view:"-" desc:"[Neurons] gap junction current for each neuron, computed each cycle in GapJuncFmVm"`
+}
+
+// On returns true if gap junctions are configured
+func (gj *GapJunctions) On() bool {
+	return gj.Gbar > 0 && gj.Igap != nil
+}
+
+// SetGapJunctions configures gap junction electrical coupling among the
+// neurons in this layer, with the coupled neurons given by the
+// connectivity of given pattern from the layer to itself, with given
+// conductance gbar per junction.  Gap junctions are symmetric, so any
+// connection in the pattern couples both neurons, and self connections
+// are ignored.  Each cycle, each neuron receives a current of gbar times
+// the sum of the Vm differences with its coupled neurons, which
+// synchronizes their activity.  A gbar of 0 turns off the coupling.
+// Only works on the CPU.
+func (ly *Layer) SetGapJunctions(pat prjn.Pattern, gbar float32) {
+	if gbar <= 0 {
+		ly.GapJunc = GapJunctions{}
+		return
+	}
+	nn := len(ly.Neurons)
+	_, _, cons := pat.Connect(&ly.Shp, &ly.Shp, true)
+	coupled := make([]bool, nn*nn)
+	for ri := 0; ri < nn; ri++ {
+		for si := 0; si < nn; si++ {
+			if si != ri && cons.Value1D(ri*nn+si) {
+				coupled[ri*nn+si] = true
+				coupled[si*nn+ri] = true
+			}
+		}
+	}
+	gj := &ly.GapJunc
+	gj.Gbar = gbar
+	gj.Cons = make([][]uint32, nn)
+	gj.Igap = make([]float32, nn)
+	for ni := 0; ni < nn; ni++ {
+		for oi := 0; oi < nn; oi++ {
+			if coupled[ni*nn+oi] {
+				gj.Cons[ni] = append(gj.Cons[ni], uint32(oi))
+			}
+		}
+	}
+}
+
+// GapJuncFmVm computes the gap junction current Igap for each neuron,
+// from the current Vm values of its coupled neurons, if SetGapJunctions
+// has been configured.  Called prior to CycleNeuron, so all neurons
+// use the Vm values from the prior cycle.
+func (ly *Layer) GapJuncFmVm() {
+	gj := &ly.GapJunc
+	if !gj.On() {
+		return
+	}
+	for ni := range ly.Neurons {
+		nrn := &ly.Neurons[ni]
+		igap := float32(0)
+		for _, oi := range gj.Cons[ni] {
+			igap += ly.Neurons[oi].Vm - nrn.Vm
+		}
+		gj.Igap[ni] = gj.Gbar * igap
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2023, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package axon
+
+import (
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// GatingRecord is a typed record of the BG gating and PVLV outcome state
+// on a given trial, as captured from the Context by RecordGating,
+// for behavioral analysis of BG / PVLV models.  Append to a GatingLog
+// on each trial, and use its ToTable method to get an etable.
+type GatingRecord struct {
+	Mode        string  `desc:"evaluation mode, e.g., Train, Test"`
+	TrialsTotal int32   `desc:"total trial count (Context.TrialsTotal)"`
+	JustGated   bool    `desc:"VSMatrix just gated on this trial"`
+	HasGated    bool    `desc:"VSMatrix has gated since the last outcome"`
+	PosUS       int32   `desc:"index of the strongest positive US present, or -1 if none"`
+	PosPV       float32 `desc:"drive-weighted positive primary value of the positive USs"`
+	NegPV       float32 `desc:"negative primary value of the negative USs"`
+	HasRew      bool    `desc:"an outcome (reward, or an expected one not received) was present"`
+	Rew         float32 `desc:"reward value"`
+	RewPred     float32 `desc:"reward prediction"`
+	DA          float32 `desc:"dopamine"`
+	ACh         float32 `desc:"acetylcholine"`
+}
+
+// RecordGating returns a GatingRecord with the current gating and outcome
+// state, which should be called at the end of the trial, after the plus
+// phase.  When running on the GPU, the Context must be synced from the GPU.
+func (ctx *Context) RecordGating() GatingRecord {
+	pp := &ctx.PVLV
+	nm := &ctx.NeuroMod
+	gr := GatingRecord{
+		Mode:        ctx.Mode.String(),
+		TrialsTotal: ctx.TrialsTotal,
+		JustGated:   pp.VSMatrix.JustGated.IsTrue(),
+		HasGated:    pp.VSMatrix.HasGated.IsTrue(),
+		PosUS:       -1,
+		PosPV:       pp.PosPV(),
+		NegPV:       pp.NegPV(),
+		HasRew:      nm.HasRew.IsTrue(),
+		Rew:         nm.Rew,
+		RewPred:     nm.RewPred,
+		DA:          nm.DA,
+		ACh:         nm.ACh,
+	}
+	maxUS := float32(0)
+	for i := int32(0); i < pp.Drive.NActive; i++ {
+		if us := pp.USpos.Get(i); us > maxUS {
+			maxUS = us
+			gr.PosUS = i
+		}
+	}
+	return gr
+}
+
+// GatingLog is a log of GatingRecords, typically one per trial.
+type GatingLog []GatingRecord
+
+// ToTable returns an etable.Table with one row per record, and a column
+// for each GatingRecord field, named the same, with bools as 0 / 1 values.
+func (gl GatingLog) ToTable() *etable.Table {
+	dt := &etable.Table{}
+	dt.SetMetaData("name", "GatingLog")
+	sch := etable.Schema{
+		{Name: "Mode", Type: etensor.STRING},
+		{Name: "TrialsTotal", Type: etensor.INT64},
+		{Name: "JustGated", Type: etensor.FLOAT64},
+		{Name: "HasGated", Type: etensor.FLOAT64},
+		{Name: "PosUS", Type: etensor.INT64},
+		{Name: "PosPV", Type: etensor.FLOAT64},
+		{Name: "NegPV", Type: etensor.FLOAT64},
+		{Name: "HasRew", Type: etensor.FLOAT64},
+		{Name: "Rew", Type: etensor.FLOAT64},
+		{Name: "RewPred", Type: etensor.FLOAT64},
+		{Name: "DA", Type: etensor.FLOAT64},
+		{Name: "ACh", Type: etensor.FLOAT64},
+	}
+	dt.SetFromSchema(sch, len(gl))
+	b2f := func(b bool) float64 {
+		if b {
+			return 1
+		}
+		return 0
+	}
+	for i := range gl {
+		gr := &gl[i]
+		dt.SetCellString("Mode", i, gr.Mode)
+		dt.SetCellFloat("TrialsTotal", i, float64(gr.TrialsTotal))
+		dt.SetCellFloat("JustGated", i, b2f(gr.JustGated))
+		dt.SetCellFloat("HasGated", i, b2f(gr.HasGated))
+		dt.SetCellFloat("PosUS", i, float64(gr.PosUS))
+		dt.SetCellFloat("PosPV", i, float64(gr.PosPV))
+		dt.SetCellFloat("NegPV", i, float64(gr.NegPV))
+		dt.SetCellFloat("HasRew", i, b2f(gr.HasRew))
+		dt.SetCellFloat("Rew", i, float64(gr.Rew))
+		dt.SetCellFloat("RewPred", i, float64(gr.RewPred))
+		dt.SetCellFloat("DA", i, float64(gr.DA))
+		dt.SetCellFloat("ACh", i, float64(gr.ACh))
+	}
+	return dt
+}
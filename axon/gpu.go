@@ -140,6 +140,12 @@ func (gp *GPU) Config(ctx *Context, net *Network) {
 	ctx.NLayers = int32(gp.Net.NLayers())
 	gp.DidBind = make(map[string]bool)
 
+	for _, pj := range gp.Net.Prjns {
+		if pj.ScaleSchedCycs != nil {
+			log.Printf("GPU.Config: Prjn %s has a SetScaleSchedule schedule, which is ignored on the GPU\n", pj.Name())
+		}
+	}
+
 	if TheGPU == nil {
 		TheGPU = vgpu.NewComputeGPU()
 		// vgpu.Debug = true
@@ -284,7 +290,8 @@ func (gp *GPU) SyncParamsToGPU() {
 	gp.SyncMemToGPU()
 }
 
-// CopyIdxsToStaging is only called when the network is built
+// CopyIdxsToStaging is only called when the network is built,
+// or rebuilt via Network.RebuildIdxs,
 // to copy the indexes specifying connectivity etc to staging from CPU.
 func (gp *GPU) CopyIdxsToStaging() {
 	if !gp.On {
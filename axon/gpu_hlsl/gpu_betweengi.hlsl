@@ -28,24 +28,21 @@
 // Set 3: external inputs
 // [[vk::binding(0, 3)]] RWStructuredBuffer<float> Exts;  // [In / Out Layers][Neurons]
 
-float BetweenLayerGiMax(float maxGi, int layIdx) {
+float BetweenLayerGiMax(in LayerParams ly, float maxGi, int layIdx) {
 	if (layIdx < 0) {
 		return maxGi;
 	}
-	float ogi = Pools[Layers[layIdx].Idxs.PoolSt].Inhib.Gi;
-	if (ogi > maxGi) {
-		maxGi = ogi;
-	}
-	return maxGi;
+	return ly.LayInhib.GiMax(maxGi, Pools[Layers[layIdx].Idxs.PoolSt].Inhib.Gi);
 }
 
 void BetweenGi2(in Context ctx, in LayerParams ly, inout Pool lpl) {
 	float maxGi = lpl.Inhib.Gi;
-	maxGi = BetweenLayerGiMax(maxGi, ly.LayInhib.Idx1);
-	maxGi = BetweenLayerGiMax(maxGi, ly.LayInhib.Idx2);
-	maxGi = BetweenLayerGiMax(maxGi, ly.LayInhib.Idx3);
-	maxGi = BetweenLayerGiMax(maxGi, ly.LayInhib.Idx4);
+	maxGi = BetweenLayerGiMax(ly, maxGi, ly.LayInhib.Idx1);
+	maxGi = BetweenLayerGiMax(ly, maxGi, ly.LayInhib.Idx2);
+	maxGi = BetweenLayerGiMax(ly, maxGi, ly.LayInhib.Idx3);
+	maxGi = BetweenLayerGiMax(ly, maxGi, ly.LayInhib.Idx4);
 	lpl.Inhib.Gi = maxGi; // our inhib is max of us and everyone in the layer pool
+	lpl.ClampGi();
 }
 
 void BetweenGi(in Context ctx, uint li, in LayerParams ly) {
@@ -47,7 +47,7 @@ void CyclePost(inout Context ctx, uint li, in LayerParams ly, inout LayerVals va
 		ly.CyclePostRSalAChLayer(ctx, vals, lay1MaxAct, lay2MaxAct, lay3MaxAct, lay4MaxAct);
 		break;
 	case RWDaLayer:
-		ly.CyclePostRWDaLayer(ctx, vals, LayVals[ly.RWDa.RWPredLayIdx]);
+		ly.CyclePostRWDaLayer(ctx, vals, LayVals[ly.RWDa.RWPredLayIdx], Layers[ly.RWDa.RWPredLayIdx].RWPred.PredFloor);
 		break;
 	case TDPredLayer:
 		ly.CyclePostTDPredLayer(ctx, vals);
@@ -33,6 +33,9 @@ void DWtSyn2(in Context ctx, in LayerParams rlay, in PrjnParams pj, uint ci, ino
 	if(pj.Learn.Learn == 0) {
 		return;
 	}
+	if(pj.Learn.SkipDWt(sn.CaSpkD, rn.CaSpkD, rlay.Learn.CaLrn.UpdtThr)) {
+		return;
+	}
 	bool isTarget = (rlay.Act.Clamp.IsTarget == 1);
 
 	pj.DWtSyn(ctx, sy, sn, rn, Pools[rlay.Idxs.PoolSt], Pools[rn.SubPoolN], isTarget);
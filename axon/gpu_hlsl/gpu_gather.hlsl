@@ -66,9 +66,15 @@ void GatherSpikesPrjn(in Context ctx, in PrjnParams pj, in LayerParams ly, uint
 	// uint bi = pj.Idxs.GBufSt + pj.Com.WriteIdx(ni, ctx.CycleTot-1); // -1 = prior time step
 	// RecvSpikes(ctx, pj, ly, ni, GBuf[bi]); // writes to gbuf
 	
-	uint bi = pj.Idxs.GBufSt + pj.Com.ReadIdx(ni, ctx.CycleTot, pj.Idxs.RecvNeurN);
+	uint rbi = pj.Com.ReadIdx(ni, ctx.CycleTot, pj.Idxs.RecvNeurN);
+	uint bi = pj.Idxs.GBufSt + rbi;
 	float gRaw = pj.Com.FloatFromGBuf(GBuf[bi]);
 	GBuf[bi] = 0;
+	if (pj.SWt.AllowNegative == 1) {
+		uint nbi = pj.Idxs.GBufSt + pj.Com.NegIdx(rbi, pj.Idxs.RecvNeurN);
+		gRaw -= pj.Com.FloatFromGBuf(GBuf[nbi]);
+		GBuf[nbi] = 0;
+	}
 	float gSyn = GSyns[pj.Idxs.GSynSt + ni];
 	pj.GatherSpikes(ctx, ly, ni, nrn, gRaw, gSyn); // integrates into G*Raw; gSyn modified in fun
 	GSyns[pj.Idxs.GSynSt + ni] = gSyn;	
@@ -94,6 +100,7 @@ void GatherSpikes2(in Context ctx, LayerParams ly, uint nin, inout Neuron nrn) {
 	for (uint pi = 0; pi < ly.Idxs.RecvN; pi++) {
 		GatherSpikesPrjn(ctx, Prjns[ly.Idxs.RecvSt + pi], ly, ni, nrn);
 	}
+	ly.GatherSpikesAlpha(nrn);
 	
 	// Note: Interlocked* methods can ONLY operate directly on the
 	// RWStructuredBuffer items, not on arg variables.  Furthermore,
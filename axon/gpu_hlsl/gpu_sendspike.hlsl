@@ -36,8 +36,13 @@
 // [[vk::binding(1, 3)]] StructuredBuffer<uint> Spikers;  // [[Neurons]] -- indexes of those that spiked
 
 void SendSpikeSyn(in Context ctx, in PrjnParams pj, in Synapse sy, in float sendVal, in uint recvNeurSt) {
-	uint bi = pj.Idxs.GBufSt + pj.Com.WriteIdx(sy.RecvIdx - recvNeurSt, ctx.CycleTot, pj.Idxs.RecvNeurN);
-	InterlockedAdd(GBuf[bi], int(sendVal * sy.Wt));
+	uint bi = pj.Com.WriteIdx(sy.RecvIdx - recvNeurSt, ctx.CycleTot, pj.Idxs.RecvNeurN);
+	int sv = int(sendVal * sy.Wt);
+	if (pj.SWt.AllowNegative == 1 && sv < 0) {
+		InterlockedAdd(GBuf[pj.Idxs.GBufSt + pj.Com.NegIdx(bi, pj.Idxs.RecvNeurN)], -sv);
+	} else {
+		InterlockedAdd(GBuf[pj.Idxs.GBufSt + bi], sv);
+	}
 }
 
 void SendSpikePrjn(in Context ctx, in PrjnParams pj, uint sendIdx, in Neuron sn) {
@@ -47,6 +52,11 @@ void SendSpikePrjn(in Context ctx, in PrjnParams pj, uint sendIdx, in Neuron sn)
 			return;
 		}
 		sendVal *= sn.Burst;
+	} else if (pj.PrjnType == IdentityPrjn) {
+		if (sn.Act == 0) {
+			return;
+		}
+		sendVal *= sn.Act;
 	} else {
 		if (sn.Spike == 0) {
 			return;
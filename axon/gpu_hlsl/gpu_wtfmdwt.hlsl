@@ -30,7 +30,7 @@
 // [[vk::binding(0, 3)]] RWStructuredBuffer<float> Exts;  // [In / Out Layers][Neurons]
 
 void WtFmDWtSyn2(in Context ctx, in PrjnParams pj, inout Synapse sy) {
-	if(pj.Learn.Learn == 0) {
+	if(pj.Learn.Learn == 0 || pj.Learn.AccumulateDWt == 1) {
 		return;
 	}
 	pj.WtFmDWtSyn(ctx, sy);
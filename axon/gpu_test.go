@@ -0,0 +1,45 @@
+// Copyright (c) 2023, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package axon
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// gpuAlignErrs checks that given struct type, and all of the struct types
+// within it, follow the HLSL layout rules that gosl requires (as checked
+// by gosl alignsl): the total size is an even multiple of 16 bytes, all
+// fields are 32 bit, and struct fields are at 16 byte offsets.
+func gpuAlignErrs(typ reflect.Type) []string {
+	var errs []string
+	if typ.Size()%16 != 0 {
+		errs = append(errs, fmt.Sprintf("%s: size %d is not a multiple of 16", typ.Name(), typ.Size()))
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		fl := typ.Field(i)
+		if fl.Type.Kind() == reflect.Struct {
+			if fl.Offset%16 != 0 {
+				errs = append(errs, fmt.Sprintf("%s.%s: struct at offset %d is not a multiple of 16", typ.Name(), fl.Name, fl.Offset))
+			}
+			errs = append(errs, gpuAlignErrs(fl.Type)...)
+			continue
+		}
+		if fl.Type.Size() != 4 {
+			errs = append(errs, fmt.Sprintf("%s.%s: size %d is not 32 bit", typ.Name(), fl.Name, fl.Type.Size()))
+		}
+	}
+	return errs
+}
+
+func TestGPUStructAlign(t *testing.T) {
+	for _, st := range []any{LayerParams{}, PrjnParams{}, Context{}, Neuron{}, Pool{}, LayerVals{}, Synapse{}} {
+		typ := reflect.TypeOf(st)
+		assert.Empty(t, gpuAlignErrs(typ), typ.Name())
+	}
+}
@@ -6,10 +6,14 @@ package axon
 
 import (
 	"fmt"
+	"log"
+	"math/rand"
 
 	"github.com/emer/emergent/ecmd"
 	"github.com/emer/emergent/env"
+	"github.com/emer/emergent/params"
 	"github.com/emer/empi/mpi"
+	"github.com/emer/etable/etable"
 	"github.com/goki/gi/gi"
 )
 
@@ -45,6 +49,58 @@ func EnvApplyInputs(net *Network, ev env.Env) {
 	}
 }
 
+////////////////////////////////////////////////////
+// Cross-validation
+
+// KFoldSplit splits the rows of given table into k folds of (nearly)
+// equal size, after randomly permuting the rows using given seed,
+// for k-fold cross-validation (see KFoldConfigEnvs).  The folds are
+// disjoint and together cover all rows.  Returns nil if k < 2
+// or k is greater than the number of rows.
+func KFoldSplit(pats *etable.Table, k int, seed int64) []*etable.IdxView {
+	nr := pats.Rows
+	if k < 2 || k > nr {
+		log.Printf("KFoldSplit: k = %d must be between 2 and the number of rows: %d\n", k, nr)
+		return nil
+	}
+	perm := rand.New(rand.NewSource(seed)).Perm(nr)
+	folds := make([]*etable.IdxView, k)
+	st := 0
+	for fi := range folds {
+		n := nr / k
+		if fi < nr%k {
+			n++
+		}
+		ix := &etable.IdxView{Table: pats}
+		ix.Idxs = make([]int, n)
+		copy(ix.Idxs, perm[st:st+n])
+		folds[fi] = ix
+		st += n
+	}
+	return folds
+}
+
+// KFoldConfigEnvs configures the given training and testing environments
+// for given fold of the folds from KFoldSplit: the testing env uses that
+// fold, and the training env uses all of the other folds.
+// The envs must then be Init as usual.
+func KFoldConfigEnvs(folds []*etable.IdxView, fold int, trn, tst *env.FixedTable) error {
+	if fold < 0 || fold >= len(folds) {
+		err := fmt.Errorf("KFoldConfigEnvs: fold %d out of range for %d folds", fold, len(folds))
+		log.Println(err)
+		return err
+	}
+	tix := &etable.IdxView{Table: folds[fold].Table}
+	for fi, fix := range folds {
+		if fi != fold {
+			tix.Idxs = append(tix.Idxs, fix.Idxs...)
+		}
+	}
+	trn.Config(tix)
+	tst.Config(folds[fold].Clone())
+	return nil
+}
+
 /////////////////////////////////////////////
 // Weights files
 
@@ -79,3 +135,27 @@ func SaveWeightsIfArgSet(net *Network, args *ecmd.Args, ctrString, runName strin
 	}
 	return ""
 }
+
+////////////////////////////////////////////////////
+// Params files
+
+// SaveParamSets saves the given params.Sets to a JSON file, so they can be
+// edited externally and loaded back at runtime with LoadParamSets,
+// without recompiling.  Selections within sheets retain their order,
+// and map keys (sheet names, params) are sorted, so the output is stable.
+func SaveParamSets(sets params.Sets, filename string) error {
+	return sets.SaveJSON(gi.FileName(filename))
+}
+
+// LoadParamSets loads params.Sets from a JSON file saved by SaveParamSets,
+// e.g., for setting as the Params.Params in an emer.NetParams.
+// Returns an error if the file cannot be read or parsed.
+func LoadParamSets(filename string) (params.Sets, error) {
+	var sets params.Sets
+	err := sets.OpenJSON(gi.FileName(filename))
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return sets, nil
+}
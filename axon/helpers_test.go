@@ -0,0 +1,112 @@
+package axon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/emer/emergent/env"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKFoldSplit(t *testing.T) {
+	pats := generateRandomPatterns(23)
+	k := 5
+	folds := KFoldSplit(pats, k, 42)
+	assert.Equal(t, k, len(folds))
+	var all []int
+	for _, fix := range folds {
+		n := fix.Len()
+		assert.True(t, n == 4 || n == 5)
+		all = append(all, fix.Idxs...)
+	}
+	sort.Ints(all)
+	assert.Equal(t, pats.Rows, len(all)) // disjoint and covering
+	for i, ri := range all {
+		assert.Equal(t, i, ri)
+	}
+
+	same := KFoldSplit(pats, k, 42)
+	assert.Equal(t, folds[0].Idxs, same[0].Idxs)
+	assert.Nil(t, KFoldSplit(pats, 1, 42))
+	assert.Nil(t, KFoldSplit(pats, 24, 42))
+
+	trn := &env.FixedTable{}
+	tst := &env.FixedTable{}
+	assert.NoError(t, KFoldConfigEnvs(folds, 2, trn, tst))
+	assert.Equal(t, folds[2].Idxs, tst.Table.Idxs)
+	assert.Equal(t, pats.Rows-folds[2].Len(), trn.Table.Len())
+	for _, ri := range tst.Table.Idxs {
+		assert.NotContains(t, trn.Table.Idxs, ri)
+	}
+	assert.NoError(t, trn.Validate())
+	assert.Error(t, KFoldConfigEnvs(folds, k, trn, tst))
+}
+
+// writeNPY writes a version 1.0 .npy file with given dtype and shape
+// header strings, e.g., "<f4" and "(3, 2)", and given data values.
+func writeNPY(t *testing.T, fname, dtype, shape string, data any) {
+	var b bytes.Buffer
+	hdr := fmt.Sprintf("{'descr': '%s', 'fortran_order': False, 'shape': %s, }", dtype, shape)
+	for (10+len(hdr)+1)%64 != 0 {
+		hdr += " "
+	}
+	hdr += "\n"
+	b.WriteString("\x93NUMPY\x01\x00")
+	binary.Write(&b, binary.LittleEndian, uint16(len(hdr)))
+	b.WriteString(hdr)
+	var bo binary.ByteOrder = binary.LittleEndian
+	if dtype[0] == '>' {
+		bo = binary.BigEndian
+	}
+	assert.NoError(t, binary.Write(&b, bo, data))
+	assert.NoError(t, os.WriteFile(fname, b.Bytes(), 0644))
+}
+
+func TestOpenPatsNPY(t *testing.T) {
+	dir := t.TempDir()
+	inFn := filepath.Join(dir, "input.npy")
+	vals := make([]float32, 3*2*2)
+	for i := range vals {
+		vals[i] = float32(i) * 0.1
+	}
+	writeNPY(t, inFn, "<f4", "(3, 2, 2)", vals)
+
+	dt := &etable.Table{}
+	assert.NoError(t, OpenPatsNPY(dt, inFn, "Input"))
+	assert.Equal(t, 3, dt.Rows)
+	col := dt.ColByName("Input").(*etensor.Float32)
+	assert.Equal(t, []int{3, 2, 2}, col.Shapes())
+	assert.Equal(t, vals, col.Values)
+	assert.Equal(t, vals[7], col.Value([]int{1, 1, 1}))
+
+	outFn := filepath.Join(dir, "output.npy")
+	writeNPY(t, outFn, ">f8", "(3,)", []float64{1, 0, 0.5})
+	assert.NoError(t, OpenPatsNPY(dt, outFn, "Output"))
+	assert.Equal(t, 2, dt.NumCols())
+	assert.Equal(t, []float32{1, 0, 0.5}, dt.ColByName("Output").(*etensor.Float32).Values)
+
+	// replace existing column
+	writeNPY(t, outFn, "<i4", "(3, 2)", []int32{1, -2, 3, 4, 5, 6})
+	assert.NoError(t, OpenPatsNPY(dt, outFn, "Output"))
+	assert.Equal(t, 2, dt.NumCols())
+	out := dt.ColByName("Output").(*etensor.Float32)
+	assert.Equal(t, []int{3, 2}, out.Shapes())
+	assert.Equal(t, float32(-2), out.Values[1])
+
+	// row mismatch
+	writeNPY(t, outFn, "|u1", "(4,)", []uint8{1, 2, 3, 4})
+	assert.Error(t, OpenPatsNPY(dt, outFn, "Output"))
+	assert.Equal(t, 3, dt.Rows)
+
+	assert.Error(t, OpenPatsNPY(dt, filepath.Join(dir, "missing.npy"), "Output"))
+	badFn := filepath.Join(dir, "bad.npy")
+	assert.NoError(t, os.WriteFile(badFn, []byte("not numpy data"), 0644))
+	assert.Error(t, OpenPatsNPY(dt, badFn, "Output"))
+}
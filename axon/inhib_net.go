@@ -0,0 +1,43 @@
+// Copyright (c) 2023, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package axon
+
+import (
+	"github.com/emer/emergent/prjn"
+)
+
+// note: Defaults not called on GPU
+
+// InhibInterneuronDefaults sets the defaults for InhibInterneuronLayer:
+// fast-spiking neurons without adaptation currents, which are broadly
+// active, with only weak inhibition among themselves.
+func (ly *LayerParams) InhibInterneuronDefaults() {
+	ly.Act.Mahp.Gbar = 0
+	ly.Act.Sahp.Gbar = 0
+	ly.Act.KNa.On.SetBool(false)
+	ly.Inhib.ActAvg.Nominal = 0.5
+	ly.Inhib.Layer.Gi = 0.5
+	ly.Inhib.Pool.Gi = 0.5
+	ly.Learn.TrgAvgAct.On.SetBool(false)
+}
+
+// AddInhibInterneurons adds an InhibInterneuronLayer of given name and size,
+// which receives Full excitatory ForwardPrjn projections from each of the
+// given excitatory layers, and sends Full InhibPrjn projections back to
+// each of them, which learn using the InhibLearn homeostatic inhibitory
+// plasticity rule.  The new layer is placed to the right of the first
+// excitatory layer.
+func (nt *Network) AddInhibInterneurons(name string, nNeurY, nNeurX int, excLays ...*Layer) *Layer {
+	inh := nt.AddLayer2D(name, nNeurY, nNeurX, InhibInterneuronLayer)
+	full := prjn.NewFull()
+	for _, ex := range excLays {
+		nt.ConnectLayers(ex, inh, full, ForwardPrjn)
+		nt.ConnectLayers(inh, ex, full, InhibPrjn)
+	}
+	if len(excLays) > 0 {
+		inh.PlaceRightOf(excLays[0], 2)
+	}
+	return inh
+}
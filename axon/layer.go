@@ -15,6 +15,7 @@ import (
 
 	"github.com/emer/emergent/emer"
 	"github.com/emer/emergent/erand"
+	"github.com/emer/emergent/prjn"
 	"github.com/emer/emergent/weights"
 	"github.com/emer/etable/etensor"
 	"github.com/goki/ki/indent"
@@ -40,6 +41,7 @@ func (ly *Layer) Object() interface{} {
 }
 
 func (ly *Layer) Defaults() {
+	ly.Cost.Defaults()
 	if ly.Params != nil {
 		ly.Params.LayType = ly.LayerType()
 		ly.Params.Defaults()
@@ -113,6 +115,9 @@ func (ly *Layer) Defaults() {
 		ly.VThalDefaults()
 	case VSGatedLayer:
 		ly.Params.VSGatedDefaults()
+
+	case InhibInterneuronLayer:
+		ly.Params.InhibInterneuronDefaults()
 	}
 	ly.UpdateParams()
 }
@@ -142,6 +147,28 @@ func (ly *Layer) UpdateParams() {
 	}
 }
 
+// SetLayerType changes the type of the layer, which can be done safely at
+// any point, including after Build: resets the type-specific params to
+// their defaults (see LayerParams.SpecialDefaults), while preserving the
+// shared Act, Inhib, and Learn params, and updates the Clamp IsInput and
+// IsTarget flags for the new type.  Types that require BuildConfig
+// settings processed in PostBuild (e.g., PulvinarLayer) must be set
+// prior to Build.  Parameters are synced to the GPU if it is on.
+func (ly *Layer) SetLayerType(typ LayerTypes) {
+	ly.Typ = typ
+	if ly.Params == nil {
+		return
+	}
+	ly.Params.LayType = typ
+	ly.Params.SpecialDefaults()
+	ly.Params.Act.Clamp.IsInput.SetBool(ly.Params.IsInput())
+	ly.Params.Act.Clamp.IsTarget.SetBool(ly.Params.IsTarget())
+	ly.UpdateParams()
+	if ly.Network != nil && ly.Network.GPU.On {
+		ly.Network.GPU.SyncParamsToGPU()
+	}
+}
+
 // PostBuild performs special post-Build() configuration steps for specific algorithms,
 // using configuration data set in BuildConfig during the ConfigNet process.
 func (ly *Layer) PostBuild() {
@@ -149,6 +176,14 @@ func (ly *Layer) PostBuild() {
 	ly.Params.LayInhib.Idx2 = ly.BuildConfigFindLayer("LayInhib2Name", false) // optional
 	ly.Params.LayInhib.Idx3 = ly.BuildConfigFindLayer("LayInhib3Name", false) // optional
 	ly.Params.LayInhib.Idx4 = ly.BuildConfigFindLayer("LayInhib4Name", false) // optional
+	ly.Params.LayInhib.Gain = 1
+	if gs, ok := ly.BuildConfig["LayInhibGain"]; ok {
+		if g, err := strconv.ParseFloat(gs, 32); err == nil {
+			ly.Params.LayInhib.Gain = float32(g)
+		} else {
+			log.Println(err)
+		}
+	}
 
 	switch ly.LayerType() {
 	case PulvinarLayer:
@@ -181,6 +216,53 @@ func (ly *Layer) PostBuild() {
 	}
 }
 
+// SetInhibFmLayers sets this layer to receive layer-level inhibition from
+// the given other layers (up to 4), where the inhibition from each other
+// layer is multiplied by gain, and this layer's inhibition is the max of
+// its own and these other inhibition values.  This produces competition
+// across layers as a function of their activity -- call on each layer
+// in a set, with the other layers in the set, for mutual inhibition.
+// Sets the LayInhibXName and LayInhibGain BuildConfig values used in
+// Build, and also updates the LayInhib params if already built.
+func (ly *Layer) SetInhibFmLayers(others []*Layer, gain float32) {
+	if len(others) > 4 {
+		log.Printf("SetInhibFmLayers: layer %s: only up to 4 other layers are supported, got: %d -- using the first 4\n", ly.Nm, len(others))
+		others = others[:4]
+	}
+	idxs := []int32{-1, -1, -1, -1}
+	for i := 0; i < 4; i++ {
+		nm := fmt.Sprintf("LayInhib%dName", i+1)
+		if i < len(others) {
+			ly.SetBuildConfig(nm, others[i].Name())
+			idxs[i] = int32(others[i].Index())
+		} else {
+			delete(ly.BuildConfig, nm)
+		}
+	}
+	ly.SetBuildConfig("LayInhibGain", fmt.Sprintf("%g", gain))
+	if ly.Params == nil {
+		return
+	}
+	li := &ly.Params.LayInhib
+	li.Idx1, li.Idx2, li.Idx3, li.Idx4 = idxs[0], idxs[1], idxs[2], idxs[3]
+	li.Gain = gain
+}
+
+// AddSelfMaint adds a recurrent self-maintenance projection within this
+// layer, using given pattern, which is marked as SelfMaint so that
+// Defaults sets the SelfMaintDefaults parameters: slower learning and high
+// initial weight variance, and the given PrjnScale.Abs strength,
+// instead of the standard projection parameters.  The projection has a
+// class of SelfMaint, for any further params.  The layer must already
+// have been added to the network.
+func (ly *Layer) AddSelfMaint(pat prjn.Pattern, abs float32) *Prjn {
+	pj := ly.Network.LateralConnectLayer(ly, pat)
+	pj.SetClass("SelfMaint")
+	pj.SelfMaint = true
+	pj.SelfMaintAbs = abs
+	return pj
+}
+
 // HasPoolInhib returns true if the layer is using pool-level inhibition (implies 4D too).
 // This is the proper check for using pool-level target average activations, for example.
 func (ly *Layer) HasPoolInhib() bool {
@@ -216,7 +298,10 @@ func (ly *Layer) AllParams() string {
 
 // UnitVarNames returns a list of variable names available on the units in this layer
 func (ly *Layer) UnitVarNames() []string {
-	return NeuronVars
+	if ly.Network == nil {
+		return NeuronVars
+	}
+	return ly.Network.UnitVarNames()
 }
 
 // UnitVarProps returns properties for variables
@@ -228,13 +313,20 @@ func (ly *Layer) UnitVarProps() map[string]string {
 // according to *this layer's* UnitVarNames() list (using a map to lookup index),
 // or -1 and error message if not found.
 func (ly *Layer) UnitVarIdx(varNm string) (int, error) {
-	return NeuronVarIdxByName(varNm)
+	if ly.Network == nil {
+		return NeuronVarIdxByName(varNm)
+	}
+	return ly.Network.NeuronVarIdx(varNm)
 }
 
 // UnitVarNum returns the number of Neuron-level variables
-// for this layer.  This is needed for extending indexes in derived types.
+// for this layer, including any registered by Network.RegisterNeuronVar.
+// This is needed for extending indexes in derived types.
 func (ly *Layer) UnitVarNum() int {
-	return len(NeuronVars)
+	if ly.Network == nil {
+		return len(NeuronVars)
+	}
+	return len(NeuronVars) + len(ly.Network.NeuronVarFuns)
 }
 
 // UnitVal1D returns value of given variable index on given unit, using 1-dimensional index.
@@ -248,8 +340,12 @@ func (ly *Layer) UnitVal1D(varIdx int, idx int) float32 {
 	if varIdx < 0 || varIdx >= ly.UnitVarNum() {
 		return mat32.NaN()
 	}
-	if varIdx >= ly.UnitVarNum()-NNeuronLayerVars {
-		lvi := varIdx - (ly.UnitVarNum() - NNeuronLayerVars)
+	if varIdx >= len(NeuronVars) {
+		nrn := &ly.Neurons[idx]
+		return ly.Network.NeuronVarFuns[varIdx-len(NeuronVars)].Fun(uint32(idx), nrn)
+	}
+	if varIdx >= len(NeuronVars)-NNeuronLayerVars {
+		lvi := varIdx - (len(NeuronVars) - NNeuronLayerVars)
 		switch lvi {
 		case 0:
 			return ly.Vals.NeuroMod.DA
@@ -469,6 +565,35 @@ func (ly *Layer) SendPrjnVals(vals *[]float32, varNm string, recvLay emer.Layer,
 	return nil
 }
 
+// NeuronCoord returns the 4D coordinates of given layer-specific neuron
+// index, as pool Y, X and unit Y, X within the pool, using the Idxs
+// shape values set in Build.  For 2D layers, py = px = 0.
+// See NeuronIdx for the inverse.
+func (ly *Layer) NeuronCoord(ni int) (py, px, uy, ux int) {
+	nux := int(ly.Params.Idxs.ShpUnX)
+	nuy := int(ly.Params.Idxs.ShpUnY)
+	npx := int(ly.Params.Idxs.ShpPlX)
+	ux = ni % nux
+	uy = (ni / nux) % nuy
+	pi := ni / (nux * nuy)
+	px = pi % npx
+	py = pi / npx
+	return
+}
+
+// NeuronIdx returns the layer-specific neuron index for given 4D
+// coordinates of pool Y, X and unit Y, X within the pool, using the
+// Idxs shape values set in Build, or -1 if the coordinates are out of
+// range.  For 2D layers, use py = px = 0.  See NeuronCoord for the inverse.
+func (ly *Layer) NeuronIdx(py, px, uy, ux int) int {
+	idxs := &ly.Params.Idxs
+	if py < 0 || py >= int(idxs.ShpPlY) || px < 0 || px >= int(idxs.ShpPlX) ||
+		uy < 0 || uy >= int(idxs.ShpUnY) || ux < 0 || ux >= int(idxs.ShpUnX) {
+		return -1
+	}
+	return ((py*int(idxs.ShpPlX)+px)*int(idxs.ShpUnY)+uy)*int(idxs.ShpUnX) + ux
+}
+
 // WriteWtsJSON writes the weights from this layer from the receiver-side perspective
 // in a JSON text format.  We build in the indentation logic to make it much faster and
 // more efficient.
@@ -702,6 +827,7 @@ func (ly *Layer) InitActAvg() {
 				nrn.ActAvg = ly.Params.Inhib.ActAvg.Nominal * nrn.TrgAvg
 				nrn.AvgDif = 0
 				nrn.DTrgAvg = 0
+				nrn.SpkThrOff = 0
 			}
 		}
 	} else {
@@ -727,8 +853,51 @@ func (ly *Layer) InitActAvg() {
 			nrn.ActAvg = ly.Params.Inhib.ActAvg.Nominal * nrn.TrgAvg
 			nrn.AvgDif = 0
 			nrn.DTrgAvg = 0
+			nrn.SpkThrOff = 0
 		}
 	}
+	if ly.TrgAvgFun != nil {
+		ly.TrgAvgFmFunc()
+	}
+}
+
+// TrgAvgFunc is a function that returns the TrgAvg target average
+// activity for the neuron at given index in the layer -- see SetTrgAvgFunc.
+type TrgAvgFunc func(ni uint32) float32
+
+// SetTrgAvgFunc sets a function that determines the TrgAvg target
+// average activity of each neuron in the layer, instead of the default
+// values spread uniformly across the TrgAvgAct.TrgRange, e.g., to
+// establish a gradient of excitability across the layer.  TrgAvg is the
+// target activity relative to the pool (or layer) average, which drives
+// synaptic scaling in SynScale, so the values should average around 1,
+// and are subject to the TrgRange limits when updated by error-driven
+// learning -- set TrgAvgAct.ErrLRate = 0 to keep them fixed.
+// The function is applied immediately and again in InitActAvg on
+// each InitWts.  Passing nil restores the default on the next InitWts.
+func (ly *Layer) SetTrgAvgFunc(fn TrgAvgFunc) {
+	ly.TrgAvgFun = fn
+	if fn != nil {
+		ly.TrgAvgFmFunc()
+		ly.Network.GPU.SyncNeuronsToGPU()
+	}
+}
+
+// TrgAvgFmFunc sets the TrgAvg of each neuron from the TrgAvgFunc,
+// along with the AvgPct and ActAvg values that track it.
+// Called by InitActAvg.
+func (ly *Layer) TrgAvgFmFunc() {
+	for ni := range ly.Neurons {
+		nrn := &ly.Neurons[ni]
+		if nrn.IsOff() {
+			continue
+		}
+		nrn.TrgAvg = ly.TrgAvgFun(uint32(ni))
+		nrn.AvgPct = nrn.TrgAvg
+		nrn.ActAvg = ly.Params.Inhib.ActAvg.Nominal * nrn.TrgAvg
+		nrn.AvgDif = 0
+		nrn.DTrgAvg = 0
+	}
 }
 
 // InitActs fully initializes activation state -- only called automatically during InitWts
@@ -739,6 +908,8 @@ func (ly *Layer) InitActs() {
 		nrn := &ly.Neurons[ni]
 		ly.Params.Act.InitActs(&ly.Network.Rand, nrn)
 	}
+	ly.SpkHist.Reset()
+	ly.ActTrc.Reset()
 	for pi := range ly.Pools {
 		pl := &ly.Pools[pi]
 		pl.Init()
@@ -748,6 +919,9 @@ func (ly *Layer) InitActs() {
 		// Target layers are dynamically updated
 	}
 	ly.InitPrjnGBuffs()
+	if ly.FrozenClamp {
+		ly.ApplyFrozen()
+	}
 }
 
 // InitPrjnGBuffs initializes the projection-level conductance buffers and
@@ -799,6 +973,10 @@ func (ly *Layer) InitExt() {
 		ly.Params.InitExt(uint32(ni), nrn)
 		ly.Exts[ni] = -1 // missing by default
 	}
+	ly.ExtSparseIdxs = nil
+	if ly.FrozenClamp {
+		ly.ApplyFrozen()
+	}
 }
 
 // ApplyExt applies external input in the form of an etensor.Float32 or 64.
@@ -827,7 +1005,16 @@ func (ly *Layer) ApplyExt(ext etensor.Tensor) {
 // ApplyExtVal applies given external value to given neuron
 // using clearMask, setMask, and toTarg from ApplyExtFlags.
 // Also saves Val in Exts for potential use by GPU.
+// If a UnitPerm permutation is set, the value is instead applied
+// to neuron UnitPerm[ni].
 func (ly *Layer) ApplyExtVal(ni int, nrn *Neuron, val float32, clearMask, setMask NeuronFlags, toTarg bool) {
+	if ly.UnitPerm != nil {
+		ni = ly.UnitPerm[ni]
+		nrn = &ly.Neurons[ni]
+		if nrn.IsOff() {
+			return
+		}
+	}
 	if len(ly.Exts) <= ni {
 		log.Printf("Layer named: %s Type: %s does not have allocated Exts vals -- is likely not registered to receive external input in LayerTypes.IsExt() -- will not be presented to GPU", ly.Name(), ly.LayerType().String())
 	} else {
@@ -845,6 +1032,47 @@ func (ly *Layer) ApplyExtVal(ni int, nrn *Neuron, val float32, clearMask, setMas
 	nrn.SetFlag(setMask)
 }
 
+// SetUnitPermutation sets a permutation of the neurons in this layer
+// that is applied to all external inputs (ApplyExt* methods), such that
+// the input value for flat index i goes to neuron perm[i].  This
+// consistently scrambles the input patterns across trials, e.g., for
+// studying the effects of representational format, without changing
+// the environment.  Passing nil turns off the permutation.
+// Returns an error if perm is not a permutation of the neuron indexes.
+func (ly *Layer) SetUnitPermutation(perm []int) error {
+	if perm == nil {
+		ly.UnitPerm = nil
+		return nil
+	}
+	nn := len(ly.Neurons)
+	if len(perm) != nn {
+		err := fmt.Errorf("SetUnitPermutation: layer %s permutation length %d != number of neurons %d", ly.Nm, len(perm), nn)
+		log.Println(err)
+		return err
+	}
+	has := make([]bool, nn)
+	for _, pi := range perm {
+		if pi < 0 || pi >= nn || has[pi] {
+			err := fmt.Errorf("SetUnitPermutation: layer %s invalid or duplicate index %d in permutation", ly.Nm, pi)
+			log.Println(err)
+			return err
+		}
+		has[pi] = true
+	}
+	ly.UnitPerm = make([]int, nn)
+	copy(ly.UnitPerm, perm)
+	return nil
+}
+
+// RandomPermutation returns a random permutation of the neuron indexes
+// in this layer generated from an independent random number stream with
+// the given seed, so it is always the same for a given seed,
+// for use in SetUnitPermutation.
+func (ly *Layer) RandomPermutation(seed int64) []int {
+	rnd := erand.NewSysRand(seed)
+	return rnd.Perm(len(ly.Neurons), -1)
+}
+
 // ApplyExtFlags gets the clear mask and set mask for updating neuron flags
 // based on layer type, and whether input should be applied to Target (else Ext)
 func (ly *Layer) ApplyExtFlags() (clearMask, setMask NeuronFlags, toTarg bool) {
@@ -933,6 +1161,244 @@ func (ly *Layer) ApplyExt1DTsr(ext etensor.Tensor) {
 	}
 }
 
+// ApplyExtSparse applies external input to the neurons at the given
+// flat (1D) indexes, with all other neurons getting a 0 input, which is
+// equivalent to ApplyExt with a full pattern of the same values.
+// Only the neurons given nonzero input on the previous call are cleared,
+// and only the given neurons are set, so this avoids writing to the full
+// layer each trial for large, mostly-empty input layers -- to benefit,
+// InitExt must not be called on this layer between calls, as it resets
+// all neurons (and the next call then writes the full layer).
+// If the layer is a Target or Compare layer type, then it goes in Target
+// otherwise it goes in Ext.
+func (ly *Layer) ApplyExtSparse(idxVals map[int]float32) {
+	clearMask, setMask, toTarg := ly.ApplyExtFlags()
+	nn := len(ly.Neurons)
+	if ly.ExtSparseIdxs == nil {
+		for ni := range ly.Neurons {
+			nrn := &ly.Neurons[ni]
+			if nrn.IsOff() {
+				continue
+			}
+			ly.ApplyExtVal(ni, nrn, 0, clearMask, setMask, toTarg)
+		}
+	} else {
+		for _, ni := range ly.ExtSparseIdxs {
+			if _, has := idxVals[ni]; has {
+				continue
+			}
+			nrn := &ly.Neurons[ni]
+			if nrn.IsOff() {
+				continue
+			}
+			ly.ApplyExtVal(ni, nrn, 0, clearMask, setMask, toTarg)
+		}
+	}
+	ly.ExtSparseIdxs = ly.ExtSparseIdxs[:0]
+	if ly.ExtSparseIdxs == nil {
+		ly.ExtSparseIdxs = make([]int, 0, len(idxVals))
+	}
+	for ni, val := range idxVals {
+		if ni < 0 || ni >= nn {
+			log.Printf("ApplyExtSparse: layer %s index %d out of range for %d neurons\n", ly.Nm, ni, nn)
+			continue
+		}
+		nrn := &ly.Neurons[ni]
+		if nrn.IsOff() {
+			continue
+		}
+		ly.ApplyExtVal(ni, nrn, val, clearMask, setMask, toTarg)
+		if val != 0 {
+			ly.ExtSparseIdxs = append(ly.ExtSparseIdxs, ni)
+		}
+	}
+}
+
+// FreezeActs captures the current Act values of the neurons in the layer
+// into FrozenActs, which can then be used as a fixed clamped activity
+// pattern via ClampToFrozen, e.g., for studying the response of
+// downstream circuits to a held-constant input.
+// When running on the GPU, call GPU.SyncNeuronsFmGPU first.
+func (ly *Layer) FreezeActs() {
+	ly.UnitVals(&ly.FrozenActs, "Act")
+}
+
+// ClampToFrozen turns on or off the clamping of the layer activity to the
+// FrozenActs pattern captured by FreezeActs, by applying it as external
+// input (Ext) to each neuron, in the same way as an InputLayer (see
+// Act.Clamp params), so that the activity is driven by the frozen pattern
+// instead of the layer's inputs, with the pool inhibition also driven by
+// the clamped input (see FrozenClampPools).  The clamping persists across
+// trials, being re-applied in InitExt, InitActs and NewState, until turned off.
+// Syncs the neurons to the GPU if it is on.
+func (ly *Layer) ClampToFrozen(on bool) {
+	if on && len(ly.FrozenActs) != len(ly.Neurons) {
+		log.Printf("ClampToFrozen: layer %s has no frozen activity -- call FreezeActs first\n", ly.Nm)
+		return
+	}
+	ly.FrozenClamp = on
+	if on {
+		ly.ApplyFrozen()
+		ly.FrozenClampPools()
+	} else {
+		for ni := range ly.Neurons {
+			ly.Params.InitExt(uint32(ni), &ly.Neurons[ni])
+			if ni < len(ly.Exts) {
+				ly.Exts[ni] = -1
+			}
+		}
+	}
+	if ly.Network != nil && ly.Network.GPU.On {
+		ly.Network.GPU.SyncNeuronsToGPU()
+		ly.Network.GPU.SyncPoolsToGPU()
+	}
+}
+
+// FrozenClampPools sets the pool inhibition to use the clamped external
+// input, as for InputLayers, so that inhibition is also independent of the
+// layer's inputs when ClampToFrozen is on.  Called in NewState.
+func (ly *Layer) FrozenClampPools() {
+	if ly.Params.Act.Clamp.Add.IsTrue() {
+		return
+	}
+	for pi := range ly.Pools {
+		ly.Pools[pi].Inhib.Clamped.SetBool(true)
+	}
+}
+
+// ApplyFrozen applies the FrozenActs values as external input to the
+// neurons, for ClampToFrozen.
+func (ly *Layer) ApplyFrozen() {
+	for ni := range ly.Neurons {
+		nrn := &ly.Neurons[ni]
+		if nrn.IsOff() {
+			continue
+		}
+		nrn.Ext = ly.FrozenActs[ni]
+		nrn.ClearFlag(NeuronHasTarg | NeuronHasCmpr)
+		nrn.SetFlag(NeuronHasExt)
+		if ni < len(ly.Exts) {
+			ly.Exts[ni] = nrn.Ext
+		}
+	}
+}
+
+// ClampPoolGi clamps the inhibitory conductance Inhib.Gi of the given pool
+// (0 = the layer-level pool, 1..n = sub-pools of a 4D layer) to the given
+// value every cycle, instead of the computed FS-FFFB value, so that the
+// level of inhibition is dissociated from the activity in the layer.
+// For a 4D layer, clamping the layer pool also affects the sub-pools, via
+// the usual max with the layer pool Gi (see Inhib.Layer.On).  The clamp
+// persists until cleared by ClearClampGi.  Syncs the pools to the GPU if on.
+func (ly *Layer) ClampPoolGi(poolIdx int, gi float32) {
+	if poolIdx < 0 || poolIdx >= len(ly.Pools) {
+		log.Printf("ClampPoolGi: layer %s pool index %d out of range for %d pools\n", ly.Nm, poolIdx, len(ly.Pools))
+		return
+	}
+	pl := &ly.Pools[poolIdx]
+	pl.GiClamp.SetBool(true)
+	pl.GiClampVal = gi
+	if ly.Network != nil {
+		ly.Network.GPU.SyncPoolsToGPU()
+	}
+}
+
+// ClearClampGi clears any ClampPoolGi clamping of inhibition
+// for all pools in the layer.  Syncs the pools to the GPU if on.
+func (ly *Layer) ClearClampGi() {
+	for pi := range ly.Pools {
+		ly.Pools[pi].GiClamp.SetBool(false)
+	}
+	if ly.Network != nil {
+		ly.Network.GPU.SyncPoolsToGPU()
+	}
+}
+
+// ResetSpkMax resets the SpkMax and SpkMaxCa values of all neurons
+// in the layer, so that they restart accumulating the peak activity
+// from the current cycle (once past Act.Dt.MaxCycStart), e.g., at the
+// onset of a new stimulus within a trial.  When running on the GPU,
+// the Neurons must be synced from the GPU before, and back after.
+func (ly *Layer) ResetSpkMax() {
+	for ni := range ly.Neurons {
+		nrn := &ly.Neurons[ni]
+		nrn.SpkMax = 0
+		nrn.SpkMaxCa = 0
+	}
+}
+
+// SetSpkMaxResets sets the cycles within the trial (ctx.Cycle) at which
+// to automatically call ResetSpkMax, so that SpkMax reflects the peak
+// activity since the last reset, e.g., for each of multiple stimuli
+// presented within a trial.  The reset happens at the end of the prior
+// cycle (in CyclePost), so that the given cycle is the first one
+// accumulated.  Passing no cycles turns off the resets.
+// Only works on the CPU.
+func (ly *Layer) SetSpkMaxResets(cycs ...int) {
+	if len(cycs) == 0 {
+		ly.SpkMaxResets = nil
+		return
+	}
+	ly.SpkMaxResets = make([]int32, len(cycs))
+	for i, cyc := range cycs {
+		ly.SpkMaxResets[i] = int32(cyc)
+	}
+}
+
+// RLRateFunc is a function that computes the recv-neuron learning rate
+// multiplier RLRate for given neuron, which has the default RLRate
+// value already computed -- see SetRLRateFunc.
+type RLRateFunc func(ni uint32, nrn *Neuron) float32
+
+// SetRLRateFunc sets a custom function to compute the RLRate learning rate
+// multiplier for each neuron in the layer, which is called at the end of
+// PlusPhase after the default RLRate is computed (from RLRate params),
+// so it can modify or replace that value, e.g., to boost learning in
+// neurons with a larger error.  Passing nil restores the default.
+// Only works on the CPU.
+func (ly *Layer) SetRLRateFunc(fn RLRateFunc) {
+	ly.RLRateFun = fn
+}
+
+// ActOutFunc is a function that computes the ActOut readout value
+// from the Act activation of a neuron -- see SetActOutFunc.
+type ActOutFunc func(act float32) float32
+
+// SetActOutFunc sets a readout function that is applied to the Act of
+// each neuron every cycle, with the result stored in the ActOut neuron
+// variable, e.g., a sigmoid or threshold nonlinearity for decoders.
+// This does not affect the dynamics in any way, which are driven by Act.
+// Passing nil stops updating ActOut.  Only works on the CPU.
+func (ly *Layer) SetActOutFunc(fn ActOutFunc) {
+	ly.ActOutFun = fn
+}
+
+// ActOutFmAct computes the ActOut readout variable for each neuron
+// from Act using the ActOutFunc, if set.  Called in CyclePost.
+func (ly *Layer) ActOutFmAct() {
+	if ly.ActOutFun == nil {
+		return
+	}
+	for ni := range ly.Neurons {
+		nrn := &ly.Neurons[ni]
+		if nrn.IsOff() {
+			continue
+		}
+		nrn.ActOut = ly.ActOutFun(nrn.Act)
+	}
+}
+
+// SpkMaxResetCycle calls ResetSpkMax if the next cycle is one of the
+// SpkMaxResets cycles.  Called in CyclePost.
+func (ly *Layer) SpkMaxResetCycle(ctx *Context) {
+	for _, cyc := range ly.SpkMaxResets {
+		if ctx.Cycle+1 == cyc {
+			ly.ResetSpkMax()
+			return
+		}
+	}
+}
+
 // ApplyExt1D applies external input in the form of a flat 1-dimensional slice of floats
 // If the layer is a Target or Compare layer type, then it goes in Target
 // otherwise it goes in Ext
@@ -993,6 +1459,11 @@ func (ly *Layer) InitGScale() {
 		if pj.IsOff() {
 			continue
 		}
+		if pj.PrjnType() == IdentityPrjn { // steady-state GeSyn = Abs * Act, with fixed weights of 1
+			pj.Params.GScale.Scale = pj.Params.PrjnScale.Abs * ly.Params.Act.Dt.GeDt
+			pj.Params.GScale.Rel = 1
+			continue
+		}
 		slay := pj.Send
 		savg := slay.Params.Inhib.ActAvg.Nominal
 		snu := len(slay.Neurons)
@@ -1015,6 +1486,9 @@ func (ly *Layer) InitGScale() {
 	}
 
 	for _, pj := range ly.RcvPrjns {
+		if pj.PrjnType() == IdentityPrjn {
+			continue
+		}
 		switch pj.Params.Com.GType {
 		case InhibitoryG:
 			if totGiRel > 0 {
@@ -1071,16 +1545,49 @@ func (ly *Layer) CostEst() (neur, syn, tot int) {
 
 // note: use float64 for stats as that is best for logging
 
+// TargPresent returns true if given neuron has a Target or Compare
+// value specified in the most recent ApplyExt call (i.e., a non-negative
+// value, as negative values indicate missing targets).
+func (ly *Layer) TargPresent(nrn *Neuron) bool {
+	return nrn.HasFlag(NeuronHasTarg | NeuronHasCmpr)
+}
+
+// PartialTargs returns true if this is a Target or Compare layer where
+// only some of the neurons have a target specified (see TargPresent).
+// In this case, the PctUnitErr and CorSim stats are computed only over the
+// neurons with targets, and only those neurons are clamped in the plus phase.
+// If no neurons have targets, all are used, as when targets are not applied.
+func (ly *Layer) PartialTargs() bool {
+	if ly.Typ != TargetLayer && ly.Typ != CompareLayer {
+		return false
+	}
+	ntrg := 0
+	n := 0
+	for ni := range ly.Neurons {
+		nrn := &ly.Neurons[ni]
+		if nrn.IsOff() {
+			continue
+		}
+		if ly.TargPresent(nrn) {
+			ntrg++
+		}
+		n++
+	}
+	return ntrg > 0 && ntrg < n
+}
+
 // PctUnitErr returns the proportion of units where the thresholded value of
 // Target (Target or Compare types) or ActP does not match that of ActM.
 // If Act > ly.Params.Act.Clamp.ErrThr, effective activity = 1 else 0
-// robust to noisy activations.
+// robust to noisy activations.  If only some of the units have targets
+// (see PartialTargs), only those units are included.
 func (ly *Layer) PctUnitErr() float64 {
 	nn := len(ly.Neurons)
 	if nn == 0 {
 		return 0
 	}
 	thr := ly.Params.Act.Clamp.ErrThr
+	partial := ly.PartialTargs()
 	wrong := 0
 	n := 0
 	for ni := range ly.Neurons {
@@ -1088,6 +1595,9 @@ func (ly *Layer) PctUnitErr() float64 {
 		if nrn.IsOff() {
 			continue
 		}
+		if partial && !ly.TargPresent(nrn) {
+			continue
+		}
 		trg := false
 		if ly.Typ == CompareLayer || ly.Typ == TargetLayer {
 			if nrn.Target > thr {
@@ -1115,6 +1625,345 @@ func (ly *Layer) PctUnitErr() float64 {
 	return 0
 }
 
+// ClassifyWinner returns the index of the neuron with the maximum ActP
+// value, as the predicted class for a localist classification output
+// layer, or -1 if there are no active neurons.  See ClassifyWinnerVar
+// for other variables, e.g., ActM for the minus-phase prediction
+// in a Target layer, where ActP reflects the clamped target.
+func (ly *Layer) ClassifyWinner() int {
+	return ly.ClassifyWinnerVar("ActP")
+}
+
+// ClassifyWinnerVar returns the index of the neuron with the maximum
+// value of given neuron variable (e.g., ActM, ActP, Target),
+// or -1 if there are no neurons with values > 0 (or if the variable
+// name is invalid).  Ties go to the lowest index (see ArgMaxVar).
+func (ly *Layer) ClassifyWinnerVar(varNm string) int {
+	idx, val := ly.ArgMaxVar(varNm)
+	if val <= 0 {
+		return -1
+	}
+	return idx
+}
+
+// ArgMaxVar returns the index and value of the neuron with the maximum
+// value of given neuron variable, skipping neurons that are off.
+// Ties are broken deterministically in favor of the lowest index,
+// so the result does not depend on anything other than the values --
+// see ArgMaxVarRand for random tie-breaking.
+// Returns -1 if there are no neurons or the variable name is invalid.
+func (ly *Layer) ArgMaxVar(varNm string) (idx int, val float32) {
+	idx = -1
+	vidx, err := NeuronVarIdxByName(varNm)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	for ni := range ly.Neurons {
+		nrn := &ly.Neurons[ni]
+		if nrn.IsOff() {
+			continue
+		}
+		v := nrn.VarByIndex(vidx)
+		if idx < 0 || v > val {
+			idx = ni
+			val = v
+		}
+	}
+	return
+}
+
+// ArgMaxVarRand returns the index and value of the neuron with the maximum
+// value of given neuron variable, as in ArgMaxVar, except that exact ties
+// are broken at random using the network Rand, which is reproducible
+// given the network random seed.
+func (ly *Layer) ArgMaxVarRand(varNm string) (idx int, val float32) {
+	idx, val = ly.ArgMaxVar(varNm)
+	if idx < 0 {
+		return
+	}
+	vidx, _ := NeuronVarIdxByName(varNm)
+	var ties []int
+	for ni := idx; ni < len(ly.Neurons); ni++ {
+		nrn := &ly.Neurons[ni]
+		if !nrn.IsOff() && nrn.VarByIndex(vidx) == val {
+			ties = append(ties, ni)
+		}
+	}
+	if len(ties) > 1 {
+		idx = ties[ly.Network.Rand.Intn(len(ties), -1)]
+	}
+	return
+}
+
+// SoftMaxVar returns a probability distribution over the neurons in
+// the layer, as the softmax of given neuron variable values divided by
+// given temperature: p_i = exp(v_i / temp) / sum_j exp(v_j / temp).
+// Higher temperatures give a more uniform distribution, while lower
+// ones concentrate on the maximum, and a temp <= 0 gives all of the
+// probability to the ArgMaxVar neuron.  Neurons that are off get 0.
+// Returns nil if the variable name is invalid.  This is a stochastic
+// alternative to ArgMaxVar for action selection (see SampleAction).
+// When running on the GPU, call GPU.SyncNeuronsFmGPU first.
+func (ly *Layer) SoftMaxVar(varNm string, temp float32) []float32 {
+	return ly.SoftMaxVarPool(varNm, 0, temp)
+}
+
+// SoftMaxVarPool returns the SoftMaxVar probability distribution over
+// the neurons in given pool index (0 = whole layer, 1+ = sub-pools),
+// in the order of the neurons within the pool.
+// Returns nil if the variable name or pool index is invalid.
+func (ly *Layer) SoftMaxVarPool(varNm string, pi int, temp float32) []float32 {
+	vidx, err := NeuronVarIdxByName(varNm)
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+	if pi < 0 || pi >= len(ly.Pools) {
+		log.Printf("SoftMaxVarPool: pool index %d out of range for layer %s\n", pi, ly.Nm)
+		return nil
+	}
+	pl := &ly.Pools[pi]
+	st := int(pl.StIdx)
+	probs := make([]float32, pl.NNeurons())
+	mxi := -1
+	var mx float32
+	for ni := range probs {
+		nrn := &ly.Neurons[st+ni]
+		if nrn.IsOff() {
+			continue
+		}
+		v := nrn.VarByIndex(vidx)
+		if mxi < 0 || v > mx {
+			mxi = ni
+			mx = v
+		}
+	}
+	if mxi < 0 {
+		return probs
+	}
+	if temp <= 0 {
+		probs[mxi] = 1
+		return probs
+	}
+	var sum float32
+	for ni := range probs {
+		nrn := &ly.Neurons[st+ni]
+		if nrn.IsOff() {
+			continue
+		}
+		p := mat32.Exp((nrn.VarByIndex(vidx) - mx) / temp) // subtract max for stability
+		probs[ni] = p
+		sum += p
+	}
+	for ni := range probs {
+		probs[ni] /= sum
+	}
+	return probs
+}
+
+// SampleAction returns the index of a neuron in the layer sampled from
+// the SoftMaxVar distribution over given neuron variable at given
+// temperature, using given random number generator, for stochastic
+// (exploratory) action selection instead of the deterministic ArgMaxVar.
+// Returns -1 if there are no neurons or the variable name is invalid.
+func (ly *Layer) SampleAction(varNm string, temp float32, rnd erand.Rand) int {
+	probs := ly.SoftMaxVar(varNm, temp)
+	idx := -1
+	r := rnd.Float32(-1)
+	var cum float32
+	for ni, p := range probs {
+		if p <= 0 {
+			continue
+		}
+		idx = ni
+		cum += p
+		if r < cum {
+			break
+		}
+	}
+	return idx
+}
+
+// AdaptCurrents returns the current adaptation K conductances for each
+// neuron in the layer, as components of the Gk computed in GkFmVm:
+// mahp = M-type medium AHP, sahp = slow AHP, and kna = sodium-gated K
+// (sum of medium and slow).  Useful for characterizing which adaptation
+// mechanism dominates in a given layer and parameter regime.
+// When running on the GPU, call GPU.SyncNeuronsFmGPU first.
+func (ly *Layer) AdaptCurrents() (mahp, sahp, kna []float32) {
+	nn := len(ly.Neurons)
+	mahp = make([]float32, nn)
+	sahp = make([]float32, nn)
+	kna = make([]float32, nn)
+	for ni := range ly.Neurons {
+		nrn := &ly.Neurons[ni]
+		if nrn.IsOff() {
+			continue
+		}
+		mahp[ni] = ly.Params.Act.Mahp.GmAHP(nrn.MahpN)
+		sahp[ni] = ly.Params.Act.Sahp.GsAHP(nrn.SahpN)
+		kna[ni] = nrn.GknaMed + nrn.GknaSlow
+	}
+	return
+}
+
+// AdaptCurrentsAvg returns the layer average of each of the adaptation
+// K conductances returned by AdaptCurrents, over neurons that are not off.
+func (ly *Layer) AdaptCurrentsAvg() (mahp, sahp, kna float32) {
+	mahps, sahps, knas := ly.AdaptCurrents()
+	n := 0
+	for ni := range ly.Neurons {
+		if ly.Neurons[ni].IsOff() {
+			continue
+		}
+		mahp += mahps[ni]
+		sahp += sahps[ni]
+		kna += knas[ni]
+		n++
+	}
+	if n > 0 {
+		mahp /= float32(n)
+		sahp /= float32(n)
+		kna /= float32(n)
+	}
+	return
+}
+
+// ConductanceVars are the names of the conductance components
+// returned by Layer.ConductanceBreakdown.
+var ConductanceVars = []string{"Ge", "Gnmda", "Gvgcc", "GgabaB", "Gk"}
+
+// ConductanceBreakdown returns the current values of each of the main
+// conductance components for each neuron in the layer, keyed by the
+// neuron variable names in ConductanceVars: the total excitatory Ge
+// (which includes Gnmda and Gvgcc), the NMDA and VGCC components
+// thereof, the GABA-B conductance (added into Gk), and the total
+// potassium Gk.  This shows which channels are driving (or preventing)
+// spiking.  Neurons that are Off have 0 values.
+// When running on the GPU, call GPU.SyncNeuronsFmGPU first.
+func (ly *Layer) ConductanceBreakdown() map[string][]float32 {
+	nn := len(ly.Neurons)
+	cb := make(map[string][]float32, len(ConductanceVars))
+	for _, vn := range ConductanceVars {
+		cb[vn] = make([]float32, nn)
+	}
+	ge, gnmda, gvgcc, ggabab, gk := cb["Ge"], cb["Gnmda"], cb["Gvgcc"], cb["GgabaB"], cb["Gk"]
+	for ni := range ly.Neurons {
+		nrn := &ly.Neurons[ni]
+		if nrn.IsOff() {
+			continue
+		}
+		ge[ni] = nrn.Ge
+		gnmda[ni] = nrn.Gnmda
+		gvgcc[ni] = nrn.Gvgcc
+		ggabab[ni] = nrn.GgabaB
+		gk[ni] = nrn.Gk
+	}
+	return cb
+}
+
+// ConductancePoolMeans returns the mean of each of the ConductanceBreakdown
+// components over the neurons in each sub-pool of a 4D layer, or over
+// the whole layer as one pool otherwise, keyed by the ConductanceVars names.
+func (ly *Layer) ConductancePoolMeans() map[string][]float32 {
+	npl := 1
+	if ly.Is4D() {
+		npl = len(ly.Pools) - 1
+	}
+	cb := ly.ConductanceBreakdown()
+	means := make(map[string][]float32, len(ConductanceVars))
+	ns := make([]int, npl)
+	for vn := range cb {
+		means[vn] = make([]float32, npl)
+	}
+	for ni := range ly.Neurons {
+		nrn := &ly.Neurons[ni]
+		if nrn.IsOff() {
+			continue
+		}
+		pi := 0
+		if ly.Is4D() {
+			pi = int(nrn.SubPool) - 1
+		}
+		ns[pi]++
+		for vn, vals := range cb {
+			means[vn][pi] += vals[ni]
+		}
+	}
+	for _, vals := range means {
+		for pi, n := range ns {
+			if n > 0 {
+				vals[pi] /= float32(n)
+			}
+		}
+	}
+	return means
+}
+
+// InhibVars are the names of the inhibition components returned by
+// InhibComponents, as used in InhibPoolComponents.
+var InhibVars = []string{"FF", "FB", "SS", "Gi"}
+
+// InhibComponents returns the components of the FS-FFFB inhibition of
+// given pool (0 = the layer-level pool, 1..n = sub-pools of a 4D layer),
+// as of the last cycle: ff and fb are the feedforward and feedback shares
+// of the fast-spiking (PV) inhibition FSGi (clamped at 0), divided in
+// proportion to the current FFs and FB-weighted FBs spiking inputs (with
+// any GeExts clamping going to ff), ss is the slow-spiking (SST) inhibition SSGi,
+// and gi is the overall Gi, which also reflects FFPrv and any pool or
+// layer max-ing.  Returns all zeros for an invalid pool index.
+// When running on the GPU, the Pools must be synced from the GPU first.
+func (ly *Layer) InhibComponents(poolIdx int) (ff, fb, ss, gi float32) {
+	if poolIdx < 0 || poolIdx >= len(ly.Pools) {
+		log.Printf("InhibComponents: layer %s pool index %d out of range for %d pools\n", ly.Nm, poolIdx, len(ly.Pools))
+		return
+	}
+	inh := &ly.Pools[poolIdx].Inhib
+	fbw := ly.Params.Inhib.Pool.FB
+	if poolIdx == 0 {
+		fbw = ly.Params.Inhib.Layer.FB
+	}
+	fsgi := inh.FSGi
+	if fsgi < 0 { // FSGi goes slightly negative as it decays below the FS0 threshold
+		fsgi = 0
+	}
+	ffd := inh.FFs
+	fbd := fbw * inh.FBs
+	ff = fsgi
+	if ffd+fbd > 0 && inh.Clamped.IsFalse() {
+		ff = fsgi * ffd / (ffd + fbd)
+		fb = fsgi * fbd / (ffd + fbd)
+	}
+	ss = inh.SSGi
+	gi = inh.Gi
+	return
+}
+
+// InhibPoolComponents returns the InhibComponents for each sub-pool
+// of a 4D layer, or for the whole layer as one pool otherwise,
+// keyed by the InhibVars names.
+func (ly *Layer) InhibPoolComponents() map[string][]float32 {
+	npl := 1
+	pst := 0
+	if ly.Is4D() {
+		npl = len(ly.Pools) - 1
+		pst = 1
+	}
+	comps := make(map[string][]float32, len(InhibVars))
+	for _, vn := range InhibVars {
+		comps[vn] = make([]float32, npl)
+	}
+	for pi := 0; pi < npl; pi++ {
+		ff, fb, ss, gi := ly.InhibComponents(pst + pi)
+		comps["FF"][pi] = ff
+		comps["FB"][pi] = fb
+		comps["SS"][pi] = ss
+		comps["Gi"][pi] = gi
+	}
+	return comps
+}
+
 // LocalistErr2D decodes a 2D layer with Y axis = redundant units, X = localist units
 // returning the indexes of the max activated localist value in the minus and plus phase
 // activities, and whether these are the same or different (err = different)
@@ -1171,6 +2020,220 @@ func (ly *Layer) LocalistErr4D() (err bool, minusIdx, plusIdx int) {
 	return
 }
 
+// SpkCnts returns the number of spikes for each neuron on the current
+// trial so far, from the SpkCnt neuron variable, which is reset in NewState.
+// When running on the GPU, call GPU.SyncNeuronsFmGPU first.
+func (ly *Layer) SpkCnts() []float32 {
+	cnts := make([]float32, len(ly.Neurons))
+	for ni := range ly.Neurons {
+		cnts[ni] = ly.Neurons[ni].SpkCnt
+	}
+	return cnts
+}
+
+// ErrSignal returns the per-neuron error-driven learning signal,
+// as the difference between the plus and minus phase CaSpkP values
+// (CaSpkP - CaSpkPM), which is the core drive for the weight changes
+// computed at the synapse level.  Call after the PlusPhase (the neuron
+// state is synced from the GPU at that point).  Neurons that are Off
+// have a 0 value.
+func (ly *Layer) ErrSignal() []float32 {
+	errs := make([]float32, len(ly.Neurons))
+	for ni := range ly.Neurons {
+		nrn := &ly.Neurons[ni]
+		if nrn.IsOff() {
+			continue
+		}
+		errs[ni] = nrn.CaSpkP - nrn.CaSpkPM
+	}
+	return errs
+}
+
+// SetSpikeHist enables recording of the spike train for each neuron over
+// given number of most recent cycles (0 = off), which is used for RateEst.
+// Spikes are recorded automatically in CyclePost when running on the CPU.
+func (ly *Layer) SetSpikeHist(ncycles int) {
+	if ncycles <= 0 {
+		ly.SpkHist = SpikeHist{}
+		return
+	}
+	ly.SpkHist.Init(len(ly.Neurons), ncycles)
+}
+
+// RecordSpikes records the current Spike values into the SpkHist spike train
+// record, if enabled via SetSpikeHist.  Called automatically in CyclePost.
+// When running on the GPU, Neurons must be synced from the GPU
+// and this called manually each cycle.
+func (ly *Layer) RecordSpikes() {
+	ly.SpkHist.Record(ly.Neurons)
+}
+
+// RateEst returns a per-neuron smooth firing rate estimate computed
+// from the spike train recorded over recent cycles (see SetSpikeHist),
+// using an exponentially-weighted window with time constant tau in cycles.
+// Rates are expressed in the same normalized units as the ISI-based Act
+// value (1 = Spike.MaxHz), and cached in SpkHist.Rates, which is returned.
+// This complements the ISIAvg-based Act with a configurable window.
+func (ly *Layer) RateEst(tau float32) []float32 {
+	rates := ly.SpkHist.RateEst(tau)
+	norm := ly.Params.Act.Spike.ActFmISI(1, .001, ly.Params.Act.Dt.Integ) // rate per cycle -> Act
+	for ni := range rates {
+		rates[ni] *= norm
+	}
+	return rates
+}
+
+// SetActTrace enables recording of the layer-average activity (Act)
+// over given number of most recent cycles (0 = off), which is used for
+// PhaseLockedActivity.  Activity is recorded automatically in CyclePost
+// when running on the CPU.
+func (ly *Layer) SetActTrace(ncycles int) {
+	if ncycles <= 0 {
+		ly.ActTrc = ActTrace{}
+		return
+	}
+	ly.ActTrc.Init(ncycles)
+}
+
+// RecordActTrace records the current layer-average Act into the ActTrc
+// record, if enabled via SetActTrace.  Called automatically in CyclePost.
+// When running on the GPU, Neurons must be synced from the GPU
+// and this called manually each cycle.
+func (ly *Layer) RecordActTrace() {
+	sum := float32(0)
+	n := 0
+	for ni := range ly.Neurons {
+		nrn := &ly.Neurons[ni]
+		if nrn.IsOff() {
+			continue
+		}
+		sum += nrn.Act
+		n++
+	}
+	if n > 0 {
+		sum /= float32(n)
+	}
+	ly.ActTrc.Record(sum)
+}
+
+// SetRecordGSyn enables recording of the trial-average GSynTrl conductance
+// of each receiving projection into this layer, which is shown by
+// Network.EffectiveInputTable.  This is recorded automatically in
+// CyclePost when running on the CPU.
+func (ly *Layer) SetRecordGSyn(on bool) {
+	ly.RecGSyn = on
+	for _, pj := range ly.RcvPrjns {
+		pj.InitGSynSum()
+		pj.GSynTrl = 0
+	}
+}
+
+// PhaseLockedActivity returns the amplitude and phase (radians) of the
+// Fourier component at given frequency (in Hz) of the layer-average
+// activity, over the most recent given number of cycles recorded via
+// SetActTrace (all recorded cycles if cycles <= 0 or more than recorded).
+// The time per cycle is based on Act.Dt.Integ (1 = 1 msec).
+// The phase is that of a cosine relative to the start of the window,
+// and both are accurate for windows spanning whole periods of the frequency.
+// This supports analyses of theta / gamma-like rhythmic dynamics.
+func (ly *Layer) PhaseLockedActivity(freq float32, cycles int) (amp, phase float32) {
+	return ly.ActTrc.Fourier(freq, cycles, 0.001*ly.Params.Act.Dt.Integ)
+}
+
+// SetRateHist enables or disables accumulation of per-neuron rates (ActM)
+// at the end of each trial, for computing rate histograms via RateHist.
+// The accumulated rates are reset whenever this is called.
+func (ly *Layer) SetRateHist(on bool) {
+	ly.RateAcc.Init(len(ly.Neurons))
+	ly.RateAcc.On = on
+}
+
+// ResetRateHist flags the accumulated rates to be reset at the start of
+// the next trial (NewState), e.g., at the start of each epoch.
+func (ly *Layer) ResetRateHist() {
+	ly.RateAcc.Reset = true
+}
+
+// RateHist returns a histogram of the average rates (ActM) of the neurons
+// in this layer accumulated over trials since the last reset,
+// with the given number of equal-sized bins over the normalized
+// 0..1 range of rates.  Each neuron contributes one count.
+// Accumulation must be enabled with SetRateHist.
+func (ly *Layer) RateHist(bins int) []int {
+	hist := make([]int, bins)
+	ly.RateAcc.AddHist(hist)
+	return hist
+}
+
+// SpikeCost returns the metabolic energy cost of the activity in this
+// layer accumulated since the last ResetCost, as the number of spikes
+// times Cost.SpikeCost plus the integrated synaptic conductance times
+// Cost.GCost -- see EnergyCost.  Accumulation must be enabled with
+// SetRecordCost.
+func (ly *Layer) SpikeCost() float32 {
+	return ly.Cost.Cost()
+}
+
+// ResetCost resets the accumulated energy cost, e.g., at the start of each epoch.
+func (ly *Layer) ResetCost() {
+	ly.Cost.Reset()
+}
+
+// SetRecordCost enables or disables accumulation of the metabolic energy
+// cost at the end of each trial -- see SpikeCost.
+// The accumulated cost is reset whenever this is called.
+func (ly *Layer) SetRecordCost(on bool) {
+	ly.Cost.Reset()
+	ly.Cost.On = on
+}
+
+// EIBalance returns the mean excitatory (Ge) and inhibitory (Gi)
+// conductances over the neurons in the layer, from their current values,
+// and the ratio of excitation to inhibition, meanGe / meanGi (0 if meanGi
+// is 0), as a measure of the E/I balance of the layer.
+// When running on the GPU, call GPU.SyncNeuronsFmGPU first.
+func (ly *Layer) EIBalance() (meanGe, meanGi, ratio float32) {
+	n := 0
+	for ni := range ly.Neurons {
+		nrn := &ly.Neurons[ni]
+		if nrn.IsOff() {
+			continue
+		}
+		meanGe += nrn.Ge
+		meanGi += nrn.Gi
+		n++
+	}
+	if n == 0 {
+		return
+	}
+	meanGe /= float32(n)
+	meanGi /= float32(n)
+	ratio = eiRatio(meanGe, meanGi)
+	return
+}
+
+// EIBalanceEpoch returns the E/I balance as in EIBalance, averaged over
+// the trials since the last ResetEIBalance, using the integrated GeInt
+// and GiInt values at the end of each trial -- see EIBalAcc.
+// Accumulation must be enabled with SetRecordEIBalance.
+func (ly *Layer) EIBalanceEpoch() (meanGe, meanGi, ratio float32) {
+	return ly.EIBal.Means()
+}
+
+// ResetEIBalance resets the accumulated E/I balance values used in
+// EIBalanceEpoch, e.g., at the start of each epoch.
+func (ly *Layer) ResetEIBalance() {
+	ly.EIBal.Reset()
+}
+
+// SetRecordEIBalance enables or disables accumulation of the E/I balance
+// at the end of each trial, for EIBalanceEpoch.
+// The accumulated values are reset whenever this is called.
+func (ly *Layer) SetRecordEIBalance(on bool) {
+	ly.EIBal.Reset()
+	ly.EIBal.On = on
+}
+
 //////////////////////////////////////////////////////////////////////////////////////
 //  Lesion
 
@@ -1238,3 +2301,28 @@ var LayerProps = ki.Props{
 		}},
 	},
 }
+
+// cloneConfigTo copies the optional CPU-side configuration of this layer,
+// set by methods such as SetSpikeFunc and SetGapJunctions, to the given
+// layer of a Network.Clone, which has the same neurons.
+// Functions are shared, and all other values are copied.
+func (ly *Layer) cloneConfigTo(cl *Layer) {
+	cl.ExtSparseIdxs = append([]int(nil), ly.ExtSparseIdxs...)
+	cl.UnitPerm = append([]int(nil), ly.UnitPerm...)
+	cl.FrozenActs = append([]float32(nil), ly.FrozenActs...)
+	cl.FrozenClamp = ly.FrozenClamp
+	cl.SpkMaxResets = append([]int32(nil), ly.SpkMaxResets...)
+	cl.RLRateFun = ly.RLRateFun
+	cl.ActOutFun = ly.ActOutFun
+	cl.TrgAvgFun = ly.TrgAvgFun
+	cl.SpikeFun = ly.SpikeFun
+	cl.GapJunc.Gbar = ly.GapJunc.Gbar
+	cl.GapJunc.Igap = append([]float32(nil), ly.GapJunc.Igap...)
+	cl.GapJunc.Cons = nil
+	for _, cons := range ly.GapJunc.Cons {
+		cl.GapJunc.Cons = append(cl.GapJunc.Cons, append([]uint32(nil), cons...))
+	}
+	cl.RecGSyn = ly.RecGSyn
+	cl.Cost = ly.Cost
+	cl.EIBal = ly.EIBal
+}
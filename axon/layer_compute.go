@@ -33,8 +33,14 @@ func (ly *Layer) GatherSpikes(ctx *Context, ni uint32, nrn *Neuron) {
 		bi := pj.Params.Com.ReadIdx(ni, ctx.CyclesTotal, pj.Params.Idxs.RecvNeurN)
 		gRaw := pj.Params.Com.FloatFromGBuf(pj.GBuf[bi])
 		pj.GBuf[bi] = 0
+		if pj.Params.SWt.AllowNegative.IsTrue() {
+			nbi := pj.Params.Com.NegIdx(bi, pj.Params.Idxs.RecvNeurN)
+			gRaw -= pj.Params.Com.FloatFromGBuf(pj.GBuf[nbi])
+			pj.GBuf[nbi] = 0
+		}
 		pj.Params.GatherSpikes(ctx, ly.Params, ni, nrn, gRaw, &pj.GSyns[ni])
 	}
+	ly.Params.GatherSpikesAlpha(nrn)
 }
 
 // GiFmSpikes gets the Spike, GeRaw and GeExt from neurons in the pools
@@ -98,6 +104,7 @@ func (ly *Layer) BetweenLayerGi(ctx *Context) {
 	maxGi = ly.BetweenLayerGiMax(maxGi, net, ly.Params.LayInhib.Idx3)
 	maxGi = ly.BetweenLayerGiMax(maxGi, net, ly.Params.LayInhib.Idx4)
 	lpl.Inhib.Gi = maxGi // our inhib is max of us and everyone in the layer pool
+	lpl.ClampGi()
 }
 
 // BetweenLayerGiMax returns max gi value for input maxGi vs
@@ -108,10 +115,7 @@ func (ly *Layer) BetweenLayerGiMax(maxGi float32, net *Network, layIdx int32) fl
 	}
 	lay := net.Layers[layIdx]
 	lpl := &lay.Pools[0]
-	if lpl.Inhib.Gi > maxGi {
-		maxGi = lpl.Inhib.Gi
-	}
-	return maxGi
+	return ly.Params.LayInhib.GiMax(maxGi, lpl.Inhib.Gi)
 }
 
 func (ly *Layer) PulvinarDriver(ni uint32) (drvGe, nonDrvPct float32) {
@@ -141,8 +145,21 @@ func (ly *Layer) GInteg(ctx *Context, ni uint32, nrn *Neuron, pl *Pool, vals *La
 	ly.Params.SpecialPostGs(ctx, ni, nrn, saveVal)
 }
 
-// SpikeFmG computes Vm from Ge, Gi, Gl conductances and then Spike from that
+// SpikeFmG computes Vm from Ge, Gi, Gl conductances and then Spike from that,
+// or calls the SpikeFun custom spike generation function if set.
+// Includes the GapJunc gap junction current if set.
 func (ly *Layer) SpikeFmG(ctx *Context, ni uint32, nrn *Neuron) {
+	if ly.SpikeFun != nil {
+		ly.SpikeFun(nrn, &ly.Params.Act)
+		ly.Params.CaFmSpike(ctx, nrn)
+		return
+	}
+	if ly.GapJunc.On() {
+		ly.Params.Act.VmFmGExt(nrn, ly.GapJunc.Igap[ni])
+		ly.Params.Act.SpikeFmVm(nrn)
+		ly.Params.CaFmSpike(ctx, nrn)
+		return
+	}
 	ly.Params.SpikeFmG(ctx, ni, nrn)
 }
 
@@ -240,6 +257,23 @@ func (ly *Layer) RSalAChLayMaxAct(net *Network, layIdx int32) float32 {
 // such as updating a neuromodulatory signal such as dopamine.
 // Any updates here must also be done in gpu_hlsl/gpu_cyclepost.hlsl
 func (ly *Layer) CyclePost(ctx *Context) {
+	if ly.SpkHist.NCycles > 0 {
+		ly.RecordSpikes()
+	}
+	if ly.ActTrc.NCycles > 0 {
+		ly.RecordActTrace()
+	}
+	if len(ly.SpkMaxResets) > 0 {
+		ly.SpkMaxResetCycle(ctx)
+	}
+	if ly.ActOutFun != nil {
+		ly.ActOutFmAct()
+	}
+	if ly.RecGSyn {
+		for _, pj := range ly.RcvPrjns {
+			pj.RecordGSyn()
+		}
+	}
 	switch ly.LayerType() {
 	case RSalienceAChLayer:
 		net := ly.Network
@@ -251,7 +285,7 @@ func (ly *Layer) CyclePost(ctx *Context) {
 	case RWDaLayer:
 		net := ly.Network
 		pvals := &net.LayVals[ly.Params.RWDa.RWPredLayIdx]
-		ly.Params.CyclePostRWDaLayer(ctx, ly.Vals, pvals)
+		ly.Params.CyclePostRWDaLayer(ctx, ly.Vals, pvals, net.Layers[ly.Params.RWDa.RWPredLayIdx].Params.RWPred.PredFloor)
 	case TDPredLayer:
 		ly.Params.CyclePostTDPredLayer(ctx, ly.Vals)
 	case TDIntegLayer:
@@ -301,6 +335,14 @@ func (ly *Layer) NewState(ctx *Context) {
 	// always safer to do this rather than not -- sometimes layer has specifically cleared
 	ly.InitPrjnGBuffs()
 	// }
+	if ly.RecGSyn {
+		for _, pj := range ly.RcvPrjns {
+			pj.InitGSynSum()
+		}
+	}
+	if ly.FrozenClamp {
+		ly.FrozenClampPools()
+	}
 }
 
 // DecayState decays activation state by given proportion
@@ -407,6 +449,28 @@ func (ly *Layer) MinusPhasePost(ctx *Context) {
 	}
 }
 
+// UnclampPartialTargs turns off the clamped inhibition mode on the pools
+// of a Target layer where only some of the neurons have targets
+// (see PartialTargs), so that the neurons without targets remain
+// subject to normal inhibition in the plus phase, while only the
+// neurons with targets are clamped.  Returns true if any pools were changed,
+// in which case the Pools must be synced to the GPU.
+// Called in Network.MinusPhaseImpl.
+func (ly *Layer) UnclampPartialTargs() bool {
+	if ly.Typ != TargetLayer || !ly.PartialTargs() {
+		return false
+	}
+	chg := false
+	for pi := range ly.Pools {
+		pl := &ly.Pools[pi]
+		if pl.Inhib.Clamped.IsTrue() {
+			pl.Inhib.Clamped.SetBool(false)
+			chg = true
+		}
+	}
+	return chg
+}
+
 // PlusPhaseStart does updating at the start of the plus phase:
 // applies Target inputs as External inputs.
 func (ly *Layer) PlusPhaseStart(ctx *Context) {
@@ -434,11 +498,22 @@ func (ly *Layer) PlusPhase(ctx *Context) {
 		pl := &ly.Pools[nrn.SubPool]
 		lpl := &ly.Pools[0]
 		ly.Params.PlusPhaseNeuron(ctx, uint32(ni), nrn, pl, lpl, ly.Vals)
+		if ly.RLRateFun != nil {
+			nrn.RLRate = ly.RLRateFun(uint32(ni), nrn)
+		}
 	}
 }
 
 // PlusPhasePost does special algorithm processing at end of plus
 func (ly *Layer) PlusPhasePost(ctx *Context) {
+	ly.RateAcc.Record(ly.Neurons)
+	ly.Cost.Record(ly.Neurons, ctx.ThetaCycles)
+	ly.EIBal.Record(ly.Neurons)
+	if ly.RecGSyn {
+		for _, pj := range ly.RcvPrjns {
+			pj.GSynTrlFmSum()
+		}
+	}
 	ly.TrgAvgFmD()
 	ly.CorSimFmActs() // GPU syncs down the state
 	if ly.Params.Act.Decay.OnRew.IsTrue() {
@@ -449,9 +524,21 @@ func (ly *Layer) PlusPhasePost(ctx *Context) {
 	switch ly.LayerType() {
 	case MatrixLayer:
 		ly.MatrixGated(ctx)
+		ly.MatrixGatedTrls()
 	}
 }
 
+// DecayOnGate decays the activation state of the layer if
+// Act.Decay.OnGate is set and VS gating just occurred, as indicated by
+// ctx.PVLV.VSMatrix.JustGated.  Called after all layers have done
+// PlusPhasePost, so that the gating state has been updated.
+func (ly *Layer) DecayOnGate(ctx *Context) {
+	if ly.Params.Act.Decay.OnGate.IsFalse() || ctx.PVLV.VSMatrix.JustGated.IsFalse() {
+		return
+	}
+	ly.DecayState(ctx, 1, 1) // note: GPU will get, and GBuf are auto-cleared in NewState
+}
+
 // TargToExt sets external input Ext from target values Target
 // This is done at end of MinusPhase to allow targets to drive activity in plus phase.
 // This can be called separately to simulate alpha cycles within theta cycles, for example.
@@ -512,7 +599,13 @@ func (ly *Layer) SpkSt2(ctx *Context) {
 // CorSimFmActs computes the correlation similarity
 // (centered cosine aka normalized dot product)
 // in activation state between minus and plus phases.
+// For Target and Compare layers with only some of the neurons
+// having targets (see PartialTargs), see CorSimFmTargs.
 func (ly *Layer) CorSimFmActs() {
+	if ly.PartialTargs() {
+		ly.CorSimFmTargs()
+		return
+	}
 	lpl := &ly.Pools[0]
 	avgM := lpl.AvgMax.Act.Minus.Avg
 	avgP := lpl.AvgMax.Act.Plus.Avg
@@ -540,6 +633,58 @@ func (ly *Layer) CorSimFmActs() {
 	ly.Params.Act.Dt.AvgVarUpdt(&ly.Vals.CorSim.Avg, &ly.Vals.CorSim.Var, ly.Vals.CorSim.Cor)
 }
 
+// CorSimFmTargs computes the correlation similarity between the minus phase
+// ActM and the plus phase values, only over neurons with targets
+// specified (see TargPresent), if any.  For Compare layers, which are not
+// clamped in the plus phase, the Target values are used instead of ActP.
+func (ly *Layer) CorSimFmTargs() {
+	partial := ly.PartialTargs()
+	plusVal := func(nrn *Neuron) float32 {
+		if ly.Typ == CompareLayer {
+			return nrn.Target
+		}
+		return nrn.ActP
+	}
+	avgM := float32(0)
+	avgP := float32(0)
+	n := 0
+	for ni := range ly.Neurons {
+		nrn := &ly.Neurons[ni]
+		if nrn.IsOff() || (partial && !ly.TargPresent(nrn)) {
+			continue
+		}
+		avgM += nrn.ActM
+		avgP += plusVal(nrn)
+		n++
+	}
+	if n > 0 {
+		avgM /= float32(n)
+		avgP /= float32(n)
+	}
+	cosv := float32(0)
+	ssm := float32(0)
+	ssp := float32(0)
+	for ni := range ly.Neurons {
+		nrn := &ly.Neurons[ni]
+		if nrn.IsOff() || (partial && !ly.TargPresent(nrn)) {
+			continue
+		}
+		ap := plusVal(nrn) - avgP // zero mean = correl
+		am := nrn.ActM - avgM
+		cosv += ap * am
+		ssm += am * am
+		ssp += ap * ap
+	}
+
+	dist := mat32.Sqrt(ssm * ssp)
+	if dist != 0 {
+		cosv /= dist
+	}
+	ly.Vals.CorSim.Cor = cosv
+
+	ly.Params.Act.Dt.AvgVarUpdt(&ly.Vals.CorSim.Avg, &ly.Vals.CorSim.Var, ly.Vals.CorSim.Cor)
+}
+
 //////////////////////////////////////////////////////////////////////////////////////
 //  Learning
 
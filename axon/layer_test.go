@@ -2,11 +2,17 @@ package axon
 
 import (
 	"bufio"
+	"math"
 	"os"
 	"testing"
 
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/erand"
+	"github.com/emer/emergent/etime"
 	"github.com/emer/emergent/prjn"
+	"github.com/emer/emergent/relpos"
 	"github.com/emer/etable/etensor"
+	"github.com/goki/mat32"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -213,3 +219,1773 @@ func TestLayerBase_IsOff(t *testing.T) {
 	assert.True(t, inToHid.IsOff())
 	assert.True(t, in2ToHid.IsOff())
 }
+
+func TestLayerRateEst(t *testing.T) {
+	net := NewNetwork("RateEstTest")
+	lay := net.AddLayer("Hidden", []int{1, 2}, SuperLayer)
+	assert.NoError(t, net.Build())
+	net.Defaults()
+	net.InitWts()
+
+	ncyc := 500
+	isis := []int{10, 4} // regular spike train intervals for each neuron
+	lay.SetSpikeHist(ncyc)
+	for cyc := 0; cyc < ncyc; cyc++ {
+		for ni, isi := range isis {
+			nrn := &lay.Neurons[ni]
+			nrn.Spike = 0
+			if cyc%isi == isi-1 {
+				nrn.Spike = 1
+			}
+		}
+		lay.RecordSpikes()
+	}
+	assert.Equal(t, ncyc, lay.SpkHist.N)
+
+	rates := lay.RateEst(100)
+	for ni, isi := range isis {
+		act := lay.Params.Act.Spike.ActFmISI(float32(isi), .001, lay.Params.Act.Dt.Integ)
+		assert.InDelta(t, 1, rates[ni]/act, 0.1)
+	}
+	assert.Greater(t, rates[1], rates[0])
+
+	lay.InitActs()
+	assert.Equal(t, 0, lay.SpkHist.N)
+}
+
+func TestPhaseLockedActivity(t *testing.T) {
+	net := NewNetwork("PhaseLockTest")
+	lay := net.AddLayer("Hidden", []int{1, 4}, SuperLayer)
+	assert.NoError(t, net.Build())
+	net.Defaults()
+	net.InitWts()
+
+	ncyc := 1000
+	freq := float32(10) // Hz: 100 cycles per period
+	amp := float32(0.2)
+	phase := float32(0.7)
+	lay.SetActTrace(ncyc)
+	for cyc := 0; cyc < ncyc; cyc++ {
+		tm := float64(cyc) * 0.001
+		drive := amp * float32(math.Cos(2*math.Pi*float64(freq)*tm+float64(phase)))
+		for ni := range lay.Neurons {
+			lay.Neurons[ni].Act = 0.3 + 0.1*float32(ni) + drive
+		}
+		lay.RecordActTrace()
+	}
+	assert.Equal(t, ncyc, lay.ActTrc.N)
+
+	pamp, pphase := lay.PhaseLockedActivity(freq, 0)
+	assert.InDelta(t, amp, pamp, 1.0e-3)
+	assert.InDelta(t, phase, pphase, 1.0e-3)
+	pamp, pphase = lay.PhaseLockedActivity(freq, 500) // whole periods
+	assert.InDelta(t, amp, pamp, 1.0e-3)
+	assert.InDelta(t, phase, pphase, 1.0e-3)
+	pamp, _ = lay.PhaseLockedActivity(23, 0) // no component at other frequencies
+	assert.Less(t, pamp, float32(0.01))
+
+	lay.InitActs()
+	assert.Equal(t, 0, lay.ActTrc.N)
+	pamp, _ = lay.PhaseLockedActivity(freq, 0)
+	assert.Equal(t, float32(0), pamp)
+}
+
+func TestLayerRateHist(t *testing.T) {
+	net := NewNetwork("RateHistTest")
+	lay := net.AddLayer("Hidden", []int{4, 5}, SuperLayer)
+	lay2 := net.AddLayer("Hidden2", []int{2, 5}, SuperLayer)
+	assert.NoError(t, net.Build())
+	net.Defaults()
+	net.InitWts()
+	ctx := NewContext()
+
+	lay.SetRateHist(true)
+	lay2.SetRateHist(true)
+	// bimodal: half the neurons fire at a low rate, half at a high rate
+	setRates := func(ly *Layer, lo, hi float32) {
+		for ni := range ly.Neurons {
+			if ni%2 == 0 {
+				ly.Neurons[ni].ActM = lo
+			} else {
+				ly.Neurons[ni].ActM = hi
+			}
+		}
+	}
+	for trl := 0; trl < 4; trl++ {
+		net.NewState(ctx)
+		setRates(lay, 0.05+0.05*float32(trl%2), 0.85+0.05*float32(trl%2))
+		setRates(lay2, 0.15, 0.95)
+		net.PlusPhase(ctx)
+	}
+	assert.Equal(t, 4, lay.RateAcc.N)
+	hist := lay.RateHist(10)
+	assert.Equal(t, []int{10, 0, 0, 0, 0, 0, 0, 0, 10, 0}, hist)
+
+	hists := net.RateHistByClass(10, "SuperLayer")
+	assert.Equal(t, []int{10, 5, 0, 0, 0, 0, 0, 0, 10, 5}, hists["SuperLayer"])
+
+	// reset takes effect at the next NewState
+	lay.ResetRateHist()
+	assert.Equal(t, 4, lay.RateAcc.N)
+	net.NewState(ctx)
+	assert.Equal(t, 0, lay.RateAcc.N)
+	assert.Equal(t, 4, lay2.RateAcc.N)
+	assert.Equal(t, make([]int, 10), lay.RateHist(10))
+}
+
+func TestPartialTargs(t *testing.T) {
+	net := createNetwork([]int{2, 2}, t)
+	ctx := NewContext()
+	out := net.AxonLayerByName("Output")
+
+	inPat := etensor.NewFloat32([]int{2, 2}, nil, nil)
+	inPat.Values[0] = 1
+	trgPat := etensor.NewFloat32([]int{2, 2}, nil, nil)
+	copy(trgPat.Values, []float32{1, -1, 0, -1}) // only units 0, 2 specified
+	net.InitExt()
+	net.AxonLayerByName("Input").ApplyExt(inPat)
+	out.ApplyExt(trgPat)
+	net.ApplyExts(ctx)
+	assert.True(t, out.PartialTargs())
+
+	net.NewState(ctx)
+	ctx.NewState(etime.Train)
+	for cyc := 0; cyc < 150; cyc++ {
+		if cyc == 100 {
+			net.MinusPhase(ctx)
+			for pi := range out.Pools {
+				assert.False(t, out.Pools[pi].Inhib.Clamped.IsTrue())
+			}
+			ctx.NewPhase(true)
+			net.PlusPhaseStart(ctx)
+		}
+		net.Cycle(ctx)
+		ctx.CycleInc()
+	}
+	net.PlusPhase(ctx)
+	for ni := range out.Neurons {
+		nrn := &out.Neurons[ni]
+		assert.Equal(t, ni%2 == 0, nrn.HasFlag(NeuronHasExt)) // only targets are clamped
+	}
+
+	// errors on units without targets are ignored
+	for ni := range out.Neurons {
+		out.Neurons[ni].ActM = 1
+	}
+	out.Neurons[2].ActM = 0
+	assert.Equal(t, 0.0, out.PctUnitErr())
+	out.Neurons[2].ActM = 1
+	assert.Equal(t, 0.5, out.PctUnitErr())
+
+	// CorSim over the target units only
+	out.Neurons[0].ActM = 0.9
+	out.Neurons[2].ActM = 0.1
+	out.CorSimFmActs()
+	assert.InDelta(t, 1, out.Vals.CorSim.Cor, 0.2) // ActP tracks the clamped targets
+
+	// Compare layers use the targets directly
+	out.SetType(emer.LayerType(CompareLayer))
+	out.Params.LayType = CompareLayer
+	out.ApplyExt(trgPat)
+	assert.True(t, out.PartialTargs())
+	out.CorSimFmActs()
+	assert.InDelta(t, 1, out.Vals.CorSim.Cor, 1.0e-5)
+
+	// Compare layers with full targets use ActP as usual
+	copy(trgPat.Values, []float32{1, 0, 0, 1})
+	out.InitExt()
+	out.ApplyExt(trgPat)
+	assert.False(t, out.PartialTargs())
+	for ni := range out.Neurons {
+		nrn := &out.Neurons[ni]
+		nrn.ActM = 0.5
+		nrn.ActP = 0.5
+	}
+	out.Neurons[0].ActM = 1
+	out.Neurons[0].ActP = 1
+	out.CorSimFmActs()
+	assert.Greater(t, out.Vals.CorSim.Cor, float32(0.9)) // Target values would give about 0.58
+}
+
+func TestConfusionMatrix(t *testing.T) {
+	cm := &ConfusionMatrix{}
+	cm.Init(3)
+	// known [Targ][Pred] counts
+	known := [][]int64{{3, 1, 0}, {0, 2, 2}, {1, 0, 4}}
+	for ti, row := range known {
+		for pi, n := range row {
+			for i := int64(0); i < n; i++ {
+				cm.Incr(pi, ti)
+			}
+		}
+	}
+	cm.Incr(-1, 0) // no winner: ignored
+	cm.Incr(0, 3)  // out of range: ignored
+	dt := cm.ToTable()
+	assert.Equal(t, 3, dt.Rows)
+	for ti, row := range known {
+		assert.Equal(t, float64(ti), dt.CellFloat("Targ", ti))
+		n := int64(0)
+		for pi, c := range row {
+			assert.Equal(t, c, cm.Count(pi, ti))
+			assert.Equal(t, float64(c), dt.CellTensorFloat1D("Pred", ti, pi))
+			n += c
+		}
+		assert.Equal(t, float64(n), dt.CellFloat("N", ti))
+		assert.InDelta(t, float64(row[ti])/float64(n), dt.CellTensorFloat1D("Prop", ti, ti), 1.0e-9)
+	}
+	cm.Reset()
+	assert.Equal(t, int64(0), cm.Count(0, 0))
+
+	net := createNetwork([]int{4, 1}, t)
+	outLay := net.AxonLayerByName("Output")
+	for ni := range outLay.Neurons {
+		outLay.Neurons[ni].ActP = 0.1
+	}
+	assert.Equal(t, 0, outLay.ClassifyWinner())
+	outLay.Neurons[2].ActP = 0.8
+	outLay.Neurons[3].ActM = 0.5
+	assert.Equal(t, 2, outLay.ClassifyWinner())
+	assert.Equal(t, 3, outLay.ClassifyWinnerVar("ActM"))
+	assert.Equal(t, -1, outLay.ClassifyWinnerVar("Target"))
+}
+
+func TestAdaptCurrents(t *testing.T) {
+	net := createNetwork([]int{4, 4}, t)
+	ctx := NewContext()
+	hid := net.AxonLayerByName("Hidden")
+	// lower gating offset so that sAHP is engaged at typical activity levels
+	hid.Params.Act.Sahp.Off = 0.05
+	hid.Params.Act.Sahp.Update()
+	net.SetRndSeed(1)
+	net.InitWts()
+
+	inPat := etensor.NewFloat32([]int{4, 4}, nil, nil)
+	for i := range inPat.Values {
+		inPat.Values[i] = 1
+	}
+	mahp, sahp, kna := hid.AdaptCurrents()
+	assert.Equal(t, len(hid.Neurons), len(sahp))
+	assert.Equal(t, len(hid.Neurons), len(mahp))
+	assert.Equal(t, len(hid.Neurons), len(kna))
+
+	// sustained drive over trials: the slow AHP integrates CaSpkD each trial
+	sahpAvgs := make([]float32, 5)
+	for trl := range sahpAvgs {
+		net.InitExt()
+		net.AxonLayerByName("Input").ApplyExt(inPat)
+		net.ApplyExts(ctx)
+		net.NewState(ctx)
+		ctx.NewState(etime.Train)
+		for cyc := 0; cyc < 200; cyc++ {
+			if cyc == 150 {
+				net.MinusPhase(ctx)
+				ctx.NewPhase(true)
+				net.PlusPhaseStart(ctx)
+			}
+			net.Cycle(ctx)
+			ctx.CycleInc()
+		}
+		net.PlusPhase(ctx)
+		var mahpAvg, knaAvg float32
+		mahpAvg, sahpAvgs[trl], knaAvg = hid.AdaptCurrentsAvg()
+		assert.Greater(t, mahpAvg, float32(0))
+		assert.Greater(t, knaAvg, float32(0))
+	}
+	assert.Greater(t, sahpAvgs[0], float32(1.0e-3))
+	assert.Greater(t, sahpAvgs[len(sahpAvgs)-1], sahpAvgs[0]) // builds up across trials
+	_, sahp, _ = hid.AdaptCurrents()
+	for ni := range sahp {
+		assert.Greater(t, sahp[ni], float32(0))
+	}
+}
+
+func TestArgMaxVar(t *testing.T) {
+	net := createNetwork([]int{4, 1}, t)
+	out := net.AxonLayerByName("Output")
+	for ni := range out.Neurons {
+		out.Neurons[ni].ActM = 0.2
+	}
+	out.Neurons[1].ActM = 0.7
+	out.Neurons[3].ActM = 0.7 // exact tie
+	for i := 0; i < 10; i++ {
+		idx, val := out.ArgMaxVar("ActM")
+		assert.Equal(t, 1, idx)
+		assert.Equal(t, float32(0.7), val)
+	}
+	assert.Equal(t, 1, out.ClassifyWinnerVar("ActM"))
+	out.Neurons[1].SetFlag(NeuronOff)
+	idx, _ := out.ArgMaxVar("ActM")
+	assert.Equal(t, 3, idx)
+	out.Neurons[1].ClearFlag(NeuronOff)
+
+	idx, _ = out.ArgMaxVar("NotAVar")
+	assert.Equal(t, -1, idx)
+
+	// random tie-break only picks among the tied units, reproducibly
+	pick := func() []int {
+		net.SetRndSeed(5)
+		picks := make([]int, 20)
+		for i := range picks {
+			picks[i], _ = out.ArgMaxVarRand("ActM")
+		}
+		return picks
+	}
+	picks := pick()
+	n1 := 0
+	for _, p := range picks {
+		assert.Contains(t, []int{1, 3}, p)
+		if p == 1 {
+			n1++
+		}
+	}
+	assert.Greater(t, n1, 0)
+	assert.Less(t, n1, len(picks))
+	assert.Equal(t, picks, pick())
+}
+
+func TestSoftMaxVar(t *testing.T) {
+	net := createNetwork([]int{4, 1}, t)
+	out := net.AxonLayerByName("Output")
+	acts := []float32{0, 0.1, 0.2, 0}
+	for ni := range out.Neurons {
+		out.Neurons[ni].ActM = acts[ni]
+	}
+	temp := float32(0.1)
+	probs := out.SoftMaxVar("ActM", temp)
+	assert.Equal(t, 4, len(probs))
+	sum := float32(0)
+	for _, p := range probs {
+		sum += p
+	}
+	assert.InDelta(t, 1, sum, 1.0e-6)
+	norm := 2 + math.E + math.E*math.E
+	exp := []float64{1 / norm, math.E / norm, math.E * math.E / norm, 1 / norm}
+	for ni, p := range probs {
+		assert.InDelta(t, exp[ni], p, 1.0e-5)
+	}
+
+	// sampling frequencies match the distribution
+	rnd := erand.NewSysRand(42)
+	n := 20000
+	counts := make([]int, 4)
+	for i := 0; i < n; i++ {
+		counts[out.SampleAction("ActM", temp, rnd)]++
+	}
+	for ni := range counts {
+		assert.InDelta(t, exp[ni], float64(counts[ni])/float64(n), 0.015)
+	}
+
+	// high temp is nearly uniform, zero temp is argmax
+	for _, p := range out.SoftMaxVar("ActM", 100) {
+		assert.InDelta(t, 0.25, p, 0.001)
+	}
+	assert.Equal(t, []float32{0, 0, 1, 0}, out.SoftMaxVar("ActM", 0))
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, 2, out.SampleAction("ActM", 0, rnd))
+	}
+
+	out.Neurons[2].SetFlag(NeuronOff)
+	probs = out.SoftMaxVar("ActM", temp)
+	assert.Equal(t, float32(0), probs[2])
+	assert.InDelta(t, math.E/(2+math.E), probs[1], 1.0e-5)
+	out.Neurons[2].ClearFlag(NeuronOff)
+
+	assert.Nil(t, out.SoftMaxVar("NotAVar", temp))
+	assert.Equal(t, -1, out.SampleAction("NotAVar", temp, rnd))
+	assert.Nil(t, out.SoftMaxVarPool("ActM", 1, temp))
+}
+
+func TestIntrinsicPlast(t *testing.T) {
+	net := NewNetwork("IntrinsicPlastTest")
+	shape := []int{4, 4}
+	inLay := net.AddLayer("Input", shape, InputLayer)
+	hidLay := net.AddLayer("Hidden", shape, SuperLayer)
+	net.ConnectLayers(inLay, hidLay, prjn.NewFull(), ForwardPrjn)
+	assert.NoError(t, net.Build())
+	net.Defaults()
+	ip := &hidLay.Params.Learn.IntrinsicPlast
+	ip.On.SetBool(true)
+	ip.Target = 0.02
+	ip.Tau = 5
+	ip.Min = -0.3
+	ip.Max = 0.3
+	ip.Update()
+	net.SetRndSeed(1)
+	net.InitWts()
+
+	inPat := etensor.NewFloat32(shape, nil, nil)
+	for i := 0; i < len(inPat.Values); i += 4 {
+		inPat.Values[i] = 1
+	}
+	ctx := NewContext()
+	trial := func() float32 {
+		net.InitExt()
+		inLay.ApplyExt(inPat)
+		net.ApplyExts(ctx)
+		net.NewState(ctx)
+		ctx.NewState(etime.Train)
+		for cyc := 0; cyc < 200; cyc++ {
+			net.Cycle(ctx)
+			ctx.CycleInc()
+			if cyc == 149 {
+				net.MinusPhase(ctx)
+				ctx.NewPhase(true)
+				net.PlusPhaseStart(ctx)
+			}
+		}
+		net.PlusPhase(ctx)
+		actm := float32(0)
+		for ni := range hidLay.Neurons {
+			actm += hidLay.Neurons[ni].ActM
+		}
+		return actm / float32(len(hidLay.Neurons))
+	}
+
+	first := trial()
+	assert.Greater(t, first, ip.Target) // over-active
+	for ni := range hidLay.Neurons {
+		nrn := &hidLay.Neurons[ni]
+		assert.InDelta(t, ip.Dt*(nrn.ActM-ip.Target), nrn.SpkThrOff, 1.0e-6) // up if over, down if under
+	}
+	last := first
+	for i := 0; i < 20; i++ {
+		last = trial()
+	}
+	assert.Less(t, mat32.Abs(last-ip.Target), 0.5*mat32.Abs(first-ip.Target))
+	for ni := range hidLay.Neurons {
+		off := hidLay.Neurons[ni].SpkThrOff
+		assert.True(t, off >= ip.Min && off <= ip.Max)
+	}
+
+	// threshold of a silent neuron stops at Min
+	off := float32(0)
+	for i := 0; i < 100; i++ {
+		ip.ThrFmAct(&off, 0)
+	}
+	assert.Equal(t, ip.Min, off)
+	for i := 0; i < 100; i++ {
+		ip.ThrFmAct(&off, 1)
+	}
+	assert.Equal(t, ip.Max, off)
+
+	net.InitWts()
+	for ni := range hidLay.Neurons {
+		assert.Equal(t, float32(0), hidLay.Neurons[ni].SpkThrOff)
+	}
+	ip.On.SetBool(false)
+	trial()
+	for ni := range hidLay.Neurons {
+		assert.Equal(t, float32(0), hidLay.Neurons[ni].SpkThrOff)
+	}
+}
+
+func TestSetInhibFmLayers(t *testing.T) {
+	shape := []int{4, 4}
+	// returns the mean ActM of layer B, with layer A input on or off
+	runB := func(inhib, aOn bool) float32 {
+		net := NewNetwork("InhibFmLayersTest")
+		inA := net.AddLayer("InputA", shape, InputLayer)
+		inB := net.AddLayer("InputB", shape, InputLayer)
+		lA := net.AddLayer("A", shape, SuperLayer)
+		lB := net.AddLayer("B", shape, SuperLayer)
+		net.ConnectLayers(inA, lA, prjn.NewFull(), ForwardPrjn)
+		net.ConnectLayers(inB, lB, prjn.NewFull(), ForwardPrjn)
+		if inhib {
+			lA.SetInhibFmLayers([]*Layer{lB}, 1)
+			lB.SetInhibFmLayers([]*Layer{lA}, 1)
+		}
+		assert.NoError(t, net.Build())
+		net.Defaults()
+		inA.Params.Inhib.Layer.On.SetBool(false) // all of InputA fully active
+		net.SetRndSeed(1)                        // same weights in each run
+		net.InitWts()
+		if inhib {
+			assert.Equal(t, int32(lA.Index()), lB.Params.LayInhib.Idx1)
+			assert.Equal(t, int32(-1), lB.Params.LayInhib.Idx2)
+			assert.Equal(t, float32(1), lB.Params.LayInhib.Gain)
+		} else {
+			assert.Equal(t, int32(-1), lB.Params.LayInhib.Idx1)
+		}
+
+		patA := etensor.NewFloat32(shape, nil, nil)
+		patB := etensor.NewFloat32(shape, nil, nil)
+		for i := range patA.Values {
+			if aOn {
+				patA.Values[i] = 1
+			}
+			if i%4 == 0 {
+				patB.Values[i] = 1
+			}
+		}
+		ctx := NewContext()
+		net.InitExt()
+		inA.ApplyExt(patA)
+		inB.ApplyExt(patB)
+		net.ApplyExts(ctx)
+		net.NewState(ctx)
+		ctx.NewState(etime.Train)
+		for cyc := 0; cyc < 150; cyc++ {
+			net.Cycle(ctx)
+			ctx.CycleInc()
+		}
+		net.MinusPhase(ctx)
+		actm := float32(0)
+		for ni := range lB.Neurons {
+			actm += lB.Neurons[ni].ActM
+		}
+		return actm / float32(len(lB.Neurons))
+	}
+
+	assert.InDelta(t, runB(false, false), runB(false, true), 1.0e-6)
+	bAlone := runB(true, false)
+	bComp := runB(true, true)
+	assert.Greater(t, bAlone, float32(0))
+	assert.Less(t, bComp, bAlone)
+
+	// updates params directly after Build
+	net := createNetwork([]int{2, 2}, t)
+	hid := net.AxonLayerByName("Hidden")
+	out := net.AxonLayerByName("Output")
+	hid.SetInhibFmLayers([]*Layer{out}, 0.5)
+	assert.Equal(t, int32(out.Index()), hid.Params.LayInhib.Idx1)
+	assert.Equal(t, float32(0.5), hid.Params.LayInhib.Gain)
+	assert.Equal(t, "0.5", hid.BuildConfig["LayInhibGain"])
+	hid.SetInhibFmLayers(nil, 1)
+	assert.Equal(t, int32(-1), hid.Params.LayInhib.Idx1)
+}
+
+func TestApplyExtSparse(t *testing.T) {
+	net := NewNetwork("SparseExtTest")
+	fullLay := net.AddLayer2D("Full", 4, 4, InputLayer)
+	sparseLay := net.AddLayer2D("Sparse", 4, 4, InputLayer)
+	trgLay := net.AddLayer2D("Target", 4, 4, TargetLayer)
+	trgSparseLay := net.AddLayer2D("TargetSparse", 4, 4, TargetLayer)
+	assert.NoError(t, net.Build())
+	net.Defaults()
+	net.InitWts()
+
+	pats := []map[int]float32{{1: 1, 5: 0.5}, {5: 1, 12: 1}, {}, {0: 0.8, 15: 1}}
+	net.InitExt()
+	for _, pat := range pats {
+		tsr := etensor.NewFloat32([]int{4, 4}, nil, nil)
+		for ni, val := range pat {
+			tsr.Values[ni] = val
+		}
+		fullLay.InitExt()
+		fullLay.ApplyExt(tsr)
+		sparseLay.ApplyExtSparse(pat)
+		trgLay.InitExt()
+		trgLay.ApplyExt(tsr)
+		trgSparseLay.ApplyExtSparse(pat)
+		for ni := range fullLay.Neurons {
+			fn := &fullLay.Neurons[ni]
+			sn := &sparseLay.Neurons[ni]
+			assert.Equal(t, fn.Ext, sn.Ext)
+			assert.Equal(t, fn.Flags, sn.Flags)
+			assert.Equal(t, fullLay.Exts[ni], sparseLay.Exts[ni])
+			tn := &trgLay.Neurons[ni]
+			tsn := &trgSparseLay.Neurons[ni]
+			assert.Equal(t, tn.Target, tsn.Target)
+			assert.Equal(t, tn.Flags, tsn.Flags)
+			assert.Equal(t, trgLay.Exts[ni], trgSparseLay.Exts[ni])
+		}
+		assert.Equal(t, len(pat), len(sparseLay.ExtSparseIdxs))
+	}
+	sparseLay.InitExt()
+	assert.Nil(t, sparseLay.ExtSparseIdxs)
+}
+
+func TestSpkCnt(t *testing.T) {
+	net := createNetwork([]int{4, 4}, t)
+	inLay := net.AxonLayerByName("Input")
+	hidLay := net.AxonLayerByName("Hidden")
+	pat := etensor.NewFloat32([]int{4, 4}, nil, nil)
+	for i := range pat.Values {
+		if i%3 == 0 {
+			pat.Values[i] = 1
+		}
+	}
+
+	ctx := NewContext()
+	sums := make([]float32, len(hidLay.Neurons))
+	for trl := 0; trl < 2; trl++ {
+		net.InitExt()
+		inLay.ApplyExt(pat)
+		net.ApplyExts(ctx)
+		net.NewState(ctx)
+		ctx.NewState(etime.Train)
+		for ni := range sums {
+			sums[ni] = 0
+		}
+		assert.Equal(t, sums, hidLay.SpkCnts())
+		for cyc := 0; cyc < 200; cyc++ {
+			net.Cycle(ctx)
+			ctx.CycleInc()
+			for ni := range hidLay.Neurons {
+				sums[ni] += hidLay.Neurons[ni].Spike
+			}
+			if cyc == 149 {
+				net.MinusPhase(ctx)
+				ctx.NewPhase(true)
+				net.PlusPhaseStart(ctx)
+			}
+		}
+		net.PlusPhase(ctx)
+		assert.Equal(t, sums, hidLay.SpkCnts())
+	}
+	tot := float32(0)
+	for _, s := range sums {
+		tot += s
+	}
+	assert.Greater(t, tot, float32(0))
+
+	net.InitActs()
+	assert.Equal(t, float32(0), hidLay.SpkCnts()[0])
+}
+
+func TestBurstThrModes(t *testing.T) {
+	bp := BurstParams{}
+	bp.Defaults()
+	assert.Equal(t, bp.ThrFmAvgMax(0.2, 0.8), bp.Thr(0.2, 0.8, 0.5))
+	bp.ThrMode = BurstThrAbs
+	assert.Equal(t, bp.ThrAbs, bp.Thr(0.2, 0.8, 0.5))
+	bp.ThrMode = BurstThrPctile
+	assert.Equal(t, float32(0.5), bp.Thr(0.2, 0.8, 0.5))
+
+	pat := etensor.NewFloat32([]int{10, 10}, nil, nil)
+	for i := range pat.Values {
+		if i%5 == 0 {
+			pat.Values[i] = 1
+		}
+	}
+	// runs a trial in given mode, returning the number of bursting Hidden
+	// neurons at the end, and the number that would burst with no threshold
+	runTrial := func(mode BurstThrModes, thrAbs float32) (nburst, nact int) {
+		net := createNetwork([]int{10, 10}, t)
+		net.SetRndSeed(3) // same weights in each mode
+		net.InitWts()
+		hid := net.AxonLayerByName("Hidden")
+		hid.Params.Burst.ThrMode = mode
+		hid.Params.Burst.ThrAbs = thrAbs
+		hid.Params.Burst.ThrPctile = 0.95 // only a few of 100 are active
+		ctx := NewContext()
+		net.InitExt()
+		net.AxonLayerByName("Input").ApplyExt(pat)
+		net.ApplyExts(ctx)
+		net.NewState(ctx)
+		ctx.NewState(etime.Train)
+		for cyc := 0; cyc < 200; cyc++ {
+			net.Cycle(ctx)
+			ctx.CycleInc()
+			if cyc == 149 {
+				net.MinusPhase(ctx)
+				ctx.NewPhase(true)
+				net.PlusPhaseStart(ctx)
+				if mode == BurstThrPctile {
+					var vals []float32
+					hid.UnitVals(&vals, "CaSpkP")
+					nbelow := 0
+					for _, v := range vals {
+						if v < hid.Pools[0].BurstThr {
+							nbelow++
+						}
+					}
+					assert.LessOrEqual(t, nbelow, 94)
+					assert.Greater(t, hid.Pools[0].BurstThr, float32(0))
+				}
+			}
+		}
+		net.PlusPhase(ctx)
+		for ni := range hid.Neurons {
+			nrn := &hid.Neurons[ni]
+			if nrn.Burst > 0 {
+				nburst++
+			}
+			if nrn.CaSpkP > 0 {
+				nact++
+			}
+		}
+		return
+	}
+	nabs, nact := runTrial(BurstThrAbs, 0)
+	assert.Equal(t, nact, nabs)
+	navg, _ := runTrial(BurstThrAvgMax, 0.1)
+	npct, _ := runTrial(BurstThrPctile, 0.1)
+	assert.Less(t, navg, nabs)
+	assert.Less(t, npct, nabs)
+	assert.Greater(t, npct, 0)
+}
+
+func TestSetLayerType(t *testing.T) {
+	pat := etensor.NewFloat32([]int{4, 4}, nil, nil)
+	for i := range pat.Values {
+		if i%4 == 0 {
+			pat.Values[i] = 1
+		}
+	}
+	// runs a trial and returns the summed abs DWt into Output, and PctUnitErr
+	runTrial := func(net *Network) (dwt float32, err float64) {
+		ctx := NewContext()
+		net.InitExt()
+		net.AxonLayerByName("Input").ApplyExt(pat)
+		net.AxonLayerByName("Output").ApplyExt(pat)
+		net.ApplyExts(ctx)
+		net.NewState(ctx)
+		ctx.NewState(etime.Train)
+		for cyc := 0; cyc < 200; cyc++ {
+			net.Cycle(ctx)
+			ctx.CycleInc()
+			if cyc == 149 {
+				net.MinusPhase(ctx)
+				ctx.NewPhase(true)
+				net.PlusPhaseStart(ctx)
+			}
+		}
+		net.PlusPhase(ctx)
+		net.DWt(ctx)
+		out := net.AxonLayerByName("Output")
+		for _, pj := range out.RcvPrjns {
+			for si := range pj.Syns {
+				dwt += mat32.Abs(pj.Syns[si].DWt)
+			}
+		}
+		return dwt, out.PctUnitErr()
+	}
+
+	trgNet := createNetwork([]int{4, 4}, t)
+	trgDWt, _ := runTrial(trgNet)
+
+	net := createNetwork([]int{4, 4}, t)
+	out := net.AxonLayerByName("Output")
+	assert.True(t, out.Params.Act.Clamp.IsTarget.IsTrue())
+	out.Params.Act.Clamp.Ge = 0.7
+	out.Params.Burst.ThrRel = 0.5
+	assert.NoError(t, net.SetLayerType("Output", CompareLayer))
+	assert.Error(t, net.SetLayerType("NoLayer", CompareLayer))
+	assert.Equal(t, CompareLayer, out.LayerType())
+	assert.Equal(t, CompareLayer, out.Params.LayType)
+	assert.False(t, out.Params.Act.Clamp.IsTarget.IsTrue())
+	assert.Equal(t, float32(0.7), out.Params.Act.Clamp.Ge) // shared params preserved
+	assert.Equal(t, float32(0.1), out.Params.Burst.ThrRel) // type-specific reset
+
+	cmpDWt, cmpErr := runTrial(net)
+	for ni := range out.Neurons {
+		nrn := &out.Neurons[ni]
+		assert.Equal(t, pat.Values[ni], nrn.Target)
+		assert.False(t, nrn.HasFlag(NeuronHasExt))
+	}
+	assert.Greater(t, trgDWt, float32(0))
+	assert.Less(t, cmpDWt, 0.5*trgDWt)
+	assert.Greater(t, cmpErr, 0.0)
+}
+
+func TestPredFloor(t *testing.T) {
+	// runs RW trial with zero prediction weights, returning min pred, max DA
+	runRW := func(floor float32) (minPred, maxDA float32) {
+		net := NewNetwork("RWTest")
+		inp := net.AddLayer2D("Input", 1, 4, InputLayer)
+		_, rp, _ := net.AddRWLayers("", relpos.RightOf, 2)
+		pj := net.ConnectToRWPrjn(inp, rp, prjn.NewFull())
+		assert.NoError(t, net.Build())
+		net.Defaults()
+		rp.Params.RWPred.PredFloor = floor
+		net.InitWts()
+		for si := range pj.Syns {
+			pj.Syns[si].Wt = 0
+		}
+		pat := etensor.NewFloat32([]int{1, 4}, nil, nil)
+		pat.Values[0] = 1
+		pat.Values[2] = 1
+		ctx := NewContext()
+		net.InitExt()
+		inp.ApplyExt(pat)
+		net.ApplyExts(ctx)
+		net.NewState(ctx)
+		ctx.NewState(etime.Train)
+		ctx.NeuroMod.SetRew(1, true)
+		minPred = 1
+		maxDA = -1
+		for cyc := 0; cyc < 50; cyc++ {
+			net.Cycle(ctx)
+			ctx.CycleInc()
+			minPred = mat32.Min(minPred, ctx.NeuroMod.RewPred)
+			maxDA = mat32.Max(maxDA, ctx.NeuroMod.DA)
+		}
+		return
+	}
+	minPred, maxDA := runRW(0)
+	assert.Less(t, minPred, float32(0.3))
+	assert.Greater(t, maxDA, float32(0.7))
+	minPred, maxDA = runRW(0.3)
+	assert.GreaterOrEqual(t, minPred, float32(0.3))
+	assert.LessOrEqual(t, maxDA, float32(0.7))
+
+	// no floor by default: negative predictions are retained
+	rwp := &LayerParams{}
+	rwp.Defaults()
+	rctx := NewContext()
+	vals, pvals := &LayerVals{}, &LayerVals{}
+	pvals.Special.V1 = 0.1
+	pvals.Special.V2 = 0.3
+	rwp.CyclePostRWDaLayer(rctx, vals, pvals, 0)
+	assert.InDelta(t, -0.2, rctx.NeuroMod.RewPred, 1.0e-6)
+	rwp.CyclePostRWDaLayer(rctx, vals, pvals, 0.1)
+	assert.InDelta(t, 0.1, rctx.NeuroMod.RewPred, 1.0e-6)
+
+	lp := &LayerParams{}
+	lp.Defaults()
+	lp.LayType = VSPatchLayer
+	lp.VSPatch.PredFloor = 0.05
+	ctx := NewContext()
+	pl := &Pool{}
+	lp.CyclePostVSPatchLayer(ctx, 1, pl)
+	assert.Equal(t, float32(0.05), ctx.PVLV.VSPatch.Get(0))
+	pl.AvgMax.CaSpkD.Cycle.Avg = 1
+	lp.CyclePostVSPatchLayer(ctx, 1, pl)
+	assert.Greater(t, ctx.PVLV.VSPatch.Get(0), float32(0.05))
+}
+
+func TestClampToFrozen(t *testing.T) {
+	net := createNetwork([]int{4, 4}, t)
+	inLay := net.AxonLayerByName("Input")
+	hid := net.AxonLayerByName("Hidden")
+	out := net.AxonLayerByName("Output")
+	patA := etensor.NewFloat32([]int{4, 4}, nil, nil)
+	patB := etensor.NewFloat32([]int{4, 4}, nil, nil)
+	for i := range patA.Values {
+		if i%4 == 0 {
+			patA.Values[i] = 1
+		}
+		if i%4 == 2 {
+			patB.Values[i] = 1
+		}
+	}
+	// runs the minus phase of a trial from a clean state, returning Output ActM
+	runTrial := func(pat *etensor.Float32) []float32 {
+		ctx := NewContext()
+		net.InitActs()
+		net.InitExt()
+		inLay.ApplyExt(pat)
+		net.ApplyExts(ctx)
+		net.NewState(ctx)
+		ctx.NewState(etime.Train)
+		for cyc := 0; cyc < 150; cyc++ {
+			net.Cycle(ctx)
+			ctx.CycleInc()
+		}
+		net.MinusPhase(ctx)
+		var acts []float32
+		out.UnitVals(&acts, "ActM")
+		return acts
+	}
+	outA := runTrial(patA)
+	outB := runTrial(patB)
+	assert.NotEqual(t, outA, outB)
+
+	hid.ClampToFrozen(true) // no frozen acts yet
+	assert.False(t, hid.FrozenClamp)
+
+	runTrial(patA)
+	hid.FreezeActs()
+	assert.Equal(t, len(hid.Neurons), len(hid.FrozenActs))
+	hid.ClampToFrozen(true)
+	assert.True(t, hid.FrozenClamp)
+	for ni := range hid.Neurons {
+		assert.Equal(t, hid.FrozenActs[ni], hid.Neurons[ni].Ext)
+		assert.True(t, hid.Neurons[ni].HasFlag(NeuronHasExt))
+	}
+	// downstream response is now the same regardless of upstream input
+	outA = runTrial(patA)
+	outB = runTrial(patB)
+	assert.Equal(t, outA, outB)
+
+	hid.ClampToFrozen(false)
+	for ni := range hid.Neurons {
+		assert.False(t, hid.Neurons[ni].HasFlag(NeuronHasExt))
+	}
+	assert.NotEqual(t, runTrial(patA), runTrial(patB))
+}
+
+func TestDecayOnGate(t *testing.T) {
+	net := createNetwork([]int{4, 4}, t)
+	inLay := net.AxonLayerByName("Input")
+	hid := net.AxonLayerByName("Hidden")
+	out := net.AxonLayerByName("Output")
+	hid.Params.Act.Decay.OnGate.SetBool(true)
+	pat := etensor.NewFloat32([]int{4, 4}, nil, nil)
+	for i := range pat.Values {
+		if i%3 == 0 {
+			pat.Values[i] = 1
+		}
+	}
+	ctx := NewContext()
+	// runs a full trial, returning the summed Act of hid and out after the plus phase
+	runTrial := func(gated bool) (hidAct, outAct float32) {
+		net.InitExt()
+		inLay.ApplyExt(pat)
+		net.ApplyExts(ctx)
+		net.NewState(ctx)
+		ctx.NewState(etime.Train)
+		ctx.PVLV.VSMatrix.JustGated.SetBool(gated) // no VS Matrix layer to set it
+		for cyc := 0; cyc < 200; cyc++ {
+			net.Cycle(ctx)
+			ctx.CycleInc()
+			if cyc == 149 {
+				net.MinusPhase(ctx)
+				ctx.NewPhase(true)
+				net.PlusPhaseStart(ctx)
+			}
+		}
+		net.PlusPhase(ctx)
+		for ni := range hid.Neurons {
+			hidAct += hid.Neurons[ni].Act
+			outAct += out.Neurons[ni].Act
+		}
+		return
+	}
+	hidAct, outAct := runTrial(false)
+	assert.Greater(t, hidAct, float32(0))
+	assert.Greater(t, outAct, float32(0))
+
+	hidAct, outAct = runTrial(true)
+	assert.Equal(t, float32(0), hidAct)
+	assert.Greater(t, outAct, float32(0))
+
+	hidAct, _ = runTrial(false)
+	assert.Greater(t, hidAct, float32(0))
+}
+
+func TestSpikeCost(t *testing.T) {
+	net := createNetwork([]int{4, 4}, t)
+	inLay := net.AxonLayerByName("Input")
+	inLay.Cost.GCost = 0.1
+	dense := etensor.NewFloat32([]int{4, 4}, nil, nil)
+	sparse := etensor.NewFloat32([]int{4, 4}, nil, nil)
+	for i := range dense.Values {
+		dense.Values[i] = 1
+		if i%8 == 0 {
+			sparse.Values[i] = 1
+		}
+	}
+	ctx := NewContext()
+	runTrial := func(pat *etensor.Float32) {
+		net.InitExt()
+		inLay.ApplyExt(pat)
+		net.ApplyExts(ctx)
+		net.NewState(ctx)
+		ctx.NewState(etime.Train)
+		for cyc := 0; cyc < 200; cyc++ {
+			net.Cycle(ctx)
+			ctx.CycleInc()
+			if cyc == 149 {
+				net.MinusPhase(ctx)
+				ctx.NewPhase(true)
+				net.PlusPhaseStart(ctx)
+			}
+		}
+		net.PlusPhase(ctx)
+	}
+	// not recorded by default
+	runTrial(dense)
+	assert.Equal(t, float32(0), net.TotalCost())
+
+	net.SetRecordCosts(true)
+	runTrial(dense)
+	denseCost := inLay.SpikeCost()
+	assert.Greater(t, inLay.Cost.Spikes, float32(0))
+	assert.Greater(t, inLay.Cost.G, float32(0))
+	assert.Greater(t, net.TotalCost(), denseCost)
+
+	net.ResetCosts()
+	assert.Equal(t, float32(0), net.TotalCost())
+	runTrial(sparse)
+	assert.Greater(t, denseCost, inLay.SpikeCost())
+
+	// accumulates across trials until reset
+	sparseCost := inLay.SpikeCost()
+	runTrial(sparse)
+	assert.Greater(t, inLay.SpikeCost(), sparseCost)
+}
+
+func TestUnitPermutation(t *testing.T) {
+	net := createNetwork([]int{2, 3}, t)
+	inLay := net.AxonLayerByName("Input")
+	outLay := net.AxonLayerByName("Output")
+	pat := etensor.NewFloat32([]int{2, 3}, nil, nil)
+	for i := range pat.Values {
+		pat.Values[i] = float32(i+1) * 0.1
+	}
+
+	perm := inLay.RandomPermutation(42)
+	assert.Equal(t, perm, inLay.RandomPermutation(42))
+	assert.Equal(t, 6, len(perm))
+	assert.NoError(t, inLay.SetUnitPermutation(perm))
+	assert.Error(t, inLay.SetUnitPermutation([]int{0, 1, 2}))
+	assert.Error(t, inLay.SetUnitPermutation([]int{0, 1, 2, 3, 4, 4}))
+	assert.Equal(t, perm, inLay.UnitPerm)
+
+	net.InitExt()
+	inLay.ApplyExt(pat)
+	for i, v := range pat.Values {
+		assert.Equal(t, v, inLay.Neurons[perm[i]].Ext)
+		assert.Equal(t, v, inLay.Exts[perm[i]])
+	}
+	inLay.ApplyExt1D32(pat.Values)
+	for i, v := range pat.Values {
+		assert.Equal(t, v, inLay.Neurons[perm[i]].Ext)
+	}
+
+	// targets are permuted as well
+	assert.NoError(t, outLay.SetUnitPermutation([]int{5, 4, 3, 2, 1, 0}))
+	outLay.ApplyExt(pat)
+	for i, v := range pat.Values {
+		assert.Equal(t, v, outLay.Neurons[5-i].Target)
+	}
+
+	assert.NoError(t, inLay.SetUnitPermutation(nil))
+	net.InitExt()
+	inLay.ApplyExt(pat)
+	for i, v := range pat.Values {
+		assert.Equal(t, v, inLay.Neurons[i].Ext)
+	}
+}
+
+func TestErrSignal(t *testing.T) {
+	net := createNetwork([]int{2, 2}, t)
+	hid := net.AxonLayerByName("Hidden")
+	out := net.AxonLayerByName("Output")
+	minus := []float32{0.2, 0.5, 0.3, 0}
+	plus := []float32{0.6, 0.5, 0.1, 0}
+	for ni := range out.Neurons {
+		out.Neurons[ni].CaSpkPM = minus[ni]
+		out.Neurons[ni].CaSpkP = plus[ni]
+		hid.Neurons[ni].CaSpkPM = 0.1
+		hid.Neurons[ni].CaSpkP = 0.1
+	}
+	out.Neurons[3].SetFlag(NeuronOff)
+	out.Neurons[3].CaSpkP = 1
+	errs := out.ErrSignal()
+	assert.Equal(t, 4, len(errs))
+	assert.InDelta(t, 0.4, errs[0], 1.0e-6)
+	assert.Equal(t, float32(0), errs[1])
+	assert.InDelta(t, -0.2, errs[2], 1.0e-6)
+	assert.Equal(t, float32(0), errs[3])
+
+	byCls := net.ErrSignalByClass("TargetLayer")
+	assert.Equal(t, 1, len(byCls))
+	assert.Equal(t, errs, byCls["Output"])
+	byCls = net.ErrSignalByClass("TargetLayer", "SuperLayer")
+	assert.Equal(t, []float32{0, 0, 0, 0}, byCls["Hidden"])
+}
+
+func TestEIBalance(t *testing.T) {
+	net := createNetwork([]int{2, 2}, t)
+	hid := net.AxonLayerByName("Hidden")
+	ges := []float32{0.2, 0.4, 0.6, 10}
+	gis := []float32{0.1, 0.2, 0.3, 10}
+	for ni := range hid.Neurons {
+		nrn := &hid.Neurons[ni]
+		nrn.Ge = ges[ni]
+		nrn.Gi = gis[ni]
+		nrn.GeInt = ges[ni]
+		nrn.GiInt = 2 * gis[ni]
+	}
+	hid.Neurons[3].SetFlag(NeuronOff)
+	ge, gi, ratio := hid.EIBalance()
+	assert.InDelta(t, 0.4, ge, 1.0e-6)
+	assert.InDelta(t, 0.2, gi, 1.0e-6)
+	assert.InDelta(t, 2, ratio, 1.0e-5)
+
+	// epoch version accumulates integrated values over trials
+	ge, gi, ratio = hid.EIBalanceEpoch()
+	assert.Equal(t, float32(0), ratio)
+	hid.EIBal.Record(hid.Neurons) // not recorded by default
+	assert.Equal(t, 0, hid.EIBal.N)
+	hid.SetRecordEIBalance(true)
+	hid.EIBal.Record(hid.Neurons)
+	for ni := range hid.Neurons {
+		hid.Neurons[ni].GeInt *= 2
+	}
+	hid.EIBal.Record(hid.Neurons)
+	ge, gi, ratio = hid.EIBalanceEpoch()
+	assert.Equal(t, 2, hid.EIBal.N)
+	assert.InDelta(t, 0.6, ge, 1.0e-6)
+	assert.InDelta(t, 0.4, gi, 1.0e-6)
+	assert.InDelta(t, 1.5, ratio, 1.0e-5)
+	hid.ResetEIBalance()
+	_, _, ratio = hid.EIBalanceEpoch()
+	assert.Equal(t, float32(0), ratio)
+
+	for ni := range hid.Neurons {
+		hid.Neurons[ni].Gi = 0
+	}
+	_, _, ratio = hid.EIBalance()
+	assert.Equal(t, float32(0), ratio)
+}
+
+func TestClampPoolGi(t *testing.T) {
+	net := createNetwork([]int{4, 4}, t)
+	inLay := net.AxonLayerByName("Input")
+	hid := net.AxonLayerByName("Hidden")
+	pat := etensor.NewFloat32([]int{4, 4}, nil, nil)
+	for i := range pat.Values {
+		if i%3 == 0 {
+			pat.Values[i] = 1
+		}
+	}
+	ctx := NewContext()
+	runCycles := func() {
+		net.InitExt()
+		inLay.ApplyExt(pat)
+		net.ApplyExts(ctx)
+		net.NewState(ctx)
+		ctx.NewState(etime.Train)
+		for cyc := 0; cyc < 50; cyc++ {
+			net.Cycle(ctx)
+			ctx.CycleInc()
+		}
+	}
+	// expected neuron Gi given the pool Gi
+	nrnGi := func(ni int, plGi float32) float32 {
+		nrn := &hid.Neurons[ni]
+		return hid.Vals.ActAvg.GiMult*plGi + nrn.GiSyn + nrn.GiNoise
+	}
+
+	hid.ClampPoolGi(0, 2)
+	runCycles()
+	assert.Equal(t, float32(2), hid.Pools[0].Inhib.Gi)
+	for ni := range hid.Neurons {
+		assert.InDelta(t, nrnGi(ni, 2), hid.Neurons[ni].Gi, 1.0e-5)
+	}
+
+	hid.ClearClampGi()
+	runCycles()
+	assert.NotEqual(t, float32(2), hid.Pools[0].Inhib.Gi)
+	assert.InDelta(t, nrnGi(0, hid.Pools[0].Inhib.Gi), hid.Neurons[0].Gi, 1.0e-5)
+
+	hid.ClampPoolGi(5, 2) // out of range: no-op
+	assert.False(t, hid.Pools[0].GiClamp.IsTrue())
+}
+
+func TestSpikeFunc(t *testing.T) {
+	net := createNetwork([]int{4, 4}, t)
+	inLay := net.AxonLayerByName("Input")
+	hid := net.AxonLayerByName("Hidden")
+	pat := etensor.NewFloat32([]int{4, 4}, nil, nil)
+	for i := range pat.Values {
+		pat.Values[i] = 1
+	}
+	ctx := NewContext()
+	runCycles := func() int {
+		net.InitExt()
+		inLay.ApplyExt(pat)
+		net.ApplyExts(ctx)
+		net.NewState(ctx)
+		ctx.NewState(etime.Train)
+		nspk := 0
+		for cyc := 0; cyc < 50; cyc++ {
+			net.Cycle(ctx)
+			ctx.CycleInc()
+			for ni := range hid.Neurons {
+				nspk += int(hid.Neurons[ni].Spike)
+			}
+		}
+		return nspk
+	}
+	assert.Greater(t, runCycles(), 0)
+	hid.SetSpikeFunc(PoissonSpikeFunc(0, 1)) // never spikes
+	assert.Equal(t, 0, runCycles())
+	hid.SetSpikeFunc(nil)
+	assert.Greater(t, runCycles(), 0)
+
+	// Poisson firing rate tracks Ge
+	gain := float32(2)
+	hid.SetSpikeFunc(PoissonSpikeFunc(gain, 1))
+	ncyc := 4000
+	for _, ge := range []float32{0.05, 0.2, 0.4} {
+		nrn := &hid.Neurons[0]
+		nspk := 0
+		for cyc := 0; cyc < ncyc; cyc++ {
+			nrn.Ge = ge
+			hid.SpikeFmG(ctx, 0, nrn)
+			nspk += int(nrn.Spike)
+		}
+		rate := float32(nspk) / float32(ncyc)
+		assert.InDelta(t, float64(gain*ge), float64(rate), 0.03)
+	}
+}
+
+func TestSpkMaxResets(t *testing.T) {
+	net := createNetwork([]int{4, 4}, t)
+	inLay := net.AxonLayerByName("Input")
+	hid := net.AxonLayerByName("Hidden")
+	pat := etensor.NewFloat32([]int{4, 4}, nil, nil)
+	for i := range pat.Values {
+		pat.Values[i] = 1
+	}
+	ctx := NewContext()
+	net.InitExt()
+	inLay.ApplyExt(pat)
+	net.ApplyExts(ctx)
+	net.NewState(ctx)
+	ctx.NewState(etime.Train)
+	spkMax := func() float32 {
+		sum := float32(0)
+		for ni := range hid.Neurons {
+			sum += hid.Neurons[ni].SpkMax
+		}
+		return sum
+	}
+	hid.SetSpkMaxResets(100)
+	var preMax float32
+	for cyc := 0; cyc < 150; cyc++ {
+		net.Cycle(ctx)
+		if cyc == 98 {
+			preMax = spkMax()
+		}
+		if cyc == 99 { // reset at end of cycle 99
+			assert.Equal(t, float32(0), spkMax())
+			for ni := range hid.Neurons {
+				assert.Equal(t, float32(0), hid.Neurons[ni].SpkMaxCa)
+			}
+		}
+		ctx.CycleInc()
+	}
+	assert.Greater(t, preMax, float32(0))
+	assert.Greater(t, spkMax(), float32(0)) // restarted accumulation
+
+	hid.ResetSpkMax()
+	assert.Equal(t, float32(0), spkMax())
+	hid.SetSpkMaxResets()
+	assert.Nil(t, hid.SpkMaxResets)
+}
+
+func TestRLRateFunc(t *testing.T) {
+	net := createNetwork([]int{4, 4}, t)
+	inLay := net.AxonLayerByName("Input")
+	outLay := net.AxonLayerByName("Output")
+	inPat := etensor.NewFloat32([]int{4, 4}, nil, nil)
+	trgPat := etensor.NewFloat32([]int{4, 4}, nil, nil)
+	for i := range inPat.Values {
+		if i%3 == 0 {
+			inPat.Values[i] = 1
+		}
+		if i%4 == 1 {
+			trgPat.Values[i] = 1
+		}
+	}
+	nn := len(outLay.Neurons)
+	base := make([]float32, nn)
+	boost := make([]float32, nn)
+	outLay.SetRLRateFunc(func(ni uint32, nrn *Neuron) float32 {
+		base[ni] = nrn.RLRate
+		boost[ni] = 1 + 10*mat32.Abs(nrn.CaSpkP-nrn.CaSpkD) // boost high error
+		return boost[ni] * nrn.RLRate
+	})
+
+	ctx := NewContext()
+	net.InitExt()
+	inLay.ApplyExt(inPat)
+	outLay.ApplyExt(trgPat)
+	net.ApplyExts(ctx)
+	net.NewState(ctx)
+	ctx.NewState(etime.Train)
+	for cyc := 0; cyc < 200; cyc++ {
+		net.Cycle(ctx)
+		ctx.CycleInc()
+		if cyc == 149 {
+			net.MinusPhase(ctx)
+			ctx.NewPhase(true)
+			net.PlusPhaseStart(ctx)
+		}
+	}
+	net.PlusPhase(ctx)
+
+	pj := outLay.RcvPrjns[0]
+	dwtSums := func() []float32 {
+		for si := range pj.Syns {
+			pj.Syns[si].DWt = 0
+		}
+		net.DWt(ctx)
+		sums := make([]float32, nn)
+		for ri := range sums {
+			syns := pj.RecvSyns(ri)
+			for ci := range syns {
+				sums[ri] += mat32.Abs(syns[ci].DWt)
+			}
+		}
+		return sums
+	}
+	boosted := dwtSums()
+	maxBoost := float32(1)
+	for ni := range outLay.Neurons {
+		nrn := &outLay.Neurons[ni]
+		assert.InDelta(t, boost[ni]*base[ni], nrn.RLRate, 1.0e-6)
+		maxBoost = mat32.Max(maxBoost, boost[ni])
+		nrn.RLRate = base[ni] // default
+	}
+	assert.Greater(t, maxBoost, float32(1.5))
+	dflt := dwtSums()
+	for ni := range outLay.Neurons {
+		assert.InDelta(t, boost[ni]*dflt[ni], boosted[ni], float64(1.0e-4*boosted[ni]+1.0e-9))
+	}
+
+	outLay.SetRLRateFunc(nil)
+	assert.Nil(t, outLay.RLRateFun)
+}
+
+func TestGapJunctions(t *testing.T) {
+	net := NewNetwork("GapTest")
+	ly := net.AddLayer2D("Inhib", 1, 2, SuperLayer)
+	assert.NoError(t, net.Build())
+	net.Defaults()
+	ges := []float32{0.3, 0.36} // different drive
+	run := func(gbar float32) (vmDif float32, nspk []int, coinc int) {
+		ly.SetGapJunctions(prjn.NewFull(), gbar)
+		net.InitWts()
+		ctx := NewContext()
+		nspk = make([]int, 2)
+		var spks [2][]int
+		ncyc := 1000
+		for cyc := 0; cyc < ncyc; cyc++ {
+			ly.GapJuncFmVm()
+			for ni := range ly.Neurons {
+				nrn := &ly.Neurons[ni]
+				nrn.Ge = ges[ni]
+				ly.SpikeFmG(ctx, uint32(ni), nrn)
+				if nrn.Spike > 0 {
+					nspk[ni]++
+					spks[ni] = append(spks[ni], cyc)
+				}
+			}
+			vmDif += mat32.Abs(ly.Neurons[0].Vm - ly.Neurons[1].Vm)
+			ctx.CycleInc()
+		}
+		vmDif /= float32(ncyc)
+		for _, c0 := range spks[0] {
+			for _, c1 := range spks[1] {
+				if c1-c0 >= -1 && c1-c0 <= 1 {
+					coinc++
+					break
+				}
+			}
+		}
+		return
+	}
+	freeDif, freeSpk, freeCoinc := run(0)
+	assert.Nil(t, ly.GapJunc.Igap)
+	assert.NotEqual(t, freeSpk[0], freeSpk[1])
+	gapDif, gapSpk, gapCoinc := run(0.5)
+	assert.Equal(t, []uint32{1}, ly.GapJunc.Cons[0])
+	assert.Equal(t, []uint32{0}, ly.GapJunc.Cons[1])
+	assert.Less(t, gapDif, freeDif)
+	assert.Equal(t, gapSpk[0], gapSpk[1]) // synchronized
+	assert.Greater(t, gapCoinc, freeCoinc)
+	assert.Greater(t, float32(gapCoinc), 0.9*float32(gapSpk[0]))
+}
+
+// tdChainVals runs given number of episodes of TD learning on a chain of
+// nStates states, each represented by one sending unit, with a reward of 1
+// after the last state, using the TD layer and TDPredPrjn learning
+// functions directly, and returns the resulting value of each state.
+func tdChainVals(lambda float32, nStates, nEps int) []float32 {
+	ctx := NewContext()
+	ilp := &LayerParams{}
+	ilp.Defaults()
+	ilp.TDInteg.Lambda = lambda
+	dlp := &LayerParams{}
+	dlp.Defaults()
+	pjp := &PrjnParams{}
+	pjp.Defaults()
+	pjp.Learn.LRate.Base = 0.5
+	pjp.Learn.LRate.Update()
+	var pvals, ivals, dvals LayerVals
+	sns := make([]Neuron, nStates)
+	rns := make([]Neuron, 2)
+	rns[1].NeurIdx = 1
+	syns := make([]Synapse, 2*nStates) // [recv][send]
+	val := func(st int) float32 {
+		if st >= nStates {
+			return 0
+		}
+		return syns[st].Wt - syns[nStates+st].Wt
+	}
+	for ep := 0; ep < nEps; ep++ {
+		prv := nStates // terminal state
+		for st := 0; st <= nStates; st++ {
+			for si := range sns {
+				sns[si].SpkPrv = 0
+			}
+			if prv < nStates {
+				sns[prv].SpkPrv = 1
+			}
+			ctx.NeuroMod.SetRew(1, st == nStates)
+			ctx.PlusPhase.SetBool(false)
+			ilp.CyclePostTDIntegLayer(ctx, &ivals, &pvals)
+			ctx.PlusPhase.SetBool(true)
+			v := val(st)
+			pvals.Special.V1 = mat32.Max(v, 0)
+			pvals.Special.V2 = mat32.Max(-v, 0)
+			ilp.CyclePostTDPredLayer(ctx, &pvals)
+			ilp.CyclePostTDIntegLayer(ctx, &ivals, &pvals)
+			dlp.CyclePostTDDaLayer(ctx, &dvals, &ivals)
+			for ri := range rns {
+				for si := range sns {
+					sy := &syns[ri*nStates+si]
+					pjp.DWtSynTDPred(ctx, sy, &sns[si], &rns[ri], nil, nil)
+					sy.Wt += sy.DWt
+					sy.DWt = 0
+				}
+			}
+			prv = st
+		}
+	}
+	vals := make([]float32, nStates)
+	for st := range vals {
+		vals[st] = val(st)
+	}
+	return vals
+}
+
+func TestTDLambda(t *testing.T) {
+	td0 := tdChainVals(0, 5, 2)
+	// one-step TD only propagates value back one state per episode
+	assert.Greater(t, td0[4], float32(0))
+	assert.Greater(t, td0[3], float32(0))
+
+	// the eligibility trace propagates value back to all states
+	tdl := tdChainVals(0.9, 5, 2)
+	for st := 0; st < 3; st++ {
+		assert.Equal(t, float32(0), td0[st])
+		assert.Greater(t, tdl[st], float32(0.5))
+	}
+}
+
+func TestNeuronCoord(t *testing.T) {
+	net := NewNetwork("CoordTest")
+	ly4 := net.AddLayer4D("Pools", 2, 3, 4, 5, SuperLayer)
+	ly2 := net.AddLayer2D("Flat", 3, 4, SuperLayer)
+	assert.NoError(t, net.Build())
+
+	for ni := range ly4.Neurons {
+		py, px, uy, ux := ly4.NeuronCoord(ni)
+		assert.Equal(t, ni, ly4.NeuronIdx(py, px, uy, ux))
+		assert.Equal(t, ni, ly4.Shp.Offset([]int{py, px, uy, ux}))
+		assert.Equal(t, uint32(py*3+px+1), ly4.Neurons[ni].SubPool)
+	}
+	py, px, uy, ux := ly4.NeuronCoord(4*5 + 2*5 + 3)
+	assert.Equal(t, []int{0, 1, 2, 3}, []int{py, px, uy, ux})
+	assert.Equal(t, -1, ly4.NeuronIdx(2, 0, 0, 0))
+	assert.Equal(t, -1, ly4.NeuronIdx(0, 0, 0, -1))
+
+	for ni := range ly2.Neurons {
+		py, px, uy, ux := ly2.NeuronCoord(ni)
+		assert.Equal(t, 0, py)
+		assert.Equal(t, 0, px)
+		assert.Equal(t, ni, uy*4+ux)
+		assert.Equal(t, ni, ly2.NeuronIdx(py, px, uy, ux))
+	}
+}
+
+func TestInhibInterneurons(t *testing.T) {
+	net := NewNetwork("InhibTest")
+	inLay := net.AddLayer2D("Input", 4, 4, InputLayer)
+	hid := net.AddLayer2D("Hidden", 4, 4, SuperLayer)
+	out := net.AddLayer2D("Output", 4, 4, SuperLayer)
+	full := prjn.NewFull()
+	net.ConnectLayers(inLay, hid, full, ForwardPrjn)
+	net.ConnectLayers(hid, out, full, ForwardPrjn)
+	inh := net.AddLayer2D("Inh", 2, 2, InhibInterneuronLayer)
+	net.ConnectLayers(hid, inh, full, ForwardPrjn)
+	inhPj := net.ConnectLayers(inh, out, full, InhibPrjn)
+	assert.NoError(t, net.Build())
+	net.Defaults()
+	net.InitWts()
+	assert.True(t, inhPj.Params.InhibLearn.On.IsTrue())
+	assert.False(t, out.RcvPrjns[0].Params.InhibLearn.On.IsTrue())
+
+	ctx := NewContext()
+	// runs cycles with given input, returning the summed Inh Act
+	// and Output synaptic inhibition GiSyn
+	runCycles := func(pat *etensor.Float32) (inhAct, outGi float32) {
+		net.InitActs()
+		net.InitExt()
+		inLay.ApplyExt(pat)
+		net.ApplyExts(ctx)
+		net.NewState(ctx)
+		ctx.NewState(etime.Train)
+		for cyc := 0; cyc < 100; cyc++ {
+			net.Cycle(ctx)
+			ctx.CycleInc()
+			for ni := range inh.Neurons {
+				inhAct += inh.Neurons[ni].Act
+			}
+			for ni := range out.Neurons {
+				outGi += out.Neurons[ni].GiSyn
+			}
+		}
+		return
+	}
+	pat := etensor.NewFloat32([]int{4, 4}, nil, nil)
+	inhAct, outGi := runCycles(pat)
+	assert.Equal(t, float32(0), inhAct)
+	assert.Equal(t, float32(0), outGi)
+
+	for i := range pat.Values {
+		if i%3 == 0 {
+			pat.Values[i] = 1
+		}
+	}
+	inhAct, outGi = runCycles(pat)
+	assert.Greater(t, inhAct, float32(0))
+	assert.Greater(t, outGi, float32(0))
+
+	// inhibition is strengthened for receivers above the target activity
+	il := &inhPj.Params.InhibLearn
+	assert.Greater(t, il.DWt(0.5, il.TrgAct+0.1), float32(0))
+	assert.Less(t, il.DWt(0.5, il.TrgAct-0.1), float32(0))
+	assert.Equal(t, float32(0), il.DWt(0, 0.5))
+}
+
+func TestMatrixActionValues(t *testing.T) {
+	net := NewNetwork("MatrixTest")
+	mtxGo := net.AddMatrixLayer("MtxGo", 1, 3, 2, 2, D1Mod)
+	mtxNo := net.AddMatrixLayer("MtxNo", 1, 3, 2, 2, D2Mod)
+	mtxGo.SetBuildConfig("OtherMatrixName", mtxNo.Name())
+	mtxNo.SetBuildConfig("OtherMatrixName", mtxGo.Name())
+	hid := net.AddLayer2D("Hidden", 2, 2, SuperLayer)
+	assert.NoError(t, net.Build())
+	net.Defaults()
+
+	goAct := []float32{0.2, 0.6, 0.5}
+	noAct := []float32{0.1, 0.3, 0.1}
+	for i := range goAct {
+		mtxGo.Pools[i+1].AvgMax.SpkMax.Cycle.Avg = goAct[i]
+		mtxNo.Pools[i+1].AvgMax.SpkMax.Cycle.Avg = noAct[i]
+	}
+	vals := mtxGo.MatrixActionValues()
+	assert.Equal(t, 3, len(vals))
+	for i := range vals {
+		assert.InDelta(t, goAct[i]-noAct[i], vals[i], 1.0e-6)
+	}
+	assert.Equal(t, vals, mtxNo.MatrixActionValues())
+	assert.Equal(t, 2, mtxGo.MatrixArgMax())
+	assert.Equal(t, 2, mtxNo.MatrixArgMax())
+
+	assert.Nil(t, hid.MatrixActionValues())
+	assert.Equal(t, -1, hid.MatrixArgMax())
+}
+
+func TestPoolGated(t *testing.T) {
+	net := NewNetwork("MatrixTest")
+	mtxGo := net.AddMatrixLayer("MtxGo", 1, 4, 2, 2, D1Mod)
+	mtxNo := net.AddMatrixLayer("MtxNo", 1, 4, 2, 2, D2Mod)
+	mtxGo.SetBuildConfig("OtherMatrixName", mtxNo.Name())
+	mtxNo.SetBuildConfig("OtherMatrixName", mtxGo.Name())
+	hid := net.AddLayer2D("Hidden", 2, 2, SuperLayer)
+	assert.NoError(t, net.Build())
+	net.Defaults()
+
+	assert.Equal(t, []bool{false, false, false, false}, mtxGo.PoolGated())
+	assert.Nil(t, mtxGo.GatedPools())
+
+	spks := []float32{0.1, 0.8, 0.0, 0.6}
+	for i := range spks {
+		mtxGo.Pools[i+1].AvgMax.SpkMax.Cycle.Avg = spks[i]
+	}
+	assert.True(t, mtxGo.GatedFmSpkMax(0.5))
+	assert.True(t, mtxGo.AnyGated())
+	assert.Equal(t, []bool{false, true, false, true}, mtxGo.PoolGated())
+	assert.Equal(t, []int{1, 3}, mtxGo.GatedPools())
+	assert.Equal(t, []int{1, 3}, mtxNo.GatedPools())
+
+	assert.False(t, mtxGo.GatedFmSpkMax(0.9))
+	assert.Nil(t, mtxGo.GatedPools())
+
+	hid.Pools[0].Gated.SetBool(true)
+	assert.Equal(t, []bool{true}, hid.PoolGated())
+	assert.Equal(t, []int{0}, hid.GatedPools())
+}
+
+func TestActOutFunc(t *testing.T) {
+	net := createNetwork([]int{4, 4}, t)
+	inLay := net.AxonLayerByName("Input")
+	hidLay := net.AxonLayerByName("Hidden")
+	outLay := net.AxonLayerByName("Output")
+	inPat := etensor.NewFloat32([]int{4, 4}, nil, nil)
+	for i := range inPat.Values {
+		if i%3 == 0 {
+			inPat.Values[i] = 1
+		}
+	}
+	thr := float32(0.1)
+	hidLay.SetActOutFunc(func(act float32) float32 {
+		if act > thr {
+			return 1
+		}
+		return 0
+	})
+
+	ctx := NewContext()
+	net.InitExt()
+	inLay.ApplyExt(inPat)
+	net.ApplyExts(ctx)
+	net.NewState(ctx)
+	ctx.NewState(etime.Train)
+	nOn := 0
+	for cyc := 0; cyc < 100; cyc++ {
+		net.Cycle(ctx)
+		ctx.CycleInc()
+		for ni := range hidLay.Neurons {
+			nrn := &hidLay.Neurons[ni]
+			aout, err := nrn.VarByName("ActOut")
+			assert.NoError(t, err)
+			if nrn.Act > thr {
+				assert.Equal(t, float32(1), aout)
+				nOn++
+			} else {
+				assert.Equal(t, float32(0), aout)
+			}
+		}
+	}
+	assert.Greater(t, nOn, 0)
+
+	var vals []float32
+	assert.NoError(t, outLay.UnitVals(&vals, "ActOut"))
+	for _, v := range vals {
+		assert.Equal(t, float32(0), v) // no function set
+	}
+
+	hidLay.SetActOutFunc(nil)
+	assert.Nil(t, hidLay.ActOutFun)
+}
+
+func TestTrgAvgFunc(t *testing.T) {
+	net := createNetwork([]int{shape1D, shape1D}, t)
+	pats := generateRandomPatterns(10)
+	inLay := net.AxonLayerByName("Input")
+	hidLay := net.AxonLayerByName("Hidden")
+	outLay := net.AxonLayerByName("Output")
+	nn := len(hidLay.Neurons)
+	isHigh := func(ni int) bool { return ni >= nn/2 }
+	trgFun := func(ni uint32) float32 {
+		if isHigh(int(ni)) {
+			return 1.5
+		}
+		return 0.5
+	}
+	hidLay.SetTrgAvgFunc(trgFun)
+	for ni := range hidLay.Neurons {
+		assert.Equal(t, trgFun(uint32(ni)), hidLay.Neurons[ni].TrgAvg)
+	}
+	net.InitWts() // re-applied
+	for ni := range hidLay.Neurons {
+		assert.Equal(t, trgFun(uint32(ni)), hidLay.Neurons[ni].TrgAvg)
+	}
+	hidLay.Params.Learn.TrgAvgAct.ErrLRate = 0
+	hidLay.Params.Learn.TrgAvgAct.SynScaleRate = 0.1
+	net.SlowInterval = 5
+
+	ctx := NewContext()
+	ntrls := 100
+	actM := make([]float32, nn)
+	for trl := 0; trl < ntrls; trl++ {
+		row := trl % pats.Rows
+		net.InitExt()
+		inLay.ApplyExt(pats.CellTensor("Input", row))
+		outLay.ApplyExt(pats.CellTensor("Output", row))
+		net.ApplyExts(ctx)
+		net.NewState(ctx)
+		ctx.NewState(etime.Train)
+		for cyc := 0; cyc < 200; cyc++ {
+			net.Cycle(ctx)
+			ctx.CycleInc()
+			if cyc == 149 {
+				net.MinusPhase(ctx)
+				ctx.NewPhase(true)
+				net.PlusPhaseStart(ctx)
+			}
+		}
+		net.PlusPhase(ctx)
+		net.DWt(ctx)
+		net.WtFmDWt(ctx)
+		if trl >= ntrls/2 { // steady state
+			for ni := range hidLay.Neurons {
+				actM[ni] += hidLay.Neurons[ni].ActM
+			}
+		}
+	}
+	var lowAct, highAct float32
+	for ni, act := range actM {
+		if isHigh(ni) {
+			highAct += act
+		} else {
+			lowAct += act
+		}
+		assert.Equal(t, trgFun(uint32(ni)), hidLay.Neurons[ni].TrgAvg) // no ErrLRate
+	}
+	assert.Greater(t, highAct, 1.2*lowAct)
+
+	hidLay.SetTrgAvgFunc(nil)
+	net.InitWts() // back to the default TrgRange values
+	mn, mx := float32(10), float32(0)
+	for ni := range hidLay.Neurons {
+		trg := hidLay.Neurons[ni].TrgAvg
+		mn = mat32.Min(mn, trg)
+		mx = mat32.Max(mx, trg)
+	}
+	assert.Equal(t, hidLay.Params.Learn.TrgAvgAct.TrgRange.Min, mn)
+	assert.InDelta(t, hidLay.Params.Learn.TrgAvgAct.TrgRange.Max, mx, 1.0e-5)
+}
+
+func TestInhibComponents(t *testing.T) {
+	net := createNetwork([]int{4, 4}, t)
+	inLay := net.AxonLayerByName("Input")
+	hid := net.AxonLayerByName("Hidden")
+	pat := etensor.NewFloat32([]int{4, 4}, nil, nil)
+	for i := range pat.Values {
+		if i%3 == 0 {
+			pat.Values[i] = 1
+		}
+	}
+	ctx := NewContext()
+	net.InitExt()
+	inLay.ApplyExt(pat)
+	net.ApplyExts(ctx)
+	net.NewState(ctx)
+	ctx.NewState(etime.Train)
+	var sumFF, sumFB float32
+	for cyc := 0; cyc < 100; cyc++ {
+		net.Cycle(ctx)
+		ctx.CycleInc()
+		ff, fb, ss, gi := hid.InhibComponents(0)
+		inh := &hid.Pools[0].Inhib
+		assert.InDelta(t, mat32.Max(inh.FSGi, 0), ff+fb, 1.0e-5)
+		assert.GreaterOrEqual(t, ff, float32(0))
+		assert.GreaterOrEqual(t, fb, float32(0))
+		assert.Equal(t, inh.SSGi, ss)
+		assert.Equal(t, inh.Gi, gi)
+		sumFF += ff
+		sumFB += fb
+	}
+	assert.Greater(t, sumFF, float32(0))
+	assert.Greater(t, sumFB, float32(0))
+	_, _, ss, gi := hid.InhibComponents(0)
+	assert.Greater(t, ss, float32(0))
+	assert.Greater(t, gi, float32(0))
+
+	// clamped input layer: all fast-spiking inhibition is from GeExts
+	ff, fb, _, _ := inLay.InhibComponents(0)
+	assert.Greater(t, ff, float32(0))
+	assert.Equal(t, float32(0), fb)
+	assert.Equal(t, inLay.Pools[0].Inhib.FSGi, ff)
+
+	comps := hid.InhibPoolComponents()
+	assert.Equal(t, len(InhibVars), len(comps))
+	assert.Equal(t, 1, len(comps["Gi"]))
+	assert.Equal(t, gi, comps["Gi"][0])
+
+	ff, fb, ss, gi = hid.InhibComponents(5) // out of range
+	assert.Equal(t, float32(0), ff+fb+ss+gi)
+}
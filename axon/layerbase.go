@@ -38,6 +38,22 @@ type LayerBase struct {
 	Neurons       []Neuron           `desc:"slice of neurons for this layer -- flat list of len = Shp.Len(). You must iterate over index and use pointer to modify values."`
 	Pools         []Pool             `desc:"computes FS-FFFB inhibition and other pooled, aggregate state variables -- has at least 1 for entire layer (lpl = layer pool), and one for each sub-pool if shape supports that (4D).  This is a sub-slice from overall Network Pools slice.  You must iterate over index and use pointer to modify values."`
 	Exts          []float32          `view:"-" desc:"external input values for this layer, allocated from network global Exts slice"`
+	ExtSparseIdxs []int              `view:"-" desc:"indexes of neurons given nonzero external input by the last ApplyExtSparse call, which are the only ones that need to be cleared on the next call -- nil after InitExt, in which case the next call writes the full layer"`
+	UnitPerm      []int              `view:"-" desc:"optional permutation of the neurons applied to all external inputs: the input value for flat index i is applied to neuron UnitPerm[i] -- see SetUnitPermutation"`
+	FrozenActs    []float32          `view:"-" desc:"snapshot of the Act values of the neurons captured by FreezeActs, which can be used to clamp the layer to a fixed activity pattern via ClampToFrozen"`
+	FrozenClamp   bool               `inactive:"+" desc:"if true, the layer is clamped to the FrozenActs activity pattern, via ClampToFrozen"`
+	SpkHist       SpikeHist          `view:"-" desc:"optional record of the recent spike train for each neuron, for computing rate estimates via RateEst -- enable with SetSpikeHist"`
+	SpkMaxResets  []int32            `desc:"cycles within the trial at which the SpkMax values are reset, so that they reflect the peak activity since the last reset, e.g., for multiple stimuli per trial -- see SetSpkMaxResets"`
+	RLRateFun     RLRateFunc         `view:"-" json:"-" xml:"-" desc:"optional custom function computing the RLRate learning rate multiplier for each neuron in PlusPhase -- see SetRLRateFunc"`
+	ActOutFun     ActOutFunc         `view:"-" json:"-" xml:"-" desc:"optional readout function computing the ActOut output variable from Act for each neuron every cycle -- see SetActOutFunc"`
+	TrgAvgFun     TrgAvgFunc         `view:"-" json:"-" xml:"-" desc:"optional function setting the TrgAvg target average activity of each neuron, used instead of the TrgAvgAct TrgRange values in InitActAvg -- see SetTrgAvgFunc"`
+	GapJunc       GapJunctions       `view:"-" desc:"optional gap junction electrical coupling among the neurons in this layer -- see SetGapJunctions"`
+	SpikeFun      SpikeFunc          `view:"-" json:"-" xml:"-" desc:"optional custom spike generation function, used instead of the default AdEx VmFmG and SpikeFmVm -- see SetSpikeFunc"`
+	RateAcc       RateHist           `view:"-" desc:"optional accumulation of per-neuron rates over trials, for computing rate histograms via RateHist -- enable with SetRateHist"`
+	ActTrc        ActTrace           `view:"-" desc:"optional record of the layer-average activity on recent cycles, for analyzing rhythmic dynamics via PhaseLockedActivity -- enable with SetActTrace"`
+	RecGSyn       bool               `inactive:"+" desc:"if true, the trial-average GSynTrl conductance of each receiving projection is recorded, for EffectiveInputTable -- enable with SetRecordGSyn"`
+	Cost          EnergyCost         `view:"inline" desc:"accumulated metabolic energy cost of spiking and synaptic conductance in this layer -- see SpikeCost"`
+	EIBal         EIBalAcc           `view:"inline" desc:"accumulated excitatory and inhibitory conductances over trials, for the E/I balance over an epoch -- see EIBalanceEpoch"`
 	BuildConfig   map[string]string  `desc:"configuration data set when the network is configured, that is used during the network Build() process via PostBuild method, after all the structure of the network has been fully constructed.  In particular, the Params is nil until Build, so setting anything specific in there (e.g., an index to another layer) must be done as a second pass.  Note that Params are all applied after Build and can set user-modifiable params, so this is for more special algorithm structural parameters set during ConfigNet() methods.,"`
 	ParamsHistory params.HistoryImpl `desc:"provides a history of parameters applied to the layer"`
 }
@@ -334,6 +350,22 @@ func (ly *LayerBase) SetBuildConfig(param, val string) {
 	ly.BuildConfig[param] = val
 }
 
+// BuildConfigs returns a copy of all the BuildConfig entries,
+// for inspecting the configuration of the layer.
+func (ly *LayerBase) BuildConfigs() map[string]string {
+	cfgs := make(map[string]string, len(ly.BuildConfig))
+	for k, v := range ly.BuildConfig {
+		cfgs[k] = v
+	}
+	return cfgs
+}
+
+// ClearBuildConfig removes the BuildConfig entry with given name, if present.
+// Takes effect the next time the network is built.
+func (ly *LayerBase) ClearBuildConfig(param string) {
+	delete(ly.BuildConfig, param)
+}
+
 // BuildConfigByName looks for given BuildConfig option by name,
 // and reports & returns an error if not found.
 func (ly *LayerBase) BuildConfigByName(nm string) (string, error) {
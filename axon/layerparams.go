@@ -47,10 +47,23 @@ type LayerIdxs struct {
 
 // LayerInhibIdxs contains indexes of layers for between-layer inhibition
 type LayerInhibIdxs struct {
-	Idx1 int32 `inactive:"+" desc:"idx of Layer to get layer-level inhibition from -- set during Build from BuildConfig LayInhib1Name if present -- -1 if not used"`
-	Idx2 int32 `inactive:"+" desc:"idx of Layer to get layer-level inhibition from -- set during Build from BuildConfig LayInhib2Name if present -- -1 if not used"`
-	Idx3 int32 `inactive:"+" desc:"idx of Layer to get layer-level inhibition from -- set during Build from BuildConfig LayInhib3Name if present -- -1 if not used"`
-	Idx4 int32 `inactive:"+" desc:"idx of Layer to geta layer-level inhibition from -- set during Build from BuildConfig LayInhib4Name if present -- -1 if not used"`
+	Idx1 int32   `inactive:"+" desc:"idx of Layer to get layer-level inhibition from -- set during Build from BuildConfig LayInhib1Name if present -- -1 if not used"`
+	Idx2 int32   `inactive:"+" desc:"idx of Layer to get layer-level inhibition from -- set during Build from BuildConfig LayInhib2Name if present -- -1 if not used"`
+	Idx3 int32   `inactive:"+" desc:"idx of Layer to get layer-level inhibition from -- set during Build from BuildConfig LayInhib3Name if present -- -1 if not used"`
+	Idx4 int32   `inactive:"+" desc:"idx of Layer to geta layer-level inhibition from -- set during Build from BuildConfig LayInhib4Name if present -- -1 if not used"`
+	Gain float32 `desc:"gain multiplier on the layer-level inhibition from the other layers, which is then combined with this layer's own inhibition by taking the max -- set during Build from BuildConfig LayInhibGain if present, else 1"`
+
+	pad, pad1, pad2 float32
+}
+
+// GiMax returns the max of given gi value and the given other layer's
+// gi value times the Gain factor.
+func (li *LayerInhibIdxs) GiMax(gi, ogi float32) float32 {
+	ogi *= li.Gain
+	if ogi > gi {
+		return ogi
+	}
+	return gi
 }
 
 // note: the following must appear above LayerParams for GPU usage which is order sensitive
@@ -137,7 +150,12 @@ func (ly *LayerParams) Defaults() {
 	ly.Inhib.Layer.On.SetBool(true)
 	ly.Inhib.Layer.Gi = 1.0
 	ly.Inhib.Pool.Gi = 1.0
+	ly.SpecialDefaults()
+}
 
+// SpecialDefaults sets the defaults for the parameters that are specific
+// to special layer types, i.e., everything except Act, Inhib, and Learn.
+func (ly *LayerParams) SpecialDefaults() {
 	ly.Burst.Defaults()
 	ly.CT.Defaults()
 	ly.Pulv.Defaults()
@@ -337,6 +355,7 @@ func (ly *LayerParams) SubPoolGiFmSpikes(ctx *Context, pl *Pool, lpl *Pool, lyIn
 		lpl.Inhib.PoolMax(pl.Inhib.Gi) // display only
 		lpl.Inhib.SaveOrig()           // effective GiOrig
 	}
+	pl.ClampGi()
 }
 
 //////////////////////////////////////////////////////////////////////////////////////
@@ -354,6 +373,17 @@ func (ly *LayerParams) GatherSpikesInit(nrn *Neuron) {
 	nrn.GiSyn = nrn.GiBase
 }
 
+// GatherSpikesAlpha computes the GeAlpha form of GeSyn, if that is
+// the Dt.GeKind, after the G*Raw and G*Syn values have been integrated
+// for all projections, with GeSyn initially having the decay component.
+func (ly *LayerParams) GatherSpikesAlpha(nrn *Neuron) {
+	if ly.Act.Dt.GeKind != GeAlpha {
+		return
+	}
+	nrn.GeRise = ly.Act.Dt.GeRiseFmRaw(nrn.GeRise, nrn.GeRaw)
+	nrn.GeSyn = nrn.GeBase + ly.Act.Dt.GeSynAlpha(nrn.GeSyn-nrn.GeBase, nrn.GeRise)
+}
+
 ////////////////////////
 //  GInteg
 
@@ -520,7 +550,7 @@ func (ly *LayerParams) GiInteg(ctx *Context, ni uint32, nrn *Neuron, pl *Pool, v
 		nrn.SSGiDend = ly.Act.Dend.SSGi * pl.Inhib.SSGi
 	}
 	ly.Act.GABAB.GABAB(nrn.GABAB, nrn.GABABx, nrn.Gi, &nrn.GABAB, &nrn.GABABx)
-	nrn.GgabaB = ly.Act.GABAB.GgabaB(nrn.GABAB, nrn.VmDend)
+	nrn.GgabaB = ly.Act.GABAB.GgabaB(nrn.GABAB, nrn.VmDend) * ly.Act.GABAB.GABABDeplete(nrn.Gi, &nrn.GABABd)
 	nrn.Gk += nrn.GgabaB // Gk was already init
 }
 
@@ -538,6 +568,12 @@ func (ly *LayerParams) GNeuroMod(ctx *Context, ni uint32, nrn *Neuron, vals *Lay
 func (ly *LayerParams) SpikeFmG(ctx *Context, ni uint32, nrn *Neuron) {
 	ly.Act.VmFmG(nrn)
 	ly.Act.SpikeFmVm(nrn)
+	ly.CaFmSpike(ctx, nrn)
+}
+
+// CaFmSpike updates the spike-driven calcium and SpkMax values
+// from the current Spike.
+func (ly *LayerParams) CaFmSpike(ctx *Context, nrn *Neuron) {
 	ly.Learn.CaFmSpike(nrn)
 	if ctx.Cycle >= ly.Act.Dt.MaxCycStart {
 		nrn.SpkMaxCa += ly.Learn.CaSpk.Dt.PDt * (nrn.CaSpkM - nrn.SpkMaxCa)
@@ -558,7 +594,7 @@ func (ly *LayerParams) PostSpikeSpecial(ctx *Context, ni uint32, nrn *Neuron, pl
 		if ctx.PlusPhase.IsTrue() {
 			actMax := lpl.AvgMax.CaSpkP.Cycle.Max
 			actAvg := lpl.AvgMax.CaSpkP.Cycle.Avg
-			thr := ly.Burst.ThrFmAvgMax(actAvg, actMax)
+			thr := ly.Burst.Thr(actAvg, actMax, pl.BurstThr)
 			if nrn.CaSpkP < thr {
 				nrn.Burst = 0
 			}
@@ -719,8 +755,13 @@ func (ly *LayerParams) CyclePostRSalAChLayer(ctx *Context, vals *LayerVals, lay1
 	ctx.NeuroMod.ACh = vals.NeuroMod.ACh
 }
 
-func (ly *LayerParams) CyclePostRWDaLayer(ctx *Context, vals *LayerVals, pvals *LayerVals) {
+// CyclePostRWDaLayer computes the DA from the reward prediction in the
+// RWPredLayer vals, clamped from below at its RWPred.PredFloor.
+func (ly *LayerParams) CyclePostRWDaLayer(ctx *Context, vals *LayerVals, pvals *LayerVals, predFloor float32) {
 	pred := pvals.Special.V1 - pvals.Special.V2
+	if predFloor > 0 && pred < predFloor {
+		pred = predFloor
+	}
 	ctx.NeuroMod.RewPred = pred // record
 	da := float32(0)
 	if ctx.NeuroMod.HasRew.IsTrue() {
@@ -752,6 +793,7 @@ func (ly *LayerParams) CyclePostTDIntegLayer(ctx *Context, vals *LayerVals, pval
 		vals.Special.V1 = rpval // minus phase is *previous trial*
 	}
 	ctx.NeuroMod.RewPred = rpval // global value will be copied to layers next cycle
+	ctx.NeuroMod.TDTrDecay = ly.TDInteg.TrDecay()
 }
 
 func (ly *LayerParams) CyclePostTDDaLayer(ctx *Context, vals *LayerVals, ivals *LayerVals) {
@@ -770,6 +812,9 @@ func (ly *LayerParams) CyclePostPPTgLayer(ctx *Context, lpl *Pool) {
 // note: needs to iterate over sub-pools in layer!
 func (ly *LayerParams) CyclePostVSPatchLayer(ctx *Context, pi int32, pl *Pool) {
 	val := ly.PVLV.Val(pl.AvgMax.CaSpkD.Cycle.Avg)
+	if ly.VSPatch.PredFloor > 0 && val < ly.VSPatch.PredFloor {
+		val = ly.VSPatch.PredFloor
+	}
 	ctx.PVLV.VSPatch.Set(pi-1, val)
 }
 
@@ -807,6 +852,7 @@ func (ly *LayerParams) NewStateNeuron(ctx *Context, ni uint32, nrn *Neuron, vals
 	nrn.SpkPrv = nrn.CaSpkD
 	nrn.SpkMax = 0
 	nrn.SpkMaxCa = 0
+	nrn.SpkCnt = 0
 
 	ly.Act.DecayState(nrn, ly.Act.Decay.Act, ly.Act.Decay.Glong)
 	if ly.LayType == PPTgLayer || ly.LayType == VSPatchLayer {
@@ -878,6 +924,7 @@ func (ly *LayerParams) PlusPhaseNeuron(ctx *Context, ni uint32, nrn *Neuron, pl
 	}
 	nrn.RLRate = mlr * dlr * modlr
 	nrn.ActAvg += ly.Act.Dt.LongAvgDt * (nrn.ActM - nrn.ActAvg)
+	ly.Learn.IntrinsicPlast.ThrFmAct(&nrn.SpkThrOff, nrn.ActM)
 	var tau float32
 	ly.Act.Sahp.NinfTauFmCa(nrn.SahpCa, &nrn.SahpN, &tau)
 	nrn.SahpCa = ly.Act.Sahp.CaInt(nrn.SahpCa, nrn.CaSpkD)
@@ -231,6 +231,18 @@ const (
 	// For visualization and / or motor action signaling.
 	VSGatedLayer
 
+	/////////////
+	// Inhib
+
+	// InhibInterneuronLayer is an explicit population of fast-spiking
+	// inhibitory interneurons, which receives excitatory projections from
+	// excitatory layers and sends InhibPrjn projections back to them,
+	// providing activity-dependent inhibition in addition to (or instead of)
+	// the abstract FS-FFFB inhibition.  The InhibPrjn projections from this
+	// layer learn using the InhibLearn homeostatic inhibitory plasticity rule.
+	// See AddInhibInterneurons.
+	InhibInterneuronLayer
+
 	LayerTypesN
 )
 
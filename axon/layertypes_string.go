@@ -45,12 +45,13 @@ func _() {
 	_ = x[GPLayer-29]
 	_ = x[VThalLayer-30]
 	_ = x[VSGatedLayer-31]
-	_ = x[LayerTypesN-32]
+	_ = x[InhibInterneuronLayer-32]
+	_ = x[LayerTypesN-33]
 }
 
-const _LayerTypes_name = "SuperLayerInputLayerTargetLayerCompareLayerCTLayerPulvinarLayerTRNLayerPTMaintLayerPTPredLayerPTNotMaintLayerRewLayerRSalienceAChLayerRWPredLayerRWDaLayerTDPredLayerTDIntegLayerTDDaLayerBLALayerCeMLayerPPTgLayerVSPatchLayerVTALayerLHbLayerDrivesLayerEffortLayerUSLayerPVLayerMatrixLayerSTNLayerGPLayerVThalLayerVSGatedLayerLayerTypesN"
+const _LayerTypes_name = "SuperLayerInputLayerTargetLayerCompareLayerCTLayerPulvinarLayerTRNLayerPTMaintLayerPTPredLayerPTNotMaintLayerRewLayerRSalienceAChLayerRWPredLayerRWDaLayerTDPredLayerTDIntegLayerTDDaLayerBLALayerCeMLayerPPTgLayerVSPatchLayerVTALayerLHbLayerDrivesLayerEffortLayerUSLayerPVLayerMatrixLayerSTNLayerGPLayerVThalLayerVSGatedLayerInhibInterneuronLayerLayerTypesN"
 
-var _LayerTypes_index = [...]uint16{0, 10, 20, 31, 43, 50, 63, 71, 83, 94, 109, 117, 134, 145, 154, 165, 177, 186, 194, 202, 211, 223, 231, 239, 250, 261, 268, 275, 286, 294, 301, 311, 323, 334}
+var _LayerTypes_index = [...]uint16{0, 10, 20, 31, 43, 50, 63, 71, 83, 94, 109, 117, 134, 145, 154, 165, 177, 186, 194, 202, 211, 223, 231, 239, 250, 261, 268, 275, 286, 294, 301, 311, 323, 344, 355}
 
 func (i LayerTypes) String() string {
 	if i < 0 || i >= LayerTypes(len(_LayerTypes_index)-1) {
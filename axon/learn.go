@@ -152,6 +152,53 @@ func (ta *TrgAvgActParams) Defaults() {
 	ta.Update()
 }
 
+//////////////////////////////////////////////////////////////////////////////////////
+//  IntrinsicPlastParams
+
+// IntrinsicPlastParams govern intrinsic plasticity of the spike threshold,
+// where each neuron's threshold offset (SpkThrOff) drifts up when the neuron
+// is more active than Target, and down when it is less active, driving
+// its activity back toward the target rate.  Updated every trial
+// based on the minus phase activity (ActM).
+type IntrinsicPlastParams struct {
+	On     slbool.Bool `desc:"whether to adapt the spike threshold to maintain a target average activity level"`
+	Target float32     `viewif:"On" def:"0.1" desc:"target average minus-phase activity (ActM) for each neuron"`
+	Tau    float32     `viewif:"On" def:"100" min:"1" desc:"time constant in trials for integrating the difference between actual and target activity into the threshold offset"`
+	Min    float32     `viewif:"On" def:"-0.1" desc:"minimum threshold offset -- bounds the drift of the threshold for neurons that remain silent"`
+	Max    float32     `viewif:"On" def:"0.1" desc:"maximum threshold offset -- bounds the drift of the threshold for neurons that remain highly active"`
+
+	Dt float32 `view:"-" json:"-" xml:"-" inactive:"+" desc:"rate = 1 / tau"`
+
+	pad, pad1 float32
+}
+
+func (ip *IntrinsicPlastParams) Update() {
+	ip.Dt = 1 / ip.Tau
+}
+
+func (ip *IntrinsicPlastParams) Defaults() {
+	ip.Target = 0.1
+	ip.Tau = 100
+	ip.Min = -0.1
+	ip.Max = 0.1
+	ip.Update()
+}
+
+// ThrFmAct updates the spike threshold offset from given activity,
+// if On, keeping it within the Min, Max range.
+func (ip *IntrinsicPlastParams) ThrFmAct(thrOff *float32, act float32) {
+	if ip.On.IsFalse() {
+		return
+	}
+	off := *thrOff + ip.Dt*(act-ip.Target)
+	if off < ip.Min {
+		off = ip.Min
+	} else if off > ip.Max {
+		off = ip.Max
+	}
+	*thrOff = off
+}
+
 //////////////////////////////////////////////////////////////////////////////////////
 //  RLRateParams
 
@@ -220,12 +267,13 @@ func (rl *RLRateParams) RLRateDiff(scap, scad float32) float32 {
 // axon.LearnNeurParams manages learning-related parameters at the neuron-level.
 // This is mainly the running average activations that drive learning
 type LearnNeurParams struct {
-	CaLrn     CaLrnParams      `view:"inline" desc:"parameterizes the neuron-level calcium signals driving learning: CaLrn = NMDA + VGCC Ca sources, where VGCC can be simulated from spiking or use the more complex and dynamic VGCC channel directly.  CaLrn is then integrated in a cascading manner at multiple time scales: CaM (as in calmodulin), CaP (ltP, CaMKII, plus phase), CaD (ltD, DAPK1, minus phase)."`
-	CaSpk     CaSpkParams      `view:"inline" desc:"parameterizes the neuron-level spike-driven calcium signals, starting with CaSyn that is integrated at the neuron level, and drives synapse-level, pre * post Ca integration, which provides the Tr trace that multiplies error signals, and drives learning directly for Target layers. CaSpk* values are integrated separately at the Neuron level and used for UpdtThr and RLRate as a proxy for the activation (spiking) based learning signal."`
-	LrnNMDA   chans.NMDAParams `view:"inline" desc:"NMDA channel parameters used for learning, vs. the ones driving activation -- allows exploration of learning parameters independent of their effects on active maintenance contributions of NMDA, and may be supported by different receptor subtypes"`
-	TrgAvgAct TrgAvgActParams  `view:"inline" desc:"synaptic scaling parameters for regulating overall average activity compared to neuron's own target level"`
-	RLRate    RLRateParams     `view:"inline" desc:"recv neuron learning rate modulation params -- an additional error-based modulation of learning for receiver side: RLRate = |CaSpkP - CaSpkD| / Max(CaSpkP, CaSpkD)"`
-	NeuroMod  NeuroModParams   `view:"inline" desc:"neuromodulation effects on learning rate and activity, as a function of layer-level DA and ACh values, which are updated from global Context values, and computed from reinforcement learning algorithms"`
+	CaLrn          CaLrnParams          `view:"inline" desc:"parameterizes the neuron-level calcium signals driving learning: CaLrn = NMDA + VGCC Ca sources, where VGCC can be simulated from spiking or use the more complex and dynamic VGCC channel directly.  CaLrn is then integrated in a cascading manner at multiple time scales: CaM (as in calmodulin), CaP (ltP, CaMKII, plus phase), CaD (ltD, DAPK1, minus phase)."`
+	CaSpk          CaSpkParams          `view:"inline" desc:"parameterizes the neuron-level spike-driven calcium signals, starting with CaSyn that is integrated at the neuron level, and drives synapse-level, pre * post Ca integration, which provides the Tr trace that multiplies error signals, and drives learning directly for Target layers. CaSpk* values are integrated separately at the Neuron level and used for UpdtThr and RLRate as a proxy for the activation (spiking) based learning signal."`
+	LrnNMDA        chans.NMDAParams     `view:"inline" desc:"NMDA channel parameters used for learning, vs. the ones driving activation -- allows exploration of learning parameters independent of their effects on active maintenance contributions of NMDA, and may be supported by different receptor subtypes"`
+	TrgAvgAct      TrgAvgActParams      `view:"inline" desc:"synaptic scaling parameters for regulating overall average activity compared to neuron's own target level"`
+	IntrinsicPlast IntrinsicPlastParams `view:"inline" desc:"intrinsic plasticity parameters for adapting each neuron's spike threshold to maintain a target average activity level -- complements the synaptic scaling of TrgAvgAct"`
+	RLRate         RLRateParams         `view:"inline" desc:"recv neuron learning rate modulation params -- an additional error-based modulation of learning for receiver side: RLRate = |CaSpkP - CaSpkD| / Max(CaSpkP, CaSpkD)"`
+	NeuroMod       NeuroModParams       `view:"inline" desc:"neuromodulation effects on learning rate and activity, as a function of layer-level DA and ACh values, which are updated from global Context values, and computed from reinforcement learning algorithms"`
 }
 
 func (ln *LearnNeurParams) Update() {
@@ -233,6 +281,7 @@ func (ln *LearnNeurParams) Update() {
 	ln.CaSpk.Update()
 	ln.LrnNMDA.Update()
 	ln.TrgAvgAct.Update()
+	ln.IntrinsicPlast.Update()
 	ln.RLRate.Update()
 	ln.NeuroMod.Update()
 }
@@ -244,6 +293,7 @@ func (ln *LearnNeurParams) Defaults() {
 	ln.LrnNMDA.ITau = 1
 	ln.LrnNMDA.Update()
 	ln.TrgAvgAct.Defaults()
+	ln.IntrinsicPlast.Defaults()
 	ln.RLRate.Defaults()
 	ln.NeuroMod.Defaults()
 }
@@ -403,13 +453,14 @@ func (sp *SWtInitParams) Update() {
 
 // SWtAdaptParams manages adaptation of SWt values
 type SWtAdaptParams struct {
-	On       slbool.Bool `desc:"if true, adaptation is active -- if false, SWt values are not updated, in which case it is generally good to have Init.SPct=0 too."`
-	LRate    float32     `viewif:"On" def:"0.1,0.01,0.001,0.0002" desc:"learning rate multiplier on the accumulated DWt values (which already have fast LRate applied) to incorporate into SWt during slow outer loop updating -- lower values impose stronger constraints, for larger networks that need more structural support, e.g., 0.001 is better after 1,000 epochs in large models.  0.1 is fine for smaller models."`
-	SubMean  float32     `viewif:"On" def:"1" desc:"amount of mean to subtract from SWt delta when updating -- generally best to set to 1"`
-	SigGain  float32     `viewif:"On" def:"6" desc:"gain of sigmoidal constrast enhancement function used to transform learned, linear LWt values into Wt values"`
-	DreamVar float32     `viewif:"On" def:"0,0.01,0.02" desc:"extra random variability to add to LWts after every SWt update, which theoretically happens at night -- hence the association with dreaming.  0.01 is max for a small network that still allows learning, 0.02 works well for larger networks that can benefit more.  generally avoid adding to projections to output layers."`
+	On              slbool.Bool `desc:"if true, adaptation is active -- if false, SWt values are not updated, in which case it is generally good to have Init.SPct=0 too."`
+	LRate           float32     `viewif:"On" def:"0.1,0.01,0.001,0.0002" desc:"learning rate multiplier on the accumulated DWt values (which already have fast LRate applied) to incorporate into SWt during slow outer loop updating -- lower values impose stronger constraints, for larger networks that need more structural support, e.g., 0.001 is better after 1,000 epochs in large models.  0.1 is fine for smaller models."`
+	SubMean         float32     `viewif:"On" def:"1" desc:"amount of mean to subtract from SWt delta when updating -- generally best to set to 1"`
+	SigGain         float32     `viewif:"On" def:"6" desc:"gain of sigmoidal constrast enhancement function used to transform learned, linear LWt values into Wt values"`
+	DreamVar        float32     `viewif:"On" def:"0,0.01,0.02" desc:"extra random variability to add to LWts after every SWt update, which theoretically happens at night -- hence the association with dreaming.  0.01 is max for a small network that still allows learning, 0.02 works well for larger networks that can benefit more.  generally avoid adding to projections to output layers."`
+	MaxDeltaPerStep float32     `def:"1000" min:"0" desc:"maximum magnitude of change in SWt per SlowAdapt update, and in LWt (or Wt for projection types without weight limits) per WtFmDWt update -- provides a safeguard against weight oscillation and blowup in high learning rate regimes.  The large default value is effectively off."`
 
-	pad, pad1, pad2 int32
+	pad, pad1 int32
 }
 
 func (sp *SWtAdaptParams) Defaults() {
@@ -418,12 +469,25 @@ func (sp *SWtAdaptParams) Defaults() {
 	sp.SubMean = 1
 	sp.SigGain = 6
 	sp.DreamVar = 0.0
+	sp.MaxDeltaPerStep = 1000
 	sp.Update()
 }
 
 func (sp *SWtAdaptParams) Update() {
 }
 
+// ClampDelta returns the given weight change limited to
+// +/- MaxDeltaPerStep in magnitude.
+func (sp *SWtAdaptParams) ClampDelta(dw float32) float32 {
+	if dw > sp.MaxDeltaPerStep {
+		return sp.MaxDeltaPerStep
+	}
+	if dw < -sp.MaxDeltaPerStep {
+		return -sp.MaxDeltaPerStep
+	}
+	return dw
+}
+
 //gosl: end learn
 
 // RndVar returns the random variance in weight value (zero mean) based on Var param
@@ -449,12 +513,17 @@ type SWtParams struct {
 	Init  SWtInitParams  `view:"inline" desc:"initialization of SWt values"`
 	Adapt SWtAdaptParams `view:"inline" desc:"adaptation of SWt values in response to LWt learning"`
 	Limit minmax.F32     `def:"{0.2 0.8}" view:"inline" desc:"range limits for SWt values"`
+
+	AllowNegative slbool.Bool `desc:"allow negative (signed) weights, where a negative SWt value produces a negative Wt, with the same magnitude limits as positive values -- learning in LWt then modulates the magnitude of the weight, in the direction that reduces the error given its sign.  For an excitatory projection, the net negative synaptic input to a receiving neuron contributes to its Gi inhibitory conductance instead of Ge.  SWt adaptation is not applied to projections with this set.  Negative weights must be set explicitly, e.g., via SetSWtsFunc.  The negative inputs are accumulated in a separate conductance buffer, which is allocated by Network.BuildPrjnGBuf (called in InitWts), so this must be set before then."`
+
+	pad, pad1, pad2 int32
 }
 
 func (sp *SWtParams) Defaults() {
 	sp.Init.Defaults()
 	sp.Adapt.Defaults()
 	sp.Limit.Set(0.2, 0.8)
+	sp.AllowNegative.SetBool(false)
 }
 
 func (sp *SWtParams) Update() {
@@ -467,16 +536,27 @@ func (sp *SWtParams) WtVal(swt, lwt float32) float32 {
 	return swt * sp.SigFmLinWt(lwt)
 }
 
-// ClipSWt returns SWt value clipped to valid range
+// ClipSWt returns SWt value clipped to valid range,
+// which is mirrored for negative values if AllowNegative
 func (sp *SWtParams) ClipSWt(swt float32) float32 {
+	if sp.AllowNegative.IsTrue() && swt < 0 {
+		return -sp.Limit.ClipVal(-swt)
+	}
 	return sp.Limit.ClipVal(swt)
 }
 
-// ClipWt returns Wt value clipped to 0-1 range
+// ClipWt returns Wt value clipped to 0-1 range,
+// or -1 to 1 if AllowNegative
 func (sp *SWtParams) ClipWt(wt float32) float32 {
 	if wt > 1 {
 		return 1
 	}
+	if sp.AllowNegative.IsTrue() {
+		if wt < -1 {
+			return -1
+		}
+		return wt
+	}
 	if wt < 0 {
 		return 0
 	}
@@ -536,7 +616,11 @@ func (sp *SWtParams) WtFmDWt(dwt, wt, lwt *float32, swt float32) {
 		return
 	}
 	// note: softbound happened at dwt stage
-	*lwt += *dwt
+	if swt < 0 { // signed weight: increasing LWt makes Wt more negative
+		*lwt -= sp.Adapt.ClampDelta(*dwt)
+	} else {
+		*lwt += sp.Adapt.ClampDelta(*dwt)
+	}
 	if *lwt < 0 {
 		*lwt = 0
 	} else if *lwt > 1 {
@@ -700,9 +784,14 @@ func (lr *LRateMod) LRateMod(net *Network, fact float32) float32 {
 
 // LearnSynParams manages learning-related parameters at the synapse-level.
 type LearnSynParams struct {
-	Learn slbool.Bool `desc:"enable learning for this projection"`
+	Learn            slbool.Bool `desc:"enable learning for this projection"`
+	HighPrecSum      slbool.Bool `viewif:"Learn" desc:"use double-precision (float64) accumulation for the per-neuron sums over synapses in DWtSubMean and SWtFmWt, to avoid loss of precision in the zero-sum computation for projections with large fan-in.  Only applies to the CPU computation."`
+	SpikeGatedDWt    slbool.Bool `viewif:"Learn" desc:"skip the DWt computation for synapses where both the sending and receiving neuron CaSpkD values are below the receiving layer's Learn.CaLrn.UpdtThr -- like the corresponding SynCa optimization, this is purely a performance optimization for sparsely active networks, which only approximates the full computation because the trace (Tr) is not updated for skipped synapses"`
+	AccumulateDWt    slbool.Bool `viewif:"Learn" desc:"accumulate the DWt weight changes computed in DWt across trials, instead of applying them in WtFmDWt -- the accumulated changes are only applied (and DWt zeroed) when Network.ApplyAccumulatedDWt is called, e.g., for batch-style learning over multiple trials"`
+	ContinuousDWt    slbool.Bool `viewif:"Learn" desc:"compute and apply weight changes every ContinuousCycles cycles during the trial, using the running calcium values at that point, instead of only at the end of the theta cycle in DWt and WtFmDWt -- for exploring continuous, online learning regimes.  Each update uses the full LRate, so it should typically be reduced by ContinuousCycles / ThetaCycles for a comparable overall rate.  Only supported on the CPU: on the GPU the standard theta-cycle learning is used."`
+	ContinuousCycles int32       `viewif:"ContinuousDWt" def:"50" min:"1" desc:"number of cycles between weight updates for ContinuousDWt"`
 
-	pad, pad1, pad2 int32
+	pad, pad1 int32
 
 	LRate    LRateParams     `viewif:"Learn" desc:"learning rate parameters, supporting two levels of modulation on top of base learning rate."`
 	Trace    TraceParams     `viewif:"Learn" desc:"trace-based learning parameters"`
@@ -710,6 +799,9 @@ type LearnSynParams struct {
 }
 
 func (ls *LearnSynParams) Update() {
+	if ls.ContinuousCycles < 1 {
+		ls.ContinuousCycles = 1
+	}
 	ls.LRate.Update()
 	ls.Trace.Update()
 	ls.KinaseCa.Update()
@@ -717,11 +809,25 @@ func (ls *LearnSynParams) Update() {
 
 func (ls *LearnSynParams) Defaults() {
 	ls.Learn.SetBool(true)
+	ls.ContinuousCycles = 50
 	ls.LRate.Defaults()
 	ls.Trace.Defaults()
 	ls.KinaseCa.Defaults()
 }
 
+// SkipDWt returns true if the DWt computation should be skipped for
+// a synapse with given sending and receiving neuron CaSpkD values,
+// when SpikeGatedDWt is on and both are below updtThr.
+func (ls *LearnSynParams) SkipDWt(snCaSpkD, rnCaSpkD, updtThr float32) bool {
+	return ls.SpikeGatedDWt.IsTrue() && snCaSpkD < updtThr && rnCaSpkD < updtThr
+}
+
+// ContinuousDWtCycle returns true if ContinuousDWt is on and the
+// given cycle within the trial is at the end of a ContinuousCycles interval.
+func (ls *LearnSynParams) ContinuousDWtCycle(cycle int32) bool {
+	return ls.ContinuousDWt.IsTrue() && (cycle+1)%ls.ContinuousCycles == 0
+}
+
 // CHLdWt returns the error-driven weight change component for a
 // CHL contrastive hebbian learning rule, optionally using the checkmark
 // temporally eXtended Contrastive Attractor Learning (XCAL) function
@@ -738,4 +844,34 @@ func (ls *LearnSynParams) DeltaDWt(plus, minus float32) float32 {
 	return plus - minus
 }
 
+///////////////////////////////////////////////////////////////////////
+//  InhibLearnParams
+
+// InhibLearnParams has parameters for learning in inhibitory projections
+// from InhibInterneuronLayer layers, using a homeostatic inhibitory
+// plasticity rule (Vogels et al., 2011): the weight increases in proportion
+// to sending interneuron activity when the receiving neuron is more active
+// than the TrgAct target level, and decreases when it is less active,
+// so that the interneurons learn to balance the excitation of each
+// receiving neuron, keeping its activity around the target.
+type InhibLearnParams struct {
+	On     slbool.Bool `desc:"use the inhibitory plasticity rule for learning in this InhibPrjn -- set by default for InhibPrjn projections from an InhibInterneuronLayer -- otherwise the standard cortical learning rule is used"`
+	TrgAct float32     `viewif:"On" def:"0.15" desc:"target level of receiving neuron activity, in terms of CaSpkD, relative to which inhibition is strengthened (above) or weakened (below)"`
+
+	pad, pad1 float32
+}
+
+func (il *InhibLearnParams) Defaults() {
+	il.TrgAct = 0.15
+}
+
+func (il *InhibLearnParams) Update() {
+}
+
+// DWt returns the weight change for given sending and receiving
+// neuron activity, in terms of CaSpkD
+func (il *InhibLearnParams) DWt(snCaSpkD, rnCaSpkD float32) float32 {
+	return snCaSpkD * (rnCaSpkD - il.TrgAct)
+}
+
 //gosl: end learn
@@ -5,7 +5,11 @@
 package axon
 
 import (
+	"fmt"
+	"log"
+	"math"
 	"strconv"
+	"strings"
 
 	"github.com/emer/emergent/egui"
 	"github.com/emer/emergent/elog"
@@ -44,6 +48,143 @@ func PCAStats(net *Network, lg *elog.Logs, stats *estats.Stats) {
 	stats.PCAStats(lg.IdxView(etime.Analyze, etime.Trial), "ActM", net.LayersByType(SuperLayer, TargetLayer, CTLayer, PTPredLayer))
 }
 
+// RunSummary returns a summary of the results of a training run, from the
+// plotted scalar items in the Train Epoch log, as a map of values keyed by
+// item name and measure, for programmatic comparison across runs:
+//   - Name:Final = value on the final epoch
+//   - Name:Best, Name:BestEpoch = best value and the epoch it occurred on,
+//     where the best is the minimum for error-like stats (having Err, SSE or
+//     Dist in their name), and the maximum otherwise
+//   - Name:FirstZero = the first epoch with a zero value for error-like
+//     stats, i.e., the time to criterion, or -1 if never reached
+//
+// Epochs are taken from the Epoch column if present, else the row number.
+// In addition, Epochs = number of epochs logged, WtUpdates = number of
+// WtFmDWt weight updates since InitWts, and SynUpdates = total number of
+// synapse updates (WtUpdates * number of synapses).
+func (nt *Network) RunSummary(lg *elog.Logs) map[string]float64 {
+	sum := make(map[string]float64)
+	sum["WtUpdates"] = float64(nt.WtUpdtCtr)
+	sum["SynUpdates"] = float64(nt.WtUpdtCtr) * float64(len(nt.Synapses))
+	sk := etime.Scope(etime.Train, etime.Epoch)
+	lt, ok := lg.Tables[sk]
+	if !ok || lt.Table.Rows == 0 {
+		return sum
+	}
+	dt := lt.Table
+	sum["Epochs"] = float64(dt.Rows)
+	epcCol := dt.ColByName("Epoch")
+	epoch := func(row int) float64 {
+		if epcCol == nil {
+			return float64(row)
+		}
+		return epcCol.FloatVal1D(row)
+	}
+	for _, item := range lg.Items {
+		if _, has := item.Write[sk]; !has || !item.Plot || item.CellShape != nil || item.Type == etensor.STRING {
+			continue
+		}
+		col := dt.ColByName(item.Name)
+		if col == nil {
+			continue
+		}
+		lower := runSummaryLowerBetter(item.Name)
+		best, bestRow, firstZero := col.FloatVal1D(0), 0, -1.0
+		for row := 0; row < dt.Rows; row++ {
+			v := col.FloatVal1D(row)
+			if (lower && v < best) || (!lower && v > best) {
+				best, bestRow = v, row
+			}
+			if lower && v == 0 && firstZero < 0 {
+				firstZero = epoch(row)
+			}
+		}
+		sum[item.Name+":Final"] = col.FloatVal1D(dt.Rows - 1)
+		sum[item.Name+":Best"] = best
+		sum[item.Name+":BestEpoch"] = epoch(bestRow)
+		if lower {
+			sum[item.Name+":FirstZero"] = firstZero
+		}
+	}
+	return sum
+}
+
+// runSummaryLowerBetter returns true if lower values of given stat are
+// better, for error-like stats, used in RunSummary.
+func runSummaryLowerBetter(name string) bool {
+	return strings.Contains(name, "Err") || strings.Contains(name, "SSE") || strings.Contains(name, "Dist")
+}
+
+// DetectPlateau finds where the improvement in a learning curve stalls,
+// from the series of values of a stat over epochs: it is the start of the
+// earliest window of given number of epochs after which the least-squares
+// slope over every window, through to the end of the series, has an
+// absolute value below slopeThr.  Returns found = false if the series is
+// shorter than the window, or the final window is not flat.
+func DetectPlateau(vals []float64, window int, slopeThr float64) (plateauStart int, found bool) {
+	if window < 2 {
+		window = 2
+	}
+	n := len(vals)
+	if n < window {
+		return 0, false
+	}
+	plateauStart = -1
+	for st := n - window; st >= 0; st-- {
+		if math.Abs(windowSlope(vals[st:st+window])) >= slopeThr {
+			break
+		}
+		plateauStart = st
+	}
+	if plateauStart < 0 {
+		return 0, false
+	}
+	return plateauStart, true
+}
+
+// windowSlope returns the least-squares slope of given values
+// as a function of their index.
+func windowSlope(vals []float64) float64 {
+	n := float64(len(vals))
+	mx := (n - 1) / 2
+	my := 0.0
+	for _, v := range vals {
+		my += v
+	}
+	my /= n
+	num, den := 0.0, 0.0
+	for i, v := range vals {
+		dx := float64(i) - mx
+		num += dx * (v - my)
+		den += dx * dx
+	}
+	return num / den
+}
+
+// LogDetectPlateau runs DetectPlateau on the given column of the log
+// for given mode and time (e.g., Train, Epoch), returning the row
+// where the plateau starts.  Returns an error if the log or the
+// column does not exist.
+func LogDetectPlateau(lg *elog.Logs, mode etime.Modes, time etime.Times, colNm string, window int, slopeThr float64) (plateauStart int, found bool, err error) {
+	lt, ok := lg.Tables[etime.Scope(mode, time)]
+	if !ok {
+		err = fmt.Errorf("LogDetectPlateau: log not found for: %s %s", mode, time)
+		log.Println(err)
+		return
+	}
+	col, err := lt.Table.ColByNameTry(colNm)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	vals := make([]float64, lt.Table.Rows)
+	for row := range vals {
+		vals[row] = col.FloatVal1D(row)
+	}
+	plateauStart, found = DetectPlateau(vals, window, slopeThr)
+	return
+}
+
 //////////////////////////////////////////////////////////////////////////////
 //  Log items
 
@@ -104,6 +245,85 @@ func LogAddDiagnosticItems(lg *elog.Logs, layerNames []string, mode etime.Modes,
 	}
 }
 
+// LogAddConductanceItems adds items recording the pool means of each of
+// the conductance components in ConductanceVars (Ge, Gnmda, etc), for each
+// of the given layers, at given mode and time, as returned by
+// Layer.ConductancePoolMeans.  Each item is named Layer_Var and holds a
+// tensor with one value per pool (sub-pool for 4D layers, else 1).
+// These are useful for seeing which channels drive the activity in a
+// layer, e.g., for tuning the Gbar conductance parameters.
+func LogAddConductanceItems(lg *elog.Logs, net *Network, mode etime.Modes, time etime.Times, layerNames ...string) {
+	for _, lnm := range layerNames {
+		ly := net.AxonLayerByName(lnm)
+		if ly == nil {
+			continue
+		}
+		npl := 1
+		if ly.Is4D() {
+			npl = ly.NSubPools()
+		}
+		for _, vn := range ConductanceVars {
+			clnm := lnm
+			cvn := vn
+			lg.AddItem(&elog.Item{
+				Name:      clnm + "_" + cvn,
+				Type:      etensor.FLOAT64,
+				CellShape: []int{npl},
+				DimNames:  []string{"Pool"},
+				FixMin:    true,
+				Write: elog.WriteMap{
+					etime.Scope(mode, time): func(ctx *elog.Context) {
+						ly := ctx.Layer(clnm).(AxonLayer).AsAxon()
+						means := ly.ConductancePoolMeans()[cvn]
+						tsr := etensor.NewFloat64([]int{len(means)}, nil, nil)
+						for pi, v := range means {
+							tsr.Values[pi] = float64(v)
+						}
+						ctx.SetTensor(tsr)
+					}}})
+		}
+	}
+}
+
+// LogAddInhibItems adds items recording each of the FS-FFFB inhibition
+// components in InhibVars (FF, FB, SS, Gi), for each of the given layers,
+// at given mode and time, as returned by Layer.InhibPoolComponents.
+// Each item is named Layer_Inhib_Var and holds a tensor with one value
+// per pool (sub-pool for 4D layers, else 1).
+// These are useful for tuning the Inhib Gi, FB, and SS parameters.
+func LogAddInhibItems(lg *elog.Logs, net *Network, mode etime.Modes, time etime.Times, layerNames ...string) {
+	for _, lnm := range layerNames {
+		ly := net.AxonLayerByName(lnm)
+		if ly == nil {
+			continue
+		}
+		npl := 1
+		if ly.Is4D() {
+			npl = ly.NSubPools()
+		}
+		for _, vn := range InhibVars {
+			clnm := lnm
+			cvn := vn
+			lg.AddItem(&elog.Item{
+				Name:      clnm + "_Inhib_" + cvn,
+				Type:      etensor.FLOAT64,
+				CellShape: []int{npl},
+				DimNames:  []string{"Pool"},
+				FixMin:    true,
+				Write: elog.WriteMap{
+					etime.Scope(mode, time): func(ctx *elog.Context) {
+						ly := ctx.Layer(clnm).(AxonLayer).AsAxon()
+						vals := ly.InhibPoolComponents()[cvn]
+						tsr := etensor.NewFloat64([]int{len(vals)}, nil, nil)
+						for pi, v := range vals {
+							tsr.Values[pi] = float64(v)
+						}
+						ctx.SetTensor(tsr)
+					}}})
+		}
+	}
+}
+
 func LogInputLayer(lg *elog.Logs, net *Network, mode etime.Modes) {
 	// input layer average activity -- important for tuning
 	layerNames := net.LayersByType(InputLayer)
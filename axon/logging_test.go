@@ -0,0 +1,110 @@
+// Copyright (c) 2023, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package axon
+
+import (
+	"math"
+	"testing"
+
+	"github.com/emer/emergent/elog"
+	"github.com/emer/emergent/estats"
+	"github.com/emer/emergent/etime"
+	"github.com/emer/etable/etensor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectPlateau(t *testing.T) {
+	// exponential improvement for 20 epochs, then flat with small noise
+	var vals []float64
+	for i := 0; i < 20; i++ {
+		vals = append(vals, math.Exp(-float64(i)/4))
+	}
+	for i := 0; i < 20; i++ {
+		vals = append(vals, vals[19]+0.001*float64(i%2))
+	}
+	st, found := DetectPlateau(vals, 5, 0.005)
+	assert.True(t, found)
+	assert.Greater(t, st, 12)
+	assert.LessOrEqual(t, st, 20)
+
+	// still improving at the end
+	st, found = DetectPlateau(vals[:15], 5, 0.005)
+	assert.False(t, found)
+
+	// too short
+	_, found = DetectPlateau(vals[:3], 5, 0.005)
+	assert.False(t, found)
+
+	// flat throughout
+	st, found = DetectPlateau([]float64{1, 1, 1, 1, 1, 1}, 3, 0.01)
+	assert.True(t, found)
+	assert.Equal(t, 0, st)
+
+	lg := &elog.Logs{}
+	row := 0
+	lg.AddItem(&elog.Item{
+		Name: "SSE",
+		Type: etensor.FLOAT64,
+		Write: elog.WriteMap{
+			etime.Scope(etime.Train, etime.Epoch): func(ctx *elog.Context) {
+				ctx.SetFloat64(vals[row])
+			}}})
+	assert.NoError(t, lg.CreateTables())
+	for row = range vals {
+		lg.Log(etime.Train, etime.Epoch)
+	}
+	lst, found, err := LogDetectPlateau(lg, etime.Train, etime.Epoch, "SSE", 5, 0.005)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	st, _ = DetectPlateau(vals, 5, 0.005)
+	assert.Equal(t, st, lst)
+
+	_, _, err = LogDetectPlateau(lg, etime.Train, etime.Epoch, "NotACol", 5, 0.005)
+	assert.Error(t, err)
+	_, _, err = LogDetectPlateau(lg, etime.Test, etime.Epoch, "SSE", 5, 0.005)
+	assert.Error(t, err)
+}
+
+func TestConductanceBreakdown(t *testing.T) {
+	net := NewNetwork("CondTest")
+	one := net.AddLayer2D("One", 1, 1, SuperLayer)
+	pools := net.AddLayer4D("Pools", 1, 2, 2, 1, SuperLayer)
+	assert.NoError(t, net.Build())
+	net.Defaults()
+	net.InitWts()
+
+	nrn := &one.Neurons[0]
+	nrn.Ge = 0.5
+	nrn.Gnmda = 0.1
+	nrn.Gvgcc = 0.02
+	nrn.GgabaB = 0.05
+	nrn.Gk = 0.3
+	cb := one.ConductanceBreakdown()
+	assert.Equal(t, len(ConductanceVars), len(cb))
+	assert.Equal(t, []float32{0.5}, cb["Ge"])
+	assert.Equal(t, []float32{0.1}, cb["Gnmda"])
+	assert.Equal(t, []float32{0.02}, cb["Gvgcc"])
+	assert.Equal(t, []float32{0.05}, cb["GgabaB"])
+	assert.Equal(t, []float32{0.3}, cb["Gk"])
+	assert.Equal(t, []float32{0.5}, one.ConductancePoolMeans()["Ge"])
+
+	for ni := range pools.Neurons {
+		pools.Neurons[ni].Gnmda = float32(ni + 1)
+	}
+	means := pools.ConductancePoolMeans()
+	assert.Equal(t, []float32{1.5, 3.5}, means["Gnmda"])
+	assert.Equal(t, []float32{0, 0}, means["Gk"])
+
+	lg := &elog.Logs{}
+	LogAddConductanceItems(lg, net, etime.Train, etime.Trial, "One", "Pools", "NoLayer")
+	assert.Equal(t, 2*len(ConductanceVars), len(lg.Items))
+	assert.NoError(t, lg.CreateTables())
+	lg.SetContext(&estats.Stats{}, net)
+	dt := lg.Log(etime.Train, etime.Trial)
+	assert.Equal(t, 0.5, dt.CellTensor("One_Ge", 0).FloatVal1D(0))
+	tsr := dt.CellTensor("Pools_Gnmda", 0)
+	assert.Equal(t, 2, tsr.Len())
+	assert.Equal(t, 3.5, tsr.FloatVal1D(1))
+}
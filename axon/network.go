@@ -6,15 +6,19 @@ package axon
 
 import (
 	"fmt"
+	"log"
+	"math"
 	"strings"
 	"unsafe"
 
 	"github.com/c2h5oh/datasize"
 	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/params"
 	"github.com/emer/emergent/prjn"
 	"github.com/emer/etable/etensor"
 	"github.com/goki/ki/ki"
 	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
 )
 
 // axon.Network has parameters for running a basic rate-coded Axon network
@@ -22,6 +26,11 @@ type Network struct {
 	NetworkBase
 	SlowInterval int `def:"100" desc:"how frequently to perform slow adaptive processes such as synaptic scaling, inhibition adaptation -- in SlowAdapt method-- long enough for meaningful changes"`
 	SlowCtr      int `inactive:"+" desc:"counter for how long it has been since last SlowAdapt step"`
+	WtUpdtCtr    int `inactive:"+" desc:"total number of WtFmDWt weight updates since InitWts -- used for RunSummary"`
+
+	NumericsGuard bool                `desc:"if true, neuron Vm, Ge, Gi, Act values are checked for NaN / Inf after every Cycle, and on the first occurrence, the offending neurons are recorded in NumericsErrs and reported, and further Cycle updating is stopped -- see CheckNumerics for a full check including synapse weights"`
+	NumericsErrs  []string            `view:"-" desc:"offending neurons detected by the NumericsGuard -- Cycle does not update while this is non-empty -- reset by InitWts, or set to nil to resume"`
+	RSARecs       map[string]*RSAActs `view:"-" desc:"activity patterns recorded by RecordActsForRSA, keyed by layer name -- see RSALayers"`
 }
 
 var KiT_Network = kit.Types.AddType(&Network{}, NetworkProps)
@@ -68,8 +77,58 @@ func (nt *Network) UpdateParams() {
 // Not all layers need to support all variables, but must safely return 0's for
 // unsupported ones.  The order of this list determines NetView variable display order.
 // This is typically a global list so do not modify!
+// Any variables added by RegisterNeuronVar are included at the end.
 func (nt *Network) UnitVarNames() []string {
-	return NeuronVars
+	if len(nt.NeuronVarFuns) == 0 {
+		return NeuronVars
+	}
+	vars := make([]string, len(NeuronVars), len(NeuronVars)+len(nt.NeuronVarFuns))
+	copy(vars, NeuronVars)
+	for _, vf := range nt.NeuronVarFuns {
+		vars = append(vars, vf.Name)
+	}
+	return vars
+}
+
+// NeuronVarFun is a virtual neuron variable computed on demand
+// by a function of the neuron state, registered by RegisterNeuronVar.
+type NeuronVarFun struct {
+	Name string                               `desc:"name of the variable"`
+	Fun  func(ni uint32, nrn *Neuron) float32 `desc:"function computing the value for given neuron, with ni = index of the neuron within its layer"`
+}
+
+// RegisterNeuronVar registers a virtual neuron variable with given name,
+// whose value is computed on demand by given function of the neuron
+// (e.g., "GeMinusGi" returning nrn.Ge - nrn.Gi).  These variables are
+// accessible through the standard Layer UnitVals, UnitValsTensor and
+// NetView paths, after all of the standard NeuronVars, and do not add
+// any cost to the computation of the network.  They are only available
+// on the CPU side: when running on the GPU, call GPU.SyncNeuronsFmGPU first.
+// Returns an error if the name is already in use.
+func (nt *Network) RegisterNeuronVar(name string, fn func(ni uint32, nrn *Neuron) float32) error {
+	if _, err := nt.NeuronVarIdx(name); err == nil {
+		err = fmt.Errorf("RegisterNeuronVar: variable name: %s already in use in network: %s", name, nt.Nm)
+		log.Println(err)
+		return err
+	}
+	nt.NeuronVarFuns = append(nt.NeuronVarFuns, NeuronVarFun{Name: name, Fun: fn})
+	return nil
+}
+
+// NeuronVarIdx returns the index of given neuron variable name,
+// including any virtual variables registered by RegisterNeuronVar,
+// which are indexed after all of the standard NeuronVars,
+// or -1 and an error if not found.
+func (nt *Network) NeuronVarIdx(varNm string) (int, error) {
+	if vidx, err := NeuronVarIdxByName(varNm); err == nil {
+		return vidx, nil
+	}
+	for i, vf := range nt.NeuronVarFuns {
+		if vf.Name == varNm {
+			return len(NeuronVars) + i, nil
+		}
+	}
+	return -1, fmt.Errorf("Neuron VarByName: variable name: %v not valid", varNm)
 }
 
 // UnitVarProps returns properties for variables
@@ -111,7 +170,42 @@ func (nt *Network) NewState(ctx *Context) {
 // algorithm-specific version is called as needed (in general, strongly prefer
 // updating the Layer specific version).
 func (nt *Network) Cycle(ctx *Context) {
+	if nt.NumericsGuard && len(nt.NumericsErrs) > 0 {
+		return
+	}
+	if ctx.PhaseCycle == 0 && nt.phaseFuns != nil {
+		for _, fn := range nt.phaseFuns[ctx.Phase] {
+			fn(ctx)
+		}
+	}
 	nt.CycleImpl(ctx)
+	if nt.NumericsGuard {
+		nt.numericsGuardCycle(ctx)
+	}
+}
+
+// PhaseFun is a function called at the start of a phase, registered
+// with Network.OnPhaseStart.
+type PhaseFun func(ctx *Context)
+
+// OnPhaseStart registers given function to be called at the start of
+// given phase (Context.Phase: 0 = minus, 1 = plus for the standard
+// minus-plus phases), by Cycle just before the first cycle of the phase
+// is computed (i.e., after Context.NewState or NewPhase).  This allows
+// phase-triggered logic, such as taking an action between the minus and
+// plus phases, to run without an external looper.  Multiple functions
+// for the same phase are called in the order registered.
+// The functions are not copied by Clone.  See ClearPhaseFuns.
+func (nt *Network) OnPhaseStart(phase int, fn PhaseFun) {
+	if nt.phaseFuns == nil {
+		nt.phaseFuns = make(map[int32][]PhaseFun)
+	}
+	nt.phaseFuns[int32(phase)] = append(nt.phaseFuns[int32(phase)], fn)
+}
+
+// ClearPhaseFuns removes all of the OnPhaseStart functions.
+func (nt *Network) ClearPhaseFuns() {
+	nt.phaseFuns = nil
 }
 
 // CycleImpl handles entire update for one cycle (msec) of neuron activity
@@ -123,6 +217,7 @@ func (nt *Network) CycleImpl(ctx *Context) {
 	nt.NeuronFun(func(ly *Layer, ni uint32, nrn *Neuron) { ly.GatherSpikes(ctx, ni, nrn) }, "GatherSpikes")
 	nt.LayerMapSeq(func(ly *Layer) { ly.GiFmSpikes(ctx) }, "GiFmSpikes")
 	nt.LayerMapSeq(func(ly *Layer) { ly.PoolGiFmSpikes(ctx) }, "PoolGiFmSpikes")
+	nt.LayerMapSeq(func(ly *Layer) { ly.GapJuncFmVm() }, "GapJuncFmVm")
 	nt.NeuronFun(func(ly *Layer, ni uint32, nrn *Neuron) { ly.CycleNeuron(ctx, ni, nrn) }, "CycleNeuron")
 	if !nt.CPURecvSpikes {
 		nt.SendSpikeFun(func(ly *Layer) { ly.SendSpike(ctx) }, "SendSpike")
@@ -130,8 +225,29 @@ func (nt *Network) CycleImpl(ctx *Context) {
 	if ctx.Testing.IsFalse() {
 		nt.NeuronFun(func(ly *Layer, ni uint32, nrn *Neuron) { ly.SynCaRecv(ctx, ni, nrn) }, "SynCaRecv")
 		nt.NeuronFun(func(ly *Layer, ni uint32, nrn *Neuron) { ly.SynCaSend(ctx, ni, nrn) }, "SynCaSend")
+		if nt.HasContinuousDWt() {
+			nt.PrjnMapSeq(func(pj *Prjn) { pj.ContinuousDWt(ctx) }, "ContinuousDWt")
+		}
 	}
 	nt.LayerMapSeq(func(ly *Layer) { ly.CyclePost(ctx) }, "CyclePost") // do not thread -- minor computation
+	if nt.ActsRing.NRecs > 0 {
+		nt.RecordActs()
+	}
+	if nt.NeurTrace.On {
+		nt.NeurTrace.Record(ctx, nt.Neurons)
+	}
+}
+
+// HasContinuousDWt returns true if any projection has
+// Learn.ContinuousDWt on, in which case ContinuousDWt is called
+// for all projections every cycle.
+func (nt *Network) HasContinuousDWt() bool {
+	for _, pj := range nt.Prjns {
+		if pj.Params.Learn.ContinuousDWt.IsTrue() {
+			return true
+		}
+	}
+	return false
 }
 
 // MinusPhase does updating after end of minus phase
@@ -202,6 +318,7 @@ func (nt *Network) DWt(ctx *Context) {
 // Also calls SynScale every Interval times
 func (nt *Network) WtFmDWt(ctx *Context) {
 	nt.WtFmDWtImpl(ctx)
+	nt.WtUpdtCtr++
 }
 
 //////////////////////////////////////////////////////////////////////////////////////
@@ -212,6 +329,8 @@ func (nt *Network) WtFmDWt(ctx *Context) {
 func (nt *Network) InitWts() {
 	nt.BuildPrjnGBuf()
 	nt.SlowCtr = 0
+	nt.WtUpdtCtr = 0
+	nt.NumericsErrs = nil
 	for _, ly := range nt.Layers {
 		if ly.IsOff() {
 			continue
@@ -266,6 +385,104 @@ func (nt *Network) InitTopoSWts() {
 	}
 }
 
+// CopyWtsFmByClass copies the synaptic weights (Wt, LWt, SWt) for all
+// projections having given class (which includes the projection type name,
+// e.g., CTCtxtPrjn) from the corresponding projections in the src network,
+// matched by receiving and sending layer names, leaving all other
+// projections unchanged.  This enables reusing a trained subsystem
+// in a new model.  Returns an error, without copying anything, if no
+// projections have the class, or if any of the matched projections is missing
+// in the src network or has a different connectivity.  When running on the GPU,
+// the src synapses must be synced from the GPU first.
+func (nt *Network) CopyWtsFmByClass(src *Network, class string) error {
+	var pjs, spjs []*Prjn
+	var errs []string
+	for _, pj := range nt.Prjns {
+		hasCls := false
+		for _, cl := range strings.Fields(pj.Class()) {
+			if cl == class {
+				hasCls = true
+				break
+			}
+		}
+		if !hasCls {
+			continue
+		}
+		slay := src.AxonLayerByName(pj.Recv.Nm)
+		if slay == nil {
+			errs = append(errs, fmt.Sprintf("%s: recv layer not found in src", pj.Name()))
+			continue
+		}
+		epj, err := slay.SendNameTry(pj.Send.Nm)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: not found in src", pj.Name()))
+			continue
+		}
+		spj := epj.(*Prjn)
+		match := len(spj.Syns) == len(pj.Syns) && len(spj.RecvCon) == len(pj.RecvCon)
+		for ri := 0; match && ri < len(pj.RecvCon); ri++ {
+			match = spj.RecvCon[ri] == pj.RecvCon[ri]
+		}
+		for ci := 0; match && ci < len(pj.RecvConIdx); ci++ {
+			match = spj.RecvConIdx[ci] == pj.RecvConIdx[ci]
+		}
+		if !match {
+			errs = append(errs, fmt.Sprintf("%s: connectivity differs from src", pj.Name()))
+			continue
+		}
+		pjs = append(pjs, pj)
+		spjs = append(spjs, spj)
+	}
+	if len(errs) > 0 {
+		err := fmt.Errorf("Network CopyWtsFmByClass: class %s in network %s: %s", class, nt.Nm, strings.Join(errs, "; "))
+		log.Println(err)
+		return err
+	}
+	if len(pjs) == 0 {
+		err := fmt.Errorf("Network CopyWtsFmByClass: no projections of class %s in network %s", class, nt.Nm)
+		log.Println(err)
+		return err
+	}
+	for i, pj := range pjs {
+		spj := spjs[i]
+		for si := range pj.Syns {
+			sy := &pj.Syns[si]
+			ssy := &spj.Syns[si]
+			sy.Wt = ssy.Wt
+			sy.LWt = ssy.LWt
+			sy.SWt = ssy.SWt
+		}
+	}
+	nt.GPU.SyncSynapsesToGPU()
+	return nil
+}
+
+// CheckDtConsistency verifies that the Act.Dt.Integ integration rate
+// constant in each layer is consistent with the time increment per cycle
+// in given Context (TimePerCycle), where Integ = 1 corresponds to 1 msec
+// per cycle.  Returns an error describing each layer with a divergent
+// Integ value, which would otherwise cause a silent drift between the
+// simulated time of the neural dynamics and Context.Time.
+func (nt *Network) CheckDtConsistency(ctx *Context) error {
+	integ := ctx.TimePerCycle / 0.001
+	var errs []string
+	for _, ly := range nt.Layers {
+		if ly.IsOff() {
+			continue
+		}
+		li := ly.Params.Act.Dt.Integ
+		if mat32.Abs(li-integ) > 1.0e-4*integ {
+			errs = append(errs, fmt.Sprintf("%s: Act.Dt.Integ = %g", ly.Name(), li))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	err := fmt.Errorf("CheckDtConsistency: Context.TimePerCycle = %g requires Act.Dt.Integ = %g, but layers differ: %s", ctx.TimePerCycle, integ, strings.Join(errs, ", "))
+	log.Println(err)
+	return err
+}
+
 // InitGScale computes the initial scaling factor for synaptic input conductances G,
 // stored in GScale.Scale, based on sending layer initial activation.
 func (nt *Network) InitGScale() {
@@ -277,6 +494,318 @@ func (nt *Network) InitGScale() {
 	}
 }
 
+// AutoScalePrjns calibrates the PrjnScale.Abs values of the excitatory
+// receiving projections into each non-input layer, so that the average
+// excitatory conductance (Ge) in each layer is near targetGe.
+// The representative input must already have been applied to the input
+// layers (e.g., via ApplyExt) -- this runs repeated calibration passes
+// of nCycles cycles each, starting from a fully decayed state, measuring
+// the layer-average Ge over the second half of each pass, and multiplying
+// the Abs factors by the ratio of target to measured Ge, until all layers
+// are within 2% of the target or a maximum number of passes is reached.
+// Layers with no measured Ge are left as is.  The resulting values are
+// already applied to the network, and are returned as a params.Sheet with
+// one "#PrjnName" selector per projection, that can be saved and applied
+// to other networks.  Call InitActs afterward to clear the calibration state.
+func (nt *Network) AutoScalePrjns(ctx *Context, targetGe float32, nCycles int) *params.Sheet {
+	const maxPasses = 10
+	const tol = 0.02
+	ges := make([]float32, len(nt.Layers))
+	for pass := 0; pass < maxPasses; pass++ {
+		nt.DecayState(ctx, 1, 1)
+		for _, ly := range nt.Layers {
+			ly.InitPrjnGBuffs()
+		}
+		nt.GPU.SyncGBufToGPU()
+		ctx.NewPhase(false)
+		for li := range ges {
+			ges[li] = 0
+		}
+		nmeas := 0
+		for cyc := 0; cyc < nCycles; cyc++ {
+			nt.Cycle(ctx)
+			ctx.CycleInc()
+			if cyc < nCycles/2 {
+				continue
+			}
+			if nt.GPU.On {
+				nt.GPU.SyncNeuronsFmGPU()
+			}
+			for li, ly := range nt.Layers {
+				for ni := range ly.Neurons {
+					ges[li] += ly.Neurons[ni].Ge
+				}
+			}
+			nmeas++
+		}
+		done := true
+		for li, ly := range nt.Layers {
+			if ly.IsOff() || ly.Params.IsInput() || len(ly.Neurons) == 0 || nmeas == 0 {
+				continue
+			}
+			ge := ges[li] / float32(nmeas*len(ly.Neurons))
+			if ge <= 0 {
+				continue
+			}
+			fact := targetGe / ge
+			if mat32.Abs(fact-1) <= tol {
+				continue
+			}
+			done = false
+			for _, pj := range ly.RcvPrjns {
+				if pj.IsOff() || !pj.Params.IsExcitatory() {
+					continue
+				}
+				pj.Params.PrjnScale.Abs *= fact
+			}
+			ly.InitGScale()
+		}
+		if done {
+			break
+		}
+		if nt.GPU.On {
+			nt.GPU.SyncParamsToGPU()
+		}
+	}
+	sheet := &params.Sheet{}
+	for _, ly := range nt.Layers {
+		if ly.IsOff() || ly.Params.IsInput() {
+			continue
+		}
+		for _, pj := range ly.RcvPrjns {
+			if pj.IsOff() || !pj.Params.IsExcitatory() {
+				continue
+			}
+			*sheet = append(*sheet, &params.Sel{Sel: "#" + pj.Name(), Desc: "AutoScalePrjns",
+				Params: params.Params{
+					"Prjn.PrjnScale.Abs": fmt.Sprintf("%g", pj.Params.PrjnScale.Abs),
+				}})
+		}
+	}
+	return sheet
+}
+
+// RebuildIdxs rebuilds the sender-based connectivity indexes and the
+// network-global copies of the connection indexes used on the GPU,
+// from the current receiver-based RecvCon connectivity in each projection,
+// and then recomputes the GScale conductance scaling factors,
+// which depend on the number of connections.
+// This must be called after any structural changes to the connectivity,
+// such as pruning synapses.
+func (nt *Network) RebuildIdxs() {
+	for _, ly := range nt.Layers {
+		if ly.IsOff() {
+			continue
+		}
+		for _, pj := range ly.RcvPrjns {
+			if pj.IsOff() {
+				continue
+			}
+			pj.RebuildSendIdxs()
+			pj.UpdtConNAvgMax()
+			for ri, rcon := range pj.RecvCon {
+				nt.PrjnRecvCon[int(pj.Params.Idxs.RecvConSt)+ri] = rcon
+			}
+		}
+	}
+	for _, ly := range nt.Layers {
+		if ly.IsOff() {
+			continue
+		}
+		for _, pj := range ly.SndPrjns {
+			if pj.IsOff() {
+				continue
+			}
+			for si, scon := range pj.SendCon {
+				nt.PrjnSendCon[int(pj.Params.Idxs.SendConSt)+si] = scon
+				sidxs := pj.SendSynIdxs(si)
+				for ci, ssi := range sidxs {
+					nt.SendSynIdxs[pj.Params.Idxs.SendSynSt+scon.Start+uint32(ci)] = pj.Syns[ssi].SynIdx
+				}
+			}
+		}
+	}
+	for _, ly := range nt.Layers {
+		if ly.IsOff() {
+			continue
+		}
+		ly.InitGScale()
+	}
+	if nt.GPU.On {
+		nt.GPU.CopyIdxsToStaging()
+		nt.GPU.CopyParamsToStaging()
+		nt.GPU.SyncMemToGPU()
+	}
+}
+
+// ReallocSynapses reallocates the network-global Synapses to hold the
+// current Syns of each projection, which must be called after any
+// projection has changed its number of synapses (e.g., GrowSyns),
+// followed by RebuildIdxs.  The synapse values are preserved, and the
+// synapse and projection indexes are updated for the new layout.
+// Any TrackWtAge LastDWtCyc values that no longer match the number of
+// synapses are reallocated, with all ages reset to -1.
+// The GPU must be reconfigured after this, as its buffers are sized
+// for the original number of synapses.
+func (nt *Network) ReallocSynapses() {
+	tot := 0
+	for _, ly := range nt.Layers {
+		for _, pj := range ly.RcvPrjns {
+			tot += len(pj.Syns)
+		}
+	}
+	if tot > math.MaxUint32 {
+		log.Fatalf("ERROR: total number of synapses is greater than uint32 capacity\n")
+	}
+	syns := make([]Synapse, tot)
+	sidx := 0
+	for _, ly := range nt.Layers {
+		for _, pj := range ly.RcvPrjns {
+			nsyn := len(pj.Syns)
+			copy(syns[sidx:sidx+nsyn], pj.Syns)
+			pj.Syns = syns[sidx : sidx+nsyn]
+			pj.Params.Idxs.SynapseSt = uint32(sidx)
+			if pj.LastDWtCyc != nil && len(pj.LastDWtCyc) != nsyn {
+				pj.LastDWtCyc = make([]int32, nsyn)
+				pj.InitWtAge()
+			}
+			for i := range pj.Syns {
+				pj.Syns[i].SynIdx = uint32(sidx + i)
+			}
+			slay := pj.Send
+			for ri, rcon := range pj.RecvCon {
+				for ci := uint32(0); ci < rcon.N; ci++ {
+					sy := &pj.Syns[rcon.Start+ci]
+					sy.RecvIdx = uint32(ri + ly.NeurStIdx) // network-global idx
+					sy.SendIdx = pj.RecvConIdx[rcon.Start+ci] + uint32(slay.NeurStIdx)
+					sy.PrjnIdx = pj.Params.Idxs.PrjnIdx
+				}
+			}
+			sidx += nsyn
+		}
+	}
+	nt.Synapses = syns
+	nt.SendSynIdxs = make([]uint32, tot)
+	ssidx := 0
+	for _, ly := range nt.Layers {
+		for _, pj := range ly.SndPrjns {
+			pj.Params.Idxs.SendSynSt = uint32(ssidx)
+			ssidx += len(pj.Syns)
+		}
+	}
+}
+
+// Clone returns a deep copy of this network, which must already have
+// been built, reproducing the layers, projections (with the same
+// synaptic connectivity), params, and the full neuron, pool and
+// synapse state, so that it will compute identically to the original.
+// The clone has its own independent Rand, seeded from RndSeed.
+// The GPU is not configured for the clone -- call ConfigGPU as needed.
+// When running on the GPU, sync the state from the GPU first.
+// The optional CPU-side configuration of the layers and projections
+// (e.g., SpikeFun, GapJunc, UnitPerm, ScaleSchedCycs, LastDWtCyc) is
+// also copied, with functions shared with the original.
+// Prjn patterns are shared with the original, and optional
+// CPU-side recording state (SpkHist, RateAcc, ActTrc, ActsRing, NeurTrace)
+// is not copied.
+func (nt *Network) Clone() *Network {
+	if len(nt.Layers) > 0 && nt.Layers[0].Params == nil {
+		log.Printf("Network Clone: network %s must be built first\n", nt.Nm)
+		return nil
+	}
+	cn := NewNetwork(nt.Nm)
+	cn.WtsFile = nt.WtsFile
+	cn.CPURecvSpikes = nt.CPURecvSpikes
+	cn.RndSeed = nt.RndSeed
+	cn.ResetRndSeed()
+	if nt.MetaData != nil {
+		cn.MetaData = make(map[string]string, len(nt.MetaData))
+		for k, v := range nt.MetaData {
+			cn.MetaData[k] = v
+		}
+	}
+	if nt.LayGroups != nil {
+		cn.LayGroups = make(map[string][]string, len(nt.LayGroups))
+		for gnm, nms := range nt.LayGroups {
+			cn.LayGroups[gnm] = append([]string(nil), nms...)
+		}
+	}
+	for _, ly := range nt.Layers {
+		cl := cn.AddLayer(ly.Nm, ly.Shp.Shp, ly.LayerType())
+		cl.Shp.CopyShape(&ly.Shp)
+		cl.Cls = ly.Cls
+		cl.Off = ly.Off
+		cl.Rel = ly.Rel
+		cl.Ps = ly.Ps
+		cl.RepIxs = append([]int(nil), ly.RepIxs...)
+		cl.RepShp.CopyShape(&ly.RepShp)
+		for k, v := range ly.BuildConfig {
+			cl.BuildConfig[k] = v
+		}
+	}
+	// connect in receiving order, then match the original sending order,
+	// so that all of the global indexes are identical.
+	pjMap := make(map[*Prjn]*Prjn)
+	for li, ly := range nt.Layers {
+		for _, pj := range ly.RcvPrjns {
+			cp := cn.ConnectLayers(cn.Layers[pj.Send.Idx], cn.Layers[li], pj.Pat, pj.Typ)
+			cp.Off = pj.Off
+			cp.Cls = pj.Cls
+			cp.Notes = pj.Notes
+			cp.InitSeed = pj.InitSeed
+			cp.SelfMaint = pj.SelfMaint
+			cp.SelfMaintAbs = pj.SelfMaintAbs
+			pjMap[pj] = cp
+		}
+	}
+	for li, ly := range nt.Layers {
+		cl := cn.Layers[li]
+		cl.SndPrjns = make(AxonPrjns, len(ly.SndPrjns))
+		for pi, pj := range ly.SndPrjns {
+			cl.SndPrjns[pi] = pjMap[pj]
+		}
+	}
+	if err := cn.Build(); err != nil {
+		log.Println(err)
+		return nil
+	}
+	for pj, cp := range pjMap {
+		cp.RecvCon = append([]StartN(nil), pj.RecvCon...)
+		cp.RecvConIdx = append([]uint32(nil), pj.RecvConIdx...)
+		cp.Syns = append([]Synapse(nil), pj.Syns...)
+		pj.cloneConfigTo(cp)
+	}
+	cn.ReallocSynapses()
+	cn.RebuildIdxs()
+	copy(cn.LayParams, nt.LayParams)
+	copy(cn.PrjnParams, nt.PrjnParams)
+	cn.BuildPrjnGBuf() // sized from the copied Com.MaxDelay params
+	if len(cn.Neurons) != len(nt.Neurons) || len(cn.Synapses) != len(nt.Synapses) || len(cn.PrjnGBuf) != len(nt.PrjnGBuf) {
+		log.Printf("Network Clone: network %s structure could not be reproduced\n", nt.Nm)
+		return nil
+	}
+	copy(cn.LayVals, nt.LayVals)
+	copy(cn.Pools, nt.Pools)
+	copy(cn.Neurons, nt.Neurons)
+	copy(cn.Synapses, nt.Synapses)
+	copy(cn.PrjnGBuf, nt.PrjnGBuf)
+	copy(cn.PrjnGSyns, nt.PrjnGSyns)
+	copy(cn.Exts, nt.Exts)
+	for li, ly := range nt.Layers {
+		ly.cloneConfigTo(cn.Layers[li])
+	}
+	cn.Threads = nt.Threads
+	cn.SlowInterval = nt.SlowInterval
+	cn.SlowCtr = nt.SlowCtr
+	cn.WtUpdtCtr = nt.WtUpdtCtr
+	for epc, shs := range nt.ParamScheds {
+		for _, sh := range shs {
+			cn.AddParamSched(epc, sh)
+		}
+	}
+	return cn
+}
+
 // DecayState decays activation state by given proportion
 // e.g., 1 = decay completely, and 0 = decay not at all.
 // glong = separate decay factor for long-timescale conductances (g)
@@ -307,6 +836,14 @@ func (nt *Network) DecayStateByClass(ctx *Context, decay, glong float32, classes
 	nt.DecayStateLayers(ctx, decay, glong, nt.LayersByClass(classes...)...)
 }
 
+// DecayStateByGroup decays activation state for given layer group
+// (see LayerGroup) by given proportion e.g., 1 = decay completely,
+// and 0 = decay not at all.
+// glong = separate decay factor for long-timescale conductances (g)
+func (nt *Network) DecayStateByGroup(ctx *Context, decay, glong float32, group string) {
+	nt.DecayStateLayers(ctx, decay, glong, nt.LayersByGroup(group)...)
+}
+
 // DecayStateLayers decays activation state for given layers
 // by given proportion e.g., 1 = decay completely, and 0 = decay not at all.
 // glong = separate decay factor for long-timescale conductances (g).
@@ -325,6 +862,33 @@ func (nt *Network) DecayStateLayers(ctx *Context, decay, glong float32, layers .
 	nt.GPU.SyncStateToGPU()
 }
 
+// DecayStateAll decays activation state for all layers in the network,
+// e.g., between unrelated stimuli, by given proportion (1 = decay
+// completely, 0 = not at all), with glong = separate decay factor for
+// long-timescale conductances (g).  A value of -1 for either factor uses
+// each layer's own Act.Decay parameters (Act, Glong), so that layers
+// that are configured to maintain their activity across trials (e.g.,
+// PFC PTMaintLayer, CTLayer) are treated differently from others.
+// See DecayStateLayers for GBuf issues.
+func (nt *Network) DecayStateAll(ctx *Context, actDecay, glongDecay float32) {
+	nt.GPU.SyncStateFmGPU() // note: because we have to sync back, we need to sync from first to be current
+	for _, ly := range nt.Layers {
+		if ly.IsOff() {
+			continue
+		}
+		decay := actDecay
+		if decay < 0 {
+			decay = ly.Params.Act.Decay.Act
+		}
+		glong := glongDecay
+		if glong < 0 {
+			glong = ly.Params.Act.Decay.Glong
+		}
+		ly.DecayState(ctx, decay, glong)
+	}
+	nt.GPU.SyncStateToGPU()
+}
+
 // InitActs fully initializes activation state -- not automatically called
 func (nt *Network) InitActs() {
 	for _, ly := range nt.Layers {
@@ -337,6 +901,30 @@ func (nt *Network) InitActs() {
 	nt.GPU.SyncGBufToGPU() // zeros everyone
 }
 
+// Reinit rebuilds the derived state of the network that depends on
+// structural parameters, while preserving the learned weights: after
+// UpdateParams, it rebuilds the GBuf spike delay buffers (BuildPrjnGBuf),
+// which is needed after increasing any Prjn Com.MaxDelay, recomputes the
+// GScale projection scaling factors (InitGScale), and reinitializes the
+// activations (InitActs).  Use this to apply changes to such parameters
+// mid-experiment.  If the GPU is in use, it is reconfigured with given
+// context if the size of the buffers changed.
+func (nt *Network) Reinit(ctx *Context) {
+	gbsz := len(nt.PrjnGBuf)
+	nt.UpdateParams()
+	nt.BuildPrjnGBuf()
+	nt.InitGScale()
+	if nt.GPU.On {
+		if len(nt.PrjnGBuf) != gbsz {
+			nt.GPU.Destroy()
+			nt.GPU.Config(ctx, nt)
+		} else {
+			nt.GPU.SyncParamsToGPU()
+		}
+	}
+	nt.InitActs()
+}
+
 // InitExt initializes external input state.
 // Call prior to applying external inputs to layers.
 func (nt *Network) InitExt() {
@@ -375,8 +963,15 @@ func (nt *Network) UpdateExtFlags() {
 
 // NewStateImpl handles all initialization at start of new input state
 func (nt *Network) NewStateImpl(ctx *Context) {
+	for _, ly := range nt.Layers {
+		if ly.RateAcc.Reset {
+			ly.RateAcc.ResetSums()
+			ly.RateAcc.Reset = false
+		}
+	}
 	if nt.GPU.On {
 		nt.GPU.RunNewState()
+		nt.frozenClampPoolsGPU()
 		return
 	}
 	for _, ly := range nt.Layers {
@@ -387,6 +982,25 @@ func (nt *Network) NewStateImpl(ctx *Context) {
 	}
 }
 
+// frozenClampPoolsGPU calls FrozenClampPools on any layers clamped
+// via ClampToFrozen, after NewState on the GPU.
+func (nt *Network) frozenClampPoolsGPU() {
+	synced := false
+	for _, ly := range nt.Layers {
+		if ly.IsOff() || !ly.FrozenClamp {
+			continue
+		}
+		if !synced {
+			nt.GPU.SyncPoolsFmGPU()
+			synced = true
+		}
+		ly.FrozenClampPools()
+	}
+	if synced {
+		nt.GPU.SyncPoolsToGPU()
+	}
+}
+
 // MinusPhaseImpl does updating after end of minus phase
 func (nt *Network) MinusPhaseImpl(ctx *Context) {
 	if nt.GPU.On {
@@ -401,17 +1015,25 @@ func (nt *Network) MinusPhaseImpl(ctx *Context) {
 		}
 	}
 	// Post happens on the CPU always
+	unclamped := false
 	for _, ly := range nt.Layers {
 		if ly.IsOff() {
 			continue
 		}
 		ly.MinusPhasePost(ctx)
+		if ly.UnclampPartialTargs() {
+			unclamped = true
+		}
+	}
+	if unclamped {
+		nt.GPU.SyncPoolsToGPU()
 	}
 }
 
 // PlusPhaseStartImpl does updating at the start of the plus phase:
 // applies Target inputs as External inputs.
 func (nt *Network) PlusPhaseStartImpl(ctx *Context) {
+	nt.BurstThrFmPctile()
 	if nt.GPU.On {
 		nt.GPU.RunPlusPhaseStart()
 	} else {
@@ -425,6 +1047,32 @@ func (nt *Network) PlusPhaseStartImpl(ctx *Context) {
 	}
 }
 
+// BurstThrFmPctile computes the percentile-based Burst thresholds for
+// SuperLayers with Burst.ThrMode = BurstThrPctile, at the start of the
+// plus phase, syncing the Neurons and Pools with the GPU as needed.
+func (nt *Network) BurstThrFmPctile() {
+	var lays []*Layer
+	for _, ly := range nt.Layers {
+		if ly.IsOff() || ly.LayerType() != SuperLayer || ly.Params.Burst.ThrMode != BurstThrPctile {
+			continue
+		}
+		lays = append(lays, ly)
+	}
+	if len(lays) == 0 {
+		return
+	}
+	if nt.GPU.On {
+		nt.GPU.SyncNeuronsFmGPU()
+		nt.GPU.SyncPoolsFmGPU()
+	}
+	for _, ly := range lays {
+		ly.BurstThrFmPctile()
+	}
+	if nt.GPU.On {
+		nt.GPU.SyncPoolsToGPU()
+	}
+}
+
 // PlusPhaseImpl does updating after end of plus phase
 func (nt *Network) PlusPhaseImpl(ctx *Context) {
 	if nt.GPU.On {
@@ -445,6 +1093,12 @@ func (nt *Network) PlusPhaseImpl(ctx *Context) {
 		}
 		ly.PlusPhasePost(ctx)
 	}
+	for _, ly := range nt.Layers { // after Post, once gating state is known
+		if ly.IsOff() {
+			continue
+		}
+		ly.DecayOnGate(ctx)
+	}
 	nt.GPU.SyncStateToGPU() // plus phase post can do anything
 }
 
@@ -471,6 +1125,26 @@ func (nt *Network) WtFmDWtImpl(ctx *Context) {
 	nt.SlowAdapt(ctx)
 }
 
+// ApplyAccumulatedDWt applies the weight changes accumulated over trials
+// in the DWt values of all projections with Learn.AccumulateDWt on,
+// and zeros the DWt values.  Computed on the CPU, with synapses synced
+// from and back to the GPU if it is in use.
+func (nt *Network) ApplyAccumulatedDWt(ctx *Context) {
+	nt.GPU.SyncSynapsesFmGPU()
+	for _, ly := range nt.Layers {
+		if ly.IsOff() {
+			continue
+		}
+		for _, pj := range ly.RcvPrjns {
+			if pj.IsOff() || pj.Params.Learn.Learn.IsFalse() || pj.Params.Learn.AccumulateDWt.IsFalse() {
+				continue
+			}
+			pj.ApplyDWt(ctx)
+		}
+	}
+	nt.GPU.SyncSynapsesToGPU()
+}
+
 // SlowAdapt is the layer-level slow adaptation functions: Synaptic scaling,
 // and adapting inhibition
 func (nt *Network) SlowAdapt(ctx *Context) {
@@ -507,6 +1181,15 @@ func (nt *Network) LRateMod(mod float32) {
 	}
 }
 
+// LRateModByGroup sets the LRate modulation parameter for Prjns
+// in given layer group (see LayerGroup), for dynamic modulation of
+// learning rate in a subset of layers (see also LRateMod).
+func (nt *Network) LRateModByGroup(group string, mod float32) {
+	nt.GroupDo(group, func(ly *Layer) {
+		ly.LRateMod(mod)
+	})
+}
+
 // LRateSched sets the schedule-based learning rate multiplier.
 // See also LRateMod.
 // Updates the effective learning rate factor accordingly.
@@ -519,6 +1202,45 @@ func (nt *Network) LRateSched(sched float32) {
 	}
 }
 
+// AddParamSched adds given params sheet to be applied at the start of
+// given epoch, when ApplyParamScheds is called for that epoch.
+// This supports arbitrary schedules of parameter changes
+// (e.g., learning rate annealing, inhibition changes), generalizing LRateSched.
+// Multiple sheets can be scheduled for the same epoch, and are applied
+// in the order added.
+func (nt *Network) AddParamSched(epoch int, sheet *params.Sheet) {
+	if nt.ParamScheds == nil {
+		nt.ParamScheds = make(map[int][]*params.Sheet)
+	}
+	nt.ParamScheds[epoch] = append(nt.ParamScheds[epoch], sheet)
+}
+
+// ApplyParamScheds applies any params sheets scheduled for given epoch
+// via AddParamSched, using ApplyParams.  This should be called at the
+// start of each epoch.  Returns true if any params were set,
+// and error if there were any errors.
+func (nt *Network) ApplyParamScheds(epoch int) (bool, error) {
+	sheets, has := nt.ParamScheds[epoch]
+	if !has {
+		return false, nil
+	}
+	applied := false
+	var rerr error
+	for _, sh := range sheets {
+		app, err := nt.ApplyParams(sh, false)
+		if app {
+			applied = true
+		}
+		if err != nil {
+			rerr = err
+		}
+	}
+	if applied {
+		nt.GPU.SyncParamsToGPU()
+	}
+	return applied, rerr
+}
+
 // SetSubMean sets the SubMean parameters in all the layers in the network
 // trgAvg is for Learn.TrgAvgAct.SubMean
 // prjn is for the prjns Learn.Trace.SubMean
@@ -554,6 +1276,192 @@ func (nt *Network) UnLesionNeurons() {
 	}
 }
 
+// RateHistByClass returns histograms of the average neuron rates
+// (see Layer.RateHist) for each of the given layer classes (or types),
+// combined across all layers of that class, keyed by class name.
+// Rate accumulation must be enabled on the layers with SetRateHist.
+func (nt *Network) RateHistByClass(bins int, classes ...string) map[string][]int {
+	hists := make(map[string][]int, len(classes))
+	for _, cl := range classes {
+		hist := make([]int, bins)
+		for _, lnm := range nt.LayersByClass(cl) {
+			ly := nt.AxonLayerByName(lnm)
+			if ly == nil || ly.IsOff() {
+				continue
+			}
+			ly.RateAcc.AddHist(hist)
+		}
+		hists[cl] = hist
+	}
+	return hists
+}
+
+// TotalCost returns the total metabolic energy cost accumulated
+// since the last ResetCosts across all layers that are not Off,
+// as the sum of Layer.SpikeCost values.
+func (nt *Network) TotalCost() float32 {
+	cost := float32(0)
+	for _, ly := range nt.Layers {
+		if ly.IsOff() {
+			continue
+		}
+		cost += ly.SpikeCost()
+	}
+	return cost
+}
+
+// ResetCosts resets the accumulated energy cost for all layers,
+// e.g., at the start of each epoch.
+func (nt *Network) ResetCosts() {
+	for _, ly := range nt.Layers {
+		ly.ResetCost()
+	}
+}
+
+// SetRecordCosts enables or disables accumulation of the metabolic energy
+// cost for all layers -- see Layer.SetRecordCost.
+func (nt *Network) SetRecordCosts(on bool) {
+	for _, ly := range nt.Layers {
+		ly.SetRecordCost(on)
+	}
+}
+
+// SymmetryError returns the mean absolute difference between the Wt
+// weights of each synapse in projection pjA and the corresponding
+// reciprocal synapse in projection pjB, which must connect the same
+// two layers in the opposite direction.  This is 0 for perfectly
+// symmetric weights, as established by InitWtSym, and can be tracked
+// over learning to measure the drift away from symmetry.
+// Synapses without a reciprocal are ignored.  Returns -1 if the
+// projections are not reciprocal or have no reciprocal synapses.
+// Syncs the synapses from the GPU if it is on.
+func (nt *Network) SymmetryError(pjA, pjB AxonPrjn) float32 {
+	pa := pjA.AsAxon()
+	pb := pjB.AsAxon()
+	if pa.Send != pb.Recv || pa.Recv != pb.Send {
+		log.Printf("SymmetryError: projections %s and %s are not reciprocal\n", pa.Name(), pb.Name())
+		return -1
+	}
+	nt.GPU.SyncSynapsesFmGPU()
+	sum := float32(0)
+	n := 0
+	for ri := range pa.Recv.Neurons {
+		syns := pa.RecvSyns(ri)
+		for ci := range syns {
+			sy := &syns[ci]
+			si := int(pa.Params.SynSendLayIdx(sy))
+			bi := pb.SynIdx(ri, si) // pb sends from ri to si
+			if bi < 0 {
+				continue
+			}
+			sum += mat32.Abs(sy.Wt - pb.Syns[bi].Wt)
+			n++
+		}
+	}
+	if n == 0 {
+		return -1
+	}
+	return sum / float32(n)
+}
+
+// TraceByClass returns the TraceSnapshot of the synaptic eligibility
+// traces for all projections having any of the given classes (which
+// include the projection type name, e.g., MatrixPrjn), keyed by
+// projection name.  Syncs the synapses from the GPU if it is on.
+func (nt *Network) TraceByClass(classes ...string) map[string][]float32 {
+	nt.GPU.SyncSynapsesFmGPU()
+	trs := make(map[string][]float32)
+	for _, ly := range nt.Layers {
+		if ly.IsOff() {
+			continue
+		}
+		for _, pj := range ly.RcvPrjns {
+			if pj.IsOff() {
+				continue
+			}
+		clsLoop:
+			for _, pcl := range strings.Fields(pj.Class()) {
+				for _, cl := range classes {
+					if pcl == cl {
+						trs[pj.Name()] = pj.TraceSnapshot()
+						break clsLoop
+					}
+				}
+			}
+		}
+	}
+	return trs
+}
+
+// ErrSignalByClass returns the ErrSignal plus - minus phase learning
+// signal for all layers having any of the given classes (which include
+// the layer type name, e.g., SuperLayer), keyed by layer name.
+// Call after the PlusPhase.
+func (nt *Network) ErrSignalByClass(classes ...string) map[string][]float32 {
+	errs := make(map[string][]float32)
+	for _, lnm := range nt.LayersByClass(classes...) {
+		ly := nt.AxonLayerByName(lnm)
+		if ly == nil || ly.IsOff() {
+			continue
+		}
+		errs[lnm] = ly.ErrSignal()
+	}
+	return errs
+}
+
+// RecordActsRing configures the recording of given neuron variables
+// (e.g., "Act", "Vm") for all neurons in the network each cycle, into a
+// fixed-size ring buffer of the most recent nrecs cycles (see ActsRing),
+// which can be read back with ActsRingTensor.  nrecs = 0 turns it off.
+// Values are recorded automatically in Cycle when running on the CPU.
+// When running on the GPU, Neurons must be synced from the GPU
+// and RecordActs called manually each cycle.
+func (nt *Network) RecordActsRing(nrecs int, vars ...string) error {
+	ar := &nt.ActsRing
+	if nrecs <= 0 {
+		*ar = ActsRing{}
+		return nil
+	}
+	vidxs := make([]int, len(vars))
+	for i, vn := range vars {
+		vi, err := NeuronVarIdxByName(vn)
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+		vidxs[i] = vi
+	}
+	nn := len(nt.Neurons)
+	ar.NRecs = nrecs
+	ar.NNeurons = nn
+	ar.Vars = vars
+	ar.VarIdxs = vidxs
+	ar.Vals = make([][]float32, len(vars))
+	for i := range vars {
+		ar.Vals[i] = make([]float32, nrecs*nn)
+	}
+	ar.Reset()
+	return nil
+}
+
+// RecordActs records the current values of the ActsRing variables
+// (see RecordActsRing).  Called automatically in Cycle on the CPU.
+func (nt *Network) RecordActs() {
+	nt.ActsRing.Record(nt.Neurons)
+}
+
+// ActsRingTensor returns the values recorded in the ActsRing for given
+// variable, as a tensor of shape [Recs][Neurons], with records ordered
+// from oldest to most recent, and neurons in the global network order
+// (see Layer.NeurStartIdx).  Returns nil if the variable is not recorded.
+func (nt *Network) ActsRingTensor(varNm string) *etensor.Float32 {
+	vi := nt.ActsRing.VarIdx(varNm)
+	if vi < 0 {
+		return nil
+	}
+	return nt.ActsRing.Tensor(vi)
+}
+
 //////////////////////////////////////////////////////////////////////////////////////
 //  Methods used in MPI computation, which don't depend on MPI specifically
 
@@ -691,6 +1599,87 @@ func (nt *Network) SizeReport() string {
 	return b.String()
 }
 
+// SetLayerType changes the type of given layer, using Layer.SetLayerType,
+// which can be done safely after Build (e.g., Target to Compare).
+// Returns an error if the layer is not found.
+func (nt *Network) SetLayerType(layer string, typ LayerTypes) error {
+	ly, err := nt.LayByNameTry(layer)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	ly.SetLayerType(typ)
+	return nil
+}
+
+// SetVmSteps sets the Act.Dt.VmSteps number of Vm integration steps
+// for all layers having given class (which includes the layer type name,
+// e.g., SuperLayer), so that only layers with fast dynamics need to pay
+// the cost of more steps.  Must be called after Build and Defaults,
+// and any subsequent call to Defaults will reset the values
+// (use a params "Layer.Act.Dt.VmSteps" setting to make it persistent).
+// Returns an error if no layers have the class or steps < 1.
+func (nt *Network) SetVmSteps(class string, steps int) error {
+	if steps < 1 {
+		err := fmt.Errorf("SetVmSteps: steps must be >= 1, got: %d", steps)
+		log.Println(err)
+		return err
+	}
+	n := 0
+	for _, ly := range nt.Layers {
+		for _, cl := range strings.Fields(ly.Class()) {
+			if cl == class {
+				ly.Params.Act.Dt.VmSteps = int32(steps)
+				ly.Params.Act.Dt.Update()
+				n++
+				break
+			}
+		}
+	}
+	if n == 0 {
+		err := fmt.Errorf("SetVmSteps: no layers found with class: %s", class)
+		log.Println(err)
+		return err
+	}
+	nt.GPU.SyncParamsToGPU()
+	return nil
+}
+
+// VmStepsReport returns a string listing the Act.Dt.VmSteps number of
+// Vm integration steps for each layer, along with an estimate of the
+// relative compute cost of Vm integration, as the number of neurons
+// times steps, and its percent of the network total.  Layers with
+// only 1 step are flagged as being potentially numerically unstable.
+func (nt *Network) VmStepsReport() string {
+	var b strings.Builder
+	tot := 0
+	for _, ly := range nt.Layers {
+		if ly.IsOff() {
+			continue
+		}
+		tot += len(ly.Neurons) * int(ly.Params.Act.Dt.VmSteps)
+	}
+	fmt.Fprintf(&b, "%14s:\t %14s\t VmSteps\t Cost\t Pct\n", "Layer", "Type")
+	for _, ly := range nt.Layers {
+		if ly.IsOff() {
+			continue
+		}
+		steps := int(ly.Params.Act.Dt.VmSteps)
+		cost := len(ly.Neurons) * steps
+		pct := float32(0)
+		if tot > 0 {
+			pct = 100 * float32(cost) / float32(tot)
+		}
+		fmt.Fprintf(&b, "%14s:\t %14s\t %d\t %d\t %5.1f", ly.Nm, ly.LayerType().String(), steps, cost, pct)
+		if steps < 2 {
+			fmt.Fprintf(&b, "\t warning: 1 step may be numerically unstable")
+		}
+		fmt.Fprintf(&b, "\n")
+	}
+	fmt.Fprintf(&b, "%14s:\t %14s\t \t %d\n", "Total", "", tot)
+	return b.String()
+}
+
 //////////////////////////////////////////////////////////////////////////////////////
 //  Network props for gui
 
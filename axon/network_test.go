@@ -3,10 +3,23 @@ package axon
 // TODO: should we make a network package?
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/emer/emergent/elog"
 	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/etime"
+	"github.com/emer/emergent/params"
+	"github.com/emer/emergent/prjn"
+	"github.com/emer/emergent/weights"
+	"github.com/emer/etable/etensor"
+	"github.com/goki/mat32"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewNetwork(t *testing.T) {
@@ -21,3 +34,1201 @@ func TestInterfaceType(t *testing.T) {
 	_, ok := testNet.EmerNet.(emer.Network)
 	assert.True(t, ok)
 }
+
+func TestSynChangeSnapshot(t *testing.T) {
+	net := createNetwork([]int{2, 2}, t)
+	ctx := NewContext()
+	assert.Nil(t, net.SynChangeSnapshot("NoSuchVar"))
+
+	first := net.SynChangeSnapshot("Wt")
+	assert.Equal(t, 3, len(first))
+	before := make(map[string][]float32)
+	for _, pj := range net.Prjns {
+		for _, dw := range first[pj.Name()] {
+			assert.Equal(t, float32(0), dw)
+		}
+		var wts []float32
+		assert.NoError(t, pj.SynVals(&wts, "Wt"))
+		before[pj.Name()] = wts
+	}
+
+	for si := range net.Synapses {
+		net.Synapses[si].DWt = 0.1
+	}
+	net.WtFmDWt(ctx)
+
+	chg := net.SynChangeSnapshot("Wt")
+	nonZero := 0
+	for _, pj := range net.Prjns {
+		pc := chg[pj.Name()]
+		assert.Equal(t, len(pj.Syns), len(pc))
+		for si := range pj.Syns {
+			assert.Equal(t, pj.Syns[si].Wt-before[pj.Name()][si], pc[si])
+			if pc[si] != 0 {
+				nonZero++
+			}
+		}
+	}
+	assert.Greater(t, nonZero, 0)
+
+	// no change since last snapshot
+	chg = net.SynChangeSnapshot("Wt")
+	for _, pc := range chg {
+		for _, dw := range pc {
+			assert.Equal(t, float32(0), dw)
+		}
+	}
+}
+
+// newRA25Net builds a network with the structure of the ra25 example.
+func newRA25Net(t *testing.T) *Network {
+	net := NewNetwork("RA25")
+	inp := net.AddLayer2D("Input", 5, 5, InputLayer)
+	hid1 := net.AddLayer2D("Hidden1", 10, 10, SuperLayer)
+	hid2 := net.AddLayer2D("Hidden2", 10, 10, SuperLayer)
+	out := net.AddLayer2D("Output", 5, 5, TargetLayer)
+	full := prjn.NewFull()
+	net.ConnectLayers(inp, hid1, full, ForwardPrjn)
+	net.BidirConnectLayers(hid1, hid2, full)
+	net.BidirConnectLayers(hid2, out, full)
+	assert.NoError(t, net.Build())
+	net.Defaults()
+	for _, pj := range net.Prjns {
+		if pj.Typ == BackPrjn {
+			pj.Params.PrjnScale.Rel = 0.2
+		}
+	}
+	net.InitWts()
+	return net
+}
+
+func TestAutoScalePrjns(t *testing.T) {
+	net := newRA25Net(t)
+	ctx := NewContext()
+	pat := etensor.NewFloat32([]int{5, 5}, nil, nil)
+	for i := 0; i < 25; i += 4 { // 7 active units, like ra25
+		pat.Values[i] = 1
+	}
+	net.InitExt()
+	net.AxonLayerByName("Input").ApplyExt(pat)
+	net.ApplyExts(ctx)
+
+	nCycles := 150
+	trgGe := float32(0.3)
+	sheet := net.AutoScalePrjns(ctx, trgGe, nCycles)
+	assert.Equal(t, len(net.Prjns), len(*sheet))
+
+	// measure the average Ge over the second half of a fresh pass
+	net.DecayState(ctx, 1, 1)
+	ges := make([]float32, len(net.Layers))
+	nmeas := 0
+	for cyc := 0; cyc < nCycles; cyc++ {
+		net.Cycle(ctx)
+		ctx.CycleInc()
+		if cyc < nCycles/2 {
+			continue
+		}
+		for li, ly := range net.Layers {
+			for ni := range ly.Neurons {
+				ges[li] += ly.Neurons[ni].Ge
+			}
+		}
+		nmeas++
+	}
+	for li, ly := range net.Layers {
+		if ly.Params.IsInput() {
+			continue
+		}
+		ge := ges[li] / float32(nmeas*len(ly.Neurons))
+		assert.InDelta(t, trgGe, ge, float64(0.25*trgGe), ly.Name())
+	}
+
+	// the sheet reproduces the calibrated values in a fresh network
+	net2 := newRA25Net(t)
+	_, err := net2.ApplyParams(sheet, false)
+	assert.NoError(t, err)
+	for pi, pj := range net.Prjns {
+		assert.InDelta(t, pj.Params.PrjnScale.Abs, net2.Prjns[pi].Params.PrjnScale.Abs, 1.0e-4)
+	}
+}
+
+func TestStateHash(t *testing.T) {
+	netA := createNetwork([]int{2, 2}, t)
+	netB := createNetwork([]int{2, 2}, t)
+	for _, net := range []*Network{netA, netB} {
+		net.SetRndSeed(1)
+		net.InitWts()
+	}
+	golden := netA.StateHash()
+	assert.Equal(t, golden, netB.StateHash())
+	assert.Equal(t, "", GoldenCheck(netB, golden))
+
+	// activation dynamics change neurons, but not the weights
+	ctx := NewContext()
+	inPat := etensor.NewFloat32([]int{2, 2}, nil, nil)
+	inPat.Values[0] = 1
+	netB.AxonLayerByName("Input").ApplyExt(inPat)
+	netB.ApplyExts(ctx)
+	for cyc := 0; cyc < 20; cyc++ {
+		netB.Cycle(ctx)
+		ctx.CycleInc()
+	}
+	assert.Equal(t, netA.WtsHash(), netB.WtsHash())
+	diff := GoldenCheck(netB, golden)
+	assert.Contains(t, diff, "Neurons:")
+	assert.NotContains(t, diff, "Wts:")
+}
+
+func TestParamScheds(t *testing.T) {
+	net := createNetwork([]int{2, 2}, t)
+	hid := net.AxonLayerByName("Hidden")
+	origGi := hid.Params.Inhib.Layer.Gi
+	net.AddParamSched(2, &params.Sheet{
+		{Sel: "#Hidden", Desc: "lower inhib",
+			Params: params.Params{
+				"Layer.Inhib.Layer.Gi": "0.8",
+			}},
+	})
+	for epc := 0; epc < 4; epc++ {
+		app, err := net.ApplyParamScheds(epc)
+		assert.NoError(t, err)
+		assert.Equal(t, epc == 2, app)
+		if epc < 2 {
+			assert.Equal(t, origGi, hid.Params.Inhib.Layer.Gi)
+		} else {
+			assert.Equal(t, float32(0.8), hid.Params.Inhib.Layer.Gi)
+		}
+	}
+	assert.Equal(t, origGi, net.AxonLayerByName("Output").Params.Inhib.Layer.Gi)
+}
+
+func TestActsRing(t *testing.T) {
+	net := createNetwork([]int{2, 2}, t)
+	ctx := NewContext()
+	assert.Error(t, net.RecordActsRing(5, "NoSuchVar"))
+	assert.NoError(t, net.RecordActsRing(5, "Act", "Vm"))
+	assert.Nil(t, net.ActsRingTensor("Ge"))
+
+	inPat := etensor.NewFloat32([]int{2, 2}, nil, nil)
+	inPat.Values[0] = 1
+	net.AxonLayerByName("Input").ApplyExt(inPat)
+	net.ApplyExts(ctx)
+	nn := len(net.Neurons)
+	vms := make([][]float32, 0)
+	for cyc := 0; cyc < 12; cyc++ {
+		net.Cycle(ctx)
+		ctx.CycleInc()
+		vm := make([]float32, nn)
+		for ni := range net.Neurons {
+			vm[ni] = net.Neurons[ni].Vm
+		}
+		vms = append(vms, vm)
+		if cyc == 2 {
+			tsr := net.ActsRingTensor("Vm")
+			assert.Equal(t, []int{3, nn}, tsr.Shapes())
+		}
+	}
+	// only the last 5 cycles remain, oldest first
+	tsr := net.ActsRingTensor("Vm")
+	assert.Equal(t, []int{5, nn}, tsr.Shapes())
+	for r := 0; r < 5; r++ {
+		for ni := 0; ni < nn; ni++ {
+			assert.Equal(t, vms[7+r][ni], tsr.Value([]int{r, ni}))
+		}
+	}
+	assert.Equal(t, 5, net.ActsRingTensor("Act").Dim(0))
+}
+
+func TestCheckDtConsistency(t *testing.T) {
+	net := createNetwork([]int{2, 2}, t)
+	ctx := NewContext()
+	assert.NoError(t, net.CheckDtConsistency(ctx))
+
+	hid := net.AxonLayerByName("Hidden")
+	hid.Params.Act.Dt.Integ = 2
+	hid.Params.Act.Dt.Update()
+	err := net.CheckDtConsistency(ctx)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Hidden")
+	assert.NotContains(t, err.Error(), "Output")
+
+	// 2 msec per cycle: now only the Hidden layer is consistent
+	ctx.TimePerCycle = 0.002
+	err = net.CheckDtConsistency(ctx)
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "Hidden")
+	assert.Contains(t, err.Error(), "Output")
+}
+
+func TestClone(t *testing.T) {
+	net := newRA25Net(t)
+	net.SetRndSeed(3)
+	net.InitWts()
+	ctx := NewContext()
+	pat := etensor.NewFloat32([]int{5, 5}, nil, nil)
+	for i := 0; i < 25; i += 4 {
+		pat.Values[i] = 1
+	}
+	runTrial := func(nt *Network, ctx *Context) {
+		nt.InitExt()
+		nt.AxonLayerByName("Input").ApplyExt(pat)
+		nt.ApplyExts(ctx)
+		nt.NewState(ctx)
+		ctx.NewState(etime.Train)
+		for cyc := 0; cyc < 200; cyc++ {
+			nt.Cycle(ctx)
+			ctx.CycleInc()
+			if cyc == 149 {
+				nt.MinusPhase(ctx)
+				ctx.NewPhase(true)
+				nt.PlusPhaseStart(ctx)
+			}
+		}
+		nt.PlusPhase(ctx)
+		nt.DWt(ctx)
+		nt.WtFmDWt(ctx)
+	}
+	runTrial(net, ctx)
+
+	cn := net.Clone()
+	require.NotNil(t, cn)
+	assert.Equal(t, net.StateHash(), cn.StateHash())
+	assert.Equal(t, len(net.Prjns), len(cn.Prjns))
+	for li, ly := range net.Layers {
+		cl := cn.Layers[li]
+		assert.Equal(t, ly.Name(), cl.Name())
+		assert.NotSame(t, ly, cl)
+		assert.Same(t, cn, cl.Network)
+		assert.Equal(t, *ly.Params, *cl.Params)
+	}
+	assert.NotNil(t, cn.Rand.Rand)
+	assert.NotSame(t, net.Rand.Rand, cn.Rand.Rand)
+
+	// identical dynamics from the same starting state
+	cctx := *ctx
+	origHash := net.StateHash()
+	runTrial(cn, &cctx)
+	assert.Equal(t, origHash, net.StateHash())
+	runTrial(net, ctx)
+	assert.Equal(t, net.StateHash(), cn.StateHash())
+
+	// mutating the clone does not affect the original
+	origHash = net.StateHash()
+	cn.Synapses[0].Wt += 0.1
+	cn.Neurons[0].Act = 1
+	cn.AxonLayerByName("Hidden1").Params.Inhib.Layer.Gi = 2
+	assert.Equal(t, origHash, net.StateHash())
+	assert.NotEqual(t, origHash, cn.StateHash())
+	assert.NotEqual(t, float32(2), net.AxonLayerByName("Hidden1").Params.Inhib.Layer.Gi)
+}
+
+// cloneFieldsEqual checks that every field of the orig struct, other than
+// those in skip, is set in orig and has the same value in clone, so that
+// any field that Clone fails to copy is caught.  Fields in zeroOK may be
+// zero in orig.  Functions are compared by pointer.
+func cloneFieldsEqual(t *testing.T, orig, clone reflect.Value, skip, zeroOK map[string]bool) {
+	typ := orig.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		nm := typ.Field(i).Name
+		if skip[nm] {
+			continue
+		}
+		of := orig.Field(i)
+		cf := clone.Field(i)
+		if !zeroOK[nm] {
+			assert.False(t, of.IsZero(), "%s.%s not configured in test", typ.Name(), nm)
+		}
+		if of.Kind() == reflect.Func {
+			assert.Equal(t, of.Pointer(), cf.Pointer(), "%s.%s not cloned", typ.Name(), nm)
+			continue
+		}
+		assert.True(t, reflect.DeepEqual(of.Interface(), cf.Interface()), "%s.%s not cloned", typ.Name(), nm)
+	}
+}
+
+func TestCloneConfig(t *testing.T) {
+	net := newRA25Net(t)
+	hid := net.AxonLayerByName("Hidden1")
+	nn := len(hid.Neurons)
+	perm := make([]int, nn)
+	for i := range perm {
+		perm[i] = nn - 1 - i
+	}
+	assert.NoError(t, hid.SetUnitPermutation(perm))
+	hid.FreezeActs()
+	hid.ClampToFrozen(true)
+	hid.SetSpkMaxResets(50, 100)
+	hid.SetRLRateFunc(func(ni uint32, nrn *Neuron) float32 { return 1 })
+	hid.SetActOutFunc(func(act float32) float32 { return act })
+	hid.SetTrgAvgFunc(func(ni uint32) float32 { return 1 })
+	hid.SetSpikeFunc(func(nrn *Neuron, ac *ActParams) {})
+	hid.SetGapJunctions(prjn.NewRect(), 0.1)
+	hid.ExtSparseIdxs = []int{1}
+	hid.SetRecordGSyn(true)
+	hid.Cost.Spikes = 10
+	hid.EIBal.N = 2
+	pj := hid.RcvPrjns[0]
+	assert.NoError(t, pj.SetScaleSchedule(map[int]float32{100: 0.5}))
+	pj.TrackWtAge(true)
+	pj.InitSeed = 7
+	pj.SelfMaint = true
+	pj.SelfMaintAbs = 2
+	pj.GSynSum = 1
+	pj.GSynN = 2
+	pj.GSynTrl = 0.5
+
+	cn := net.Clone()
+	chid := cn.AxonLayerByName("Hidden1")
+	cpj := chid.RcvPrjns[0]
+
+	// structure, state and per-run accumulators are checked in TestClone
+	laySkip := map[string]bool{"AxonLay": true, "Network": true, "RcvPrjns": true, "SndPrjns": true,
+		"RepShp": true, "Neurons": true, "Pools": true, "Exts": true, "SpkHist": true, "RateAcc": true, "ActTrc": true,
+		"ParamsHistory": true}
+	layZero := map[string]bool{"Cls": true, "Off": true, "Typ": true, "Rel": true, "Ps": true,
+		"RepIxs": true, "BuildConfig": true}
+	cloneFieldsEqual(t, reflect.ValueOf(hid.LayerBase), reflect.ValueOf(chid.LayerBase), laySkip, layZero)
+
+	prjSkip := map[string]bool{"AxonPrj": true, "Send": true, "Recv": true, "Pat": true,
+		"ParamsHistory": true, "RecvCon": true, "Syns": true, "RecvConIdx": true, "SendCon": true,
+		"SendSynIdx": true, "SendConIdx": true, "GBuf": true, "GSyns": true}
+	prjZero := map[string]bool{"Off": true, "Cls": true, "Notes": true, "Typ": true}
+	cloneFieldsEqual(t, reflect.ValueOf(pj.PrjnBase), reflect.ValueOf(cpj.PrjnBase), prjSkip, prjZero)
+
+	// slices are not shared with the original
+	chid.UnitPerm[0] = 0
+	cpj.ScaleSchedGains[0] = 1
+	assert.Equal(t, nn-1, hid.UnitPerm[0])
+	assert.Equal(t, float32(0.5), pj.ScaleSchedGains[0])
+}
+
+// newRemapNet returns a network with given hidden layer name and size,
+// with weights that vary linearly along the hidden layer Y axis.
+func newRemapNet(t *testing.T, hidNm string, hidSz int) *Network {
+	net := NewNetwork("Remap")
+	inp := net.AddLayer2D("Input", 4, 4, InputLayer)
+	hid := net.AddLayer2D(hidNm, hidSz, hidSz, SuperLayer)
+	out := net.AddLayer2D("Output", 4, 4, TargetLayer)
+	full := prjn.NewFull()
+	net.ConnectLayers(inp, hid, full, ForwardPrjn)
+	net.ConnectLayers(hid, out, full, ForwardPrjn)
+	assert.NoError(t, net.Build())
+	net.Defaults()
+	net.InitWts()
+	return net
+}
+
+func TestSetWtsRemap(t *testing.T) {
+	// expected weight as a function of position along a 10 unit dimension
+	linWt := func(pos float32) float32 { return 0.2 + 0.6*pos/9 }
+	netA := newRemapNet(t, "Hidden", 10)
+	hidA := netA.AxonLayerByName("Hidden")
+	hidA.RcvPrjns[0].SetWtsFunc(func(si, ri int, send, recv *etensor.Shape) float32 {
+		return linWt(float32(ri / 10))
+	})
+	hidA.SndPrjns[0].SetWtsFunc(func(si, ri int, send, recv *etensor.Shape) float32 {
+		return linWt(float32(si / 10))
+	})
+	var b bytes.Buffer
+	assert.NoError(t, netA.WriteWtsJSON(&b))
+	nw, err := weights.NetReadJSON(&b)
+	assert.NoError(t, err)
+
+	// interpolated position in the original layer for a 12 unit dimension
+	interpPos := func(i int) float32 {
+		return mat32.Clamp((float32(i)+0.5)*10/12-0.5, 0, 9)
+	}
+	netB := newRemapNet(t, "HiddenB", 12)
+	hidB := netB.AxonLayerByName("HiddenB")
+	assert.NoError(t, netB.SetWtsRemap(nw, map[string]string{"Hidden": "HiddenB"}, "interp"))
+	inPj := hidB.RcvPrjns[0]
+	for ri := range hidB.Neurons {
+		for _, sy := range inPj.RecvSyns(ri) {
+			assert.InDelta(t, linWt(interpPos(ri/12)), sy.Wt, 5.0e-4)
+			assert.InDelta(t, sy.Wt, sy.SWt, 5.0e-4)
+		}
+	}
+	outPj := hidB.SndPrjns[0]
+	for ri := range outPj.Recv.Neurons {
+		syns := outPj.RecvSyns(ri)
+		for ci := range syns {
+			sy := &syns[ci]
+			si := int(outPj.Params.SynSendLayIdx(sy))
+			assert.InDelta(t, linWt(interpPos(si/12)), sy.Wt, 5.0e-4)
+		}
+	}
+
+	// tile wraps around to the start of the original layer
+	assert.NoError(t, netB.SetWtsRemap(nw, map[string]string{"Hidden": "HiddenB"}, "tile"))
+	for ri := range hidB.Neurons {
+		for _, sy := range inPj.RecvSyns(ri) {
+			assert.InDelta(t, linWt(float32((ri/12)%10)), sy.Wt, 5.0e-4)
+		}
+	}
+
+	// skip leaves the resized layer alone
+	netC := newRemapNet(t, "HiddenB", 12)
+	hidC := netC.AxonLayerByName("HiddenB")
+	origWt := hidC.RcvPrjns[0].Syns[0].Wt
+	assert.NoError(t, netC.SetWtsRemap(nw, map[string]string{"Hidden": "HiddenB"}, "skip"))
+	assert.Equal(t, origWt, hidC.RcvPrjns[0].Syns[0].Wt)
+
+	assert.Error(t, netC.SetWtsRemap(nw, nil, "bogus"))
+}
+
+// newCopyWtsNet returns a network with a Super and CT layer, where
+// the CTCtxtPrjn from Super to CT has given pattern.
+func newCopyWtsNet(t *testing.T, seed int64, ctxtPat prjn.Pattern) *Network {
+	net := NewNetwork("CopyWts")
+	inp := net.AddLayer2D("Input", 4, 4, InputLayer)
+	hid, ct := net.AddSuperCT2D("Hidden", 4, 4, 2, ctxtPat)
+	full := prjn.NewFull()
+	net.ConnectLayers(inp, hid, full, ForwardPrjn)
+	net.ConnectLayers(inp, ct, full, ForwardPrjn)
+	assert.NoError(t, net.Build())
+	net.Defaults()
+	net.SetRndSeed(seed)
+	net.InitWts()
+	return net
+}
+
+func TestCopyWtsFmByClass(t *testing.T) {
+	src := newCopyWtsNet(t, 1, prjn.NewFull())
+	net := newCopyWtsNet(t, 2, prjn.NewFull())
+	wtsOf := func(pj *Prjn) []float32 {
+		wts := make([]float32, len(pj.Syns))
+		for si := range pj.Syns {
+			wts[si] = pj.Syns[si].Wt
+		}
+		return wts
+	}
+	ct := net.AxonLayerByName("HiddenCT")
+	ctxtPj := ct.RcvPrjns[0]
+	assert.Equal(t, CTCtxtPrjn, ctxtPj.PrjnType())
+	srcCtxtPj := src.AxonLayerByName("HiddenCT").RcvPrjns[0]
+	assert.NotEqual(t, wtsOf(srcCtxtPj), wtsOf(ctxtPj))
+	others := make(map[string][]float32)
+	for _, pj := range net.Prjns {
+		if pj != ctxtPj {
+			others[pj.Name()] = wtsOf(pj)
+		}
+	}
+
+	assert.NoError(t, net.CopyWtsFmByClass(src, "CTCtxtPrjn"))
+	assert.Equal(t, wtsOf(srcCtxtPj), wtsOf(ctxtPj))
+	for si := range ctxtPj.Syns {
+		assert.Equal(t, srcCtxtPj.Syns[si].SWt, ctxtPj.Syns[si].SWt)
+		assert.Equal(t, srcCtxtPj.Syns[si].LWt, ctxtPj.Syns[si].LWt)
+	}
+	for _, pj := range net.Prjns {
+		if pj != ctxtPj {
+			assert.Equal(t, others[pj.Name()], wtsOf(pj))
+		}
+	}
+
+	assert.Error(t, net.CopyWtsFmByClass(src, "NoSuchClass"))
+
+	// structural mismatch: nothing is copied
+	oneSrc := newCopyWtsNet(t, 3, prjn.NewOneToOne())
+	before := wtsOf(ctxtPj)
+	assert.Error(t, net.CopyWtsFmByClass(oneSrc, "CTCtxtPrjn"))
+	assert.Equal(t, before, wtsOf(ctxtPj))
+}
+
+func TestWtStatsTable(t *testing.T) {
+	net := newRA25Net(t)
+	dt := net.WtStatsTable()
+	assert.Equal(t, len(net.Prjns), dt.Rows)
+	row := 0
+	for _, ly := range net.Layers {
+		for _, pj := range ly.RcvPrjns {
+			assert.Equal(t, pj.Send.Name(), dt.CellString("Send", row))
+			assert.Equal(t, ly.Name(), dt.CellString("Recv", row))
+			assert.Equal(t, float64(len(pj.Syns)), dt.CellFloat("NSyns", row))
+			assert.Equal(t, float64(pj.Params.GScale.Scale), dt.CellFloat("GScale", row))
+			wsum := float64(0)
+			for si := range pj.Syns {
+				wsum += float64(pj.Syns[si].Wt)
+			}
+			assert.InDelta(t, wsum/float64(len(pj.Syns)), dt.CellFloat("WtMean", row), 1.0e-6)
+			assert.Greater(t, dt.CellFloat("WtVar", row), 0.0)
+			assert.GreaterOrEqual(t, dt.CellFloat("FracSat", row), 0.0)
+			assert.Less(t, dt.CellFloat("FracSat", row), 0.5)
+			row++
+		}
+	}
+
+	net.AxonLayerByName("Hidden2").SetOff(true)
+	assert.Equal(t, len(net.Prjns)-4, net.WtStatsTable().Rows)
+}
+
+func TestEffectiveInputTable(t *testing.T) {
+	net := newRA25Net(t)
+	ctx := NewContext()
+	hid2 := net.AxonLayerByName("Hidden2")
+	hid2.SetRecordGSyn(true)
+	pat := etensor.NewFloat32([]int{5, 5}, nil, nil)
+	for i := 0; i < 25; i += 4 {
+		pat.Values[i] = 1
+	}
+	net.InitExt()
+	net.AxonLayerByName("Input").ApplyExt(pat)
+	net.AxonLayerByName("Output").ApplyExt(pat)
+	net.ApplyExts(ctx)
+	net.NewState(ctx)
+	ctx.NewState(etime.Train)
+	for cyc := 0; cyc < 200; cyc++ {
+		net.Cycle(ctx)
+		ctx.CycleInc()
+		if cyc == 149 {
+			net.MinusPhase(ctx)
+			ctx.NewPhase(true)
+			net.PlusPhaseStart(ctx)
+		}
+	}
+	net.PlusPhase(ctx)
+
+	// not recorded unless enabled
+	for _, pj := range net.AxonLayerByName("Hidden1").RcvPrjns {
+		assert.Equal(t, 0, pj.GSynN)
+		assert.Equal(t, float32(0), pj.GSynTrl)
+	}
+
+	dt := net.EffectiveInputTable("Hidden2")
+	assert.Equal(t, len(hid2.RcvPrjns), dt.Rows)
+	pct := 0.0
+	for row, pj := range hid2.RcvPrjns {
+		assert.Equal(t, pj.Send.Name(), dt.CellString("Send", row))
+		assert.Equal(t, pj.PrjnType().String(), dt.CellString("Type", row))
+		assert.Equal(t, float64(pj.Params.GScale.Scale), dt.CellFloat("GScale", row))
+		assert.Equal(t, float64(pj.GSynTrl), dt.CellFloat("GSyn", row))
+		assert.Greater(t, dt.CellFloat("GSyn", row), 0.0)
+		pct += dt.CellFloat("Pct", row)
+	}
+	assert.InDelta(t, 100, pct, 1.0e-4)
+
+	// GSynTrl is retained until the end of the next trial
+	net.NewState(ctx)
+	assert.Equal(t, 0, hid2.RcvPrjns[0].GSynN)
+	assert.Equal(t, float64(hid2.RcvPrjns[0].GSynTrl), net.EffectiveInputTable("Hidden2").CellFloat("GSyn", 0))
+
+	assert.Nil(t, net.EffectiveInputTable("NoLayer"))
+}
+
+func TestRandState(t *testing.T) {
+	newNet := func() *Network {
+		nt := newRA25Net(t)
+		for _, ly := range nt.Layers {
+			ly.Params.Act.Init.GeBase = 0.2
+			ly.Params.Act.Init.GeVar = 0.05
+			ly.Params.Act.Noise.On.SetBool(true)
+			ly.Params.Act.Noise.Ge = 0.01
+			ly.Params.Act.Noise.Gi = 0.01
+			ly.Params.Act.Noise.Update()
+		}
+		nt.SetRndSeed(5)
+		nt.InitWts()
+		return nt
+	}
+	net := newNet()
+	ctx := NewContext()
+	pat := etensor.NewFloat32([]int{5, 5}, nil, nil)
+	for i := 0; i < 25; i += 4 {
+		pat.Values[i] = 1
+	}
+	// runs a trial starting from random initial activations
+	runTrial := func(nt *Network, ctx *Context) {
+		nt.InitActs()
+		nt.InitExt()
+		nt.AxonLayerByName("Input").ApplyExt(pat)
+		nt.ApplyExts(ctx)
+		nt.NewState(ctx)
+		ctx.NewState(etime.Train)
+		for cyc := 0; cyc < 50; cyc++ {
+			nt.Cycle(ctx)
+			ctx.CycleInc()
+		}
+	}
+	// a separately-built network, with its generator moved elsewhere,
+	// resumes the same sequence from the checkpoint
+	rn := newNet()
+	rctx := NewContext()
+	runTrial(net, ctx)
+	runTrial(rn, rctx)
+	state := net.RandState(ctx)
+	require.NotNil(t, state)
+	rn.SetRndSeed(10)
+	rn.Rand.Float64(-1)
+	rctx.RandCtr.Add(100)
+	assert.NotEqual(t, state, rn.RandState(rctx))
+
+	runTrial(net, ctx)
+	hash := net.StateHash()
+	rnd := net.Rand.Float64(-1)
+
+	assert.NoError(t, rn.SetRandState(rctx, state))
+	assert.Equal(t, state, rn.RandState(rctx))
+	assert.Equal(t, int64(5), rn.RndSeed)
+	runTrial(rn, rctx)
+	assert.Equal(t, hash, rn.StateHash())
+	assert.Equal(t, rnd, rn.Rand.Float64(-1))
+
+	// restoring the original also repeats its sequence
+	hid := net.AxonLayerByName("Hidden1")
+	gebs := make([]float32, len(hid.Neurons))
+	assert.NoError(t, net.SetRandState(ctx, state))
+	net.InitActs()
+	for ni := range hid.Neurons {
+		gebs[ni] = hid.Neurons[ni].GeBase
+	}
+	net.InitActs()
+	assert.NotEqual(t, gebs[0], hid.Neurons[0].GeBase)
+	assert.NoError(t, net.SetRandState(ctx, state))
+	net.InitActs()
+	for ni := range hid.Neurons {
+		assert.Equal(t, gebs[ni], hid.Neurons[ni].GeBase)
+	}
+
+	assert.Error(t, net.SetRandState(ctx, []byte{1, 2}))
+}
+
+func TestSetVmSteps(t *testing.T) {
+	net := NewNetwork("VmStepsTest")
+	inLay := net.AddLayer2D("Input", 4, 4, InputLayer)
+	hidLay := net.AddLayer2D("Hidden", 4, 4, SuperLayer)
+	fastLay := net.AddLayer2D("Fast", 4, 4, SuperLayer)
+	fastLay.SetClass("FastDyn")
+	net.ConnectLayers(inLay, hidLay, prjn.NewFull(), ForwardPrjn)
+	net.ConnectLayers(hidLay, fastLay, prjn.NewFull(), ForwardPrjn)
+	assert.NoError(t, net.Build())
+	net.Defaults()
+
+	assert.NoError(t, net.SetVmSteps("FastDyn", 8))
+	assert.Equal(t, int32(8), fastLay.Params.Act.Dt.VmSteps)
+	assert.Equal(t, float32(1)/8, fastLay.Params.Act.Dt.DtStep)
+	assert.Equal(t, int32(2), hidLay.Params.Act.Dt.VmSteps)
+
+	assert.NoError(t, net.SetVmSteps("InputLayer", 1))
+	assert.Equal(t, int32(1), inLay.Params.Act.Dt.VmSteps)
+	assert.Equal(t, int32(8), fastLay.Params.Act.Dt.VmSteps)
+
+	assert.Error(t, net.SetVmSteps("NoSuchClass", 4))
+	assert.Error(t, net.SetVmSteps("FastDyn", 0))
+	assert.Equal(t, int32(8), fastLay.Params.Act.Dt.VmSteps)
+
+	rep := net.VmStepsReport()
+	lines := strings.Split(strings.TrimSpace(rep), "\n")
+	assert.Equal(t, 5, len(lines)) // header, 3 layers, total
+	assert.Contains(t, lines[1], "Input")
+	assert.Contains(t, lines[1], "warning")
+	assert.NotContains(t, lines[2], "warning")
+	assert.Contains(t, lines[3], "\t 8\t 128\t  72.7")
+	assert.Contains(t, lines[4], "176")
+}
+
+func TestCheckNumerics(t *testing.T) {
+	net := NewNetwork("NumericsTest")
+	inLay := net.AddLayer2D("Input", 4, 4, InputLayer)
+	hidLay := net.AddLayer2D("Hidden", 4, 4, SuperLayer)
+	pj := net.ConnectLayers(inLay, hidLay, prjn.NewFull(), ForwardPrjn)
+	assert.NoError(t, net.Build())
+	net.Defaults()
+	net.InitWts()
+	net.NumericsGuard = true
+
+	inPat := etensor.NewFloat32([]int{4, 4}, nil, nil)
+	for i := 0; i < 16; i += 3 {
+		inPat.Values[i] = 1
+	}
+	ctx := NewContext()
+	net.InitExt()
+	inLay.ApplyExt(inPat)
+	net.ApplyExts(ctx)
+	net.NewState(ctx)
+	ctx.NewState(etime.Train)
+	cycles := func(n int) {
+		for cyc := 0; cyc < n; cyc++ {
+			net.Cycle(ctx)
+			ctx.CycleInc()
+		}
+	}
+	cycles(20)
+	assert.Nil(t, net.CheckNumerics())
+	assert.Nil(t, net.NumericsErrs)
+
+	pj.Syns[5].Wt = mat32.Inf(1)
+	hidLay.Neurons[3].Vm = mat32.NaN()
+	bad := net.CheckNumerics()
+	assert.Equal(t, 2, len(bad))
+	assert.Equal(t, "Hidden[3].Vm: NaN", bad[0])
+	ri := pj.Params.SynRecvLayIdx(&pj.Syns[5])
+	si := pj.Params.SynSendLayIdx(&pj.Syns[5])
+	assert.Equal(t, fmt.Sprintf("Hidden<-Input[%d,%d].Wt: +Inf", ri, si), bad[1])
+	pj.Syns[5].Wt = 0.5
+
+	// guard stops updating on the first cycle with corrupted neurons
+	cycles(1)
+	assert.Greater(t, len(net.NumericsErrs), 0)
+	assert.Contains(t, net.NumericsErrs, "Hidden[3].Vm: NaN")
+	vm := inLay.Neurons[0].Vm
+	cycles(5)
+	assert.Equal(t, vm, inLay.Neurons[0].Vm)
+
+	net.InitWts()
+	assert.Nil(t, net.NumericsErrs)
+	assert.Nil(t, net.CheckNumerics())
+}
+
+func TestDescribe(t *testing.T) {
+	net := NewNetwork("DescTest")
+	inLay := net.AddLayer2D("Input", 4, 4, InputLayer)
+	hidLay := net.AddLayer4D("Hidden", 2, 2, 3, 3, SuperLayer)
+	outLay := net.AddLayer2D("Output", 4, 4, TargetLayer)
+	hidLay.SetClass("Hid")
+	net.ConnectLayers(inLay, hidLay, prjn.NewFull(), ForwardPrjn).SetClass("InToHid")
+	net.BidirConnectLayers(hidLay, outLay, prjn.NewOneToOne())
+	assert.NoError(t, net.Build())
+
+	b := net.Describe()
+	nd := &NetDesc{}
+	assert.NoError(t, json.Unmarshal(b, nd))
+	assert.Equal(t, net.NetDesc(), nd)
+	assert.Equal(t, "DescTest", nd.Name)
+	assert.Equal(t, 3, len(nd.Layers))
+	assert.Equal(t, LayerDesc{Name: "Hidden", Type: "SuperLayer", Shape: []int{2, 2, 3, 3}, Class: "Hid"}, nd.Layers[1])
+	assert.Equal(t, "TargetLayer", nd.Layers[2].Type)
+	assert.Equal(t, 3, len(nd.Prjns))
+	assert.Contains(t, nd.Prjns, PrjnDesc{Send: "Input", Recv: "Hidden", Type: "ForwardPrjn", Pattern: "Full", Class: "InToHid"})
+	assert.Contains(t, nd.Prjns, PrjnDesc{Send: "Output", Recv: "Hidden", Type: "BackPrjn", Pattern: "OneToOne"})
+	assert.Contains(t, nd.Prjns, PrjnDesc{Send: "Hidden", Recv: "Output", Type: "ForwardPrjn", Pattern: "OneToOne"})
+}
+
+func TestRunSummary(t *testing.T) {
+	net := newRA25Net(t)
+	inLay := net.AxonLayerByName("Input")
+	outLay := net.AxonLayerByName("Output")
+	pats := make([]*etensor.Float32, 4)
+	for pi := range pats {
+		pats[pi] = etensor.NewFloat32([]int{5, 5}, nil, nil)
+		for i := pi; i < 25; i += 4 {
+			pats[pi].Values[i] = 1
+		}
+	}
+
+	var epoch int
+	var pctErr float64
+	lg := &elog.Logs{}
+	addItem := func(name string, plot bool, val *float64) {
+		lg.AddItem(&elog.Item{
+			Name: name,
+			Type: etensor.FLOAT64,
+			Plot: plot,
+			Write: elog.WriteMap{
+				etime.Scope(etime.Train, etime.Epoch): func(ctx *elog.Context) {
+					if val == nil {
+						ctx.SetInt(epoch)
+					} else {
+						ctx.SetFloat64(*val)
+					}
+				}}})
+	}
+	pctCor := 0.0
+	addItem("Epoch", false, nil)
+	addItem("PctErr", true, &pctErr)
+	addItem("PctCor", true, &pctCor)
+	assert.NoError(t, lg.CreateTables())
+
+	ctx := NewContext()
+	var errs []float64
+	var outs []float32
+	for epoch = 0; epoch < 3; epoch++ {
+		nerr := 0
+		for _, pat := range pats {
+			net.InitExt()
+			inLay.ApplyExt(pat)
+			outLay.ApplyExt(pat)
+			net.ApplyExts(ctx)
+			net.NewState(ctx)
+			ctx.NewState(etime.Train)
+			for cyc := 0; cyc < 200; cyc++ {
+				net.Cycle(ctx)
+				ctx.CycleInc()
+				if cyc == 149 {
+					net.MinusPhase(ctx)
+					ctx.NewPhase(true)
+					net.PlusPhaseStart(ctx)
+				}
+			}
+			net.PlusPhase(ctx)
+			net.DWt(ctx)
+			net.WtFmDWt(ctx)
+			outLay.UnitVals(&outs, "ActM")
+			for i, v := range outs {
+				if (v > 0.5) != (pat.Values[i] > 0.5) {
+					nerr++
+					break
+				}
+			}
+		}
+		pctErr = float64(nerr) / float64(len(pats))
+		pctCor = 1 - pctErr
+		errs = append(errs, pctErr)
+		lg.Log(etime.Train, etime.Epoch)
+	}
+
+	sum := net.RunSummary(lg)
+	assert.Equal(t, 3.0, sum["Epochs"])
+	assert.Equal(t, 12.0, sum["WtUpdates"])
+	assert.Equal(t, 12.0*float64(len(net.Synapses)), sum["SynUpdates"])
+	assert.Equal(t, errs[2], sum["PctErr:Final"])
+	assert.Equal(t, 1-errs[2], sum["PctCor:Final"])
+	best, bestEpc, firstZero := errs[0], 0.0, -1.0
+	for i, e := range errs {
+		if e < best {
+			best, bestEpc = e, float64(i)
+		}
+		if e == 0 && firstZero < 0 {
+			firstZero = float64(i)
+		}
+	}
+	assert.Equal(t, best, sum["PctErr:Best"])
+	assert.Equal(t, bestEpc, sum["PctErr:BestEpoch"])
+	assert.Equal(t, firstZero, sum["PctErr:FirstZero"])
+	assert.Equal(t, 1-best, sum["PctCor:Best"])
+	assert.Equal(t, bestEpc, sum["PctCor:BestEpoch"])
+	_, has := sum["PctCor:FirstZero"]
+	assert.False(t, has)
+	_, has = sum["Epoch:Final"]
+	assert.False(t, has)
+
+	net.InitWts()
+	sum = net.RunSummary(&elog.Logs{})
+	assert.Equal(t, 0.0, sum["WtUpdates"])
+	_, has = sum["Epochs"]
+	assert.False(t, has)
+}
+
+func TestMigrateWts(t *testing.T) {
+	// synthetic version 1 weights: no version, and no Wt1 (SWt) values
+	v1 := `{
+	"Network": "LayerTest",
+	"MetaData": {"Epoch": "10"},
+	"Layers": [
+		{
+			"Layer": "Hidden",
+			"MetaData": {"ActMAvg": "0.2", "ActPAvg": "0.25", "GiMult": "1"},
+			"Prjns": [
+				{
+					"From": "Input",
+					"Rs": [
+						{"Ri": 0, "N": 4, "Si": [0, 1, 2, 3], "Wt": [0.1, 0.2, 0.3, 0.4]},
+						{"Ri": 1, "N": 4, "Si": [0, 1, 2, 3], "Wt": [0.5, 0.6, 0.7, 0.8]}
+					]
+				}
+			]
+		}
+	]
+}`
+	nw, err := weights.NetReadJSON(strings.NewReader(v1))
+	assert.NoError(t, err)
+	v, err := WtsFileVersion(nw)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+
+	net := createNetwork([]int{2, 2}, t)
+	pj := net.AxonLayerByName("Hidden").RcvPrjns[0]
+	syns := pj.RecvSyns(1)
+	swts := make([]float32, len(syns))
+	for si := range syns {
+		swts[si] = syns[si].SWt
+	}
+	assert.NoError(t, net.ReadWtsJSON(strings.NewReader(v1)))
+	assert.Equal(t, "10", net.MetaData["Epoch"])
+	assert.NotContains(t, net.MetaData, WtsVersionKey)
+	// the initialized SWt is kept, and LWt is recovered from Wt
+	for si := range syns {
+		wt := 0.5 + 0.1*float32(si)
+		assert.InDelta(t, wt, syns[si].Wt, 1.0e-6)
+		assert.Equal(t, swts[si], syns[si].SWt)
+		assert.InDelta(t, pj.Params.SWt.LWtFmWts(wt, swts[si]), syns[si].LWt, 1.0e-6)
+	}
+
+	// written weights have the current version
+	var b bytes.Buffer
+	assert.NoError(t, net.WriteWtsJSON(&b))
+	nw, err = weights.NetReadJSON(&b)
+	assert.NoError(t, err)
+	v, err = WtsFileVersion(nw)
+	assert.NoError(t, err)
+	assert.Equal(t, WtsVersion, v)
+	assert.Equal(t, "10", nw.MetaData["Epoch"])
+	assert.NoError(t, net.SetWts(nw))
+
+	// later and invalid versions are rejected
+	nw.SetMetaData(WtsVersionKey, fmt.Sprintf("%d", WtsVersion+1))
+	assert.Error(t, net.SetWts(nw))
+	nw.SetMetaData(WtsVersionKey, "bogus")
+	assert.Error(t, MigrateWts(nw))
+}
+
+func TestStreamApplyInputs(t *testing.T) {
+	net := createNetwork([]int{4, 4}, t)
+	inLay := net.AxonLayerByName("Input")
+	outLay := net.AxonLayerByName("Output")
+	ntrl := 6
+	ch := make(chan StreamPair)
+	go func() {
+		for i := 0; i < ntrl; i++ {
+			in := etensor.NewFloat32([]int{4, 4}, nil, nil)
+			trg := etensor.NewFloat32([]int{4, 4}, nil, nil)
+			in.Values[i] = 1
+			trg.Values[15-i] = 1
+			ch <- StreamPair{Input: in, Target: trg}
+		}
+		close(ch)
+	}()
+	st := NewChanStream(ch)
+	ctx := NewContext()
+	for i := 0; i < ntrl; i++ {
+		_, ok := StreamApplyInputs(net, st, "Input", "Output")
+		assert.True(t, ok)
+		net.ApplyExts(ctx)
+		net.NewState(ctx)
+		ctx.NewState(etime.Train)
+		for cyc := 0; cyc < 10; cyc++ {
+			net.Cycle(ctx)
+			ctx.CycleInc()
+		}
+		for ni := range inLay.Neurons {
+			exp := float32(0)
+			if ni == i {
+				exp = 1
+			}
+			assert.Equal(t, exp, inLay.Neurons[ni].Ext)
+		}
+		assert.Equal(t, float32(1), outLay.Neurons[15-i].Target)
+		assert.Equal(t, float32(0), outLay.Neurons[i].Target)
+		assert.Equal(t, i+1, st.N)
+	}
+	_, ok := StreamApplyInputs(net, st, "Input", "Output")
+	assert.False(t, ok)
+	assert.Equal(t, ntrl, st.N)
+	assert.Equal(t, float32(1), inLay.Neurons[ntrl-1].Ext) // unchanged
+}
+
+func TestDegree(t *testing.T) {
+	net := NewNetwork("DegreeTest")
+	inLay := net.AddLayer2D("Input", 4, 4, InputLayer)
+	hidLay := net.AddLayer2D("Hidden", 2, 4, SuperLayer)
+	outLay := net.AddLayer2D("Output", 2, 2, TargetLayer)
+	full := net.ConnectLayers(inLay, hidLay, prjn.NewFull(), ForwardPrjn)
+	rnd := prjn.NewUnifRnd()
+	rnd.PCon = 0.5
+	sparse := net.ConnectLayers(hidLay, outLay, rnd, ForwardPrjn)
+	sparse.SetClass("Sparse")
+	assert.NoError(t, net.Build())
+
+	conv, div := full.Degree()
+	assert.Equal(t, float32(16), conv)
+	assert.Equal(t, float32(8), div)
+	conv, div = sparse.Degree()
+	assert.Equal(t, float32(4), conv) // 0.5 * 8 senders
+	assert.Equal(t, float32(2), div)  // 4 * 4 / 8 senders
+
+	hist := net.DegreeDistribution("Sparse").(*etensor.Float32)
+	assert.Equal(t, []int{2, 5}, hist.Shapes()) // max degree 4
+	assert.Equal(t, float32(4), hist.Value([]int{0, 4}))
+	rsum, ssum := float32(0), float32(0)
+	for d := 0; d <= 4; d++ {
+		rsum += hist.Value([]int{0, d})
+		ssum += hist.Value([]int{1, d}) * float32(d)
+	}
+	assert.Equal(t, float32(4), rsum)  // all receivers
+	assert.Equal(t, float32(16), ssum) // all connections
+
+	hist = net.DegreeDistribution("ForwardPrjn").(*etensor.Float32)
+	assert.Equal(t, []int{2, 17}, hist.Shapes())
+	assert.Equal(t, float32(8), hist.Value([]int{0, 16}))
+	assert.Equal(t, float32(16), hist.Value([]int{1, 8}))
+}
+
+func TestBenchmark(t *testing.T) {
+	net := newRA25Net(t)
+	ctx := NewContext()
+	pat := etensor.NewFloat32([]int{5, 5}, nil, nil)
+	for i := 0; i < 25; i += 4 {
+		pat.Values[i] = 1
+	}
+	net.InitExt()
+	net.AxonLayerByName("Input").ApplyExt(pat)
+	net.AxonLayerByName("Output").ApplyExt(pat)
+	net.ApplyExts(ctx)
+
+	br := Benchmark(net, ctx, 2)
+	assert.False(t, br.GPU)
+	assert.Equal(t, 2, br.Trials)
+	assert.Equal(t, 2*int(ctx.ThetaCycles), br.Cycles)
+	assert.Equal(t, len(net.Neurons), br.Neurons)
+	assert.Equal(t, len(net.Synapses), br.Synapses)
+	assert.Greater(t, br.Secs, 0.0)
+	assert.Greater(t, br.CyclesPerSec, 0.0)
+	assert.InDelta(t, float64(br.Neurons)*br.CyclesPerSec, br.NeuronsPerSec, 1.0e-6*br.NeuronsPerSec)
+	assert.Greater(t, br.SynapsesPerSec, br.NeuronsPerSec)
+	assert.Contains(t, br.FunSecs, "CycleNeuron")
+	assert.False(t, net.RecFunTimes) // restored
+	assert.Contains(t, br.String(), "Cycles/sec")
+}
+
+func TestTraceNeurons(t *testing.T) {
+	net := createNetwork([]int{4, 4}, t)
+	inLay := net.AxonLayerByName("Input")
+	hidLay := net.AxonLayerByName("Hidden")
+	inPat := etensor.NewFloat32([]int{4, 4}, nil, nil)
+	for i := range inPat.Values {
+		if i%3 == 0 {
+			inPat.Values[i] = 1
+		}
+	}
+	specs := []NeuronSpec{{Layer: "Hidden", Idx: 5}}
+	assert.NoError(t, net.TraceNeurons(specs, []string{"Vm", "Ge"}))
+	dt := net.NeurTrace.Table
+	assert.Equal(t, 4, dt.NumCols())
+	assert.Equal(t, 0, dt.Rows)
+
+	ctx := NewContext()
+	runTrial := func() {
+		net.InitExt()
+		inLay.ApplyExt(inPat)
+		net.ApplyExts(ctx)
+		net.NewState(ctx)
+		ctx.NewState(etime.Train)
+		for cyc := 0; cyc < 200; cyc++ {
+			net.Cycle(ctx)
+			ctx.CycleInc()
+			if cyc == 149 {
+				net.MinusPhase(ctx)
+				ctx.NewPhase(true)
+				net.PlusPhaseStart(ctx)
+			}
+		}
+		net.PlusPhase(ctx)
+	}
+	runTrial()
+	assert.Equal(t, 200, dt.Rows)
+	nrn := &hidLay.Neurons[5]
+	assert.Equal(t, float64(nrn.Vm), dt.CellFloat("Hidden_5_Vm", 199))
+	assert.Equal(t, float64(nrn.Ge), dt.CellFloat("Hidden_5_Ge", 199))
+	assert.Equal(t, float64(0), dt.CellFloat("Cycle", 0))
+	assert.Equal(t, float64(199), dt.CellFloat("Cycle", 199))
+	vmChanged := false
+	for r := 1; r < dt.Rows; r++ {
+		if dt.CellFloat("Hidden_5_Vm", r) != dt.CellFloat("Hidden_5_Vm", 0) {
+			vmChanged = true
+		}
+	}
+	assert.True(t, vmChanged)
+
+	net.NeurTrace.Stop()
+	runTrial()
+	assert.Equal(t, 200, dt.Rows)
+	net.NeurTrace.Start()
+	runTrial()
+	assert.Equal(t, 400, dt.Rows)
+	net.NeurTrace.Reset()
+	assert.Equal(t, 0, dt.Rows)
+
+	assert.Error(t, net.TraceNeurons([]NeuronSpec{{Layer: "Hidden", Idx: 16}}, []string{"Vm"}))
+	assert.Error(t, net.TraceNeurons([]NeuronSpec{{Layer: "NoLayer", Idx: 0}}, []string{"Vm"}))
+	assert.Error(t, net.TraceNeurons(specs, []string{"NotAVar"}))
+	assert.NoError(t, net.TraceNeurons(nil, nil))
+	assert.False(t, net.NeurTrace.On)
+}
+
+func TestDecayStateAll(t *testing.T) {
+	net := NewNetwork("DecayTest")
+	inLay := net.AddLayer2D("Input", 2, 2, InputLayer)
+	pfcLay := net.AddLayer2D("PFC", 2, 2, PTMaintLayer)
+	assert.NoError(t, net.Build())
+	net.Defaults()
+	net.InitWts()
+	inDecay := inLay.Params.Act.Decay.Act
+	assert.Greater(t, inDecay, float32(0))
+	assert.Equal(t, float32(0), pfcLay.Params.Act.Decay.Act)
+
+	setActs := func() {
+		for _, ly := range []*Layer{inLay, pfcLay} {
+			for ni := range ly.Neurons {
+				ly.Neurons[ni].Act = 0.8
+			}
+		}
+	}
+	ctx := NewContext()
+	setActs()
+	net.DecayStateAll(ctx, -1, -1) // layer params
+	for ni := range inLay.Neurons {
+		assert.InDelta(t, 0.8-inDecay*0.8, inLay.Neurons[ni].Act, 1.0e-6)
+		assert.Equal(t, float32(0.8), pfcLay.Neurons[ni].Act)
+	}
+
+	setActs()
+	net.DecayStateAll(ctx, 1, -1) // override
+	for ni := range inLay.Neurons {
+		assert.Equal(t, float32(0), inLay.Neurons[ni].Act)
+		assert.Equal(t, float32(0), pfcLay.Neurons[ni].Act)
+	}
+}
+
+func TestReinit(t *testing.T) {
+	net := createNetwork([]int{4, 4}, t)
+	inLay := net.AxonLayerByName("Input")
+	hid := net.AxonLayerByName("Hidden")
+	pj := hid.RcvPrjns[0]
+	assert.Equal(t, inLay, pj.Send)
+	pat := etensor.NewFloat32([]int{4, 4}, nil, nil)
+	for i := range pat.Values {
+		if i%2 == 0 {
+			pat.Values[i] = 1
+		}
+	}
+	// returns the first cycle at which input spikes arrive in Hidden
+	arrival := func() int {
+		ctx := NewContext()
+		net.InitExt()
+		inLay.ApplyExt(pat)
+		net.ApplyExts(ctx)
+		net.NewState(ctx)
+		ctx.NewState(etime.Train)
+		for cyc := 0; cyc < 50; cyc++ {
+			net.Cycle(ctx)
+			ctx.CycleInc()
+			for _, g := range pj.GSyns {
+				if g > 0 {
+					return cyc
+				}
+			}
+		}
+		return -1
+	}
+	ctx := NewContext()
+	net.Reinit(ctx)
+	arr0 := arrival()
+	assert.Greater(t, arr0, 0)
+
+	wts := make([]float32, len(pj.Syns))
+	for si := range pj.Syns {
+		wts[si] = pj.Syns[si].Wt
+	}
+	gbsz := len(pj.GBuf)
+	delay := pj.Params.Com.Delay
+	pj.Params.Com.MaxDelay = 6
+	pj.Params.Com.Delay = delay + 3
+	net.Reinit(ctx)
+	assert.Equal(t, uint32(6), net.MaxDelay)
+	assert.Equal(t, len(hid.Neurons)*7, len(pj.GBuf))
+	assert.Greater(t, len(pj.GBuf), gbsz)
+	assert.Equal(t, delay+4, pj.Params.Com.DelLen)
+	for si := range pj.Syns {
+		assert.Equal(t, wts[si], pj.Syns[si].Wt)
+	}
+	assert.Equal(t, arr0+3, arrival())
+}
@@ -15,10 +15,13 @@ import (
 	"io"
 	"log"
 	"math"
+	"math/rand"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"unsafe"
 
 	"github.com/emer/emergent/emer"
 	"github.com/emer/emergent/erand"
@@ -27,6 +30,8 @@ import (
 	"github.com/emer/emergent/relpos"
 	"github.com/emer/emergent/timer"
 	"github.com/emer/emergent/weights"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
 	"github.com/goki/gi/gi"
 	"github.com/goki/ki/indent"
 	"github.com/goki/kigen/dedupe"
@@ -41,6 +46,7 @@ type NetworkBase struct {
 	WtsFile       string              `desc:"filename of last weights file loaded or saved"`
 	LayMap        map[string]*Layer   `view:"-" desc:"map of name to layers -- layer names must be unique"`
 	LayClassMap   map[string][]string `view:"-" desc:"map of layer classes -- made during Build"`
+	LayGroups     map[string][]string `view:"-" desc:"named groups of layers, as lists of layer names, for coordinated programmatic operations on related layers -- see LayerGroup, GroupDo"`
 	MinPos        mat32.Vec3          `view:"-" desc:"minimum display position in network"`
 	MaxPos        mat32.Vec3          `view:"-" desc:"maximum display position in network"`
 	MetaData      map[string]string   `desc:"optional metadata that is saved in network weights files -- e.g., can indicate number of epochs that were trained, or any other information about this network that would be useful to save"`
@@ -65,13 +71,20 @@ type NetworkBase struct {
 
 	Exts []float32 `view:"-" desc:"[In / Targ Layers][Neurons] external input values for all Input / Target / Compare layers in the network -- the ApplyExt methods write to this per layer, and it is then actually applied in one consistent method."`
 
-	Rand        erand.SysRand          `view:"-" desc:"random number generator for the network -- all random calls must use this -- set seed here for weight initialization values"`
-	RndSeed     int64                  `inactive:"+" desc:"random seed to be set at the start of configuring the network and initializing the weights -- set this to get a different set of weights"`
-	Threads     NetThreads             `desc:"threading config and implementation for CPU"`
-	GPU         GPU                    `view:"inline" desc:"GPU implementation"`
-	RecFunTimes bool                   `view:"-" desc:"record function timer information"`
-	FunTimes    map[string]*timer.Time `view:"-" desc:"timers for each major function (step of processing)"`
-	WaitGp      sync.WaitGroup         `view:"-" desc:"network-level wait group for synchronizing threaded layer calls"`
+	Rand          erand.SysRand                   `view:"-" desc:"random number generator for the network -- all random calls must use this -- set seed here for weight initialization values"`
+	RndSeed       int64                           `inactive:"+" desc:"random seed to be set at the start of configuring the network and initializing the weights -- set this to get a different set of weights"`
+	randSrc       *randSource                     // source for Rand, whose state is saved by RandState
+	phaseFuns     map[int32][]PhaseFun            // OnPhaseStart functions by phase, called in Cycle
+	Threads       NetThreads                      `desc:"threading config and implementation for CPU"`
+	GPU           GPU                             `view:"inline" desc:"GPU implementation"`
+	RecFunTimes   bool                            `view:"-" desc:"record function timer information"`
+	FunTimes      map[string]*timer.Time          `view:"-" desc:"timers for each major function (step of processing)"`
+	WaitGp        sync.WaitGroup                  `view:"-" desc:"network-level wait group for synchronizing threaded layer calls"`
+	SynSnaps      map[string]map[string][]float32 `view:"-" desc:"baseline synapse values recorded by SynChangeSnapshot, by variable name and then projection name"`
+	ParamScheds   map[int][]*params.Sheet         `view:"-" desc:"params sheets scheduled to be applied at the start of given epochs -- see AddParamSched, ApplyParamScheds"`
+	ActsRing      ActsRing                        `view:"-" desc:"optional ring buffer of selected neuron variables recorded each cycle -- see RecordActsRing"`
+	NeuronVarFuns []NeuronVarFun                  `view:"-" desc:"virtual neuron variables computed on demand from other neuron values -- see RegisterNeuronVar"`
+	NeurTrace     NeuronTrace                     `view:"-" desc:"optional cycle-by-cycle record of selected variables of selected neurons -- see TraceNeurons"`
 }
 
 // InitName MUST be called to initialize the network's pointer to itself as an emer.Network
@@ -172,6 +185,48 @@ func (nt *NetworkBase) LayersByClass(classes ...string) []string {
 	return layers
 }
 
+// LayerGroup registers a named group of layers, for coordinated operations
+// on related sets of layers via GroupDo, and group-scoped methods such as
+// DecayStateByGroup and LRateModByGroup.  Any existing group of the same
+// name is replaced.  Group membership is stored by layer name in LayGroups,
+// so logging and stats code can access the layers via LayersByGroup.
+func (nt *NetworkBase) LayerGroup(name string, layers ...*Layer) {
+	if nt.LayGroups == nil {
+		nt.LayGroups = make(map[string][]string)
+	}
+	nms := make([]string, len(layers))
+	for i, ly := range layers {
+		nms[i] = ly.Name()
+	}
+	nt.LayGroups[name] = nms
+}
+
+// LayersByGroup returns the list of layer names in given layer group,
+// registered via LayerGroup.  Returns nil if the group does not exist.
+func (nt *NetworkBase) LayersByGroup(name string) []string {
+	return nt.LayGroups[name]
+}
+
+// GroupDo calls given function on each layer in given layer group,
+// registered via LayerGroup, skipping any layers that are Off.
+// Returns an error if the group does not exist.
+func (nt *NetworkBase) GroupDo(name string, fn func(ly *Layer)) error {
+	nms, ok := nt.LayGroups[name]
+	if !ok {
+		err := fmt.Errorf("Layer group named: %v not found in Network: %v\n", name, nt.Nm)
+		log.Println(err)
+		return err
+	}
+	for _, lnm := range nms {
+		ly := nt.AxonLayerByName(lnm)
+		if ly == nil || ly.IsOff() {
+			continue
+		}
+		fn(ly)
+	}
+	return nil
+}
+
 // StdVertLayout arranges layers in a standard vertical (z axis stack) layout, by setting
 // the Rel settings
 func (nt *NetworkBase) StdVertLayout() {
@@ -291,6 +346,29 @@ func (nt *NetworkBase) AllParams() string {
 	return nds
 }
 
+// DumpBuildConfigs returns a listing of the BuildConfig entries for all
+// layers in the network that have any, sorted by name within each layer,
+// for diagnosing the configuration of specialized layers (e.g., the
+// driver and other layer names used in PostBuild).
+func (nt *NetworkBase) DumpBuildConfigs() string {
+	var b strings.Builder
+	for _, ly := range nt.Layers {
+		if len(ly.BuildConfig) == 0 {
+			continue
+		}
+		keys := make([]string, 0, len(ly.BuildConfig))
+		for k := range ly.BuildConfig {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fmt.Fprintf(&b, "Layer: %s\n", ly.Nm)
+		for _, k := range keys {
+			fmt.Fprintf(&b, "\t%s:\t%s\n", k, ly.BuildConfig[k])
+		}
+	}
+	return b.String()
+}
+
 // KeyLayerParams returns a listing for all layers in the network,
 // of the most important layer-level params (specific to each algorithm).
 func (nt *NetworkBase) KeyLayerParams() string {
@@ -367,6 +445,132 @@ func (nt *NetworkBase) AllPrjnScales() string {
 	return str
 }
 
+// WtStatsTable returns a table with one row per projection in the Network
+// (skipping those that are Off, or in Off layers), ordered by receiving layer,
+// with columns: Send, Recv layer names, NSyns = number of synapses,
+// mean and variance of Wt and SWt, FracSat = proportion of synapses whose
+// sigmoidal learned weight factor (Wt / 2*SWt) is within 0.05 of its 0 or 1
+// limit, and the current GScale.  This provides a snapshot of overall
+// weight health, e.g., for logging at the end of each epoch.
+// When running on the GPU, call GPU.SyncSynapsesFmGPU first.
+func (nt *NetworkBase) WtStatsTable() *etable.Table {
+	dt := &etable.Table{}
+	dt.SetMetaData("name", "WtStats")
+	dt.SetMetaData("desc", "weight statistics for each projection")
+	dt.SetMetaData("read-only", "true")
+	dt.SetFromSchema(etable.Schema{
+		{Name: "Send", Type: etensor.STRING, CellShape: nil, DimNames: nil},
+		{Name: "Recv", Type: etensor.STRING, CellShape: nil, DimNames: nil},
+		{Name: "NSyns", Type: etensor.INT64, CellShape: nil, DimNames: nil},
+		{Name: "WtMean", Type: etensor.FLOAT64, CellShape: nil, DimNames: nil},
+		{Name: "WtVar", Type: etensor.FLOAT64, CellShape: nil, DimNames: nil},
+		{Name: "SWtMean", Type: etensor.FLOAT64, CellShape: nil, DimNames: nil},
+		{Name: "SWtVar", Type: etensor.FLOAT64, CellShape: nil, DimNames: nil},
+		{Name: "FracSat", Type: etensor.FLOAT64, CellShape: nil, DimNames: nil},
+		{Name: "GScale", Type: etensor.FLOAT64, CellShape: nil, DimNames: nil},
+	}, 0)
+	for _, ly := range nt.Layers {
+		if ly.IsOff() {
+			continue
+		}
+		for _, pj := range ly.RcvPrjns {
+			if pj.IsOff() {
+				continue
+			}
+			var wsum, wsq, swsum, swsq float64
+			nsat := 0
+			for si := range pj.Syns {
+				sy := &pj.Syns[si]
+				wsum += float64(sy.Wt)
+				wsq += float64(sy.Wt) * float64(sy.Wt)
+				swsum += float64(sy.SWt)
+				swsq += float64(sy.SWt) * float64(sy.SWt)
+				sw := sy.Wt
+				if sy.SWt > 0 {
+					sw /= 2 * sy.SWt
+				}
+				if sw <= 0.05 || sw >= 0.95 {
+					nsat++
+				}
+			}
+			row := dt.Rows
+			dt.AddRows(1)
+			dt.SetCellString("Send", row, pj.Send.Name())
+			dt.SetCellString("Recv", row, ly.Name())
+			n := len(pj.Syns)
+			dt.SetCellFloat("NSyns", row, float64(n))
+			dt.SetCellFloat("GScale", row, float64(pj.Params.GScale.Scale))
+			if n == 0 {
+				continue
+			}
+			fn := float64(n)
+			wmean := wsum / fn
+			swmean := swsum / fn
+			dt.SetCellFloat("WtMean", row, wmean)
+			dt.SetCellFloat("WtVar", row, wsq/fn-wmean*wmean)
+			dt.SetCellFloat("SWtMean", row, swmean)
+			dt.SetCellFloat("SWtVar", row, swsq/fn-swmean*swmean)
+			dt.SetCellFloat("FracSat", row, float64(nsat)/fn)
+		}
+	}
+	return dt
+}
+
+// EffectiveInputTable returns a table with one row per receiving projection
+// into given layer (skipping those that are Off), showing which projections
+// are actually driving the layer, with columns: Send layer name, prjn Type,
+// PrjnScale Abs and Rel parameters, the resulting GScale scaling factor,
+// GSyn = the average GSyns conductance from the prjn over the last trial
+// (GSynTrl), and Pct = the percent of the total GSyn over all of the
+// layer's projections (excitatory and inhibitory) contributed by this one.
+// GSynTrl is only computed when running on the CPU, and only after
+// recording has been enabled with Layer.SetRecordGSyn.
+// Returns nil and logs an error if the layer is not found.
+func (nt *NetworkBase) EffectiveInputTable(layer string) *etable.Table {
+	ly, err := nt.LayByNameTry(layer)
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+	dt := &etable.Table{}
+	dt.SetMetaData("name", layer+"EffInput")
+	dt.SetMetaData("desc", "effective input from each projection into layer "+layer)
+	dt.SetMetaData("read-only", "true")
+	dt.SetFromSchema(etable.Schema{
+		{Name: "Send", Type: etensor.STRING, CellShape: nil, DimNames: nil},
+		{Name: "Type", Type: etensor.STRING, CellShape: nil, DimNames: nil},
+		{Name: "Abs", Type: etensor.FLOAT64, CellShape: nil, DimNames: nil},
+		{Name: "Rel", Type: etensor.FLOAT64, CellShape: nil, DimNames: nil},
+		{Name: "GScale", Type: etensor.FLOAT64, CellShape: nil, DimNames: nil},
+		{Name: "GSyn", Type: etensor.FLOAT64, CellShape: nil, DimNames: nil},
+		{Name: "Pct", Type: etensor.FLOAT64, CellShape: nil, DimNames: nil},
+	}, 0)
+	tot := float32(0)
+	for _, pj := range ly.RcvPrjns {
+		if pj.IsOff() {
+			continue
+		}
+		tot += pj.GSynTrl
+	}
+	for _, pj := range ly.RcvPrjns {
+		if pj.IsOff() {
+			continue
+		}
+		row := dt.Rows
+		dt.AddRows(1)
+		dt.SetCellString("Send", row, pj.Send.Name())
+		dt.SetCellString("Type", row, pj.PrjnType().String())
+		dt.SetCellFloat("Abs", row, float64(pj.Params.PrjnScale.Abs))
+		dt.SetCellFloat("Rel", row, float64(pj.Params.PrjnScale.Rel))
+		dt.SetCellFloat("GScale", row, float64(pj.Params.GScale.Scale))
+		dt.SetCellFloat("GSyn", row, float64(pj.GSynTrl))
+		if tot > 0 {
+			dt.SetCellFloat("Pct", row, 100*float64(pj.GSynTrl/tot))
+		}
+	}
+	return dt
+}
+
 // AddLayerInit is implementation routine that takes a given layer and
 // adds it to the network, and initializes and configures it properly.
 func (nt *NetworkBase) AddLayerInit(ly *Layer, name string, shape []int, typ LayerTypes) {
@@ -447,6 +651,17 @@ func (nt *NetworkBase) ConnectLayersPrjn(send, recv *Layer, pat prjn.Pattern, ty
 	return pj
 }
 
+// ConnectIdentity adds a one-to-one IdentityPrjn from send to recv layer,
+// which relays the sending Act values to the receiving GeSyn,
+// scaled by the PrjnScale.Abs gain.  The layers should have the same
+// number of neurons.
+func (nt *NetworkBase) ConnectIdentity(send, recv *Layer) *Prjn {
+	if send.Shp.Len() != recv.Shp.Len() {
+		log.Printf("ConnectIdentity: sending layer %s has %d units, but receiving layer %s has %d\n", send.Nm, send.Shp.Len(), recv.Nm, recv.Shp.Len())
+	}
+	return nt.ConnectLayers(send, recv, prjn.NewOneToOne(), IdentityPrjn)
+}
+
 // BidirConnectLayerNames establishes bidirectional projections between two layers,
 // referenced by name, with low = the lower layer that sends a Forward projection
 // to the high layer, and receives a Back projection in the opposite direction.
@@ -705,6 +920,7 @@ func (nt *NetworkBase) Build() error {
 func (nt *NetworkBase) BuildPrjnGBuf() {
 	nt.MaxDelay = 0
 	npjneur := uint32(0)
+	nnegneur := uint32(0) // separate negative buffers for SWt.AllowNegative
 	pjidx := uint32(0)
 	for _, ly := range nt.Layers {
 		nneur := uint32(len(ly.Neurons))
@@ -723,10 +939,13 @@ func (nt *NetworkBase) BuildPrjnGBuf() {
 				nt.MaxDelay = pj.Params.Com.MaxDelay
 			}
 			npjneur += nneur
+			if pj.Params.SWt.AllowNegative.IsTrue() {
+				nnegneur += nneur
+			}
 		}
 	}
 	mxlen := nt.MaxDelay + 1
-	gbsz := npjneur * mxlen
+	gbsz := (npjneur + nnegneur) * mxlen
 	if uint32(cap(nt.PrjnGBuf)) >= gbsz {
 		nt.PrjnGBuf = nt.PrjnGBuf[:gbsz]
 	} else {
@@ -744,6 +963,9 @@ func (nt *NetworkBase) BuildPrjnGBuf() {
 		nneur := uint32(len(ly.Neurons))
 		for _, pj := range ly.RcvPrjns {
 			gbs := nneur * mxlen
+			if pj.Params.SWt.AllowNegative.IsTrue() {
+				gbs *= 2
+			}
 			pj.Params.Idxs.GBufSt = gbi
 			pj.GBuf = nt.PrjnGBuf[gbi : gbi+gbs]
 			gbi += gbs
@@ -822,6 +1044,27 @@ func (nt *NetworkBase) WriteWtsJSON(w io.Writer) error {
 	w.Write(indent.TabBytes(depth))
 	w.Write([]byte(fmt.Sprintf("\"Network\": %q,\n", nt.Nm))) // note: can't use \n in `` so need "
 	w.Write(indent.TabBytes(depth))
+	w.Write([]byte("\"MetaData\": {\n"))
+	depth++
+	w.Write(indent.TabBytes(depth))
+	w.Write([]byte(fmt.Sprintf("%q: \"%d\"", WtsVersionKey, WtsVersion)))
+	mks := make([]string, 0, len(nt.MetaData))
+	for mk := range nt.MetaData {
+		if mk != WtsVersionKey {
+			mks = append(mks, mk)
+		}
+	}
+	sort.Strings(mks)
+	for _, mk := range mks {
+		w.Write([]byte(",\n"))
+		w.Write(indent.TabBytes(depth))
+		w.Write([]byte(fmt.Sprintf("%q: %q", mk, nt.MetaData[mk])))
+	}
+	w.Write([]byte("\n"))
+	depth--
+	w.Write(indent.TabBytes(depth))
+	w.Write([]byte("},\n"))
+	w.Write(indent.TabBytes(depth))
 	onls := make([]emer.Layer, 0, len(nt.Layers))
 	for _, ly := range nt.Layers {
 		if !ly.IsOff() {
@@ -867,20 +1110,24 @@ func (nt *NetworkBase) ReadWtsJSON(r io.Reader) error {
 	return err
 }
 
-// SetWts sets the weights for this network from weights.Network decoded values
+// SetWts sets the weights for this network from weights.Network decoded values,
+// first upgrading them to the current WtsVersion format with MigrateWts.
 func (nt *NetworkBase) SetWts(nw *weights.Network) error {
-	var err error
+	err := MigrateWts(nw)
+	if err != nil {
+		return err // note: already logged
+	}
 	if nw.Network != "" {
 		nt.Nm = nw.Network
 	}
-	if nw.MetaData != nil {
+	for mk, mv := range nw.MetaData {
+		if mk == WtsVersionKey {
+			continue
+		}
 		if nt.MetaData == nil {
-			nt.MetaData = nw.MetaData
-		} else {
-			for mk, mv := range nw.MetaData {
-				nt.MetaData[mk] = mv
-			}
+			nt.MetaData = make(map[string]string)
 		}
+		nt.MetaData[mk] = mv
 	}
 	for li := range nw.Layers {
 		lw := &nw.Layers[li]
@@ -963,6 +1210,120 @@ func (nt *Network) WtsHash() string {
 	return HashEncodeSlice(wts)
 }
 
+// StateHash returns a hash code of the full dynamic state of the network,
+// combining separate hashes of all Neurons, Pools, and weights, in the form
+// "Neurons:<hash> Pools:<hash> Wts:<hash>".  Unlike WtsHash, this is
+// sensitive to any changes in activation dynamics, and is intended for
+// regression testing, with GoldenCheck.
+func (nt *Network) StateHash() string {
+	nt.GPU.SyncStateFmGPU()
+	nt.GPU.SyncSynapsesFmGPU()
+	var nh, ph string
+	if len(nt.Neurons) > 0 {
+		nh = HashEncodeBytes(unsafe.Slice((*byte)(unsafe.Pointer(&nt.Neurons[0])), len(nt.Neurons)*int(unsafe.Sizeof(Neuron{}))))
+	} else {
+		nh = HashEncodeBytes(nil)
+	}
+	if len(nt.Pools) > 0 {
+		ph = HashEncodeBytes(unsafe.Slice((*byte)(unsafe.Pointer(&nt.Pools[0])), len(nt.Pools)*int(unsafe.Sizeof(Pool{}))))
+	} else {
+		ph = HashEncodeBytes(nil)
+	}
+	return fmt.Sprintf("Neurons:%s Pools:%s Wts:%s", nh, ph, nt.WtsHash())
+}
+
+// GoldenCheck compares the StateHash of given network against an expected
+// "golden" value, returning an empty string if they match, and otherwise
+// a summary of which components (Neurons, Pools, Wts) differ.
+// Typical use in a regression test is to run a standard model (e.g., ra25)
+// with a fixed random seed for a fixed number of trials, and then:
+//
+//	if diff := axon.GoldenCheck(net, golden); diff != "" {
+//		t.Error(diff)
+//	}
+//
+// where golden is the StateHash recorded from a known-good version.
+// Any change in the algorithm that affects the activation dynamics or
+// learning will then be detected, and the golden value must be updated
+// if the change is intended.
+func GoldenCheck(net *Network, expected string) string {
+	got := net.StateHash()
+	if got == expected {
+		return ""
+	}
+	gf := strings.Fields(got)
+	ef := strings.Fields(expected)
+	diffs := []string{}
+	for i, g := range gf {
+		e := ""
+		if i < len(ef) {
+			e = ef[i]
+		}
+		if g != e {
+			nm, gh, _ := strings.Cut(g, ":")
+			_, eh, _ := strings.Cut(e, ":")
+			diffs = append(diffs, fmt.Sprintf("%s: got %s, expected %s", nm, gh, eh))
+		}
+	}
+	if len(diffs) == 0 {
+		diffs = append(diffs, fmt.Sprintf("got %q, expected %q", got, expected))
+	}
+	return "GoldenCheck: StateHash mismatch -- " + strings.Join(diffs, "; ")
+}
+
+// SynChangeSnapshot returns the change in given synapse variable
+// (e.g., "Wt", "LWt") for each projection, keyed by projection name,
+// relative to the values at the last call to this method for the same
+// variable, which are maintained internally as a baseline in SynSnaps.
+// The values are in the natural recv-based ordering of synapses,
+// as in Prjn SynVals.  The first call for a given variable returns all zeros.
+// This is useful for visualizing learning dynamics, e.g., as DWt heatmaps.
+func (nt *Network) SynChangeSnapshot(varNm string) map[string][]float32 {
+	vidx, err := SynapseVarByName(varNm)
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+	nt.GPU.SyncSynapsesFmGPU()
+	if nt.SynSnaps == nil {
+		nt.SynSnaps = make(map[string]map[string][]float32)
+	}
+	base, has := nt.SynSnaps[varNm]
+	if !has {
+		base = make(map[string][]float32)
+		nt.SynSnaps[varNm] = base
+	}
+	chg := make(map[string][]float32)
+	for _, ly := range nt.Layers {
+		if ly.IsOff() {
+			continue
+		}
+		for _, pj := range ly.RcvPrjns {
+			if pj.IsOff() {
+				continue
+			}
+			pnm := pj.Name()
+			ns := len(pj.Syns)
+			bv := base[pnm]
+			hasBase := len(bv) == ns
+			if !hasBase {
+				bv = make([]float32, ns)
+				base[pnm] = bv
+			}
+			cv := make([]float32, ns)
+			for si := range pj.Syns {
+				val := pj.Syns[si].VarByIndex(vidx)
+				if hasBase {
+					cv[si] = val - bv[si]
+				}
+				bv[si] = val
+			}
+			chg[pnm] = cv
+		}
+	}
+	return chg
+}
+
 func HashEncodeSlice(slice []float32) string {
 	byteSlice := make([]byte, len(slice)*4)
 	for i, f := range slice {
@@ -976,6 +1337,12 @@ func HashEncodeSlice(slice []float32) string {
 	return hex.EncodeToString(md5Sum)
 }
 
+// HashEncodeBytes returns a hex-encoded md5 hash of given bytes
+func HashEncodeBytes(bs []byte) string {
+	md5Sum := md5.Sum(bs)
+	return hex.EncodeToString(md5Sum[:])
+}
+
 // VarRange returns the min / max values for given variable
 // todo: support r. s. projection values
 func (nt *NetworkBase) VarRange(varNm string) (min, max float32, err error) {
@@ -1010,8 +1377,9 @@ func (nt *NetworkBase) SetRndSeed(seed int64) {
 // ResetRndSeed sets random seed to saved RndSeed, ensuring that the
 // network-specific random seed generator has been created.
 func (nt *NetworkBase) ResetRndSeed() {
-	if nt.Rand.Rand == nil {
-		nt.Rand.NewRand(nt.RndSeed)
+	if nt.Rand.Rand == nil || nt.randSrc == nil {
+		nt.randSrc = newRandSource(nt.RndSeed)
+		nt.Rand.Rand = rand.New(nt.randSrc)
 	} else {
 		nt.Rand.Seed(nt.RndSeed)
 	}
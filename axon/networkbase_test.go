@@ -78,3 +78,77 @@ func TestDelete(t *testing.T) {
 	net.DeleteAll()
 	assert.Equal(t, 0, net.NLayers())
 }
+
+func TestLayerGroup(t *testing.T) {
+	net := NewNetwork("testNet")
+	shape := []int{2, 2}
+	input := net.AddLayer("Input", shape, InputLayer)
+	hid1 := net.AddLayer("Hidden1", shape, SuperLayer)
+	hid2 := net.AddLayer("Hidden2", shape, SuperLayer)
+	output := net.AddLayer("Output", shape, TargetLayer)
+
+	full := prjn.NewFull()
+	net.ConnectLayers(input, hid1, full, ForwardPrjn)
+	net.ConnectLayers(hid1, hid2, full, ForwardPrjn)
+	net.ConnectLayers(hid2, output, full, ForwardPrjn)
+
+	assert.Nil(t, net.Build())
+	net.Defaults()
+	net.InitWts()
+
+	net.LayerGroup("Hiddens", hid1, hid2)
+	assert.Equal(t, []string{"Hidden1", "Hidden2"}, net.LayersByGroup("Hiddens"))
+	assert.Error(t, net.GroupDo("NoSuchGroup", func(ly *Layer) {}))
+
+	var visited []string
+	assert.NoError(t, net.GroupDo("Hiddens", func(ly *Layer) { visited = append(visited, ly.Name()) }))
+	assert.Equal(t, []string{"Hidden1", "Hidden2"}, visited)
+
+	net.LRateModByGroup("Hiddens", 0.5)
+	for _, ly := range net.Layers {
+		for _, pj := range ly.RcvPrjns {
+			if ly == hid1 || ly == hid2 {
+				assert.Equal(t, float32(0.5), pj.Params.Learn.LRate.Mod)
+			} else {
+				assert.Equal(t, float32(1), pj.Params.Learn.LRate.Mod)
+			}
+		}
+	}
+
+	for ni := range net.Neurons {
+		net.Neurons[ni].Act = 1
+	}
+	ctx := NewContext()
+	net.DecayStateByGroup(ctx, 1, 0, "Hiddens")
+	for _, ly := range net.Layers {
+		for ni := range ly.Neurons {
+			if ly == hid1 || ly == hid2 {
+				assert.Equal(t, ly.Params.Act.Init.Act, ly.Neurons[ni].Act)
+			} else {
+				assert.Equal(t, float32(1), ly.Neurons[ni].Act)
+			}
+		}
+	}
+}
+
+func TestBuildConfigs(t *testing.T) {
+	net := NewNetwork("testNet")
+	input := net.AddLayer2D("Input", 2, 2, InputLayer)
+	hidden := net.AddLayer2D("Hidden", 2, 2, SuperLayer)
+	hidden.SetBuildConfig("LayInhib1Name", "Input")
+	hidden.SetBuildConfig("DriveLayName", "Input")
+
+	cfgs := hidden.BuildConfigs()
+	assert.Equal(t, map[string]string{"LayInhib1Name": "Input", "DriveLayName": "Input"}, cfgs)
+	cfgs["Other"] = "x" // copy does not affect the layer
+	assert.Equal(t, 2, len(hidden.BuildConfig))
+	assert.Equal(t, 0, len(input.BuildConfigs()))
+
+	assert.Equal(t, "Layer: Hidden\n\tDriveLayName:\tInput\n\tLayInhib1Name:\tInput\n", net.DumpBuildConfigs())
+
+	hidden.ClearBuildConfig("DriveLayName")
+	hidden.ClearBuildConfig("NotThere")
+	assert.Equal(t, map[string]string{"LayInhib1Name": "Input"}, hidden.BuildConfigs())
+	hidden.ClearBuildConfig("LayInhib1Name")
+	assert.Equal(t, "", net.DumpBuildConfigs())
+}
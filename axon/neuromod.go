@@ -33,7 +33,9 @@ type NeuroModVals struct {
 	AChRaw float32 `inactive:"+" desc:"raw ACh value used in updating global ACh value by RSalienceAChLayer"`
 	PPTg   float32 `inactive:"+" desc:"raw PPTg value reflecting the positive-rectified delta output of the Amygdala, which drives ACh and DA in the PVLV framework "`
 
-	pad, pad1 float32
+	TDTrDecay float32 `inactive:"+" desc:"per-trial decay factor for the TD(lambda) eligibility trace used in TDPredPrjn learning, set by the TDIntegLayer from its TDInteg.Discount * Lambda -- 0 = one-step TD(0)"`
+
+	pad float32
 }
 
 func (nm *NeuroModVals) Init() {
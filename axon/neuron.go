@@ -156,6 +156,18 @@ type Neuron struct {
 	CtxtGe     float32 `desc:"context (temporally delayed) excitatory conductance, driven by deep bursting at end of the plus phase, for CT layers."`
 	CtxtGeRaw  float32 `desc:"raw update of context (temporally delayed) excitatory conductance, driven by deep bursting at end of the plus phase, for CT layers."`
 	CtxtGeOrig float32 `desc:"original CtxtGe value prior to any decay factor -- updates at end of plus phase."`
+
+	ActOut float32 `desc:"output readout of Act, transformed by the layer ActOutFunc if set (e.g., sigmoid, threshold) -- does not affect the dynamics, and is 0 if no function is set -- see Layer.SetActOutFunc"`
+
+	GeRise float32 `desc:"rise component of the excitatory synaptic conductance for the GeAlpha Dt.GeKind, integrated from GeRaw with Dt.GeRiseTau, and subtracted from the decay component to produce GeSyn"`
+
+	SpkThrOff float32 `desc:"offset added to the spike threshold, adapted by Learn.IntrinsicPlast to maintain a target average activity level (intrinsic plasticity) -- positive values make the neuron less excitable"`
+
+	SpkCnt float32 `desc:"number of spikes on the current trial, counted every cycle a spike occurs and reset in NewState -- an exact measure of the rate of spiking, in contrast to the ISI-derived Act estimate"`
+
+	GABABd float32 `desc:"GABA-B / GIRK depletion factor (0 = none, 1 = fully depleted) when GABAB.Deplete is On -- increases with sustained inhibitory drive and recovers during silence, reducing GgabaB by (1 - GABABd)"`
+
+	pad, pad1, pad2 float32
 }
 
 func (nrn *Neuron) HasFlag(flag NeuronFlags) bool {
@@ -198,6 +210,8 @@ var NeuronVarProps = map[string]string{
 	"AvgPct":    `range:"2"`,
 	"TrgAvg":    `range:"2"`,
 	"DTrgAvg":   `auto-scale:"+"`,
+	"SpkThrOff": `auto-scale:"+"`,
+	"SpkCnt":    `auto-scale:"+"`,
 	"MahpN":     `auto-scale:"+"`,
 	"GknaMed":   `auto-scale:"+"`,
 	"GknaSlow":  `auto-scale:"+"`,
@@ -208,6 +222,7 @@ var NeuronVarProps = map[string]string{
 	"GgabaB":    `auto-scale:"+"`,
 	"GABAB":     `auto-scale:"+"`,
 	"GABABx":    `auto-scale:"+"`,
+	"GABABd":    `min:"0" max:"1"`,
 	"Gvgcc":     `auto-scale:"+"`,
 	"VgccCa":    `auto-scale:"+"`,
 	"VgccCaInt": `auto-scale:"+"`,
@@ -1,9 +1,13 @@
 package axon
 
 import (
-	"github.com/stretchr/testify/assert"
+	"math"
 	"reflect"
 	"testing"
+
+	"github.com/emer/emergent/erand"
+	"github.com/emer/etable/etensor"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestNeuronVarStart(t *testing.T) {
@@ -18,3 +22,266 @@ func TestNeuron(t *testing.T) {
 	nrn := Neuron{}
 	assert.Contains(t, nrn.VarNames(), "Spike")
 }
+
+// noiseCorr returns the correlation of Ge noise spikes between two
+// neurons in the same pool, for given CorrFrac.
+func noiseCorr(corrFrac float32) float32 {
+	ac := ActParams{}
+	ac.Defaults()
+	ac.Noise.On.SetBool(true)
+	ac.Noise.Ge = 1
+	ac.Noise.CorrFrac = corrFrac
+	ac.Update()
+
+	ctx := NewContext()
+	p0, p1 := float32(1), float32(1)
+	n := 50000
+	var s0, s1, s00, s11, s01 float64
+	for i := 0; i < n; i++ {
+		ge0 := float64(ac.Noise.PGe(ctx, &p0, 0) + ac.Noise.PGeCorr(ctx, 0))
+		ge1 := float64(ac.Noise.PGe(ctx, &p1, 1) + ac.Noise.PGeCorr(ctx, 0))
+		s0 += ge0
+		s1 += ge1
+		s00 += ge0 * ge0
+		s11 += ge1 * ge1
+		s01 += ge0 * ge1
+		ctx.CycleInc()
+	}
+	nf := float64(n)
+	cov := s01/nf - (s0/nf)*(s1/nf)
+	v0 := s00/nf - (s0/nf)*(s0/nf)
+	v1 := s11/nf - (s1/nf)*(s1/nf)
+	return float32(cov / (math.Sqrt(v0 * v1)))
+}
+
+func TestNoiseCorrFrac(t *testing.T) {
+	for _, cf := range []float32{0, 0.5, 1} {
+		assert.InDelta(t, cf, noiseCorr(cf), 0.05)
+	}
+}
+
+func TestClampGeRamp(t *testing.T) {
+	ac := ActParams{}
+	ac.Defaults()
+	ac.Clamp.IsTarget.SetBool(true)
+	ac.Clamp.RampCycles = 10
+	ac.Clamp.RampStart = 0.2
+	ac.Update()
+
+	ctx := NewContext()
+	nrn := &Neuron{}
+	nrn.Ext = 1
+	nrn.SetFlag(NeuronHasExt)
+
+	ctx.NewPhase(false) // no ramp in minus phase
+	ac.GeFmSyn(ctx, 0, nrn, 0, 0)
+	assert.Equal(t, ac.Clamp.Ge, nrn.Ge)
+
+	ctx.NewPhase(true)
+	for cyc := 0; cyc < 20; cyc++ {
+		ac.GeFmSyn(ctx, 0, nrn, 0, 0)
+		ramp := float32(1)
+		if cyc < 10 {
+			ramp = 0.2 + 0.8*float32(cyc)/10
+		}
+		assert.InDelta(t, ac.Clamp.Ge*ramp, nrn.Ge, 1.0e-6)
+		ctx.CycleInc()
+	}
+}
+
+func TestGABABDeplete(t *testing.T) {
+	lp := &LayerParams{}
+	lp.Defaults()
+	lp.Act.GABAB.Deplete.On.SetBool(true)
+	lp.Update()
+	lpNo := &LayerParams{}
+	lpNo.Defaults()
+	lpNo.Update()
+	assert.False(t, lpNo.Act.GABAB.Deplete.On.IsTrue()) // default off
+
+	ctx := NewContext()
+	vals := &LayerVals{}
+	vals.ActAvg.GiMult = 1
+	pl := &Pool{}
+	nrn := &Neuron{VmDend: 0.5}
+	nrnNo := &Neuron{VmDend: 0.5}
+	run := func(gi float32, ncyc int) {
+		pl.Inhib.Gi = gi
+		for cyc := 0; cyc < ncyc; cyc++ {
+			lp.GiInteg(ctx, 0, nrn, pl, vals)
+			lpNo.GiInteg(ctx, 0, nrnNo, pl, vals)
+		}
+	}
+
+	// sustained drive attenuates the response relative to no depletion
+	run(1, 200)
+	assert.Greater(t, nrn.GABABd, float32(0))
+	early := nrn.GgabaB / nrnNo.GgabaB
+	run(1, 1000)
+	late := nrn.GgabaB / nrnNo.GgabaB
+	assert.Less(t, late, early)
+	assert.Less(t, late, float32(0.5))
+
+	// recovers during silence
+	run(0, 3000)
+	assert.Less(t, nrn.GABABd, float32(0.01))
+	run(1, 1)
+	assert.InDelta(t, 1, nrn.GgabaB/nrnNo.GgabaB, 0.02)
+	assert.Equal(t, float32(0), nrnNo.GABABd)
+}
+
+func TestRegisterNeuronVar(t *testing.T) {
+	net := NewNetwork("NeuronVarTest")
+	ly := net.AddLayer2D("Layer", 2, 2, SuperLayer)
+	assert.NoError(t, net.Build())
+	net.Defaults()
+	net.InitWts()
+
+	assert.NoError(t, net.RegisterNeuronVar("GeMinusGi", func(ni uint32, nrn *Neuron) float32 {
+		return nrn.Ge - nrn.Gi
+	}))
+	assert.NoError(t, net.RegisterNeuronVar("Idx", func(ni uint32, nrn *Neuron) float32 {
+		return float32(ni)
+	}))
+	assert.Error(t, net.RegisterNeuronVar("Ge", nil))
+	assert.Error(t, net.RegisterNeuronVar("Idx", nil))
+	assert.Equal(t, len(NeuronVars)+2, len(net.UnitVarNames()))
+	assert.Equal(t, "GeMinusGi", ly.UnitVarNames()[len(NeuronVars)])
+
+	for ni := range ly.Neurons {
+		ly.Neurons[ni].Ge = float32(ni)
+		ly.Neurons[ni].Gi = 0.5
+	}
+	var vals []float32
+	assert.NoError(t, ly.UnitVals(&vals, "GeMinusGi"))
+	assert.Equal(t, []float32{-0.5, 0.5, 1.5, 2.5}, vals)
+
+	tsr := &etensor.Float32{}
+	assert.NoError(t, ly.UnitValsTensor(tsr, "Idx"))
+	assert.Equal(t, []float32{0, 1, 2, 3}, tsr.Values)
+
+	// standard layer-level vars are unaffected
+	ly.Vals.NeuroMod.DA = 0.3
+	assert.NoError(t, ly.UnitVals(&vals, "DA"))
+	assert.Equal(t, float32(0.3), vals[0])
+	assert.Error(t, ly.UnitVals(&vals, "NotAVar"))
+}
+
+func TestMaxRateHz(t *testing.T) {
+	run := func(maxHz float32) (nspk, minISI int) {
+		ac := ActParams{}
+		ac.Defaults()
+		ac.Spike.MaxRateHz = maxHz
+		ac.Update()
+		nrn := &Neuron{}
+		ac.InitActs(erand.NewSysRand(1), nrn)
+		minISI = 1000
+		last := -1
+		for cyc := 0; cyc < 1000; cyc++ { // 1 sec
+			nrn.Ge = 2 // very strong drive
+			ac.VmFmG(nrn)
+			ac.SpikeFmVm(nrn)
+			if nrn.Spike > 0 {
+				nspk++
+				if last >= 0 && cyc-last < minISI {
+					minISI = cyc - last
+				}
+				last = cyc
+			}
+		}
+		return
+	}
+	freeSpk, freeISI := run(0)
+	capSpk, capISI := run(100)
+	assert.Greater(t, freeSpk, 100)
+	assert.Less(t, freeISI, 10)
+	assert.LessOrEqual(t, capSpk, 100)
+	assert.Greater(t, capSpk, 50) // still firing near the cap
+	assert.GreaterOrEqual(t, capISI, 10)
+
+	sp := &SpikeParams{}
+	sp.Defaults()
+	assert.Equal(t, sp.Tr, sp.RefractCycs())
+	sp.MaxRateHz = 300 // less than Tr limit
+	assert.Equal(t, sp.Tr, sp.RefractCycs())
+	sp.MaxRateHz = 150 // 6.67 msec -> 7 cycle ISI
+	assert.Equal(t, int32(6), sp.RefractCycs())
+}
+
+func TestGeAlpha(t *testing.T) {
+	lpExp := &LayerParams{}
+	lpExp.Defaults()
+	lpExp.Update()
+	assert.Equal(t, GeExp, lpExp.Act.Dt.GeKind) // default
+	lpAlpha := &LayerParams{}
+	lpAlpha.Defaults()
+	lpAlpha.Act.Dt.GeKind = GeAlpha
+	lpAlpha.Update()
+
+	// EPSC in response to a single input spike at cycle 0, integrated
+	// as in GatherSpikes, for a single projection
+	epsc := func(lp *LayerParams, raw []float32) []float32 {
+		nrn := &Neuron{GeBase: 0.1}
+		gSyn := float32(0)
+		ges := make([]float32, len(raw))
+		for cyc, r := range raw {
+			lp.GatherSpikesInit(nrn)
+			gSyn = lp.Act.Dt.GeSynFmRaw(gSyn, r)
+			nrn.GeRaw += r
+			nrn.GeSyn += gSyn
+			lp.GatherSpikesAlpha(nrn)
+			ges[cyc] = nrn.GeSyn - nrn.GeBase
+		}
+		return ges
+	}
+	raw := make([]float32, 100)
+	raw[0] = 1
+	exp := epsc(lpExp, raw)
+	alpha := epsc(lpAlpha, raw)
+
+	peak := func(ges []float32) (int, float32) {
+		pi, pv := 0, ges[0]
+		for i, g := range ges {
+			if g > pv {
+				pi, pv = i, g
+			}
+		}
+		return pi, pv
+	}
+	sum := func(ges []float32) float32 {
+		s := float32(0)
+		for _, g := range ges {
+			s += g
+		}
+		return s
+	}
+	expPi, expPv := peak(exp)
+	alphaPi, alphaPv := peak(alpha)
+	assert.Equal(t, 0, expPi) // instantaneous rise
+	assert.InDelta(t, 1, expPv, 1.0e-6)
+	assert.InDelta(t, 0, alpha[0], 1.0e-6) // gradual rise
+	assert.Greater(t, alphaPi, 0)
+	assert.Less(t, alphaPv, expPv)
+	for _, g := range alpha {
+		assert.Greater(t, g, float32(-1.0e-6))
+	}
+	assert.Less(t, alpha[99], alphaPv*0.01) // decays
+	// same total conductance
+	assert.InDelta(t, sum(exp), sum(alpha), 0.01)
+
+	// slower rise peaks later
+	lpAlpha.Act.Dt.GeRiseTau = 3
+	lpAlpha.Update()
+	slowPi, slowPv := peak(epsc(lpAlpha, raw))
+	assert.Greater(t, slowPi, alphaPi)
+	assert.Less(t, slowPv, alphaPv)
+
+	// same steady state as exp
+	for i := range raw {
+		raw[i] = 0.1
+	}
+	exp = epsc(lpExp, raw)
+	alpha = epsc(lpAlpha, raw)
+	assert.InDelta(t, lpExp.Act.Dt.GeSynFmRawSteady(0.1), exp[99], 1.0e-4)
+	assert.InDelta(t, exp[99], alpha[99], 1.0e-4)
+}
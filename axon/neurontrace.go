@@ -0,0 +1,135 @@
+// Copyright (c) 2023, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package axon
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// NeuronSpec specifies a single neuron by the name of its layer
+// and its flat index within the layer.
+type NeuronSpec struct {
+	Layer string `desc:"name of the layer"`
+	Idx   int    `desc:"flat index of the neuron within the layer"`
+}
+
+// NeuronTrace records selected variables of selected neurons every
+// cycle into a table, with one row per cycle, for detailed analysis
+// of the full trajectory of single neurons.  The table has a Cycle
+// and CyclesTotal column from the Context, followed by a column for
+// each neuron and variable, named Layer_Idx_Var.
+// Configure with Network.TraceNeurons.
+type NeuronTrace struct {
+	On       bool          `desc:"whether recording is currently on -- see Start, Stop"`
+	Specs    []NeuronSpec  `desc:"the neurons being recorded"`
+	Vars     []string      `desc:"names of the neuron variables recorded"`
+	NeurIdxs []int         `view:"-" desc:"indexes of the Specs neurons in the global network Neurons list"`
+	VarIdxs  []int         `view:"-" desc:"indexes of the Vars in the NeuronVars list"`
+	Table    *etable.Table `view:"-" desc:"table of recorded values, with one row per cycle"`
+}
+
+// Start starts (or resumes) recording each cycle.
+func (tr *NeuronTrace) Start() {
+	tr.On = tr.Table != nil
+}
+
+// Stop stops recording, keeping the values recorded so far.
+func (tr *NeuronTrace) Stop() {
+	tr.On = false
+}
+
+// Reset removes all of the recorded rows from the table.
+func (tr *NeuronTrace) Reset() {
+	if tr.Table != nil {
+		tr.Table.SetNumRows(0)
+	}
+}
+
+// ColName returns the name of the table column for given neuron spec
+// and variable name.
+func (tr *NeuronTrace) ColName(ns NeuronSpec, varNm string) string {
+	return fmt.Sprintf("%s_%d_%s", ns.Layer, ns.Idx, varNm)
+}
+
+// Record adds a row to the table with the current values of the
+// variables for each neuron, if On.
+func (tr *NeuronTrace) Record(ctx *Context, neurs []Neuron) {
+	if !tr.On {
+		return
+	}
+	dt := tr.Table
+	row := dt.Rows
+	dt.AddRows(1)
+	dt.Cols[0].SetFloat1D(row, float64(ctx.Cycle))
+	dt.Cols[1].SetFloat1D(row, float64(ctx.CyclesTotal))
+	ci := 2
+	for _, ni := range tr.NeurIdxs {
+		nrn := &neurs[ni]
+		for _, vidx := range tr.VarIdxs {
+			dt.Cols[ci].SetFloat1D(row, float64(nrn.VarByIndex(vidx)))
+			ci++
+		}
+	}
+}
+
+// TraceNeurons configures the recording of given neuron variables for
+// given neurons every cycle into the NeurTrace table (see NeuronTrace),
+// and starts recording.  Any prior record is discarded, and passing
+// no specs turns it off.  Use NeurTrace.Stop and Start to control the
+// recording, e.g., to only record selected trials.  Returns an error
+// if a layer, neuron index, or variable is not valid.
+// When running on the GPU, Neurons must be synced from the GPU
+// and NeurTrace.Record called manually each cycle.
+func (nt *Network) TraceNeurons(specs []NeuronSpec, vars []string) error {
+	tr := &nt.NeurTrace
+	*tr = NeuronTrace{}
+	if len(specs) == 0 {
+		return nil
+	}
+	vidxs := make([]int, len(vars))
+	for i, vn := range vars {
+		vi, err := NeuronVarIdxByName(vn)
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+		vidxs[i] = vi
+	}
+	nidxs := make([]int, len(specs))
+	sch := etable.Schema{
+		{Name: "Cycle", Type: etensor.INT64},
+		{Name: "CyclesTotal", Type: etensor.INT64},
+	}
+	for i, ns := range specs {
+		ly := nt.AxonLayerByName(ns.Layer)
+		if ly == nil {
+			err := fmt.Errorf("TraceNeurons: layer %s not found in network %s", ns.Layer, nt.Nm)
+			log.Println(err)
+			return err
+		}
+		if ns.Idx < 0 || ns.Idx >= len(ly.Neurons) {
+			err := fmt.Errorf("TraceNeurons: neuron index %d out of range for layer %s", ns.Idx, ns.Layer)
+			log.Println(err)
+			return err
+		}
+		nidxs[i] = ly.NeurStIdx + ns.Idx
+		for _, vn := range vars {
+			sch = append(sch, etable.Column{Name: tr.ColName(ns, vn), Type: etensor.FLOAT32})
+		}
+	}
+	tr.Specs = specs
+	tr.Vars = vars
+	tr.NeurIdxs = nidxs
+	tr.VarIdxs = vidxs
+	tr.Table = &etable.Table{}
+	tr.Table.SetMetaData("name", "NeuronTrace")
+	tr.Table.SetFromSchema(sch, 0)
+	tr.Start()
+	return nil
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2023, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package axon
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// OpenPatsNPY reads a NumPy .npy array file into given column of the
+// table, as a float32 tensor column, with the first (outer) dimension of
+// the array as the rows of the table and the remaining dimensions as the
+// shape of each cell: e.g., a (25, 5, 5) array gives 25 rows of 5x5
+// patterns, as used for the input patterns of a layer.  Any existing
+// column of that name is replaced, and if the table is empty, its number
+// of rows is set from the array -- otherwise the array must have the
+// same number of rows as the table.  Supports C-ordered arrays of float,
+// int, uint and bool types in either byte order, as written by numpy.save.
+func OpenPatsNPY(dt *etable.Table, filename string, colName string) error {
+	fp, err := os.Open(filename)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	defer fp.Close()
+	shape, vals, err := ReadNPY(bufio.NewReader(fp))
+	if err != nil {
+		err = fmt.Errorf("OpenPatsNPY: file %s: %w", filename, err)
+		log.Println(err)
+		return err
+	}
+	if len(shape) == 0 {
+		err = fmt.Errorf("OpenPatsNPY: file %s: array must have at least 1 dimension", filename)
+		log.Println(err)
+		return err
+	}
+	rows := shape[0]
+	has := dt.ColByName(colName) != nil
+	others := dt.NumCols()
+	if has {
+		others--
+	}
+	if others > 0 && dt.Rows != rows {
+		err = fmt.Errorf("OpenPatsNPY: file %s has %d rows, but the table has %d", filename, rows, dt.Rows)
+		log.Println(err)
+		return err
+	}
+	if has {
+		dt.DeleteColName(colName)
+	}
+	if others == 0 {
+		dt.SetNumRows(rows)
+	}
+	tsr := etensor.NewFloat32(shape, nil, nil)
+	copy(tsr.Values, vals)
+	return dt.AddCol(tsr, colName)
+}
+
+var (
+	npyDescrRe = regexp.MustCompile(`'descr'\s*:\s*'([^']*)'`)
+	npyFortRe  = regexp.MustCompile(`'fortran_order'\s*:\s*(True|False)`)
+	npyShapeRe = regexp.MustCompile(`'shape'\s*:\s*\(([^)]*)\)`)
+)
+
+// ReadNPY reads a NumPy .npy format array from given reader, returning
+// the shape of the array and its values in row-major (C) order,
+// converted to float32.  See OpenPatsNPY for supported types.
+func ReadNPY(r io.Reader) (shape []int, vals []float32, err error) {
+	magic := make([]byte, 8)
+	if _, err = io.ReadFull(r, magic); err != nil {
+		return
+	}
+	if !bytes.Equal(magic[:6], []byte("\x93NUMPY")) {
+		err = fmt.Errorf("not a NumPy .npy file")
+		return
+	}
+	var hlen int
+	switch magic[6] {
+	case 1:
+		var hl uint16
+		err = binary.Read(r, binary.LittleEndian, &hl)
+		hlen = int(hl)
+	case 2, 3:
+		var hl uint32
+		err = binary.Read(r, binary.LittleEndian, &hl)
+		hlen = int(hl)
+	default:
+		err = fmt.Errorf("unsupported .npy format version: %d", magic[6])
+	}
+	if err != nil {
+		return
+	}
+	hdr := make([]byte, hlen)
+	if _, err = io.ReadFull(r, hdr); err != nil {
+		return
+	}
+	descr := npyDescrRe.FindSubmatch(hdr)
+	fort := npyFortRe.FindSubmatch(hdr)
+	shp := npyShapeRe.FindSubmatch(hdr)
+	if descr == nil || fort == nil || shp == nil {
+		err = fmt.Errorf("invalid .npy header: %s", string(hdr))
+		return
+	}
+	if string(fort[1]) == "True" {
+		err = fmt.Errorf("fortran_order arrays are not supported")
+		return
+	}
+	n := 1
+	for _, ds := range strings.Split(string(shp[1]), ",") {
+		ds = strings.TrimSpace(ds)
+		if ds == "" {
+			continue
+		}
+		var d int
+		d, err = strconv.Atoi(ds)
+		if err != nil {
+			return
+		}
+		shape = append(shape, d)
+		n *= d
+	}
+
+	dtype := string(descr[1])
+	if len(dtype) < 3 {
+		err = fmt.Errorf("invalid .npy dtype: %s", dtype)
+		return
+	}
+	var bo binary.ByteOrder = binary.LittleEndian
+	if dtype[0] == '>' {
+		bo = binary.BigEndian
+	}
+	kind := dtype[1]
+	size, err := strconv.Atoi(dtype[2:])
+	if err != nil {
+		return
+	}
+	data := make([]byte, n*size)
+	if _, err = io.ReadFull(r, data); err != nil {
+		return
+	}
+	vals = make([]float32, n)
+	for i := range vals {
+		b := data[i*size : (i+1)*size]
+		switch {
+		case kind == 'f' && size == 4:
+			vals[i] = math.Float32frombits(bo.Uint32(b))
+		case kind == 'f' && size == 8:
+			vals[i] = float32(math.Float64frombits(bo.Uint64(b)))
+		case (kind == 'u' || kind == 'b') && size == 1:
+			vals[i] = float32(b[0])
+		case kind == 'u' && size == 2:
+			vals[i] = float32(bo.Uint16(b))
+		case kind == 'u' && size == 4:
+			vals[i] = float32(bo.Uint32(b))
+		case kind == 'u' && size == 8:
+			vals[i] = float32(bo.Uint64(b))
+		case kind == 'i' && size == 1:
+			vals[i] = float32(int8(b[0]))
+		case kind == 'i' && size == 2:
+			vals[i] = float32(int16(bo.Uint16(b)))
+		case kind == 'i' && size == 4:
+			vals[i] = float32(int32(bo.Uint32(b)))
+		case kind == 'i' && size == 8:
+			vals[i] = float32(int64(bo.Uint64(b)))
+		default:
+			err = fmt.Errorf("unsupported .npy dtype: %s", dtype)
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2023, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package axon
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/goki/mat32"
+)
+
+// CheckNumerics scans the neuron Vm, Ge, Gi, Act values and the synapse Wt
+// values in the network for NaN or Inf values, returning a list of
+// identifiers for those that have them, of the form Layer[ni].Var for
+// neurons, and Recv<-Send[ri,si].Wt for synapses (layer-specific indexes).
+// Returns nil if all values are finite.  Layers and projections that are
+// Off are skipped.  When running on the GPU, call GPU.SyncNeuronsFmGPU and
+// GPU.SyncSynapsesFmGPU first to get the current values.
+func (nt *Network) CheckNumerics() []string {
+	bad := nt.checkNeuronNumerics()
+	nt.ForEachSynapse(func(ly *Layer, pj *Prjn, sy *Synapse, si, ri int) {
+		if notFinite(sy.Wt) {
+			bad = append(bad, fmt.Sprintf("%s<-%s[%d,%d].Wt: %g", ly.Nm, pj.Send.Nm, ri, si, sy.Wt))
+		}
+	})
+	return bad
+}
+
+// checkNeuronNumerics is the neuron-level part of CheckNumerics,
+// which is cheap enough to run every cycle for the NumericsGuard.
+func (nt *Network) checkNeuronNumerics() []string {
+	var bad []string
+	for _, ly := range nt.Layers {
+		if ly.IsOff() {
+			continue
+		}
+		for ni := range ly.Neurons {
+			nrn := &ly.Neurons[ni]
+			vals := [...]float32{nrn.Vm, nrn.Ge, nrn.Gi, nrn.Act}
+			for vi, vnm := range [...]string{"Vm", "Ge", "Gi", "Act"} {
+				if notFinite(vals[vi]) {
+					bad = append(bad, fmt.Sprintf("%s[%d].%s: %g", ly.Nm, ni, vnm, vals[vi]))
+				}
+			}
+		}
+	}
+	return bad
+}
+
+// numericsGuardCycle is called after each Cycle when NumericsGuard is on,
+// checking the neuron values and recording and reporting any NaN / Inf
+// values in NumericsErrs, which stops further updating in Cycle.
+func (nt *Network) numericsGuardCycle(ctx *Context) {
+	if nt.GPU.On {
+		nt.GPU.SyncNeuronsFmGPU()
+	}
+	bad := nt.checkNeuronNumerics()
+	if len(bad) == 0 {
+		return
+	}
+	nt.NumericsErrs = bad
+	log.Printf("NumericsGuard: network %s stopped at cycle %d (total: %d) due to non-finite values:\n\t%s\n", nt.Nm, ctx.Cycle, ctx.CyclesTotal, strings.Join(bad, "\n\t"))
+}
+
+// notFinite returns true if given value is NaN or Inf
+func notFinite(v float32) bool {
+	return mat32.IsNaN(v) || mat32.IsInf(v, 0)
+}
@@ -197,6 +197,20 @@ func (ly *Layer) MatrixGated(ctx *Context) bool {
 	return mtxGated
 }
 
+// MatrixGatedTrls updates the GatedTrls count of trials since each pool
+// last gated, based on the current Gated state, for the MatrixPrjn
+// CreditWindow.  Called at the end of the plus phase after MatrixGated.
+func (ly *Layer) MatrixGatedTrls() {
+	for pi := range ly.Pools {
+		pl := &ly.Pools[pi]
+		if pl.Gated.IsTrue() {
+			pl.GatedTrls = 0
+		} else {
+			pl.GatedTrls++
+		}
+	}
+}
+
 // GatedFmSpkMax updates the Gated state in Pools of given layer,
 // based on Avg SpkMax being above given threshold.
 // returns true if any gated.
@@ -228,6 +242,88 @@ func (ly *Layer) AnyGated() bool {
 	return ly.Pools[0].Gated.IsTrue()
 }
 
+// PoolGated returns the Gated state of each sub-pool (stripe) as last
+// computed by MatrixGated / GatedFmSpkMax, in pool order, for multi-pool
+// (4D) BG layers, or a single layer-level value for layers without
+// sub-pools.  NoGo (D2) MatrixLayers don't track gating at the sub-pool
+// level, so the state of the corresponding Go layer is returned for them.
+// When running on the GPU, call GPU.SyncPoolsFmGPU first.
+func (ly *Layer) PoolGated() []bool {
+	gly := ly
+	if ly.LayerType() == MatrixLayer && ly.Params.Learn.NeuroMod.DAMod != D1Mod && ly.Params.Matrix.OtherMatrixIdx >= 0 {
+		gly = ly.Network.Layers[int(ly.Params.Matrix.OtherMatrixIdx)]
+	}
+	if len(gly.Pools) == 1 {
+		return []bool{gly.Pools[0].Gated.IsTrue()}
+	}
+	gated := make([]bool, len(gly.Pools)-1)
+	for pi := 1; pi < len(gly.Pools); pi++ {
+		gated[pi-1] = gly.Pools[pi].Gated.IsTrue()
+	}
+	return gated
+}
+
+// GatedPools returns the indexes of the sub-pools (stripes) that gated,
+// as given by PoolGated, starting at 0 for the first sub-pool.
+// Returns an empty slice if nothing gated.
+func (ly *Layer) GatedPools() []int {
+	var gps []int
+	for pi, g := range ly.PoolGated() {
+		if g {
+			gps = append(gps, pi)
+		}
+	}
+	return gps
+}
+
+// MatrixActionValues returns the action values encoded by a pair of BG
+// MatrixLayer Go (D1) and NoGo (D2) layers, as the difference between
+// the Go and NoGo pool-average SpkMax for each sub-pool (stripe),
+// which represents a separate action, in pool order (a single value for
+// layers without sub-pools).  This is the same activity measure used
+// to determine gating (see GatedFmSpkMax), so it is typically called
+// after the minus phase.  Can be called on either the Go or the NoGo
+// layer, and returns nil if this is not a MatrixLayer.
+// When running on the GPU, call GPU.SyncPoolsFmGPU first.
+func (ly *Layer) MatrixActionValues() []float32 {
+	if ly.LayerType() != MatrixLayer {
+		log.Printf("MatrixActionValues: layer %s is not a MatrixLayer\n", ly.Nm)
+		return nil
+	}
+	goLy := ly
+	noLy := ly.Network.Layers[int(ly.Params.Matrix.OtherMatrixIdx)]
+	if ly.Params.Learn.NeuroMod.DAMod != D1Mod {
+		goLy, noLy = noLy, goLy
+	}
+	st := 1
+	if len(goLy.Pools) == 1 {
+		st = 0
+	}
+	vals := make([]float32, len(goLy.Pools)-st)
+	for pi := st; pi < len(goLy.Pools); pi++ {
+		val := goLy.Pools[pi].AvgMax.SpkMax.Cycle.Avg
+		if pi < len(noLy.Pools) {
+			val -= noLy.Pools[pi].AvgMax.SpkMax.Cycle.Avg
+		}
+		vals[pi-st] = val
+	}
+	return vals
+}
+
+// MatrixArgMax returns the index of the action with the highest
+// MatrixActionValues value, i.e., the action that would be selected,
+// or -1 if this is not a MatrixLayer.
+func (ly *Layer) MatrixArgMax() int {
+	vals := ly.MatrixActionValues()
+	mxi := -1
+	for i, v := range vals {
+		if mxi < 0 || v > vals[mxi] {
+			mxi = i
+		}
+	}
+	return mxi
+}
+
 func (ly *Layer) MatrixPostBuild() {
 	ly.Params.Matrix.ThalLay1Idx = ly.BuildConfigFindLayer("ThalLay1Name", false) // optional
 	ly.Params.Matrix.ThalLay2Idx = ly.BuildConfigFindLayer("ThalLay2Name", false) // optional
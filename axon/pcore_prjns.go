@@ -4,7 +4,17 @@
 
 package axon
 
-import "github.com/goki/gosl/slbool"
+import (
+	"github.com/goki/gosl/slbool"
+	"github.com/goki/ki/kit"
+)
+
+//go:generate stringer -type=DAClampModes
+
+var KiT_DAClampModes = kit.Enums.AddEnum(DAClampModesN, kit.NotBitFlag, nil)
+
+func (ev DAClampModes) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *DAClampModes) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
 
 //gosl: start pcore_prjns
 
@@ -14,10 +24,14 @@ import "github.com/goki/gosl/slbool"
 // and subsequent activity, and is based biologically on synaptic tags.
 // Trace is reset at time of reward based on ACh level from CINs.
 type MatrixPrjnParams struct {
-	CurTrlDA    slbool.Bool `def:"true" desc:"if true, current trial DA dopamine can drive learning (i.e., synaptic co-activity trace is updated prior to DA-driven dWt), otherwise DA is applied to existing trace before trace is updated, meaning that at least one trial must separate gating activity and DA"`
-	NoGateLRate float32     `def:"0,0.005" desc:"learning rate for when no gating took place, in proportion to the level of ACh that indicates the salience of the event.  A low level of this learning prevents the highly maladaptive situation where the BG is not gating and thus no learning can occur."`
-	AChDecay    float32     `def:"2" min:"0" desc:"multiplier on CIN ACh level for decaying prior traces -- decay never exceeds 1, so a larger number ensures complete decay with lower ACh levels."`
-	UseHasRew   slbool.Bool `desc:"use Context.HasRew as a unique learning and clearing signal -- if set, then AChDecay is not used.  This is mainly for debugging -- ACh is the appropriate biological mechanism."`
+	CurTrlDA     slbool.Bool `def:"true" desc:"if true, current trial DA dopamine can drive learning (i.e., synaptic co-activity trace is updated prior to DA-driven dWt), otherwise DA is applied to existing trace before trace is updated, meaning that at least one trial must separate gating activity and DA"`
+	NoGateLRate  float32     `def:"0,0.005" desc:"learning rate for when no gating took place, in proportion to the level of ACh that indicates the salience of the event.  A low level of this learning prevents the highly maladaptive situation where the BG is not gating and thus no learning can occur."`
+	AChDecay     float32     `def:"2" min:"0" desc:"multiplier on CIN ACh level for decaying prior traces -- decay never exceeds 1, so a larger number ensures complete decay with lower ACh levels."`
+	UseHasRew    slbool.Bool `desc:"use Context.HasRew as a unique learning and clearing signal -- if set, then AChDecay is not used.  This is mainly for debugging -- ACh is the appropriate biological mechanism."`
+	CreditWindow int32       `def:"0" min:"0" desc:"if > 0, the number of trials over which gating activity can receive credit from subsequent dopamine: the trace is cleared once the layer has not gated for this many trials, so a window of 1 only credits gating on the same trial as the DA.  0 = no limit, with the trace only cleared via AChDecay."`
+	CreditDecay  float32     `def:"0" min:"0" max:"1" desc:"proportion of the trace that decays on each trial, so that gating on earlier trials within the CreditWindow receives exponentially less credit from dopamine.  0 = no per-trial decay."`
+
+	pad, pad1 float32
 }
 
 func (tp *MatrixPrjnParams) Defaults() {
@@ -29,6 +43,12 @@ func (tp *MatrixPrjnParams) Defaults() {
 func (tp *MatrixPrjnParams) Update() {
 }
 
+// InCreditWindow returns false if the trace is outside of the
+// CreditWindow, given the number of trials since the layer last gated.
+func (tp *MatrixPrjnParams) InCreditWindow(gatedTrls int32) bool {
+	return tp.CreditWindow <= 0 || gatedTrls < tp.CreditWindow
+}
+
 // TraceDecay returns the decay factor as a function of ach level and context
 func (tp *MatrixPrjnParams) TraceDecay(ctx *Context, ach float32) float32 {
 	if tp.UseHasRew.IsTrue() {
@@ -41,6 +61,64 @@ func (tp *MatrixPrjnParams) TraceDecay(ctx *Context, ach float32) float32 {
 	return dk
 }
 
+// DAClampModes are ways of treating the dopamine-driven weight changes
+// when dopamine is negative (a dip), in DWtDAClampParams.
+type DAClampModes int32
+
+const (
+	// DAClampAllow allows negative-DA weight changes without any change.
+	DAClampAllow DAClampModes = iota
+
+	// DAClampFloor limits the magnitude of negative-DA weight changes
+	// to the Floor value -- a Floor of 0 turns off learning from dips.
+	DAClampFloor
+
+	// DAClampScale multiplies negative-DA weight changes by the Scale factor.
+	DAClampScale
+
+	DAClampModesN
+)
+
+// DWtDAClampParams control the dopamine-driven weight changes in
+// MatrixPrjn and VSPatchPrjn projections when dopamine is negative,
+// to give explicit control over learning from dips relative to bursts.
+type DWtDAClampParams struct {
+	Mode  DAClampModes `desc:"how to treat weight changes when DA is negative"`
+	Floor float32      `viewif:"Mode=DAClampFloor" def:"0.01" min:"0" desc:"maximum magnitude of weight change when DA is negative, for DAClampFloor mode"`
+	Scale float32      `viewif:"Mode=DAClampScale" def:"0.5" min:"0" desc:"multiplier on weight changes when DA is negative, for DAClampScale mode"`
+
+	pad float32
+}
+
+func (dc *DWtDAClampParams) Defaults() {
+	dc.Mode = DAClampAllow
+	dc.Floor = 0.01
+	dc.Scale = 0.5
+}
+
+func (dc *DWtDAClampParams) Update() {
+}
+
+// DWt returns the dopamine-driven weight change dwt, clamped
+// according to the Mode if da is negative.
+func (dc *DWtDAClampParams) DWt(da, dwt float32) float32 {
+	if da >= 0 {
+		return dwt
+	}
+	switch dc.Mode {
+	case DAClampFloor:
+		if dwt > dc.Floor {
+			return dc.Floor
+		}
+		if dwt < -dc.Floor {
+			return -dc.Floor
+		}
+	case DAClampScale:
+		return dc.Scale * dwt
+	}
+	return dwt
+}
+
 //gosl: end pcore_pjrns
 
 func (pj *PrjnParams) MatrixDefaults() {
@@ -166,8 +166,13 @@ type Pool struct {
 	PoolIdx      uint32      `view:"-" desc:"pool index in global pool list: [Layer][Pool]"`
 	IsLayPool    slbool.Bool `inactive:"+" desc:"is this a layer-wide pool?  if not, it represents a sub-pool of units within a 4D layer"`
 	Gated        slbool.Bool `inactive:"+" desc:"for special types where relevant (e.g., MatrixLayer, VThalLayer), indicates if the pool was gated"`
+	BurstThr     float32     `inactive:"+" desc:"for SuperLayer with Burst.ThrMode = BurstThrPctile, the threshold on CaSpkP for Burst, computed as a percentile of CaSpkP values in the pool at the start of the plus phase"`
+	GatedTrls    int32       `inactive:"+" desc:"for MatrixLayer, the number of trials since the pool last gated, updated at the end of each plus phase -- 0 on a trial when it gated.  Used for the MatrixPrjn Matrix.CreditWindow."`
 
-	pad, pad1 uint32
+	GiClamp    slbool.Bool `inactive:"+" desc:"if true, Inhib.Gi is clamped to GiClampVal every cycle, instead of the computed FS-FFFB value -- see Layer.ClampPoolGi"`
+	GiClampVal float32     `inactive:"+" desc:"value that Inhib.Gi is clamped to when GiClamp is set"`
+
+	pad1, pad2 uint32
 
 	Inhib  fsfffb.Inhib `inactive:"+" desc:"fast-slow FFFB inhibition values"`
 	AvgMax PoolAvgMax   `desc:"average and max values for relevant variables in this pool, at different time scales"`
@@ -182,6 +187,7 @@ func (pl *Pool) Init() {
 	pl.AvgDif.N = int32(pl.NNeurons())
 	pl.AvgDif.Init()
 	pl.Gated.SetBool(false)
+	pl.GatedTrls = 0
 }
 
 // NNeurons returns the number of neurons in the pool: EdIdx - StIdx
@@ -189,6 +195,14 @@ func (pl *Pool) NNeurons() int {
 	return int(pl.EdIdx - pl.StIdx)
 }
 
+// ClampGi sets Inhib.Gi to GiClampVal if GiClamp is set.
+// Called after the pool inhibition has been computed.
+func (pl *Pool) ClampGi() {
+	if pl.GiClamp.IsTrue() {
+		pl.Inhib.Gi = pl.GiClampVal
+	}
+}
+
 //gosl: end pool
 
 /* todo: fixme below -- dumping this here so layer is clean
@@ -7,6 +7,8 @@ package axon
 import (
 	"fmt"
 	"io"
+	"log"
+	"sort"
 	"strconv"
 
 	"github.com/emer/emergent/erand"
@@ -57,6 +59,9 @@ func (pj *Prjn) Defaults() {
 	switch pj.PrjnType() {
 	case InhibPrjn:
 		pj.Params.SWt.Adapt.On.SetBool(false)
+		if pj.Send.LayerType() == InhibInterneuronLayer {
+			pj.Params.InhibLearn.On.SetBool(true)
+		}
 	case RWPrjn, TDPredPrjn:
 		pj.Params.RLPredPrjnDefaults()
 	case BLAAcqPrjn:
@@ -67,6 +72,11 @@ func (pj *Prjn) Defaults() {
 		pj.Params.VSPatchPrjnDefaults()
 	case MatrixPrjn:
 		pj.Params.MatrixDefaults()
+	case IdentityPrjn:
+		pj.Params.IdentityPrjnDefaults()
+	}
+	if pj.SelfMaint && pj.IsSelf() {
+		pj.Params.SelfMaintDefaults(pj.SelfMaintAbs)
 	}
 }
 
@@ -113,6 +123,199 @@ func (pj *Prjn) SetSynVal(varNm string, sidx, ridx int, val float32) error {
 	return nil
 }
 
+// TraceSnapshot returns a copy of the current Tr eligibility trace value
+// for each synapse, in the natural recv-based ordering of synapses as in
+// SynVals.  This can be compared with DWtSnapshot to see how the traces
+// drive the resulting weight changes, for debugging credit assignment.
+// When running on the GPU, call GPU.SyncSynapsesFmGPU first.
+func (pj *Prjn) TraceSnapshot() []float32 {
+	trs := make([]float32, len(pj.Syns))
+	for si := range pj.Syns {
+		trs[si] = pj.Syns[si].Tr
+	}
+	return trs
+}
+
+// DWtSnapshot returns a copy of the current DWt weight change value
+// for each synapse, in the same ordering as TraceSnapshot.
+// When running on the GPU, call GPU.SyncSynapsesFmGPU first.
+func (pj *Prjn) DWtSnapshot() []float32 {
+	dwts := make([]float32, len(pj.Syns))
+	for si := range pj.Syns {
+		dwts[si] = pj.Syns[si].DWt
+	}
+	return dwts
+}
+
+// WtDecomp returns copies of the structural SWt, learned LWt, and overall
+// Wt weight values for each synapse, in the same ordering as TraceSnapshot.
+// Wt = SWt * SigFmLinWt(LWt), so the LWt multiplicatively modulates
+// the SWt, and Wt = SWt when LWt is at its neutral value of 0.5.
+// When running on the GPU, call GPU.SyncSynapsesFmGPU first.
+func (pj *Prjn) WtDecomp() (swt, lwt, wt []float32) {
+	ns := len(pj.Syns)
+	swt = make([]float32, ns)
+	lwt = make([]float32, ns)
+	wt = make([]float32, ns)
+	for si := range pj.Syns {
+		sy := &pj.Syns[si]
+		swt[si] = sy.SWt
+		lwt[si] = sy.LWt
+		wt[si] = sy.Wt
+	}
+	return
+}
+
+// SynCaVals returns copies of the synaptic kinase calcium state CaM, CaP,
+// and CaD for each synapse, in the same ordering as TraceSnapshot.
+// This is for validating the in-network integration against the
+// standalone kinase equations, e.g., as explored in examples/kinaseq.
+// Synaptic Ca is only updated when the sending or receiving neuron
+// spikes, so the values are as of the last update time in CaUpT:
+// use Params.Learn.KinaseCa.CurCa to decay them to the current time.
+// When running on the GPU, call GPU.SyncSynapsesFmGPU first.
+func (pj *Prjn) SynCaVals() (caM, caP, caD []float32) {
+	ns := len(pj.Syns)
+	caM = make([]float32, ns)
+	caP = make([]float32, ns)
+	caD = make([]float32, ns)
+	for si := range pj.Syns {
+		sy := &pj.Syns[si]
+		caM[si] = sy.CaM
+		caP[si] = sy.CaP
+		caD[si] = sy.CaD
+	}
+	return
+}
+
+// LWtContribution returns the fraction of the variance in the weights
+// that is due to the learned LWt component, computed as
+// Var(Wt - SWt) / (Var(SWt) + Var(Wt - SWt)), where Wt - SWt is the
+// learned deviation from the structural weight (see WtDecomp).
+// This is 0 when the LWt weights are all neutral, and approaches 1
+// when the weights are dominated by learning.  Returns 0 if there
+// is no variance in either component.
+// When running on the GPU, call GPU.SyncSynapsesFmGPU first.
+func (pj *Prjn) LWtContribution() float32 {
+	ns := len(pj.Syns)
+	if ns == 0 {
+		return 0
+	}
+	var sSum, lSum float64
+	for si := range pj.Syns {
+		sy := &pj.Syns[si]
+		sSum += float64(sy.SWt)
+		lSum += float64(sy.Wt - sy.SWt)
+	}
+	sMean := sSum / float64(ns)
+	lMean := lSum / float64(ns)
+	var sVar, lVar float64
+	for si := range pj.Syns {
+		sy := &pj.Syns[si]
+		sd := float64(sy.SWt) - sMean
+		ld := float64(sy.Wt-sy.SWt) - lMean
+		sVar += sd * sd
+		lVar += ld * ld
+	}
+	if sVar+lVar == 0 {
+		return 0
+	}
+	return float32(lVar / (sVar + lVar))
+}
+
+// SetScaleSchedule sets a schedule of gain multipliers on the effective
+// GScale.Scale conductance scaling of this projection as a function of
+// the cycle within the trial (ctx.Cycle), applied in SendSpike: each gain
+// takes effect at the given cycle and remains in effect until the next
+// scheduled cycle, with a gain of 1 prior to the first one.  For example,
+// {0: 0, 150: 1} gates the projection on only in the plus phase.
+// Passing nil or an empty map turns off the schedule.
+// Gains must be >= 0, as the conductance buffers only hold non-negative
+// values.  Only works on the CPU: returns an error if the network is
+// running on the GPU, and GPU.Config warns about any schedules that
+// were set before it.  The schedule is unchanged if an error is returned.
+func (pj *Prjn) SetScaleSchedule(cycGains map[int]float32) error {
+	if nt := pj.Recv.Network; nt != nil && nt.GPU.On {
+		err := fmt.Errorf("Prjn %s SetScaleSchedule: not supported when running on the GPU", pj.Name())
+		log.Println(err)
+		return err
+	}
+	cycs := make([]int, 0, len(cycGains))
+	for cyc, gain := range cycGains {
+		if gain < 0 {
+			err := fmt.Errorf("Prjn %s SetScaleSchedule: gain must be >= 0, got: %g at cycle %d", pj.Name(), gain, cyc)
+			log.Println(err)
+			return err
+		}
+		cycs = append(cycs, cyc)
+	}
+	pj.ScaleSchedCycs = nil
+	pj.ScaleSchedGains = nil
+	if len(cycs) == 0 {
+		return nil
+	}
+	sort.Ints(cycs)
+	for _, cyc := range cycs {
+		pj.ScaleSchedCycs = append(pj.ScaleSchedCycs, int32(cyc))
+		pj.ScaleSchedGains = append(pj.ScaleSchedGains, cycGains[cyc])
+	}
+	return nil
+}
+
+// ScaleSchedGain returns the gain multiplier from the SetScaleSchedule
+// schedule in effect at given cycle within the trial (1 if none).
+func (pj *Prjn) ScaleSchedGain(cyc int32) float32 {
+	gain := float32(1)
+	for i, sc := range pj.ScaleSchedCycs {
+		if sc > cyc {
+			break
+		}
+		gain = pj.ScaleSchedGains[i]
+	}
+	return gain
+}
+
+// TrackWtAge turns on or off the tracking of when each synapse last
+// changed its weight, recorded in LastDWtCyc by WtFmDWt, for analyzing
+// whether learning has converged -- see WtAgeStats.  Tracking is reset
+// to -1 = never changed when turned on, and in InitWts.
+// Only works on the CPU: the GPU does not update LastDWtCyc.
+func (pj *Prjn) TrackWtAge(on bool) {
+	if !on {
+		pj.LastDWtCyc = nil
+		return
+	}
+	pj.LastDWtCyc = make([]int32, len(pj.Syns))
+	pj.InitWtAge()
+}
+
+// InitWtAge resets the LastDWtCyc weight age tracking values, if on.
+func (pj *Prjn) InitWtAge() {
+	for si := range pj.LastDWtCyc {
+		pj.LastDWtCyc[si] = -1
+	}
+}
+
+// WtAgeStats returns the fraction of synapses whose weights changed
+// within the given window of cycles prior to the current
+// ctx.CyclesTotal, based on the LastDWtCyc values recorded when
+// TrackWtAge is on (returns 0 if not).  A low value late in training
+// indicates that learning has effectively converged, while a high
+// value indicates ongoing churning of the weights.
+func (pj *Prjn) WtAgeStats(ctx *Context, window int32) (fracRecent float32) {
+	n := len(pj.LastDWtCyc)
+	if n == 0 {
+		return 0
+	}
+	nrecent := 0
+	for _, cyc := range pj.LastDWtCyc {
+		if cyc >= 0 && ctx.CyclesTotal-cyc <= window {
+			nrecent++
+		}
+	}
+	return float32(nrecent) / float32(n)
+}
+
 ///////////////////////////////////////////////////////////////////////
 //  Weights File
 
@@ -339,6 +542,10 @@ func (pj *Prjn) InitWts(nt *Network) {
 		spct = 0
 	}
 	smn := pj.Params.SWt.Init.Mean
+	var rnd erand.Rand = &nt.Rand
+	if pj.InitSeed != 0 {
+		rnd = erand.NewSysRand(pj.InitSeed)
+	}
 	for ri := range rlay.Neurons {
 		nrn := &rlay.Neurons[ri]
 		if nrn.IsOff() {
@@ -347,12 +554,49 @@ func (pj *Prjn) InitWts(nt *Network) {
 		syns := pj.RecvSyns(ri)
 		for ci := range syns {
 			sy := &syns[ci]
-			pj.InitWtsSyn(&nt.Rand, sy, smn, spct)
+			pj.InitWtsSyn(rnd, sy, smn, spct)
 		}
 	}
 	if pj.Params.SWt.Adapt.On.IsTrue() && !rlay.Params.IsTarget() {
 		pj.SWtRescale()
 	}
+	pj.InitWtAge()
+}
+
+// BreakSymmetry adds small random offsets, uniformly distributed in the
+// range +/- amount, to the initial structural weight (SWt) of each synapse,
+// using the network Rand generator, so that otherwise identical receiving
+// units get distinct incoming weights and can differentiate in learning.
+// The weight Wt is then recomputed from the SWt and current LWt.
+// Call after InitWts.  Syncs the synapses to the GPU if it is on.
+func (pj *Prjn) BreakSymmetry(amount float32) {
+	if amount <= 0 {
+		return
+	}
+	nt := pj.Recv.Network
+	for si := range pj.Syns {
+		sy := &pj.Syns[si]
+		sy.SWt = pj.Params.SWt.ClipSWt(sy.SWt + amount*(2*nt.Rand.Float32(-1)-1))
+		sy.Wt = pj.Params.SWt.WtVal(sy.SWt, sy.LWt)
+	}
+	nt.GPU.SyncSynapsesToGPU()
+}
+
+// InitWtsSeeded initializes the weights of this projection from an
+// independent random number stream with given seed, which is saved in
+// InitSeed so that subsequent network InitWts calls also use it.
+// Thus, the initial weights of this projection are the same regardless
+// of the other projections in the network, and they do not affect the
+// weights of other projections, which aids reproducibility as a model
+// is developed incrementally.  A seed of 0 reverts to using the shared
+// network random number generator.  Weight symmetry (SWt.Init.Sym) is
+// not enforced by this call, but is by the network InitWts.
+func (pj *Prjn) InitWtsSeeded(seed int64) {
+	pj.InitSeed = seed
+	nt := pj.Recv.Network
+	pj.InitWts(nt)
+	nt.GPU.SyncSynapsesToGPU()
+	nt.GPU.SyncGBufToGPU()
 }
 
 // SWtRescale rescales the SWt values to preserve the target overall mean value,
@@ -515,6 +759,166 @@ func (pj *Prjn) InitGBuffs() {
 	}
 }
 
+// RecordGSyn adds the current receiving-layer average GSyns value to GSynSum,
+// for computing the trial-level GSynTrl.  Called in Layer.CyclePost
+// if enabled via Layer.SetRecordGSyn.
+func (pj *Prjn) RecordGSyn() {
+	if len(pj.GSyns) == 0 {
+		return
+	}
+	sum := float32(0)
+	for _, g := range pj.GSyns {
+		sum += g
+	}
+	pj.GSynSum += sum / float32(len(pj.GSyns))
+	pj.GSynN++
+}
+
+// GSynTrlFmSum computes GSynTrl as the average of GSynSum over the cycles
+// recorded on the current trial.  Called in Layer.PlusPhasePost.
+func (pj *Prjn) GSynTrlFmSum() {
+	if pj.GSynN > 0 {
+		pj.GSynTrl = pj.GSynSum / float32(pj.GSynN)
+	}
+}
+
+// InitGSynSum resets the GSynSum accumulation for a new trial.
+// Called in Layer.NewState.
+func (pj *Prjn) InitGSynSum() {
+	pj.GSynSum = 0
+	pj.GSynN = 0
+}
+
+// RecomputeGScale recomputes the GScale conductance scaling factors
+// based on the current number of receiving connections (RecvCon N values)
+// and PrjnScale Abs / Rel parameters.  GScale is otherwise only computed
+// at initialization, and becomes stale after structural changes
+// such as pruning or adding synapses.  Because GScale.Rel is normalized
+// across all the receiving projections of the receiving layer,
+// this recomputes the GScale for all of those projections.
+// Network.RebuildIdxs does this for all layers after structural changes.
+func (pj *Prjn) RecomputeGScale() {
+	for _, rpj := range pj.Recv.RcvPrjns {
+		rpj.UpdtConNAvgMax()
+	}
+	pj.Recv.InitGScale()
+}
+
+// GrowSyns adds new synapses between currently-unconnected pairs of
+// sending (si) and receiving (ri) neurons for which the candidates
+// predicate returns true (e.g., for co-active pairs), up to maxNew
+// new synapses in total, visiting receivers and then senders in order.
+// New synapses start with SWt = SWt.Init.Mean and a small weight
+// Wt = 0.1 * SWt.  Because this changes the number of synapses,
+// the network-global Synapses are reallocated (see ReallocSynapses)
+// and all indexes are rebuilt (see RebuildIdxs).  This is not supported
+// when running on the GPU, which has fixed-size synapse buffers.
+// Returns the number of synapses added.
+func (pj *Prjn) GrowSyns(candidates func(si, ri int) bool, maxNew int) int {
+	if pj.IsOff() || maxNew <= 0 {
+		return 0
+	}
+	nt := pj.Recv.Network
+	if nt.GPU.On {
+		log.Printf("Prjn %s GrowSyns: not supported when running on the GPU\n", pj.Name())
+		return 0
+	}
+	slen := len(pj.SendCon)
+	rlen := len(pj.RecvCon)
+	conn := make([]bool, slen)
+	adds := make([][]uint32, rlen)
+	nnew := 0
+	for ri := 0; ri < rlen && nnew < maxNew; ri++ {
+		for si := range conn {
+			conn[si] = false
+		}
+		rcon := pj.RecvCon[ri]
+		for ci := uint32(0); ci < rcon.N; ci++ {
+			conn[pj.RecvConIdx[rcon.Start+ci]] = true
+		}
+		for si := 0; si < slen && nnew < maxNew; si++ {
+			if conn[si] || !candidates(si, ri) {
+				continue
+			}
+			adds[ri] = append(adds[ri], uint32(si))
+			nnew++
+		}
+	}
+	if nnew == 0 {
+		return 0
+	}
+
+	type synCon struct {
+		si  uint32
+		sy  Synapse
+		age int32
+	}
+	tot := 0
+	for ri, rcon := range pj.RecvCon {
+		tot += int(rcon.N) + len(adds[ri])
+	}
+	syns := make([]Synapse, tot)
+	rconIdx := make([]uint32, tot)
+	recvCon := make([]StartN, rlen)
+	var ages []int32
+	if pj.LastDWtCyc != nil {
+		ages = make([]int32, tot)
+	}
+	swt := pj.Params.SWt.Init.Mean
+	idx := 0
+	var cons []synCon
+	for ri, rcon := range pj.RecvCon {
+		cons = cons[:0]
+		for ci := uint32(0); ci < rcon.N; ci++ {
+			sc := synCon{si: pj.RecvConIdx[rcon.Start+ci], sy: pj.Syns[rcon.Start+ci]}
+			if ages != nil {
+				sc.age = pj.LastDWtCyc[rcon.Start+ci]
+			}
+			cons = append(cons, sc)
+		}
+		for _, si := range adds[ri] {
+			sc := synCon{si: si, age: -1}
+			sy := &sc.sy
+			sy.SWt = swt
+			sy.Wt = 0.1 * swt
+			sy.LWt = pj.Params.SWt.LWtFmWts(sy.Wt, sy.SWt)
+			InitSynCa(sy)
+			cons = append(cons, sc)
+		}
+		sort.SliceStable(cons, func(i, j int) bool { return cons[i].si < cons[j].si })
+		recvCon[ri] = StartN{Start: uint32(idx), N: uint32(len(cons))}
+		for _, sc := range cons {
+			syns[idx] = sc.sy
+			rconIdx[idx] = sc.si
+			if ages != nil {
+				ages[idx] = sc.age
+			}
+			idx++
+		}
+	}
+	pj.Syns = syns
+	pj.LastDWtCyc = ages
+	pj.RecvConIdx = rconIdx
+	pj.RecvCon = recvCon
+	pj.SendSynIdx = make([]uint32, tot)
+	pj.SendConIdx = make([]uint32, tot)
+	nt.ReallocSynapses()
+	nt.RebuildIdxs()
+	return nnew
+}
+
 var PrjnProps = ki.Props{
 	"EnumType:Typ": KiT_PrjnTypes, // uses our PrjnTypes for GUI
 }
+
+// cloneConfigTo copies the optional CPU-side configuration and state of
+// this projection, set by methods such as SetScaleSchedule and TrackWtAge,
+// to the given projection of a Network.Clone, which has the same synapses.
+func (pj *Prjn) cloneConfigTo(cp *Prjn) {
+	cp.ScaleSchedCycs = append([]int32(nil), pj.ScaleSchedCycs...)
+	cp.ScaleSchedGains = append([]float32(nil), pj.ScaleSchedGains...)
+	cp.LastDWtCyc = append([]int32(nil), pj.LastDWtCyc...)
+	cp.GSynSum = pj.GSynSum
+	cp.GSynN = pj.GSynN
+	cp.GSynTrl = pj.GSynTrl
+}
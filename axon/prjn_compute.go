@@ -54,11 +54,19 @@ func (pj *Prjn) RecvSpikes(ctx *Context, recvIdx int) {
 // sending and receiving spikes.
 func (pj *Prjn) SendSpike(ctx *Context, sendIdx int, nrn *Neuron) {
 	scale := pj.Params.GScale.Scale * pj.Params.Com.FloatToIntFactor() // pre-bake in conversion to uint factor
+	if pj.ScaleSchedCycs != nil {
+		scale *= pj.ScaleSchedGain(ctx.Cycle)
+	}
 	if pj.PrjnType() == CTCtxtPrjn {
 		if ctx.Cycle != ctx.ThetaCycles-1-int32(pj.Params.Com.DelLen) {
 			return
 		}
 		scale *= nrn.Burst // Burst is regular CaSpkP for all non-SuperLayer neurons
+	} else if pj.PrjnType() == IdentityPrjn {
+		if nrn.Act == 0 {
+			return
+		}
+		scale *= nrn.Act // continuous rate code every cycle
 	} else {
 		if nrn.Spike == 0 {
 			return
@@ -66,13 +74,18 @@ func (pj *Prjn) SendSpike(ctx *Context, sendIdx int, nrn *Neuron) {
 	}
 	pjcom := &pj.Params.Com
 	wrOff := pjcom.WriteOff(ctx.CyclesTotal)
+	allowNeg := pj.Params.SWt.AllowNegative.IsTrue()
 	sidxs := pj.SendSynIdxs(sendIdx)
 	for _, ssi := range sidxs {
 		sy := &pj.Syns[ssi]
 		recvIdx := pj.Params.SynRecvLayIdx(sy)
 		sv := int32(scale * sy.Wt)
 		bi := pjcom.WriteIdxOff(recvIdx, wrOff, pj.Params.Idxs.RecvNeurN)
-		pj.GBuf[bi] += sv
+		if allowNeg && sv < 0 {
+			pj.GBuf[pjcom.NegIdx(bi, pj.Params.Idxs.RecvNeurN)] -= sv
+		} else {
+			pj.GBuf[bi] += sv
+		}
 	}
 }
 
@@ -127,7 +140,30 @@ func (pj *Prjn) SynCaRecv(ctx *Context, ni uint32, rn *Neuron, updtThr float32)
 // DWt computes the weight change (learning), based on
 // synaptically-integrated spiking, computed at the Theta cycle interval.
 // This is the trace version for hidden units, and uses syn CaP - CaD for targets.
+// Does nothing if Learn.ContinuousDWt is on -- see ContinuousDWt.
 func (pj *Prjn) DWt(ctx *Context) {
+	if pj.Params.Learn.ContinuousDWt.IsTrue() {
+		return
+	}
+	pj.ComputeDWt(ctx)
+}
+
+// ContinuousDWt computes and applies the weight changes using the
+// current running calcium values, if Learn.ContinuousDWt is on and
+// the current cycle is at the end of a Learn.ContinuousCycles interval.
+// Called every cycle during the trial, on the CPU.
+func (pj *Prjn) ContinuousDWt(ctx *Context) {
+	if pj.Params.Learn.Learn.IsFalse() || !pj.Params.Learn.ContinuousDWtCycle(ctx.Cycle) {
+		return
+	}
+	pj.ComputeDWt(ctx)
+	pj.DWtSubMean(ctx)
+	pj.ApplyDWt(ctx)
+}
+
+// ComputeDWt computes the weight change (learning) as in DWt,
+// regardless of the Learn.ContinuousDWt setting.
+func (pj *Prjn) ComputeDWt(ctx *Context) {
 	if pj.Params.Learn.Learn.IsFalse() {
 		return
 	}
@@ -135,14 +171,19 @@ func (pj *Prjn) DWt(ctx *Context) {
 	rlay := pj.Recv
 	layPool := &rlay.Pools[0]
 	isTarget := rlay.Params.Act.Clamp.IsTarget.IsTrue()
+	updtThr := rlay.Params.Learn.CaLrn.UpdtThr
 	for ri := range rlay.Neurons {
 		rn := &rlay.Neurons[ri]
-		// note: UpdtThr doesn't make sense here b/c Tr needs to be updated
+		// note: UpdtThr doesn't make sense here b/c Tr needs to be updated,
+		// unless SpikeGatedDWt is on, which accepts that approximation
 		syns := pj.RecvSyns(ri)
 		for ci := range syns {
 			sy := &syns[ci]
 			si := pj.Params.SynSendLayIdx(sy)
 			sn := &slay.Neurons[si]
+			if pj.Params.Learn.SkipDWt(sn.CaSpkD, rn.CaSpkD, updtThr) {
+				continue
+			}
 			subPool := &rlay.Pools[rn.SubPool]
 			pj.Params.DWtSyn(ctx, sy, sn, rn, layPool, subPool, isTarget)
 		}
@@ -164,18 +205,33 @@ func (pj *Prjn) DWtSubMean(ctx *Context) {
 		}
 		sumDWt := float32(0)
 		nnz := 0 // non-zero
-		for ci := range syns {
-			sy := &syns[ci]
-			dw := sy.DWt
-			if dw != 0 {
-				sumDWt += dw
-				nnz++
+		if pj.Params.Learn.HighPrecSum.IsTrue() {
+			sum64 := float64(0)
+			for ci := range syns {
+				dw := syns[ci].DWt
+				if dw != 0 {
+					sum64 += float64(dw)
+					nnz++
+				}
 			}
+			if nnz <= 1 {
+				continue
+			}
+			sumDWt = float32(sum64 / float64(nnz))
+		} else {
+			for ci := range syns {
+				sy := &syns[ci]
+				dw := sy.DWt
+				if dw != 0 {
+					sumDWt += dw
+					nnz++
+				}
+			}
+			if nnz <= 1 {
+				continue
+			}
+			sumDWt /= float32(nnz)
 		}
-		if nnz <= 1 {
-			continue
-		}
-		sumDWt /= float32(nnz)
 		for ci := range syns {
 			sy := &syns[ci]
 			if sy.DWt != 0 {
@@ -187,12 +243,26 @@ func (pj *Prjn) DWtSubMean(ctx *Context) {
 
 // WtFmDWt updates the synaptic weight values from delta-weight changes.
 // called on the *receiving* projections.
+// Does nothing if Learn.AccumulateDWt is on -- see ApplyDWt.
 func (pj *Prjn) WtFmDWt(ctx *Context) {
+	if pj.Params.Learn.AccumulateDWt.IsTrue() {
+		return
+	}
+	pj.ApplyDWt(ctx)
+}
+
+// ApplyDWt updates the synaptic weight values from delta-weight changes,
+// and zeros the DWt values, regardless of the Learn.AccumulateDWt setting.
+func (pj *Prjn) ApplyDWt(ctx *Context) {
 	rlay := pj.Recv
+	track := pj.LastDWtCyc != nil
 	for ri := range rlay.Neurons {
 		syns := pj.RecvSyns(ri)
 		for ci := range syns {
 			sy := &syns[ci]
+			if track && sy.DWt != 0 {
+				pj.LastDWtCyc[int(pj.RecvCon[ri].Start)+ci] = ctx.CyclesTotal
+			}
 			pj.Params.WtFmDWtSyn(ctx, sy)
 		}
 	}
@@ -206,9 +276,9 @@ func (pj *Prjn) SlowAdapt(ctx *Context) {
 
 // SWtFmWt updates structural, slowly-adapting SWt value based on
 // accumulated DSWt values, which are zero-summed with additional soft bounding
-// relative to SWt limits.
+// relative to SWt limits.  Not applied if SWt.AllowNegative.
 func (pj *Prjn) SWtFmWt() {
-	if pj.Params.Learn.Learn.IsFalse() || pj.Params.SWt.Adapt.On.IsFalse() {
+	if pj.Params.Learn.Learn.IsFalse() || pj.Params.SWt.Adapt.On.IsFalse() || pj.Params.SWt.AllowNegative.IsTrue() {
 		return
 	}
 	rlay := pj.Recv
@@ -219,6 +289,7 @@ func (pj *Prjn) SWtFmWt() {
 	min := pj.Params.SWt.Limit.Min
 	lr := pj.Params.SWt.Adapt.LRate
 	dvar := pj.Params.SWt.Adapt.DreamVar
+	hiPrec := pj.Params.Learn.HighPrecSum.IsTrue()
 	for ri := range rlay.Neurons {
 		syns := pj.RecvSyns(ri)
 		nCons := len(syns)
@@ -226,6 +297,7 @@ func (pj *Prjn) SWtFmWt() {
 			continue
 		}
 		avgDWt := float32(0)
+		sum64 := float64(0)
 		for ci := range syns {
 			sy := &syns[ci]
 			if sy.DSWt >= 0 { // softbound for SWt
@@ -233,14 +305,22 @@ func (pj *Prjn) SWtFmWt() {
 			} else {
 				sy.DSWt *= (sy.SWt - min)
 			}
-			avgDWt += sy.DSWt
+			if hiPrec {
+				sum64 += float64(sy.DSWt)
+			} else {
+				avgDWt += sy.DSWt
+			}
+		}
+		if hiPrec {
+			avgDWt = float32(sum64 / float64(nCons))
+		} else {
+			avgDWt /= float32(nCons)
 		}
-		avgDWt /= float32(nCons)
 		avgDWt *= pj.Params.SWt.Adapt.SubMean
 		if dvar > 0 {
 			for ci := range syns {
 				sy := &syns[ci]
-				sy.SWt += lr * (sy.DSWt - avgDWt)
+				sy.SWt += pj.Params.SWt.Adapt.ClampDelta(lr * (sy.DSWt - avgDWt))
 				sy.DSWt = 0
 				if sy.Wt == 0 { // restore failed wts
 					sy.Wt = pj.Params.SWt.WtVal(sy.SWt, sy.LWt)
@@ -251,7 +331,7 @@ func (pj *Prjn) SWtFmWt() {
 		} else {
 			for ci := range syns {
 				sy := &syns[ci]
-				sy.SWt += lr * (sy.DSWt - avgDWt)
+				sy.SWt += pj.Params.SWt.Adapt.ClampDelta(lr * (sy.DSWt - avgDWt))
 				sy.DSWt = 0
 				if sy.Wt == 0 { // restore failed wts
 					sy.Wt = pj.Params.SWt.WtVal(sy.SWt, sy.LWt)
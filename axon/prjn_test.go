@@ -0,0 +1,1163 @@
+// Copyright (c) 2023, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package axon
+
+import (
+	"math"
+	"testing"
+
+	"github.com/emer/emergent/etime"
+	"github.com/emer/emergent/prjn"
+	"github.com/emer/etable/etensor"
+	"github.com/goki/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecomputeGScale(t *testing.T) {
+	net := NewNetwork("GScaleTest")
+	inLay := net.AddLayer("Input", []int{20, 20}, InputLayer)
+	hidLay := net.AddLayer("Hidden", []int{10, 10}, SuperLayer)
+	pj := net.ConnectLayers(inLay, hidLay, prjn.NewFull(), ForwardPrjn)
+	assert.NoError(t, net.Build())
+	net.Defaults()
+	inLay.Params.Inhib.ActAvg.Nominal = 0.1
+	pj.Params.SWt.Init.Var = 0
+	net.InitWts()
+
+	// effective input to the hidden layer for a sending pattern
+	// with every 10th unit active, matching the Nominal activity.
+	effIn := func() float32 {
+		sum := float32(0)
+		for ri := range hidLay.Neurons {
+			syns := pj.RecvSyns(ri)
+			for ci := range syns {
+				sy := &syns[ci]
+				if pj.Params.SynSendLayIdx(sy)%10 == 0 {
+					sum += pj.Params.GScale.Scale * sy.Wt
+				}
+			}
+		}
+		return sum / float32(len(hidLay.Neurons))
+	}
+
+	fullIn := effIn()
+	fullScale := pj.Params.GScale.Scale
+	assert.Equal(t, float32(400), pj.RecvConNAvgMax.Avg)
+
+	// prune the second half of the synapses for each receiver
+	for ri := range pj.RecvCon {
+		pj.RecvCon[ri].N /= 2
+	}
+	assert.Equal(t, fullScale, pj.Params.GScale.Scale) // stale until rebuilt
+	net.RebuildIdxs()
+
+	assert.Equal(t, float32(200), pj.RecvConNAvgMax.Avg)
+	for si, scon := range pj.SendCon {
+		if si < 200 {
+			assert.Equal(t, uint32(100), scon.N)
+		} else {
+			assert.Equal(t, uint32(0), scon.N)
+		}
+		for _, ssi := range pj.SendSynIdxs(si) {
+			assert.Equal(t, uint32(si), pj.Params.SynSendLayIdx(&pj.Syns[ssi]))
+		}
+	}
+	for ri, rcon := range pj.RecvCon {
+		assert.Equal(t, rcon, net.PrjnRecvCon[int(pj.Params.Idxs.RecvConSt)+ri])
+	}
+
+	assert.Greater(t, pj.Params.GScale.Scale, fullScale)
+	prunedIn := effIn()
+	assert.InDelta(t, 1, prunedIn/fullIn, 0.15)
+}
+
+func TestMaxDeltaPerStep(t *testing.T) {
+	ctx := NewContext()
+	pj := PrjnParams{}
+	pj.Defaults()
+	pj.PrjnType = RWPrjn // no weight limits
+	pj.Update()
+
+	// aggressive learning without bounds blows up the weights
+	runWts := func() float32 {
+		sy := Synapse{Wt: 0.5, LWt: 0.5}
+		for i := 0; i < 100; i++ {
+			sy.DWt = 10
+			pj.WtFmDWtSyn(ctx, &sy)
+		}
+		return sy.Wt
+	}
+	assert.Equal(t, float32(1000.5), runWts())
+	pj.SWt.Adapt.MaxDeltaPerStep = 0.01
+	assert.InDelta(t, 1.5, runWts(), 1.0e-4)
+
+	// LWt is bounded per step for standard cortical prjns
+	pj.PrjnType = ForwardPrjn
+	sy := Synapse{SWt: 0.5, LWt: 0.5, DWt: 0.4}
+	sy.Wt = pj.SWt.WtVal(sy.SWt, sy.LWt)
+	pj.WtFmDWtSyn(ctx, &sy)
+	assert.InDelta(t, 0.51, sy.LWt, 1.0e-6)
+}
+
+func TestMaxDeltaPerStepSWt(t *testing.T) {
+	net := NewNetwork("SWtTest")
+	inLay := net.AddLayer("Input", []int{4, 4}, InputLayer)
+	hidLay := net.AddLayer("Hidden", []int{4, 4}, SuperLayer)
+	pj := net.ConnectLayers(inLay, hidLay, prjn.NewFull(), ForwardPrjn)
+	assert.NoError(t, net.Build())
+	net.Defaults()
+	net.InitWts()
+
+	maxd := float32(0.001)
+	pj.Params.SWt.Adapt.LRate = 1 // aggressive
+	pj.Params.SWt.Adapt.MaxDeltaPerStep = maxd
+	swts := make([]float32, len(pj.Syns))
+	for si := range pj.Syns {
+		sy := &pj.Syns[si]
+		swts[si] = sy.SWt
+		if si%2 == 0 {
+			sy.DSWt = 5
+		} else {
+			sy.DSWt = -5
+		}
+	}
+	pj.SWtFmWt()
+	nchg := 0
+	for si := range pj.Syns {
+		dsw := pj.Syns[si].SWt - swts[si]
+		assert.LessOrEqual(t, mat32.Abs(dsw), maxd+1.0e-6)
+		if dsw != 0 {
+			nchg++
+		}
+	}
+	assert.Greater(t, nchg, 0)
+}
+
+func TestHighPrecSum(t *testing.T) {
+	net := NewNetwork("HighPrecTest")
+	inLay := net.AddLayer2D("Input", 200, 100, InputLayer)
+	hidLay := net.AddLayer2D("Hidden", 1, 1, SuperLayer)
+	pj := net.ConnectLayers(inLay, hidLay, prjn.NewFull(), ForwardPrjn)
+	assert.NoError(t, net.Build())
+	net.Defaults()
+	pj.Params.Learn.Trace.SubMean = 1
+	ctx := NewContext()
+
+	// zero-sum residual of DWt after subtracting the mean
+	residual := func(hiPrec bool) float64 {
+		pj.Params.Learn.HighPrecSum.SetBool(hiPrec)
+		for si := range pj.Syns {
+			pj.Syns[si].DWt = 1 + float32(si%7)*0.1
+		}
+		pj.DWtSubMean(ctx)
+		sum := float64(0)
+		for si := range pj.Syns {
+			sum += float64(pj.Syns[si].DWt)
+		}
+		return math.Abs(sum)
+	}
+	lowRes := residual(false)
+	highRes := residual(true)
+	assert.Less(t, highRes, lowRes)
+	assert.Less(t, highRes, 0.01)
+}
+
+func TestGrowSyns(t *testing.T) {
+	net := NewNetwork("GrowTest")
+	inLay := net.AddLayer("Input", []int{4, 1}, InputLayer)
+	hidLay := net.AddLayer("Hidden", []int{4, 1}, SuperLayer)
+	outLay := net.AddLayer("Output", []int{4, 1}, TargetLayer)
+	pj := net.ConnectLayers(inLay, hidLay, prjn.NewOneToOne(), ForwardPrjn)
+	opj := net.ConnectLayers(hidLay, outLay, prjn.NewOneToOne(), ForwardPrjn)
+	assert.NoError(t, net.Build())
+	net.Defaults()
+	net.InitWts()
+	owts := make([]float32, len(opj.Syns))
+	for si := range opj.Syns {
+		owts[si] = opj.Syns[si].Wt
+	}
+
+	pj.TrackWtAge(true)
+	for si := range pj.LastDWtCyc {
+		pj.LastDWtCyc[si] = int32(10 + si)
+	}
+
+	// correlated pairs: each hidden unit with the next input unit
+	nnew := pj.GrowSyns(func(si, ri int) bool { return si == (ri+1)%4 }, 10)
+	assert.Equal(t, 4, nnew)
+	assert.Equal(t, 8, len(pj.Syns))
+	assert.Equal(t, 12, len(net.Synapses))
+	assert.Equal(t, len(pj.Syns), len(pj.LastDWtCyc))
+	for ri, rcon := range pj.RecvCon { // ages move with their synapses
+		for ci := uint32(0); ci < rcon.N; ci++ {
+			age := pj.LastDWtCyc[rcon.Start+ci]
+			if int(pj.RecvConIdx[rcon.Start+ci]) == ri {
+				assert.Equal(t, int32(10+ri), age)
+			} else {
+				assert.Equal(t, int32(-1), age)
+			}
+		}
+	}
+	assert.Equal(t, 0, pj.GrowSyns(func(si, ri int) bool { return si == (ri+1)%4 }, 10))
+	for ri, rcon := range pj.RecvCon {
+		assert.Equal(t, uint32(2), rcon.N)
+		for _, sy := range pj.RecvSyns(ri) {
+			assert.Equal(t, uint32(ri+hidLay.NeurStIdx), sy.RecvIdx)
+		}
+	}
+	for si, scon := range pj.SendCon {
+		assert.Equal(t, uint32(2), scon.N)
+		for _, ssi := range pj.SendSynIdxs(si) {
+			assert.Equal(t, uint32(si), pj.Params.SynSendLayIdx(&pj.Syns[ssi]))
+		}
+	}
+	for si := range opj.Syns { // other prjns preserved
+		assert.Equal(t, owts[si], opj.Syns[si].Wt)
+		assert.Equal(t, &net.Synapses[opj.Params.Idxs.SynapseSt+uint32(si)], &opj.Syns[si])
+	}
+
+	// input to unit 0 now drives hidden units 0 and 3
+	ctx := NewContext()
+	inPat := etensor.NewFloat32([]int{4, 1}, nil, nil)
+	inPat.Values[0] = 1
+	net.InitExt()
+	inLay.ApplyExt(inPat)
+	net.ApplyExts(ctx)
+	net.NewState(ctx)
+	ctx.NewState(etime.Train)
+	for cyc := 0; cyc < 50; cyc++ {
+		net.Cycle(ctx)
+		ctx.CycleInc()
+	}
+	for ri := range hidLay.Neurons {
+		ge := hidLay.Neurons[ri].GeSyn
+		if ri == 0 || ri == 3 {
+			assert.Greater(t, ge, float32(0))
+		} else {
+			assert.Equal(t, float32(0), ge)
+		}
+	}
+	assert.Greater(t, hidLay.Neurons[0].GeSyn, hidLay.Neurons[3].GeSyn)
+	net.DWt(ctx)
+	net.WtFmDWt(ctx) // uses the grown LastDWtCyc
+
+	// ages that no longer match the synapses are reallocated and reset
+	pj.LastDWtCyc = pj.LastDWtCyc[:3]
+	net.ReallocSynapses()
+	assert.Equal(t, len(pj.Syns), len(pj.LastDWtCyc))
+	assert.Equal(t, int32(-1), pj.LastDWtCyc[0])
+}
+
+func TestSpikeGatedDWt(t *testing.T) {
+	net := NewNetwork("SpikeGatedTest")
+	inLay := net.AddLayer2D("Input", 10, 10, InputLayer)
+	hidLay := net.AddLayer2D("Hidden", 10, 10, SuperLayer)
+	outLay := net.AddLayer2D("Output", 10, 10, TargetLayer)
+	pj := net.ConnectLayers(inLay, hidLay, prjn.NewFull(), ForwardPrjn)
+	net.BidirConnectLayers(hidLay, outLay, prjn.NewFull())
+	assert.NoError(t, net.Build())
+	net.Defaults()
+	net.InitWts()
+
+	// sparse input and output patterns
+	inPat := etensor.NewFloat32([]int{10, 10}, nil, nil)
+	outPat := etensor.NewFloat32([]int{10, 10}, nil, nil)
+	for i := 0; i < 100; i += 10 {
+		inPat.Values[i] = 1
+		outPat.Values[i+5] = 1
+	}
+	ctx := NewContext()
+	net.InitExt()
+	inLay.ApplyExt(inPat)
+	outLay.ApplyExt(outPat)
+	net.ApplyExts(ctx)
+	net.NewState(ctx)
+	ctx.NewState(etime.Train)
+	for cyc := 0; cyc < 200; cyc++ {
+		net.Cycle(ctx)
+		ctx.CycleInc()
+		if cyc == 149 {
+			net.MinusPhase(ctx)
+			ctx.NewPhase(true)
+			net.PlusPhaseStart(ctx)
+		}
+	}
+	net.PlusPhase(ctx)
+
+	syns := make([]Synapse, len(pj.Syns))
+	copy(syns, pj.Syns)
+	pj.DWt(ctx)
+	full := make([]float32, len(pj.Syns))
+	maxDWt := float32(0)
+	for si := range pj.Syns {
+		full[si] = pj.Syns[si].DWt
+		maxDWt = mat32.Max(maxDWt, mat32.Abs(full[si]))
+	}
+	assert.Greater(t, maxDWt, float32(0))
+
+	copy(pj.Syns, syns)
+	pj.Params.Learn.SpikeGatedDWt.SetBool(true)
+	pj.DWt(ctx)
+	nskip := 0
+	updtThr := hidLay.Params.Learn.CaLrn.UpdtThr
+	for si := range pj.Syns {
+		sy := &pj.Syns[si]
+		sn := &inLay.Neurons[pj.Params.SynSendLayIdx(sy)]
+		rn := &hidLay.Neurons[pj.Params.SynRecvLayIdx(sy)]
+		if sn.CaSpkD < updtThr && rn.CaSpkD < updtThr {
+			nskip++
+			assert.Equal(t, syns[si].DWt, sy.DWt)
+			assert.Equal(t, syns[si].Tr, sy.Tr)
+		}
+		assert.InDelta(t, full[si], sy.DWt, float64(0.01*maxDWt))
+	}
+	assert.Greater(t, nskip, 0)
+}
+
+func TestIdentityPrjn(t *testing.T) {
+	net := NewNetwork("IdentityTest")
+	inLay := net.AddLayer("Input", []int{4, 1}, InputLayer)
+	relLay := net.AddLayer("Relay", []int{4, 1}, SuperLayer)
+	hidLay := net.AddLayer("Hidden", []int{4, 1}, SuperLayer)
+	pj := net.ConnectIdentity(inLay, relLay)
+	net.ConnectLayers(hidLay, relLay, prjn.NewFull(), BackPrjn)
+	assert.NoError(t, net.Build())
+	net.Defaults()
+	inLay.Params.Inhib.Layer.On.SetBool(false) // all input units active
+	gain := float32(2)
+	pj.Params.PrjnScale.Abs = gain
+	pj.Params.SWt.Init.Mean = 0 // weights are kept at 1
+	pj.Params.Update()
+	net.InitWts()
+
+	assert.Equal(t, IdentityPrjn, pj.PrjnType())
+	assert.Equal(t, float32(1), pj.Params.GScale.Rel)
+	assert.Equal(t, float32(1), relLay.RcvPrjns[1].Params.GScale.Rel) // not normalized with identity
+	assert.False(t, pj.Params.Learn.Learn.IsTrue())
+	assert.InDelta(t, gain*relLay.Params.Act.Dt.GeDt, pj.Params.GScale.Scale, 1.0e-6)
+	assert.Equal(t, float32(1), pj.Syns[0].Wt)
+
+	// graded input levels
+	inPat := etensor.NewFloat32([]int{4, 1}, nil, nil)
+	for i := range inPat.Values {
+		inPat.Values[i] = 0.4 + 0.2*float32(i)
+	}
+	ctx := NewContext()
+	net.InitExt()
+	inLay.ApplyExt(inPat)
+	net.ApplyExts(ctx)
+	net.NewState(ctx)
+	ctx.NewState(etime.Train)
+	for cyc := 0; cyc < 150; cyc++ {
+		net.Cycle(ctx)
+		ctx.CycleInc()
+	}
+	// hidden layer receives no input, so relay GeSyn is only from identity,
+	// and tracks gain * Act of the corresponding input unit
+	for ni := range relLay.Neurons {
+		act := inLay.Neurons[ni].Act
+		ge := relLay.Neurons[ni].GeSyn
+		assert.Greater(t, act, float32(0))
+		assert.InDelta(t, gain*act, ge, float64(0.1*gain*act))
+	}
+}
+
+func TestDWtDAClamp(t *testing.T) {
+	ctx := NewContext()
+	pj := PrjnParams{}
+	pj.Defaults()
+	sn := &Neuron{CaSpkD: 0.5}
+	rn := &Neuron{CaSpkD: 0.5}
+	lpl := &Pool{}
+
+	// VSPatch DWt for given DA and RLRate, which has DA sign factored in
+	vsDWt := func(da, rlrate float32) float32 {
+		pj.PrjnType = VSPatchPrjn
+		ctx.NeuroMod.DA = da
+		rn.RLRate = rlrate
+		sy := &Synapse{}
+		pj.DWtSynVSPatch(ctx, sy, sn, rn, lpl, lpl)
+		return sy.DWt
+	}
+	// Matrix DWt from an existing trace, for given DA and RLRate
+	mtxDWt := func(da, rlrate float32) float32 {
+		pj.PrjnType = MatrixPrjn
+		ctx.NeuroMod.DA = da
+		ctx.NeuroMod.ACh = 0
+		rn.RLRate = rlrate
+		sy := &Synapse{Tr: 0.5}
+		pj.DWtSynMatrix(ctx, sy, sn, rn, lpl, lpl)
+		return sy.DWt
+	}
+
+	pos := vsDWt(1, 1)
+	neg := vsDWt(-1, -1)
+	assert.Greater(t, pos, float32(0))
+	assert.Equal(t, -pos, neg)
+	mpos := mtxDWt(1, 1)
+	mneg := mtxDWt(-1, -1)
+	assert.Greater(t, mpos, float32(0))
+	assert.Equal(t, -mpos, mneg)
+
+	pj.DWtDAClamp.Mode = DAClampFloor
+	pj.DWtDAClamp.Floor = 0
+	assert.Equal(t, pos, vsDWt(1, 1)) // positive DA unaffected
+	assert.Equal(t, float32(0), vsDWt(-1, -1))
+	assert.Equal(t, float32(0), mtxDWt(-1, -1))
+	pj.DWtDAClamp.Floor = 0.5 * pos
+	assert.Equal(t, -0.5*pos, vsDWt(-1, -1))
+	assert.Equal(t, 0.5*pos, vsDWt(-1, 1)) // D2-like sign reversal
+	pj.DWtDAClamp.Floor = 10
+	assert.Equal(t, neg, vsDWt(-1, -1))
+
+	pj.DWtDAClamp.Mode = DAClampScale
+	pj.DWtDAClamp.Scale = 0.25
+	assert.Equal(t, pos, vsDWt(1, 1))
+	assert.InDelta(t, 0.25*neg, vsDWt(-1, -1), 1.0e-7)
+	assert.InDelta(t, 0.25*mneg, mtxDWt(-1, -1), 1.0e-7)
+	assert.Equal(t, mpos, mtxDWt(1, 1))
+
+	pj.DWtDAClamp.Mode = DAClampAllow
+	assert.Equal(t, neg, vsDWt(-1, -1))
+}
+
+func TestMatrixCreditWindow(t *testing.T) {
+	ctx := NewContext()
+	pj := PrjnParams{}
+	pj.Defaults()
+	pj.PrjnType = MatrixPrjn
+	pj.Matrix.NoGateLRate = 0
+	sn := &Neuron{CaSpkD: 0.5}
+	rn := &Neuron{SpkMax: 0.8, RLRate: 1}
+	lpl := &Pool{}
+
+	// gating on the first trial, followed by reward (DA, ACh) after given
+	// number of trials -- returns the resulting weight change
+	rewDWt := func(delay int) float32 {
+		sy := &Synapse{}
+		lpl.GatedTrls = 0
+		for trl := 0; trl <= delay; trl++ {
+			lpl.Gated.SetBool(trl == 0)
+			if trl > 0 {
+				lpl.GatedTrls++
+			}
+			rew := trl == delay
+			ctx.NeuroMod.DA = 0
+			ctx.NeuroMod.ACh = 0
+			if rew {
+				ctx.NeuroMod.DA = 1
+				ctx.NeuroMod.ACh = 1
+			}
+			sy.DWt = 0
+			pj.DWtSynMatrix(ctx, sy, sn, rn, lpl, lpl)
+		}
+		return sy.DWt
+	}
+
+	full := pj.Learn.LRate.Eff * rn.SpkMax * sn.CaSpkD
+	for delay := 0; delay < 6; delay++ { // default: no limit, no decay
+		assert.InDelta(t, full, rewDWt(delay), 1.0e-7)
+	}
+
+	pj.Matrix.CreditWindow = 3
+	pj.Matrix.CreditDecay = 0.5
+	decay := float32(1)
+	for delay := 0; delay < 6; delay++ {
+		if delay < 3 {
+			assert.InDelta(t, full*decay, rewDWt(delay), 1.0e-7)
+		} else {
+			assert.Equal(t, float32(0), rewDWt(delay))
+		}
+		decay *= 0.5
+	}
+
+	pj.Matrix.CreditWindow = 1 // only same-trial gating gets credit
+	pj.Matrix.CreditDecay = 0
+	assert.InDelta(t, full, rewDWt(0), 1.0e-7)
+	assert.Equal(t, float32(0), rewDWt(1))
+}
+
+func TestInitWtsSeeded(t *testing.T) {
+	// extra adds another layer and projection, built before the seeded one
+	build := func(extra bool, seed int64) (*Network, *Prjn) {
+		net := NewNetwork("SeededTest")
+		inLay := net.AddLayer2D("Input", 5, 5, InputLayer)
+		hidLay := net.AddLayer2D("Hidden", 4, 4, SuperLayer)
+		if extra {
+			exLay := net.AddLayer2D("Extra", 3, 3, InputLayer)
+			net.ConnectLayers(exLay, hidLay, prjn.NewFull(), ForwardPrjn)
+		}
+		pj := net.ConnectLayers(inLay, hidLay, prjn.NewFull(), ForwardPrjn)
+		pj.InitSeed = seed
+		assert.NoError(t, net.Build())
+		net.Defaults()
+		net.InitWts()
+		return net, pj
+	}
+	wts := func(pj *Prjn) []float32 {
+		var wts []float32
+		pj.SynVals(&wts, "Wt")
+		return wts
+	}
+
+	_, pj1 := build(false, 0)
+	_, pj2 := build(true, 0)
+	assert.NotEqual(t, wts(pj1), wts(pj2)) // shared stream shifted by extra prjn
+
+	_, pj1 = build(false, 42)
+	net2, pj2 := build(true, 42)
+	w1 := wts(pj1)
+	assert.Equal(t, w1, wts(pj2))
+
+	// re-init gives the same weights, and a different seed differs
+	pj2.InitWtsSeeded(42)
+	assert.Equal(t, w1, wts(pj2))
+	net2.InitWts()
+	assert.Equal(t, w1, wts(pj2))
+	pj2.InitWtsSeeded(43)
+	assert.Equal(t, int64(43), pj2.InitSeed)
+	assert.NotEqual(t, w1, wts(pj2))
+}
+
+func TestTraceSnapshot(t *testing.T) {
+	net := createNetwork([]int{4, 4}, t)
+	inLay := net.AxonLayerByName("Input")
+	hid := net.AxonLayerByName("Hidden")
+	pj := hid.RcvPrjns[0]
+	pj.Params.Learn.Trace.Tau = 2
+	pj.Params.Learn.Trace.Update()
+
+	pat := etensor.NewFloat32([]int{4, 4}, nil, nil)
+	for i := range pat.Values {
+		if i%3 == 0 {
+			pat.Values[i] = 1
+		}
+	}
+	ctx := NewContext()
+	net.InitExt()
+	inLay.ApplyExt(pat)
+	net.ApplyExts(ctx)
+	net.NewState(ctx)
+	ctx.NewState(etime.Train)
+	for cyc := 0; cyc < 200; cyc++ {
+		net.Cycle(ctx)
+		ctx.CycleInc()
+		if cyc == 149 {
+			net.MinusPhase(ctx)
+			ctx.NewPhase(true)
+			net.PlusPhaseStart(ctx)
+		}
+	}
+	net.PlusPhase(ctx)
+	net.DWt(ctx)
+
+	tr0 := pj.TraceSnapshot()
+	var vals []float32
+	pj.SynVals(&vals, "Tr")
+	assert.Equal(t, vals, tr0)
+	pj.SynVals(&vals, "DWt")
+	assert.Equal(t, vals, pj.DWtSnapshot())
+	trmax := float32(0)
+	for _, tr := range tr0 {
+		trmax = mat32.Max(trmax, tr)
+	}
+	assert.Greater(t, trmax, float32(0))
+
+	// with no further synaptic Ca, the trace decays by 1 / Tau per DWt
+	for ri := range hid.Neurons {
+		rn := &hid.Neurons[ri]
+		syns := pj.RecvSyns(ri)
+		for ci := range syns {
+			sy := &syns[ci]
+			sy.CaM, sy.CaP, sy.CaD = 0, 0, 0
+			sn := &inLay.Neurons[pj.Params.SynSendLayIdx(sy)]
+			pj.Params.DWtSyn(ctx, sy, sn, rn, &hid.Pools[0], &hid.Pools[rn.SubPool], false)
+		}
+	}
+	tr1 := pj.TraceSnapshot()
+	for si := range tr0 {
+		assert.InDelta(t, 0.5*tr0[si], tr1[si], 1.0e-6)
+	}
+
+	trs := net.TraceByClass("ForwardPrjn")
+	assert.Equal(t, 2, len(trs))
+	assert.Equal(t, tr1, trs[pj.Name()])
+	assert.Equal(t, 0, len(net.TraceByClass("MatrixPrjn")))
+}
+
+func TestWtAgeStats(t *testing.T) {
+	net := createNetwork([]int{2, 2}, t)
+	hid := net.AxonLayerByName("Hidden")
+	pj := hid.RcvPrjns[0]
+	ctx := NewContext()
+	assert.Equal(t, float32(0), pj.WtAgeStats(ctx, 100))
+
+	pj.TrackWtAge(true)
+	assert.Equal(t, len(pj.Syns), len(pj.LastDWtCyc))
+	assert.Equal(t, float32(0), pj.WtAgeStats(ctx, 100))
+
+	ctx.CyclesTotal = 1000
+	for si := range pj.Syns {
+		if si%2 == 0 {
+			pj.Syns[si].DWt = 0.01
+		}
+	}
+	pj.WtFmDWt(ctx)
+	assert.Equal(t, int32(1000), pj.LastDWtCyc[0])
+	assert.Equal(t, int32(-1), pj.LastDWtCyc[1])
+	assert.Equal(t, float32(0.5), pj.WtAgeStats(ctx, 100))
+
+	// only one synapse changes later on
+	ctx.CyclesTotal = 2000
+	pj.Syns[1].DWt = 0.01
+	pj.WtFmDWt(ctx)
+	assert.Equal(t, float32(1)/float32(len(pj.Syns)), pj.WtAgeStats(ctx, 100))
+	assert.Equal(t, float32(0.5)+float32(1)/float32(len(pj.Syns)), pj.WtAgeStats(ctx, 1000))
+
+	net.InitWts()
+	assert.Equal(t, float32(0), pj.WtAgeStats(ctx, 1000))
+	pj.TrackWtAge(false)
+	assert.Nil(t, pj.LastDWtCyc)
+}
+
+func TestScaleSchedule(t *testing.T) {
+	pat := etensor.NewFloat32([]int{4, 4}, nil, nil)
+	for i := range pat.Values {
+		if i%3 == 0 {
+			pat.Values[i] = 1
+		}
+	}
+	// runs a trial, returning the summed Input -> Hidden GSyns in the
+	// first and second halves of the trial
+	runTrial := func(sched map[int]float32) (first, second float32) {
+		net := createNetwork([]int{4, 4}, t)
+		net.SetRndSeed(1) // same weights in each run
+		net.InitWts()
+		inLay := net.AxonLayerByName("Input")
+		pj := net.AxonLayerByName("Hidden").RcvPrjns[0]
+		assert.NoError(t, pj.SetScaleSchedule(sched))
+		ctx := NewContext()
+		net.InitExt()
+		inLay.ApplyExt(pat)
+		net.ApplyExts(ctx)
+		net.NewState(ctx)
+		ctx.NewState(etime.Train)
+		for cyc := 0; cyc < 200; cyc++ {
+			net.Cycle(ctx)
+			ctx.CycleInc()
+			if cyc == 149 {
+				net.MinusPhase(ctx)
+				ctx.NewPhase(true)
+				net.PlusPhaseStart(ctx)
+			}
+			for _, g := range pj.GSyns {
+				if cyc < 100 {
+					first += g
+				} else {
+					second += g
+				}
+			}
+		}
+		return
+	}
+	baseFirst, baseSecond := runTrial(nil)
+	assert.Greater(t, baseFirst, float32(0))
+	assert.Greater(t, baseSecond, float32(0))
+
+	// the input layer activity is unaffected, so conductance scales linearly
+	halfFirst, halfSecond := runTrial(map[int]float32{0: 0.5})
+	assert.InDelta(t, 0.5*baseFirst, halfFirst, float64(0.001*baseFirst))
+	assert.InDelta(t, 0.5*baseSecond, halfSecond, float64(0.001*baseSecond))
+
+	gateFirst, gateSecond := runTrial(map[int]float32{0: 0, 100: 1})
+	assert.Equal(t, float32(0), gateFirst)
+	assert.Greater(t, gateSecond, float32(0))
+
+	net := createNetwork([]int{4, 4}, t)
+	pj := net.AxonLayerByName("Hidden").RcvPrjns[0]
+	assert.NoError(t, pj.SetScaleSchedule(map[int]float32{150: 1, 10: 0.2}))
+	assert.Equal(t, []int32{10, 150}, pj.ScaleSchedCycs)
+	assert.Equal(t, float32(1), pj.ScaleSchedGain(5))
+	assert.Equal(t, float32(0.2), pj.ScaleSchedGain(10))
+	assert.Equal(t, float32(0.2), pj.ScaleSchedGain(149))
+	assert.Equal(t, float32(1), pj.ScaleSchedGain(150))
+
+	// negative gains are rejected, leaving the schedule unchanged
+	assert.Error(t, pj.SetScaleSchedule(map[int]float32{0: 1, 50: -0.5}))
+	assert.Equal(t, []int32{10, 150}, pj.ScaleSchedCycs)
+
+	assert.NoError(t, pj.SetScaleSchedule(nil))
+	assert.Nil(t, pj.ScaleSchedCycs)
+}
+
+func TestBreakSymmetry(t *testing.T) {
+	net := NewNetwork("SymTest")
+	inLay := net.AddLayer2D("Input", 3, 3, InputLayer)
+	hidLay := net.AddLayer2D("Hidden", 1, 2, SuperLayer)
+	pj := net.ConnectLayers(inLay, hidLay, prjn.NewFull(), ForwardPrjn)
+	assert.NoError(t, net.Build())
+	net.Defaults()
+	pj.Params.SWt.Init.Var = 0
+	net.InitWts()
+
+	recvWts := func(ri int) []float32 {
+		syns := pj.RecvSyns(ri)
+		wts := make([]float32, len(syns))
+		for ci := range syns {
+			wts[ci] = syns[ci].Wt
+		}
+		return wts
+	}
+	orig := recvWts(0)
+	assert.Equal(t, orig, recvWts(1))
+
+	pj.BreakSymmetry(0) // no-op
+	assert.Equal(t, orig, recvWts(1))
+
+	pj.BreakSymmetry(0.02)
+	w0, w1 := recvWts(0), recvWts(1)
+	assert.NotEqual(t, w0, w1)
+	for ci := range w0 {
+		assert.InDelta(t, orig[ci], w0[ci], 0.02)
+		assert.InDelta(t, orig[ci], w1[ci], 0.02)
+	}
+	for si := range pj.Syns {
+		sy := &pj.Syns[si]
+		assert.InDelta(t, sy.Wt, pj.Params.SWt.WtVal(sy.SWt, sy.LWt), 1.0e-6)
+	}
+}
+
+func TestWtMatrixRank(t *testing.T) {
+	net := NewNetwork("RankTest")
+	inLay := net.AddLayer2D("Input", 2, 3, InputLayer)
+	hid := net.AddLayer2D("Hidden", 1, 5, SuperLayer)
+	pj := net.ConnectLayers(inLay, hid, prjn.NewFull(), ForwardPrjn)
+	assert.NoError(t, net.Build())
+	net.Defaults()
+	net.InitWts()
+
+	// rank 2 outer product weights
+	u1 := []float32{0.1, 0.2, 0.3, 0.4, 0.5}
+	v1 := []float32{0.5, 0.4, 0.3, 0.2, 0.1, 0.5}
+	u2 := []float32{0.3, 0.1, 0.4, 0.1, 0.5}
+	v2 := []float32{0.1, 0.5, 0.1, 0.5, 0.3, 0.2}
+	pj.SetWtsFunc(func(si, ri int, send, recv *etensor.Shape) float32 {
+		return u1[ri]*v1[si] + u2[ri]*v2[si]
+	})
+	mat := pj.WtMatrix()
+	assert.Equal(t, []int{5, 6}, mat.Shapes())
+	assert.InDelta(t, u1[2]*v1[4]+u2[2]*v2[4], mat.Value([]int{2, 4}), 1.0e-6)
+	assert.Equal(t, 2, pj.WtMatrixRank(1.0e-4))
+	assert.Equal(t, map[string]int{pj.Name(): 2}, net.WtRankByClass(1.0e-4, "ForwardPrjn"))
+	assert.Equal(t, 0, len(net.WtRankByClass(1.0e-4, "BackPrjn")))
+
+	id := etensor.NewFloat32([]int{4, 3}, nil, nil)
+	for i := 0; i < 3; i++ {
+		id.Set([]int{i, i}, float32(i+1))
+	}
+	assert.Equal(t, 3, MatrixRank(id, 1.0e-4))
+	svs := SingularVals(id)
+	assert.InDelta(t, 3, svs[0], 1.0e-5)
+	assert.InDelta(t, 1, svs[2], 1.0e-5)
+	assert.Equal(t, 2, MatrixRank(id, 0.4)) // 1 < 0.4 * 3
+	assert.Equal(t, 0, MatrixRank(etensor.NewFloat32([]int{3, 3}, nil, nil), 1.0e-4))
+}
+
+func TestWtDecomp(t *testing.T) {
+	net := NewNetwork("DecompTest")
+	inLay := net.AddLayer2D("Input", 2, 3, InputLayer)
+	hid := net.AddLayer2D("Hidden", 1, 5, SuperLayer)
+	pj := net.ConnectLayers(inLay, hid, prjn.NewFull(), ForwardPrjn)
+	assert.NoError(t, net.Build())
+	net.Defaults()
+	net.InitWts()
+
+	swt, lwt, wt := pj.WtDecomp()
+	assert.Equal(t, len(pj.Syns), len(wt))
+	for si := range pj.Syns {
+		sy := &pj.Syns[si]
+		assert.Equal(t, sy.SWt, swt[si])
+		assert.Equal(t, sy.LWt, lwt[si])
+		assert.Equal(t, sy.Wt, wt[si])
+		assert.InDelta(t, wt[si], pj.Params.SWt.WtVal(swt[si], lwt[si]), 1.0e-3)
+	}
+
+	// all structural: neutral LWt
+	for si := range pj.Syns {
+		sy := &pj.Syns[si]
+		sy.SWt = 0.3 + 0.02*float32(si%10)
+		sy.LWt = 0.5
+		sy.Wt = pj.Params.SWt.WtVal(sy.SWt, sy.LWt)
+	}
+	assert.InDelta(t, 0, pj.LWtContribution(), 1.0e-4)
+
+	// all learned: uniform SWt
+	for si := range pj.Syns {
+		sy := &pj.Syns[si]
+		sy.SWt = 0.5
+		sy.LWt = 0.3 + 0.04*float32(si%10)
+		sy.Wt = pj.Params.SWt.WtVal(sy.SWt, sy.LWt)
+	}
+	assert.InDelta(t, 1, pj.LWtContribution(), 1.0e-4)
+
+	// mixture
+	for si := range pj.Syns {
+		sy := &pj.Syns[si]
+		sy.SWt = 0.3 + 0.02*float32(si%10)
+		sy.LWt = 0.47 + 0.01*float32(si%7)
+		sy.Wt = pj.Params.SWt.WtVal(sy.SWt, sy.LWt)
+	}
+	lc := pj.LWtContribution()
+	assert.Greater(t, lc, float32(0.1))
+	assert.Less(t, lc, float32(0.9))
+}
+
+func TestSynCaVals(t *testing.T) {
+	net := createNetwork([]int{4, 4}, t)
+	inLay := net.AxonLayerByName("Input")
+	hid := net.AxonLayerByName("Hidden")
+	pj := hid.RcvPrjns[0]
+	// update all synapses on every spike, as in the standalone equations
+	inLay.Params.Learn.CaLrn.UpdtThr = 0
+	hid.Params.Learn.CaLrn.UpdtThr = 0
+	kp := &pj.Params.Learn.KinaseCa
+	net.SetRndSeed(1)
+	net.InitWts()
+
+	pat := etensor.NewFloat32([]int{4, 4}, nil, nil)
+	for i := range pat.Values {
+		if i%3 == 0 {
+			pat.Values[i] = 1
+		}
+	}
+	ctx := NewContext()
+	net.InitExt()
+	inLay.ApplyExt(pat)
+	net.ApplyExts(ctx)
+	net.NewState(ctx)
+	ctx.NewState(etime.Train)
+
+	// standalone kinaseq SynSpkCont integration, driven by the spike trains
+	// of the sending and receiving neurons in the network: Ca drives the
+	// cascade on every cycle where either neuron spikes, and all values
+	// decay on every cycle, whereas the network only updates at spikes and
+	// catches up on the decay in between.  The standalone values are
+	// captured at each update, for comparison with the values as of CaUpT.
+	mdt, pdt, ddt := 1/kp.Dt.MTau, 1/kp.Dt.PTau, 1/kp.Dt.DTau
+	ns := len(pj.Syns)
+	cm := make([]float32, ns)
+	cp := make([]float32, ns)
+	cd := make([]float32, ns)
+	sm := make([]float32, ns)
+	sp := make([]float32, ns)
+	sd := make([]float32, ns)
+	supt := make([]int32, ns)
+	for cyc := 0; cyc < 200; cyc++ {
+		net.Cycle(ctx)
+		for ri := range hid.Neurons {
+			rn := &hid.Neurons[ri]
+			syns := pj.RecvSyns(ri)
+			for ci := range syns {
+				si := int(pj.RecvCon[ri].Start) + ci
+				sn := &inLay.Neurons[pj.Params.SynSendLayIdx(&syns[ci])]
+				spk := sn.Spike > 0 || rn.Spike > 0
+				ca := float32(0)
+				if spk {
+					ca = kp.SpikeG * sn.CaSyn * rn.CaSyn
+				}
+				cm[si] += mdt * (ca - cm[si])
+				cp[si] += pdt * (cm[si] - cp[si])
+				cd[si] += ddt * (cp[si] - cd[si])
+				if spk {
+					sm[si], sp[si], sd[si] = cm[si], cp[si], cd[si]
+					supt[si] = ctx.CyclesTotal
+				}
+			}
+		}
+		ctx.CycleInc()
+	}
+
+	caM, caP, caD := pj.SynCaVals()
+	assert.Equal(t, ns, len(caP))
+	maxP := float32(0)
+	for si := range pj.Syns {
+		maxP = mat32.Max(maxP, sp[si])
+	}
+	assert.Greater(t, maxP, float32(0.05)) // some synapses are driven
+	for si := range pj.Syns {
+		sy := &pj.Syns[si]
+		assert.Equal(t, sy.CaM, caM[si])
+		assert.Equal(t, sy.CaP, caP[si])
+		assert.Equal(t, sy.CaD, caD[si])
+		assert.Equal(t, supt[si], sy.CaUpT)
+		// the network decays between spikes using the approximate
+		// 4 msec FmCa4 steps in CurCa, which leaves CaP and CaD up to
+		// about 20% lower than the per-cycle integration
+		assert.InDelta(t, sm[si], caM[si], float64(0.02*sm[si])+1.0e-6)
+		assert.InDelta(t, sp[si], caP[si], float64(0.25*sp[si])+1.0e-6)
+		assert.InDelta(t, sd[si], caD[si], float64(0.25*sd[si])+1.0e-6)
+	}
+}
+
+func TestNegativeWts(t *testing.T) {
+	net := NewNetwork("NegTest")
+	inLay := net.AddLayer2D("Input", 1, 2, InputLayer)
+	hid := net.AddLayer2D("Hidden", 1, 2, SuperLayer)
+	pj := net.ConnectLayers(inLay, hid, prjn.NewFull(), ForwardPrjn)
+	assert.NoError(t, net.Build())
+	net.Defaults()
+	pj.Params.SWt.AllowNegative.SetBool(true)
+	inLay.Params.Inhib.Layer.On.SetBool(false) // both inputs fully active
+	net.InitWts()
+	// separate buffer for the negative inputs
+	assert.Equal(t, 2*len(hid.Neurons)*int(net.MaxDelay+1), len(pj.GBuf))
+
+	sp := &pj.Params.SWt
+	assert.Equal(t, float32(-0.8), sp.ClipSWt(-0.9))
+	assert.Equal(t, float32(-0.2), sp.ClipSWt(-0.1))
+	assert.Equal(t, float32(-1), sp.ClipWt(-1.5))
+	dwt, wt, lwt := float32(0.1), float32(-0.5), float32(0.5)
+	sp.WtFmDWt(&dwt, &wt, &lwt, -0.5)
+	assert.InDelta(t, 0.4, lwt, 1.0e-6)
+	assert.Greater(t, wt, float32(-0.5)) // less negative
+
+	setWts := func(w0, w1 float32) {
+		for ri := range hid.Neurons {
+			syns := pj.RecvSyns(ri)
+			for ci := range syns {
+				sy := &syns[ci]
+				sy.SWt = w0
+				if pj.Params.SynSendLayIdx(sy) == 1 {
+					sy.SWt = w1
+				}
+				sy.LWt = 0.5
+				sy.Wt = sp.WtVal(sy.SWt, sy.LWt)
+			}
+		}
+	}
+	ctx := NewContext()
+	run := func(in0, in1 float32) (nspk int, gi float32) {
+		net.InitActs()
+		pat := etensor.NewFloat32([]int{1, 2}, nil, nil)
+		pat.Values[0] = in0
+		pat.Values[1] = in1
+		net.InitExt()
+		inLay.ApplyExt(pat)
+		net.ApplyExts(ctx)
+		net.NewState(ctx)
+		ctx.NewState(etime.Train)
+		for cyc := 0; cyc < 150; cyc++ {
+			net.Cycle(ctx)
+			ctx.CycleInc()
+			for ni := range hid.Neurons {
+				nspk += int(hid.Neurons[ni].Spike)
+			}
+		}
+		return nspk, hid.Neurons[0].GiSyn
+	}
+	setWts(0.8, 0.5)
+	posSpk, _ := run(1, 1)
+	setWts(0.8, -0.3)
+	negSpk, _ := run(1, 1)
+	assert.Greater(t, posSpk, 0)
+	assert.Less(t, negSpk, posSpk)
+
+	setWts(0.8, -0.8)
+	nspk, gi := run(0, 1) // only the negative input is active
+	assert.Equal(t, 0, nspk)
+	assert.Greater(t, gi, float32(0))
+	assert.Equal(t, float32(0), hid.Neurons[0].GeSyn)
+}
+
+func TestSymmetryError(t *testing.T) {
+	net := createNetwork([]int{3, 3}, t)
+	hid := net.AxonLayerByName("Hidden")
+	out := net.AxonLayerByName("Output")
+	inToHid := hid.RcvPrjns[0]
+	hidToOut := out.RcvPrjns[0]
+	outToHid := hid.RcvPrjns[1]
+	assert.Equal(t, out, outToHid.Send)
+
+	assert.Equal(t, float32(0), net.SymmetryError(hidToOut, outToHid))
+	assert.Equal(t, float32(0), net.SymmetryError(outToHid, hidToOut))
+
+	hidToOut.BreakSymmetry(0.1)
+	serr := net.SymmetryError(hidToOut, outToHid)
+	assert.Greater(t, serr, float32(0.01))
+	assert.Less(t, serr, float32(0.1))
+	assert.InDelta(t, serr, net.SymmetryError(outToHid, hidToOut), 1.0e-6)
+
+	net.InitWts() // re-symmetrizes
+	assert.Equal(t, float32(0), net.SymmetryError(hidToOut, outToHid))
+
+	assert.Equal(t, float32(-1), net.SymmetryError(inToHid, hidToOut))
+}
+
+func TestAccumulateDWt(t *testing.T) {
+	net := createNetwork([]int{3, 3}, t)
+	hid := net.AxonLayerByName("Hidden")
+	pj := hid.RcvPrjns[0]
+	pj.Params.PrjnType = RWPrjn // linear weight update
+	ctx := NewContext()
+
+	nTrials := 5
+	dwt := func(trl, si int) float32 {
+		return 0.001 * float32(trl+1) * float32(si%5-2)
+	}
+	wts0 := make([]float32, len(pj.Syns))
+	for si := range pj.Syns {
+		wts0[si] = pj.Syns[si].Wt
+	}
+
+	// separate application on each trial
+	for trl := 0; trl < nTrials; trl++ {
+		for si := range pj.Syns {
+			pj.Syns[si].DWt += dwt(trl, si)
+		}
+		net.WtFmDWt(ctx)
+	}
+	sepWts := make([]float32, len(pj.Syns))
+	for si := range pj.Syns {
+		sepWts[si] = pj.Syns[si].Wt
+		pj.Syns[si].Wt = wts0[si]
+		pj.Syns[si].LWt = wts0[si]
+	}
+
+	// accumulated over trials, applied once
+	pj.Params.Learn.AccumulateDWt.SetBool(true)
+	for trl := 0; trl < nTrials; trl++ {
+		for si := range pj.Syns {
+			pj.Syns[si].DWt += dwt(trl, si)
+		}
+		net.WtFmDWt(ctx)
+		for si := range pj.Syns {
+			assert.Equal(t, wts0[si], pj.Syns[si].Wt)
+		}
+	}
+	assert.NotEqual(t, float32(0), pj.Syns[0].DWt)
+	net.ApplyAccumulatedDWt(ctx)
+	for si := range pj.Syns {
+		sy := &pj.Syns[si]
+		assert.InDelta(t, sepWts[si], sy.Wt, 1.0e-6)
+		assert.Equal(t, float32(0), sy.DWt)
+	}
+	assert.NotEqual(t, wts0[0], pj.Syns[0].Wt)
+}
+
+func TestContinuousDWt(t *testing.T) {
+	netT := createNetwork([]int{4, 4}, t) // theta-based
+	netC := createNetwork([]int{4, 4}, t) // continuous
+	assert.False(t, netC.HasContinuousDWt())
+	for pi, pj := range netC.Prjns {
+		copy(pj.Syns, netT.Prjns[pi].Syns)
+		pj.Params.Learn.ContinuousDWt.SetBool(true)
+		pj.Params.Learn.LRate.Base *= float32(pj.Params.Learn.ContinuousCycles) / 200
+		pj.Params.Learn.LRate.Update()
+	}
+	assert.True(t, netC.HasContinuousDWt())
+
+	inPat := etensor.NewFloat32([]int{4, 4}, nil, nil)
+	outPat := etensor.NewFloat32([]int{4, 4}, nil, nil)
+	for i := range inPat.Values {
+		if i%3 == 0 {
+			inPat.Values[i] = 1
+		}
+		if i%4 == 1 {
+			outPat.Values[i] = 1
+		}
+	}
+	// trial runs one trial, calling cycFun after each cycle
+	trial := func(net *Network, cycFun func(cyc int)) {
+		ctx := NewContext()
+		net.InitExt()
+		net.AxonLayerByName("Input").ApplyExt(inPat)
+		net.AxonLayerByName("Output").ApplyExt(outPat)
+		net.ApplyExts(ctx)
+		net.NewState(ctx)
+		ctx.NewState(etime.Train)
+		for cyc := 0; cyc < 200; cyc++ {
+			net.Cycle(ctx)
+			ctx.CycleInc()
+			if cyc == 149 {
+				net.MinusPhase(ctx)
+				ctx.NewPhase(true)
+				net.PlusPhaseStart(ctx)
+			}
+			cycFun(cyc)
+		}
+		net.PlusPhase(ctx)
+		net.DWt(ctx)
+		net.WtFmDWt(ctx)
+	}
+
+	pjT := netT.AxonLayerByName("Output").RcvPrjns[0]
+	pjC := netC.AxonLayerByName("Output").RcvPrjns[0]
+	_, _, wt0 := pjT.WtDecomp()
+	trial(netT, func(cyc int) {
+		_, _, wt := pjT.WtDecomp()
+		assert.Equal(t, wt0, wt) // only changes at the end of the trial
+	})
+	changed := false
+	trial(netC, func(cyc int) {
+		if cyc == 149 { // end of minus phase
+			_, _, wt := pjC.WtDecomp()
+			changed = changed || !assert.ObjectsAreEqual(wt0, wt)
+		}
+		for _, dw := range pjC.DWtSnapshot() {
+			assert.Equal(t, float32(0), dw) // applied immediately
+		}
+	})
+	assert.True(t, changed) // changed within the trial
+	for trl := 1; trl < 5; trl++ {
+		trial(netT, func(cyc int) {})
+		trial(netC, func(cyc int) {})
+	}
+
+	_, _, wtT := pjT.WtDecomp()
+	_, _, wtC := pjC.WtDecomp()
+	assert.NotEqual(t, wt0, wtT)
+	assert.NotEqual(t, wt0, wtC)
+	assert.NotEqual(t, wtT, wtC)
+}
+
+func TestSelfMaintPrjn(t *testing.T) {
+	net := NewNetwork("SelfMaintTest")
+	inLay := net.AddLayer2D("Input", 4, 4, InputLayer)
+	hidLay := net.AddLayer2D("Hidden", 4, 4, SuperLayer)
+	ptLay := net.AddPTMaintLayer2D("PT", 4, 4)
+	full := prjn.NewFull()
+	net.ConnectLayers(inLay, hidLay, full, ForwardPrjn)
+	net.ConnectLayers(hidLay, ptLay, full, ForwardPrjn)
+	lat := net.LateralConnectLayer(hidLay, full)
+	sm := hidLay.AddSelfMaint(full, 3)
+	ptSelf := net.ConnectPTMaintSelf(ptLay, full)
+	assert.NoError(t, net.Build())
+	net.Defaults()
+
+	assert.True(t, sm.IsSelf())
+	assert.True(t, sm.SelfMaint)
+	assert.Equal(t, "SelfMaint", sm.Cls)
+	assert.Equal(t, float32(0.0001), sm.Params.Learn.LRate.Base)
+	assert.Equal(t, float32(3), sm.Params.PrjnScale.Abs)
+	assert.Equal(t, float32(0.5), sm.Params.SWt.Init.Var)
+
+	// a standard lateral self-projection keeps the standard defaults
+	assert.True(t, lat.IsSelf())
+	assert.False(t, lat.SelfMaint)
+	assert.NotEqual(t, sm.Params.Learn.LRate.Base, lat.Params.Learn.LRate.Base)
+	assert.Equal(t, float32(1), lat.Params.PrjnScale.Abs)
+
+	assert.True(t, ptSelf.SelfMaint)
+	assert.Equal(t, "SelfMaint PTSelfMaint", ptSelf.Cls)
+	assert.Equal(t, float32(0.0001), ptSelf.Params.Learn.LRate.Base)
+	assert.Equal(t, float32(2), ptSelf.Params.PrjnScale.Abs)
+
+	// persists through re-applying Defaults
+	sm.Params.Learn.LRate.Base = 0.1
+	net.Defaults()
+	assert.Equal(t, float32(0.0001), sm.Params.Learn.LRate.Base)
+
+	assert.False(t, hidLay.RcvPrjns[0].IsSelf())
+}
@@ -30,6 +30,9 @@ type PrjnBase struct {
 	Recv          *Layer             `desc:"receiving layer for this projection"`
 	Pat           prjn.Pattern       `desc:"pattern of connectivity"`
 	Typ           PrjnTypes          `desc:"type of projection -- Forward, Back, Lateral, or extended type in specialized algorithms -- matches against .Cls parameter styles (e.g., .Back etc)"`
+	InitSeed      int64              `desc:"if non-zero, the weights are initialized from an independent random number stream with this seed, instead of the shared network generator, so that they are not affected by adding or removing other projections -- see InitWtsSeeded"`
+	SelfMaint     bool               `desc:"this is a recurrent self-maintenance projection within a layer (Send == Recv), which gets the slower learning and other SelfMaintDefaults parameters in Defaults, instead of the standard ones -- see Layer.AddSelfMaint"`
+	SelfMaintAbs  float32            `viewif:"SelfMaint" desc:"absolute scaling PrjnScale.Abs set by Defaults for a SelfMaint projection"`
 	ParamsHistory params.HistoryImpl `desc:"provides a history of parameters applied to the layer"`
 
 	RecvConNAvgMax minmax.AvgMax32 `inactive:"+" view:"inline" desc:"average and maximum number of recv connections in the receiving layer"`
@@ -44,8 +47,20 @@ type PrjnBase struct {
 	SendConIdx []uint32 `view:"-" desc:"[SendNeurons[[SendCon.N RecvNeurons] index of other neuron that receives the sender's synaptic input, ordered by the sending layer's order of units as the outer loop, and SendCon.N receiving units within that.  It is generally preferable to use the Synapse SendIdx where needed, instead of this slice, because then the memory access will be close by other values on the synapse."`
 
 	// spike aggregation values:
-	GBuf  []int32   `view:"-" desc:"[RecvNeurons][Params.Com.MaxDelay] Ge or Gi conductance ring buffer for each neuron, accessed through Params.Com.ReadIdx, WriteIdx -- scale * weight is added with Com delay offset -- a subslice from network PrjnGBuf. Uses int-encoded float values for faster GPU atomic integration"`
+	GBuf  []int32   `view:"-" desc:"[RecvNeurons][Params.Com.MaxDelay] Ge or Gi conductance ring buffer for each neuron, accessed through Params.Com.ReadIdx, WriteIdx -- scale * weight is added with Com delay offset -- a subslice from network PrjnGBuf. Uses int-encoded float values for faster GPU atomic integration.  With SWt.AllowNegative, a second buffer of the same size follows, for negative values (see Com.NegIdx)."`
 	GSyns []float32 `view:"-" desc:"[RecvNeurons] projection-level synaptic conductance values, integrated by prjn before being integrated at the neuron level, which enables the neuron to perform non-linear integration as needed -- a subslice from network PrjnGSyn."`
+
+	// trial-level conductance measures:
+	GSynSum float32 `view:"-" desc:"sum over cycles of the current trial of the receiving-layer average GSyns value, accumulated in CyclePost if Layer.RecGSyn (CPU only)"`
+	GSynN   int     `view:"-" desc:"number of cycles accumulated in GSynSum"`
+	GSynTrl float32 `inactive:"+" desc:"average GSyns conductance contribution from this projection over the cycles of the last trial, computed in PlusPhasePost from GSynSum -- see Network.EffectiveInputTable (CPU only)"`
+
+	// optional time-varying scaling:
+	ScaleSchedCycs  []int32   `view:"-" desc:"cycles within the trial at which the ScaleSchedGains take effect, in increasing order -- see SetScaleSchedule"`
+	ScaleSchedGains []float32 `view:"-" desc:"gain multipliers on GScale.Scale taking effect at the corresponding ScaleSchedCycs cycle -- see SetScaleSchedule"`
+
+	// optional weight age tracking:
+	LastDWtCyc []int32 `view:"-" desc:"[RecvNeurons][RecvCon.N SendingNeurons] Context.CyclesTotal when each synapse last had a non-zero DWt applied in WtFmDWt, or -1 if never -- only allocated when enabled by TrackWtAge (CPU only)"`
 }
 
 // emer.Prjn interface
@@ -72,6 +87,10 @@ func (pj *PrjnBase) Type() emer.PrjnType                   { return emer.PrjnTyp
 func (pj *PrjnBase) PrjnTypeName() string                  { return pj.Typ.String() }
 func (pj *PrjnBase) IsOff() bool                           { return pj.Off }
 
+// IsSelf returns true if this is a self-projection, with the same
+// sending and receiving layer, e.g., from LateralConnectLayer.
+func (pj *PrjnBase) IsSelf() bool { return pj.Send == pj.Recv }
+
 // SetOff individual projection. Careful: Layer.SetOff(true) will reactivate all prjns of that layer,
 // so prjn-level lesioning should always be done last.
 func (pj *PrjnBase) SetOff(off bool) { pj.Off = off }
@@ -200,6 +219,65 @@ func (pj *PrjnBase) SetConStartN(con *[]StartN, avgmax *minmax.AvgMax32, tn *ete
 	return idx
 }
 
+// UpdtConNAvgMax recomputes the RecvConNAvgMax and SendConNAvgMax stats
+// from the current RecvCon and SendCon N values, e.g., after
+// structural changes in the number of connections.
+func (pj *PrjnBase) UpdtConNAvgMax() {
+	pj.RecvConNAvgMax.Init()
+	for ri, rcon := range pj.RecvCon {
+		pj.RecvConNAvgMax.UpdateVal(float32(rcon.N), int32(ri))
+	}
+	pj.RecvConNAvgMax.CalcAvg()
+	pj.SendConNAvgMax.Init()
+	for si, scon := range pj.SendCon {
+		pj.SendConNAvgMax.UpdateVal(float32(scon.N), int32(si))
+	}
+	pj.SendConNAvgMax.CalcAvg()
+}
+
+// RebuildSendIdxs rebuilds the sender-based SendCon, SendSynIdx and
+// SendConIdx indexes from the receiver-based RecvCon and RecvConIdx,
+// which are authoritative for the current connectivity.
+// This must be called after any structural changes that alter the
+// RecvCon N values (e.g., pruning synapses), which can only reduce
+// the number of connections relative to the originally allocated ones.
+func (pj *PrjnBase) RebuildSendIdxs() {
+	if pj.Off {
+		return
+	}
+	slen := len(pj.SendCon)
+	sconN := make([]uint32, slen)
+	for ri, rcon := range pj.RecvCon {
+		for ci := uint32(0); ci < rcon.N; ci++ {
+			si := pj.RecvConIdx[rcon.Start+ci]
+			if int(si) >= slen {
+				log.Printf("%v programmer error: send idx: %v out of range at recv idx: %v\n", pj.String(), si, ri)
+				continue
+			}
+			sconN[si]++
+		}
+	}
+	idx := uint32(0)
+	for si := range pj.SendCon {
+		pj.SendCon[si] = StartN{Start: idx, N: sconN[si]}
+		idx += sconN[si]
+		sconN[si] = 0
+	}
+	for ri, rcon := range pj.RecvCon {
+		for ci := uint32(0); ci < rcon.N; ci++ {
+			si := pj.RecvConIdx[rcon.Start+ci]
+			if int(si) >= slen {
+				continue
+			}
+			scon := pj.SendCon[si]
+			sci := scon.Start + sconN[si]
+			pj.SendConIdx[sci] = uint32(ri)
+			pj.SendSynIdx[sci] = rcon.Start + ci
+			sconN[si]++
+		}
+	}
+}
+
 // String satisfies fmt.Stringer for prjn
 func (pj *PrjnBase) String() string {
 	str := ""
@@ -93,9 +93,11 @@ type PrjnParams struct {
 	//     each applies to a specific prjn type.
 	//     use the `viewif` field tag to condition on PrjnType.
 
-	RLPred RLPredPrjnParams `viewif:"PrjnType=[RWPrjn,TDPredPrjn]" view:"inline" desc:"Params for RWPrjn and TDPredPrjn for doing dopamine-modulated learning for reward prediction: Da * Send activity. Use in RWPredLayer or TDPredLayer typically to generate reward predictions. If the Da sign is positive, the first recv unit learns fully; for negative, second one learns fully.  Lower lrate applies for opposite cases.  Weights are positive-only."`
-	Matrix MatrixPrjnParams `viewif:"PrjnType=MatrixPrjn" view:"inline" desc:"for trace-based learning in the MatrixPrjn. A trace of synaptic co-activity is formed, and then modulated by dopamine whenever it occurs.  This bridges the temporal gap between gating activity and subsequent activity, and is based biologically on synaptic tags. Trace is reset at time of reward based on ACh level from CINs."`
-	BLAAcq BLAAcqPrjnParams `viewif:"PrjnType=BLAAcqPrjn" view:"inline" desc:"Basolateral Amygdala acquisition pathway projection parameters, for negative activation delta direction (extinction)."`
+	RLPred     RLPredPrjnParams `viewif:"PrjnType=[RWPrjn,TDPredPrjn]" view:"inline" desc:"Params for RWPrjn and TDPredPrjn for doing dopamine-modulated learning for reward prediction: Da * Send activity. Use in RWPredLayer or TDPredLayer typically to generate reward predictions. If the Da sign is positive, the first recv unit learns fully; for negative, second one learns fully.  Lower lrate applies for opposite cases.  Weights are positive-only."`
+	Matrix     MatrixPrjnParams `viewif:"PrjnType=MatrixPrjn" view:"inline" desc:"for trace-based learning in the MatrixPrjn. A trace of synaptic co-activity is formed, and then modulated by dopamine whenever it occurs.  This bridges the temporal gap between gating activity and subsequent activity, and is based biologically on synaptic tags. Trace is reset at time of reward based on ACh level from CINs."`
+	BLAAcq     BLAAcqPrjnParams `viewif:"PrjnType=BLAAcqPrjn" view:"inline" desc:"Basolateral Amygdala acquisition pathway projection parameters, for negative activation delta direction (extinction)."`
+	DWtDAClamp DWtDAClampParams `viewif:"PrjnType=[MatrixPrjn,VSPatchPrjn]" view:"inline" desc:"controls the dopamine-driven weight changes in MatrixPrjn and VSPatchPrjn when dopamine is negative: allowed, floored, or scaled."`
+	InhibLearn InhibLearnParams `viewif:"PrjnType=InhibPrjn" view:"inline" desc:"homeostatic inhibitory plasticity for InhibPrjn projections from an InhibInterneuronLayer, which learn to keep receiving activity around a target level."`
 
 	Idxs PrjnIdxs `view:"-" desc:"recv and send neuron-level projection index array access info"`
 }
@@ -108,6 +110,8 @@ func (pj *PrjnParams) Defaults() {
 	pj.RLPred.Defaults()
 	pj.Matrix.Defaults()
 	pj.BLAAcq.Defaults()
+	pj.DWtDAClamp.Defaults()
+	pj.InhibLearn.Defaults()
 }
 
 func (pj *PrjnParams) Update() {
@@ -118,10 +122,16 @@ func (pj *PrjnParams) Update() {
 	pj.RLPred.Update()
 	pj.Matrix.Update()
 	pj.BLAAcq.Update()
+	pj.DWtDAClamp.Update()
+	pj.InhibLearn.Update()
 
 	if pj.PrjnType == CTCtxtPrjn {
 		pj.Com.GType = ContextG
 	}
+	if pj.PrjnType == IdentityPrjn { // InitGScale assumes weights of 1
+		pj.SWt.Init.Mean = 1
+		pj.SWt.Init.Var = 0
+	}
 }
 
 func (pj *PrjnParams) AllParams() string {
@@ -145,6 +155,9 @@ func (pj *PrjnParams) AllParams() string {
 	case BLAAcqPrjn:
 		b, _ = json.MarshalIndent(&pj.BLAAcq, "", " ")
 		str += "BLAAcq: {\n " + JsonToParams(b)
+	case InhibPrjn:
+		b, _ = json.MarshalIndent(&pj.InhibLearn, "", " ")
+		str += "InhibLearn: {\n " + JsonToParams(b)
 	}
 	return str
 }
@@ -169,6 +182,27 @@ func (pj *PrjnParams) SetFixedWts() {
 	pj.SWt.Init.Sym.SetBool(false)
 }
 
+// IdentityPrjnDefaults sets defaults for the IdentityPrjn type:
+// fixed weights of 1, so the relayed Act is only scaled by PrjnScale.Abs.
+// The weights are kept at 1 in Update.
+func (pj *PrjnParams) IdentityPrjnDefaults() {
+	pj.SetFixedWts()
+	pj.SWt.Init.Mean = 1
+}
+
+// SelfMaintDefaults sets defaults for a recurrent self-maintenance
+// projection (see Layer.AddSelfMaint), with given PrjnScale.Abs:
+// a slower learning rate, so the maintained patterns are not rapidly
+// learned into the weights, and high initial weight variance,
+// so activity does not just spread out over time.
+func (pj *PrjnParams) SelfMaintDefaults(abs float32) {
+	pj.PrjnScale.Rel = 1
+	pj.PrjnScale.Abs = abs
+	pj.Learn.LRate.Base = 0.0001
+	pj.SWt.Init.Mean = 0.5
+	pj.SWt.Init.Var = 0.5
+}
+
 // SynRecvLayIdx converts the Synapse RecvIdx of recv neuron's index
 // in network level global list of all neurons to receiving
 // layer-specific index.
@@ -193,8 +227,21 @@ func (pj *PrjnParams) GatherSpikes(ctx *Context, ly *LayerParams, ni uint32, nrn
 	switch pj.Com.GType {
 	case ExcitatoryG:
 		*gSyn = ly.Act.Dt.GeSynFmRaw(*gSyn, gRaw)
-		nrn.GeRaw += gRaw
-		nrn.GeSyn += *gSyn
+		if pj.SWt.AllowNegative.IsTrue() { // net negative input is inhibitory
+			if gRaw < 0 {
+				nrn.GiRaw -= gRaw
+			} else {
+				nrn.GeRaw += gRaw
+			}
+			if *gSyn < 0 {
+				nrn.GiSyn -= *gSyn
+			} else {
+				nrn.GeSyn += *gSyn
+			}
+		} else {
+			nrn.GeRaw += gRaw
+			nrn.GeSyn += *gSyn
+		}
 	case InhibitoryG:
 		*gSyn = ly.Act.Dt.GiSynFmRaw(*gSyn, gRaw)
 		nrn.GiRaw += gRaw
@@ -214,7 +261,7 @@ func (pj *PrjnParams) GatherSpikes(ctx *Context, ly *LayerParams, ni uint32, nrn
 // DoSynCa returns false if should not do synaptic-level calcium updating.
 // Done by default in Cortex, not for some other special projection types.
 func (pj *PrjnParams) DoSynCa() bool {
-	if pj.PrjnType == RWPrjn || pj.PrjnType == TDPredPrjn || pj.PrjnType == MatrixPrjn || pj.PrjnType == VSPatchPrjn || pj.PrjnType == BLAAcqPrjn || pj.PrjnType == BLAExtPrjn {
+	if pj.PrjnType == RWPrjn || pj.PrjnType == TDPredPrjn || pj.PrjnType == MatrixPrjn || pj.PrjnType == VSPatchPrjn || pj.PrjnType == BLAAcqPrjn || pj.PrjnType == BLAExtPrjn || pj.PrjnType == IdentityPrjn {
 		return false
 	}
 	return true
@@ -303,6 +350,12 @@ func (pj *PrjnParams) DWtSyn(ctx *Context, sy *Synapse, sn, rn *Neuron, layPool,
 		pj.DWtSynBLAAcq(ctx, sy, sn, rn, layPool, subPool)
 	case BLAExtPrjn:
 		pj.DWtSynBLAExt(ctx, sy, sn, rn, layPool, subPool)
+	case InhibPrjn:
+		if pj.InhibLearn.On.IsTrue() {
+			pj.DWtSynInhib(ctx, sy, sn, rn, layPool, subPool)
+		} else {
+			pj.DWtSynCortex(ctx, sy, sn, rn, layPool, subPool, isTarget)
+		}
 	default:
 		pj.DWtSynCortex(ctx, sy, sn, rn, layPool, subPool, isTarget)
 	}
@@ -385,6 +438,13 @@ func (pj *PrjnParams) DWtSynBLAExt(ctx *Context, sy *Synapse, sn, rn *Neuron, la
 	sy.DWt += rn.RLRate * pj.Learn.LRate.Eff * err
 }
 
+// DWtSynInhib computes the weight change (learning) at given synapse,
+// for InhibPrjn projections from an InhibInterneuronLayer, using the
+// InhibLearn homeostatic inhibitory plasticity rule.
+func (pj *PrjnParams) DWtSynInhib(ctx *Context, sy *Synapse, sn, rn *Neuron, layPool, subPool *Pool) {
+	sy.DWt += pj.Learn.LRate.Eff * pj.InhibLearn.DWt(sn.CaSpkD, rn.CaSpkD)
+}
+
 // DWtSynRWPred computes the weight change (learning) at given synapse,
 // for the RWPredPrjn type
 func (pj *PrjnParams) DWtSynRWPred(ctx *Context, sy *Synapse, sn, rn *Neuron, layPool, subPool *Pool) {
@@ -421,7 +481,8 @@ func (pj *PrjnParams) DWtSynRWPred(ctx *Context, sy *Synapse, sn, rn *Neuron, la
 }
 
 // DWtSynTDPred computes the weight change (learning) at given synapse,
-// for the TDRewPredPrjn type
+// for the TDRewPredPrjn type, using an eligibility trace of prior sending
+// activity in Tr for TD(lambda) learning if TDInteg.Lambda > 0.
 func (pj *PrjnParams) DWtSynTDPred(ctx *Context, sy *Synapse, sn, rn *Neuron, layPool, subPool *Pool) {
 	// todo: move all of this into rn.RLRate
 	lda := ctx.NeuroMod.DA
@@ -439,7 +500,12 @@ func (pj *PrjnParams) DWtSynTDPred(ctx *Context, sy *Synapse, sn, rn *Neuron, la
 		}
 	}
 
-	dwt := da * sn.SpkPrv // no recv unit activation, prior trial act
+	tr := sn.SpkPrv                 // no recv unit activation, prior trial act
+	if ctx.NeuroMod.TDTrDecay > 0 { // TD(lambda) eligibility trace
+		tr += ctx.NeuroMod.TDTrDecay * sy.Tr
+		sy.Tr = tr
+	}
+	dwt := da * tr
 	sy.DWt += eff_lr * dwt
 }
 
@@ -468,14 +534,18 @@ func (pj *PrjnParams) DWtSynMatrix(ctx *Context, sy *Synapse, sn, rn *Neuron, la
 	}
 
 	tr := sy.Tr
+	if !pj.Matrix.InCreditWindow(layPool.GatedTrls) {
+		tr = 0 // no gating within the window to give credit to
+	}
 	if pj.Matrix.CurTrlDA.IsTrue() { // off by default -- used for quick-and-dirty 1 trial
 		tr += dtr
 	}
 	// learning is based on current trace * RLRate(DA * ACh)
-	dwt += rn.RLRate * pj.Learn.LRate.Eff * tr
+	dwt += pj.DWtDAClamp.DWt(ctx.NeuroMod.DA, rn.RLRate*pj.Learn.LRate.Eff*tr)
 
 	// decay at time of US signaled by ACh
 	tr -= pj.Matrix.TraceDecay(ctx, ctx.NeuroMod.ACh) * tr
+	tr -= pj.Matrix.CreditDecay * tr // per-trial decay
 
 	// if we didn't get new trace already, add it
 	if pj.Matrix.CurTrlDA.IsFalse() {
@@ -497,7 +567,7 @@ func (pj *PrjnParams) DWtSynVSPatch(ctx *Context, sy *Synapse, sn, rn *Neuron, l
 	// note: rn.RLRate already has DA * (D1 vs. D2 sign reversal) factored in.
 	// and also the logic that non-positive DA leads to weight decreases.
 	dwt := rn.RLRate * pj.Learn.LRate.Eff * sn.CaSpkD * ract
-	sy.DWt += dwt
+	sy.DWt += pj.DWtDAClamp.DWt(ctx.NeuroMod.DA, dwt)
 }
 
 ///////////////////////////////////////////////////
@@ -531,7 +601,7 @@ func (pj *PrjnParams) WtFmDWtSynNoLimits(ctx *Context, sy *Synapse) {
 	if sy.DWt == 0 {
 		return
 	}
-	sy.Wt += sy.DWt
+	sy.Wt += pj.SWt.Adapt.ClampDelta(sy.DWt)
 	if sy.Wt < 0 {
 		sy.Wt = 0
 	}
@@ -83,6 +83,14 @@ const (
 	// Trace is reset at time of reward based on ACh level (from CINs in biology).
 	MatrixPrjn
 
+	// IdentityPrjn is a one-to-one fixed-weight relay projection that sends
+	// the sender's rate-code Act every cycle, instead of discrete spikes,
+	// such that the receiving GeSyn reproduces the sending Act times
+	// PrjnScale.Abs as a gain factor.  It does not learn, and is not
+	// included in the GScale Rel normalization of other projections.
+	// Use ConnectIdentity to create.
+	IdentityPrjn
+
 	PrjnTypesN
 )
 
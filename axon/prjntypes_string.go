@@ -24,12 +24,13 @@ func _() {
 	_ = x[BLAExtPrjn-8]
 	_ = x[VSPatchPrjn-9]
 	_ = x[MatrixPrjn-10]
-	_ = x[PrjnTypesN-11]
+	_ = x[IdentityPrjn-11]
+	_ = x[PrjnTypesN-12]
 }
 
-const _PrjnTypes_name = "ForwardPrjnBackPrjnLateralPrjnInhibPrjnCTCtxtPrjnRWPrjnTDPredPrjnBLAAcqPrjnBLAExtPrjnVSPatchPrjnMatrixPrjnPrjnTypesN"
+const _PrjnTypes_name = "ForwardPrjnBackPrjnLateralPrjnInhibPrjnCTCtxtPrjnRWPrjnTDPredPrjnBLAAcqPrjnBLAExtPrjnVSPatchPrjnMatrixPrjnIdentityPrjnPrjnTypesN"
 
-var _PrjnTypes_index = [...]uint8{0, 11, 19, 30, 39, 49, 55, 65, 75, 85, 96, 106, 116}
+var _PrjnTypes_index = [...]uint8{0, 11, 19, 30, 39, 49, 55, 65, 75, 85, 96, 106, 118, 128}
 
 func (i PrjnTypes) String() string {
 	if i < 0 || i >= PrjnTypes(len(_PrjnTypes_index)-1) {
@@ -336,9 +336,10 @@ func (vt *VTAVals) Zero() {
 //   - Dipping / pausing inhibitory inputs from lateral habenula (LHb) reflecting
 //     predicted positive outcome > actual, or actual negative > predicted.
 type VTA struct {
-	PVThr float32 `desc:"threshold for activity of PVpos or VSPatchPos to determine if a PV event (actual PV or omission thereof) is present"`
+	PVThr   float32 `desc:"threshold for activity of PVpos or VSPatchPos to determine if a PV event (actual PV or omission thereof) is present"`
+	PVnegDA float32 `def:"0" desc:"gain on the negative PV (PVneg) that is netted against the positive PV in computing DA -- 0 (default) leaves DA unaffected by negative USs -- 1 gives DA reflecting the net of simultaneous positive and negative outcomes"`
 
-	pad, pad1, pad2 float32
+	pad, pad1 float32
 
 	Gain VTAVals `view:"inline" desc:"gain multipliers on inputs from each input"`
 	Raw  VTAVals `view:"inline" inactive:"+" desc:"raw current values -- inputs to the computation"`
@@ -367,10 +368,11 @@ func (vt *VTA) DAFmRaw() {
 	if vt.Vals.VSPatchPos < 0 {
 		vt.Vals.VSPatchPos = 0
 	}
-	pvDA := vt.Vals.PVpos - vt.Vals.VSPatchPos
+	pvNeg := vt.PVnegDA * vt.Vals.PVneg // negative PV nets against positive
+	pvDA := vt.Vals.PVpos - vt.Vals.VSPatchPos - pvNeg
 	csDA := mat32.Max(vt.Vals.PPTg, vt.Vals.LHbBurst) // - vt.Vals.LHbDip
 	netDA := float32(0)
-	if vt.Vals.PVpos > vt.PVThr || vt.Vals.VSPatchPos > vt.PVThr { // if actual PV, ignore PPTg and apply VSPatchPos
+	if vt.Vals.PVpos > vt.PVThr || vt.Vals.VSPatchPos > vt.PVThr || pvNeg > vt.PVThr { // if actual PV, ignore PPTg and apply VSPatchPos
 		netDA = pvDA
 	} else {
 		netDA = pvDA + csDA // throw it all in..
@@ -472,6 +474,17 @@ func (pp *PVLV) SetNegUS(usn int32, val float32) {
 	pp.USneg.Set(usn, val)
 }
 
+// SetMixedUS sets a positive US (associated with same-indexed Drive) and
+// a negative US at the same time, for outcomes having both reward and
+// punishment (e.g., approach-avoidance conflict).  If VTA.PVnegDA > 0,
+// the resulting DA reflects the net of the drive-weighted positive and
+// the negative values, each subject to the VTA Gain factors.  Note that negative US 0 is
+// reserved for the effort / disappointment cost.
+func (pp *PVLV) SetMixedUS(posIdx int32, posMag float32, negIdx int32, negMag float32) {
+	pp.SetPosUS(posIdx, posMag)
+	pp.SetNegUS(negIdx, negMag)
+}
+
 // InitDrives initializes all the Drives to zero
 func (pp *PVLV) InitDrives() {
 	pp.Drive.Drives.Zero()
@@ -524,8 +537,8 @@ func (pp *PVLV) HasPosUS() bool {
 
 // HasNegUS returns true if there is at least one non-zero negative US
 func (pp *PVLV) HasNegUS() bool {
-	for i := int32(0); i < pp.Drive.NActive; i++ {
-		if pp.USpos.Get(i) > 0 {
+	for i := int32(0); i < pp.Drive.NNegUSs; i++ {
+		if pp.USneg.Get(i) > 0 {
 			return true
 		}
 	}
@@ -40,13 +40,15 @@ func (pp *PVLVParams) Val(val float32) float32 {
 type VSPatchParams struct {
 	NoDALRate float32 `def:"0.1" desc:"learning rate when no positive dopamine is present (i.e., when not learning to predict a positive valence PV / US outcome.  if too high, extinguishes too quickly.  if too low, doesn't discriminate US vs. non-US trials as well."`
 	NoDAThr   float32 `def:"0.01" desc:"threshold on DA level to engage the NoDALRate -- use a small positive number just in case"`
+	PredFloor float32 `def:"0" min:"0" desc:"floor on the VSPatch reward prediction value for each pool, to avoid unrealistically large DA bursts when predictions go to zero -- note that a floor above the VTA PVThr causes every trial to be treated as a PV event, so it should be kept below that -- 0 = no floor"`
 
-	pad, pad1 float32
+	pad float32
 }
 
 func (pp *VSPatchParams) Defaults() {
 	pp.NoDALRate = 0.1
 	pp.NoDAThr = 0.01
+	pp.PredFloor = 0
 }
 
 func (pp *VSPatchParams) Update() {
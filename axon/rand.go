@@ -16,12 +16,31 @@ type RandFunIdx uint32
 // requires random number generation. If you add a new function, you need to add
 // a new enum entry here.
 // RandFunIdxN is the total number of random functions. It autoincrements due to iota.
+// Only the per-neuron functions are counted in RandFunIdxN, which sets
+// how much Context.RandCtr advances each cycle: adding to it would
+// shift all existing random streams.
 const (
 	RandFunActPGe RandFunIdx = iota
 	RandFunActPGi
 	RandFunIdxN
 )
 
+// Per-pool random functions are appended after the per-neuron ones.
+// They draw from the same counter offsets as the per-neuron functions
+// (RandFunIdxN is not increased), using PoolRandIdx to keep the pool
+// index in a separate range of indexes from the neuron indexes.
+const (
+	RandFunActPGeCorr RandFunIdx = RandFunIdxN + iota
+	RandFunActPGiCorr
+)
+
+// PoolRandIdx returns the index to use with GetRandomNumber for
+// per-pool random functions, counting down from the maximum uint32,
+// so it never coincides with a neuron index.
+func PoolRandIdx(pi uint32) uint32 {
+	return 0xFFFFFFFF - pi
+}
+
 // GetRandomNumber returns a random number that depends on the index, counter and function index.
 // We increment the counter after each cycle, so that we get new random numbers.
 // This whole scheme exists to ensure equal results under different multithreading settings.
@@ -29,6 +48,9 @@ func GetRandomNumber(index uint32, counter slrand.Counter, funIdx RandFunIdx) fl
 	// todo: gpu needs to have the shortcut to work directly on uint2
 	var randCtr slrand.Counter
 	randCtr = counter
+	if funIdx >= RandFunIdxN {
+		funIdx -= RandFunIdxN
+	}
 	randCtr.Add(uint32(funIdx))
 	ctr := randCtr.Uint2()
 	return slrand.Float(&ctr, index)
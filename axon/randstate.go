@@ -0,0 +1,84 @@
+// Copyright (c) 2023, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package axon
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log"
+	"math/rand"
+)
+
+// randSource is the rand.Source used for the network Rand, implementing
+// the SplitMix64 generator, whose entire state is a single uint64,
+// so it can be saved and restored exactly by RandState.
+type randSource struct {
+	state uint64
+}
+
+func newRandSource(seed int64) *randSource {
+	rs := &randSource{}
+	rs.Seed(seed)
+	return rs
+}
+
+func (rs *randSource) Seed(seed int64) {
+	rs.state = uint64(seed)
+}
+
+func (rs *randSource) Uint64() uint64 {
+	rs.state += 0x9e3779b97f4a7c15
+	z := rs.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+func (rs *randSource) Int63() int64 {
+	return int64(rs.Uint64() >> 1)
+}
+
+// randStateLen is the number of bytes in the RandState:
+// RndSeed, the Rand source state, and the Context.RandCtr Lo, Hi, HiSeed.
+const randStateLen = 8 + 8 + 3*4
+
+// RandState returns the current state of the network Rand random
+// number generator, and of the Context.RandCtr counter that drives
+// the per-cycle noise, which can be restored with SetRandState to
+// continue the same sequences of random numbers, e.g., for exactly
+// resuming a run from a saved checkpoint along with the weights.
+// Returns nil if the network Rand has not been created by
+// SetRndSeed or ResetRndSeed.
+func (nt *NetworkBase) RandState(ctx *Context) []byte {
+	if nt.randSrc == nil || nt.Rand.Rand == nil {
+		log.Printf("RandState: network %s Rand has not been initialized with SetRndSeed\n", nt.Nm)
+		return nil
+	}
+	state := make([]byte, randStateLen)
+	binary.LittleEndian.PutUint64(state, uint64(nt.RndSeed))
+	binary.LittleEndian.PutUint64(state[8:], nt.randSrc.state)
+	binary.LittleEndian.PutUint32(state[16:], ctx.RandCtr.Lo)
+	binary.LittleEndian.PutUint32(state[20:], ctx.RandCtr.Hi)
+	binary.LittleEndian.PutUint32(state[24:], ctx.RandCtr.HiSeed)
+	return state
+}
+
+// SetRandState restores the state of the network Rand random number
+// generator and the Context.RandCtr counter from state previously
+// returned by RandState.  RndSeed is also set to the seed in the state.
+func (nt *NetworkBase) SetRandState(ctx *Context, state []byte) error {
+	if len(state) != randStateLen {
+		err := fmt.Errorf("SetRandState: network %s: state must be %d bytes, got: %d", nt.Nm, randStateLen, len(state))
+		log.Println(err)
+		return err
+	}
+	nt.RndSeed = int64(binary.LittleEndian.Uint64(state))
+	nt.randSrc = &randSource{state: binary.LittleEndian.Uint64(state[8:])}
+	nt.Rand.Rand = rand.New(nt.randSrc)
+	ctx.RandCtr.Lo = binary.LittleEndian.Uint32(state[16:])
+	ctx.RandCtr.Hi = binary.LittleEndian.Uint32(state[20:])
+	ctx.RandCtr.HiSeed = binary.LittleEndian.Uint32(state[24:])
+	return nil
+}
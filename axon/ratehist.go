@@ -0,0 +1,62 @@
+// Copyright (c) 2023, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package axon
+
+// RateHist accumulates the per-neuron firing rate (ActM) over trials,
+// for computing the distribution of rates across neurons in a layer
+// as a histogram (see Layer.RateHist).  Rates are recorded at the end
+// of each trial in PlusPhasePost, on the CPU, so this works the same
+// when running on the GPU.
+type RateHist struct {
+	On    bool      `desc:"accumulate the per-neuron rates at the end of each trial"`
+	Reset bool      `desc:"if set, the accumulated rates are reset at the start of the next trial (NewState), and this flag is then cleared -- set it at the start of each epoch to accumulate over epochs"`
+	N     int       `desc:"number of trials accumulated since the last reset"`
+	Sums  []float32 `view:"-" desc:"[NNeurons] accumulated sums of rates for each neuron"`
+}
+
+// Init allocates the sums for given number of neurons, and resets them.
+func (rh *RateHist) Init(nneur int) {
+	rh.Sums = make([]float32, nneur)
+	rh.N = 0
+}
+
+// ResetSums resets the accumulated sums.
+func (rh *RateHist) ResetSums() {
+	rh.N = 0
+	for i := range rh.Sums {
+		rh.Sums[i] = 0
+	}
+}
+
+// Record adds the ActM rate values of given neurons to the sums.
+func (rh *RateHist) Record(neurs []Neuron) {
+	if !rh.On || len(neurs) != len(rh.Sums) {
+		return
+	}
+	for ni := range neurs {
+		rh.Sums[ni] += neurs[ni].ActM
+	}
+	rh.N++
+}
+
+// AddHist adds the counts of neurons whose average rate falls into each
+// of the given number of equal-sized bins over the normalized 0..1 range
+// into the given hist, which must have len = bins.
+// Rates >= 1 go into the last bin.
+func (rh *RateHist) AddHist(hist []int) {
+	bins := len(hist)
+	if rh.N == 0 || bins == 0 {
+		return
+	}
+	for _, sum := range rh.Sums {
+		bi := int((sum / float32(rh.N)) * float32(bins))
+		if bi < 0 {
+			bi = 0
+		} else if bi >= bins {
+			bi = bins - 1
+		}
+		hist[bi]++
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2023, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package axon
+
+import (
+	"github.com/emer/emergent/erand"
+	"github.com/goki/ki/kit"
+)
+
+//go:generate stringer -type=RewSchedTypes
+
+var KiT_RewSchedTypes = kit.Enums.AddEnum(RewSchedTypesN, kit.NotBitFlag, nil)
+
+func (ev RewSchedTypes) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *RewSchedTypes) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// RewSchedTypes are the standard operant conditioning schedules of
+// reinforcement, for RewardSchedule.
+type RewSchedTypes int32
+
+const (
+	// FixedRatio delivers reward after every N responses.
+	FixedRatio RewSchedTypes = iota
+
+	// VariableRatio delivers reward after a random number of responses,
+	// uniformly distributed between 1 and 2N-1, with an average of N.
+	VariableRatio
+
+	// FixedInterval delivers reward for the first response after
+	// N trials have elapsed since the last reward.
+	FixedInterval
+
+	// VariableInterval delivers reward for the first response after a
+	// random number of trials since the last reward, uniformly distributed
+	// between 1 and 2N-1, with an average of N.
+	VariableInterval
+
+	RewSchedTypesN
+)
+
+// RewardSchedule implements the standard operant conditioning schedules of
+// reinforcement (see RewSchedTypes), determining on each trial whether
+// reward is available, and whether a response is rewarded, for use in
+// any environment for PVLV / BG learning experiments.
+// Call Init at the start, and Step on every trial.
+type RewardSchedule struct {
+	Type   RewSchedTypes `desc:"type of schedule"`
+	N      int           `min:"1" desc:"number of responses (for ratio schedules) or trials (for interval schedules) required for reward -- the average for variable schedules"`
+	Req    int           `inactive:"+" desc:"current number of responses or trials required for the next reward -- N for fixed schedules, and sampled after each reward for variable ones"`
+	Resps  int           `inactive:"+" desc:"number of responses since the last reward"`
+	Trials int           `inactive:"+" desc:"number of trials since the last reward"`
+	Rand   erand.SysRand `view:"-" desc:"random number generator for variable schedules"`
+}
+
+// NewRewardSchedule returns a new RewardSchedule of given type and N,
+// using given random seed for variable schedules, initialized with Init.
+func NewRewardSchedule(typ RewSchedTypes, n int, seed int64) *RewardSchedule {
+	rs := &RewardSchedule{Type: typ, N: n}
+	rs.Rand.NewRand(seed)
+	rs.Init()
+	return rs
+}
+
+// Init resets the counts and samples a new requirement
+func (rs *RewardSchedule) Init() {
+	rs.Resps = 0
+	rs.Trials = 0
+	rs.NewReq()
+}
+
+// IsRatio returns true for ratio schedules, which count responses,
+// and false for interval schedules, which count trials.
+func (rs *RewardSchedule) IsRatio() bool {
+	return rs.Type == FixedRatio || rs.Type == VariableRatio
+}
+
+// NewReq sets the Req requirement for the next reward: N for fixed
+// schedules, and a uniform random sample between 1 and 2N-1 for
+// variable ones.
+func (rs *RewardSchedule) NewReq() {
+	n := rs.N
+	if n < 1 {
+		n = 1
+	}
+	switch rs.Type {
+	case VariableRatio, VariableInterval:
+		rs.Req = 1 + rs.Rand.Intn(2*n-1, -1)
+	default:
+		rs.Req = n
+	}
+}
+
+// Avail returns true if reward is available, i.e., if a response on
+// the next trial (next call to Step) would be rewarded.
+func (rs *RewardSchedule) Avail() bool {
+	if rs.IsRatio() {
+		return rs.Resps+1 >= rs.Req
+	}
+	return rs.Trials+1 >= rs.Req
+}
+
+// Step updates the schedule for a new trial, given whether a response
+// was made on this trial, and returns true if reward is delivered.
+func (rs *RewardSchedule) Step(resp bool) bool {
+	avail := rs.Avail()
+	rs.Trials++
+	if !resp {
+		return false
+	}
+	rs.Resps++
+	if !avail {
+		return false
+	}
+	rs.Resps = 0
+	rs.Trials = 0
+	rs.NewReq()
+	return true
+}
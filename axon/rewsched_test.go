@@ -0,0 +1,57 @@
+package axon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRewardSchedule(t *testing.T) {
+	// fixed ratio: every 3rd response, ignoring trials without a response
+	rs := NewRewardSchedule(FixedRatio, 3, 1)
+	var rews []bool
+	for _, resp := range []bool{true, false, true, true, true, true, false, true} {
+		rews = append(rews, rs.Step(resp))
+	}
+	assert.Equal(t, []bool{false, false, false, true, false, false, false, true}, rews)
+
+	// fixed interval: first response after 4 trials
+	rs = NewRewardSchedule(FixedInterval, 4, 1)
+	rews = nil
+	for _, resp := range []bool{true, false, false, false, true, true, true, true, true} {
+		rews = append(rews, rs.Step(resp))
+	}
+	assert.Equal(t, []bool{false, false, false, false, true, false, false, false, true}, rews)
+
+	// variable schedules have the expected average rate
+	ntrls := 20000
+	for _, typ := range []RewSchedTypes{VariableRatio, VariableInterval} {
+		rs = NewRewardSchedule(typ, 5, 42)
+		nrew := 0
+		for trl := 0; trl < ntrls; trl++ {
+			assert.Equal(t, rs.Avail(), rs.Step(true))
+			if rs.Trials == 0 {
+				nrew++
+			}
+			assert.GreaterOrEqual(t, rs.Req, 1)
+			assert.LessOrEqual(t, rs.Req, 9)
+		}
+		assert.InDelta(t, 0.2, float64(nrew)/float64(ntrls), 0.01, typ.String())
+	}
+
+	// variable ratio is not fixed
+	rs = NewRewardSchedule(VariableRatio, 5, 42)
+	reqs := map[int]bool{}
+	for trl := 0; trl < 100; trl++ {
+		reqs[rs.Req] = true
+		rs.Step(true)
+	}
+	assert.Greater(t, len(reqs), 3)
+
+	// same seed gives the same schedule
+	rs1 := NewRewardSchedule(VariableInterval, 5, 7)
+	rs2 := NewRewardSchedule(VariableInterval, 5, 7)
+	for trl := 0; trl < 100; trl++ {
+		assert.Equal(t, rs1.Step(true), rs2.Step(true))
+	}
+}
@@ -49,10 +49,14 @@ func (rp *RSalAChParams) Thr(val float32) float32 {
 // learning dynamic (i.e., PV learning in the PVLV framework).
 type RWPredParams struct {
 	PredRange minmax.F32 `desc:"default 0.1..0.99 range of predictions that can be represented -- having a truncated range preserves some sensitivity in dopamine at the extremes of good or poor performance"`
+	PredFloor float32    `def:"0" min:"0" desc:"floor on the overall reward prediction (positive - negative unit values) used by the RWDaLayer to compute dopamine, which bounds the positive DA from a reward at Rew - PredFloor, to avoid unrealistically large DA bursts when predictions go to zero -- 0 = no floor"`
+
+	pad, pad1, pad2 float32
 }
 
 func (rp *RWPredParams) Defaults() {
 	rp.PredRange.Set(0.01, 0.99)
+	rp.PredFloor = 0
 }
 
 func (rp *RWPredParams) Update() {
@@ -87,14 +91,20 @@ func (rp *RWDaParams) GeFmDA(da float32) float32 {
 type TDIntegParams struct {
 	Discount     float32 `desc:"discount factor -- how much to discount the future prediction from TDPred"`
 	PredGain     float32 `desc:"gain factor on TD rew pred activations"`
+	Lambda       float32 `min:"0" max:"1" desc:"TD(lambda) eligibility trace parameter: the trace of sending activity used for learning in TDPredPrjn projections decays by Discount * Lambda per trial, so that the DA signal drives learning for multiple preceding states, propagating value back along a sequence of states faster.  0 = standard one-step TD(0) learning"`
 	TDPredLayIdx int32   `inactive:"+" desc:"idx of TDPredLayer to get reward prediction from -- set during Build from BuildConfig TDPredLayName"`
-
-	pad uint32
 }
 
 func (tp *TDIntegParams) Defaults() {
 	tp.Discount = 0.9
 	tp.PredGain = 1
+	tp.Lambda = 0
+}
+
+// TrDecay returns the per-trial decay factor for the TD(lambda)
+// eligibility trace: Discount * Lambda
+func (tp *TDIntegParams) TrDecay() float32 {
+	return tp.Discount * tp.Lambda
 }
 
 func (tp *TDIntegParams) Update() {
@@ -0,0 +1,146 @@
+// Copyright (c) 2023, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package axon
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/emer/etable/etensor"
+	"github.com/emer/etable/metric"
+)
+
+// RSA performs a representational similarity analysis comparing two sets
+// of activity patterns, actsA and actsB, where each has one pattern per
+// stimulus, in the same order of stimuli for both (the pattern sizes can
+// differ between A and B).  Returns the representational dissimilarity
+// matrices (RDMs) for each set, as [stimuli][stimuli] tensors with
+// 1 - the correlation between each pair of patterns, and the correlation
+// between the two RDMs over the off-diagonal upper triangle, which measures
+// how similarly the two sets encode the stimuli.  Returns a corr of 0 if
+// the number of patterns differ or there are fewer than 3 of them.
+func RSA(actsA, actsB [][]float32) (corr float32, rdmA, rdmB etensor.Tensor) {
+	rdmA = RDM(actsA)
+	rdmB = RDM(actsB)
+	n := len(actsA)
+	if len(actsB) != n {
+		log.Printf("RSA: number of patterns differ: %d vs. %d\n", n, len(actsB))
+		return
+	}
+	if n < 3 {
+		return
+	}
+	ta := rdmA.(*etensor.Float32)
+	tb := rdmB.(*etensor.Float32)
+	var va, vb []float32
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			va = append(va, ta.Values[i*n+j])
+			vb = append(vb, tb.Values[i*n+j])
+		}
+	}
+	corr = metric.Correlation32(va, vb)
+	return
+}
+
+// RDM returns the representational dissimilarity matrix for given set
+// of activity patterns, as a [patterns][patterns] tensor of 1 - the
+// correlation between each pair of patterns.
+func RDM(acts [][]float32) *etensor.Float32 {
+	n := len(acts)
+	rdm := etensor.NewFloat32([]int{n, n}, nil, []string{"Pat", "Pat"})
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			d := 1 - metric.Correlation32(acts[i], acts[j])
+			rdm.Values[i*n+j] = d
+			rdm.Values[j*n+i] = d
+		}
+	}
+	return rdm
+}
+
+// RSAActs records activity patterns for a layer keyed by stimulus,
+// for use in RSA analysis -- see Network.RecordActsForRSA.
+type RSAActs struct {
+	Keys []string    `desc:"stimulus keys, in order first recorded"`
+	Acts [][]float32 `desc:"activity pattern for each stimulus key"`
+}
+
+// Record records given pattern for given key, replacing any existing one.
+func (ra *RSAActs) Record(key string, acts []float32) {
+	for i, k := range ra.Keys {
+		if k == key {
+			ra.Acts[i] = acts
+			return
+		}
+	}
+	ra.Keys = append(ra.Keys, key)
+	ra.Acts = append(ra.Acts, acts)
+}
+
+// KeyActs returns the pattern for given key, or nil if not recorded.
+func (ra *RSAActs) KeyActs(key string) []float32 {
+	for i, k := range ra.Keys {
+		if k == key {
+			return ra.Acts[i]
+		}
+	}
+	return nil
+}
+
+// RecordActsForRSA records the current minus-phase activity (ActM) of
+// given layer as the pattern for given stimulus key, replacing any
+// existing pattern for that key, for subsequent analysis with RSALayers
+// or RSA, using the patterns in RSARecs.  Call after MinusPhase.
+// When running on the GPU, call GPU.SyncNeuronsFmGPU first.
+func (nt *Network) RecordActsForRSA(layer string, key string) error {
+	ly, err := nt.LayByNameTry(layer)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	var acts []float32
+	ly.UnitVals(&acts, "ActM")
+	if nt.RSARecs == nil {
+		nt.RSARecs = make(map[string]*RSAActs)
+	}
+	ra, ok := nt.RSARecs[layer]
+	if !ok {
+		ra = &RSAActs{}
+		nt.RSARecs[layer] = ra
+	}
+	ra.Record(key, acts)
+	return nil
+}
+
+// ResetRSARecs resets all the patterns recorded by RecordActsForRSA.
+func (nt *Network) ResetRSARecs() {
+	nt.RSARecs = nil
+}
+
+// RSALayers performs the RSA analysis comparing the patterns recorded
+// by RecordActsForRSA for two layers, over the stimulus keys recorded
+// for both, in the order recorded for layA.  Returns an error if either
+// layer has no recorded patterns.
+func (nt *Network) RSALayers(layA, layB string) (corr float32, rdmA, rdmB etensor.Tensor, err error) {
+	ra, oka := nt.RSARecs[layA]
+	rb, okb := nt.RSARecs[layB]
+	if !oka || !okb {
+		err = fmt.Errorf("RSALayers: no patterns recorded for layer(s): %s, %s", layA, layB)
+		log.Println(err)
+		return
+	}
+	var actsA, actsB [][]float32
+	for i, k := range ra.Keys {
+		bacts := rb.KeyActs(k)
+		if bacts == nil {
+			continue
+		}
+		actsA = append(actsA, ra.Acts[i])
+		actsB = append(actsB, bacts)
+	}
+	corr, rdmA, rdmB = RSA(actsA, actsB)
+	return
+}
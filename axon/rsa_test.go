@@ -0,0 +1,93 @@
+// Copyright (c) 2023, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package axon
+
+import (
+	"testing"
+
+	"github.com/emer/etable/etensor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRSA(t *testing.T) {
+	// two clusters of similar patterns: 0,1 and 2,3
+	actsA := [][]float32{
+		{1, 1, 1, 0, 0, 0},
+		{1, 1, 0.8, 0, 0, 0.2},
+		{0, 0, 0, 1, 1, 1},
+		{0.2, 0, 0, 0.8, 1, 1},
+	}
+	// same structure, different units and scale
+	actsB := make([][]float32, len(actsA))
+	for i, pat := range actsA {
+		bp := make([]float32, len(pat))
+		for j := range pat {
+			bp[j] = 0.5 * pat[len(pat)-1-j]
+		}
+		actsB[i] = bp
+	}
+	corr, rdmA, rdmB := RSA(actsA, actsB)
+	assert.InDelta(t, 1, corr, 1.0e-5)
+	assert.Equal(t, []int{4, 4}, rdmA.Shapes())
+	ra := rdmA.(*etensor.Float32)
+	assert.Equal(t, float32(0), ra.Value([]int{2, 2}))
+	assert.Equal(t, ra.Value([]int{0, 2}), ra.Value([]int{2, 0}))
+	assert.Less(t, ra.Value([]int{0, 1}), ra.Value([]int{0, 2}))
+	assert.InDelta(t, ra.Value([]int{0, 1}), rdmB.(*etensor.Float32).Value([]int{0, 1}), 1.0e-5)
+
+	// crossing the cluster structure: 0,2 and 1,3 similar
+	actsC := [][]float32{actsA[0], actsA[2], actsA[1], actsA[3]}
+	corr, _, _ = RSA(actsA, actsC)
+	assert.Less(t, corr, float32(0))
+
+	corr, _, _ = RSA(actsA, actsC[:3])
+	assert.Equal(t, float32(0), corr)
+}
+
+func TestRecordActsForRSA(t *testing.T) {
+	net := NewNetwork("RSATest")
+	aLay := net.AddLayer2D("A", 2, 3, SuperLayer)
+	bLay := net.AddLayer2D("B", 3, 2, SuperLayer)
+	assert.NoError(t, net.Build())
+	net.Defaults()
+	net.InitWts()
+
+	pats := [][]float32{
+		{1, 1, 1, 0, 0, 0},
+		{1, 1, 0.8, 0, 0, 0.2},
+		{0, 0, 0, 1, 1, 1},
+		{0.2, 0, 0, 0.8, 1, 1},
+	}
+	keys := []string{"s0", "s1", "s2", "s3"}
+	for i, pat := range pats {
+		for ni := range pat {
+			aLay.Neurons[ni].ActM = pat[ni]
+			bLay.Neurons[ni].ActM = pat[len(pat)-1-ni]
+		}
+		assert.NoError(t, net.RecordActsForRSA("A", keys[i]))
+		if i < 3 {
+			assert.NoError(t, net.RecordActsForRSA("B", keys[i]))
+		}
+	}
+	assert.Error(t, net.RecordActsForRSA("NoLayer", "s0"))
+	assert.Equal(t, keys, net.RSARecs["A"].Keys)
+	assert.Equal(t, pats[1], net.RSARecs["A"].KeyActs("s1"))
+
+	// re-recording a key replaces the pattern
+	bLay.Neurons[0].ActM = 0.1
+	assert.NoError(t, net.RecordActsForRSA("B", "s2"))
+	assert.Equal(t, 3, len(net.RSARecs["B"].Keys))
+	assert.Equal(t, float32(0.1), net.RSARecs["B"].KeyActs("s2")[0])
+
+	// only the keys recorded for both layers are compared
+	corr, rdmA, _, err := net.RSALayers("A", "B")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 3}, rdmA.Shapes())
+	assert.Greater(t, corr, float32(0.9))
+
+	net.ResetRSARecs()
+	_, _, _, err = net.RSALayers("A", "B")
+	assert.Error(t, err)
+}
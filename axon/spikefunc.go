@@ -0,0 +1,42 @@
+// Copyright (c) 2023, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package axon
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// SpikeFunc is a custom spike generation function, which sets the Spike
+// for the neuron on the current cycle from its conductances (Ge, Gi etc)
+// and any other state, in place of the default AdEx VmFmG and SpikeFmVm.
+// It should call ActParams.SpikeISI to update Spike and the ISI-based
+// values, including Act.  Spike-driven calcium is updated automatically
+// after it is called.  It may be called in parallel across neurons,
+// so any shared state must be protected.
+type SpikeFunc func(nrn *Neuron, ac *ActParams)
+
+// SetSpikeFunc sets a custom spike generation function for this layer,
+// which is used instead of the default AdEx VmFmG and SpikeFmVm
+// (e.g., PoissonSpikeFunc).  Passing nil restores the default.
+// This is only supported when running on the CPU, as the GPU
+// always uses the default.
+func (ly *Layer) SetSpikeFunc(fn SpikeFunc) {
+	ly.SpikeFun = fn
+}
+
+// PoissonSpikeFunc returns a SpikeFunc that generates Poisson spikes,
+// with a probability per cycle of gain * Ge, using a random number
+// generator with given seed.  Vm is not updated.
+func PoissonSpikeFunc(gain float32, seed int64) SpikeFunc {
+	var mu sync.Mutex
+	rnd := rand.New(rand.NewSource(seed))
+	return func(nrn *Neuron, ac *ActParams) {
+		mu.Lock()
+		p := rnd.Float32()
+		mu.Unlock()
+		ac.SpikeISI(nrn, p < gain*nrn.Ge)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2023, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package axon
+
+import "github.com/goki/mat32"
+
+// SpikeHist records the recent spike train for each neuron in a layer,
+// in a ring buffer of NCycles, for computing smooth rate estimates
+// (see Layer.RateEst).  This is CPU-only state -- when running on the GPU,
+// the Neurons must be synced back each cycle and Layer.RecordSpikes called.
+type SpikeHist struct {
+	NCycles  int       `desc:"number of recent cycles of spikes to record -- 0 = off"`
+	NNeurons int       `desc:"number of neurons recorded per cycle"`
+	Idx      int       `desc:"ring buffer index where the next cycle will be recorded"`
+	N        int       `desc:"number of cycles recorded so far, up to NCycles"`
+	Spikes   []float32 `view:"-" desc:"[NCycles][NNeurons] ring buffer of recorded spikes"`
+	Rates    []float32 `view:"-" desc:"[NNeurons] cached rate estimates from the last call to RateEst"`
+}
+
+// Init allocates the buffers for given number of neurons and cycles,
+// and resets the recorded history.
+func (sh *SpikeHist) Init(nneur, ncycles int) {
+	sh.NCycles = ncycles
+	sh.NNeurons = nneur
+	sh.Spikes = make([]float32, ncycles*nneur)
+	sh.Rates = make([]float32, nneur)
+	sh.Reset()
+}
+
+// Reset resets the recorded history, without reallocating.
+func (sh *SpikeHist) Reset() {
+	sh.Idx = 0
+	sh.N = 0
+	for i := range sh.Spikes {
+		sh.Spikes[i] = 0
+	}
+}
+
+// Record records the Spike values of given neurons for the current cycle.
+func (sh *SpikeHist) Record(neurs []Neuron) {
+	if sh.NCycles <= 0 || len(neurs) != sh.NNeurons {
+		return
+	}
+	st := sh.Idx * sh.NNeurons
+	for ni := range neurs {
+		sh.Spikes[st+ni] = neurs[ni].Spike
+	}
+	sh.Idx = (sh.Idx + 1) % sh.NCycles
+	if sh.N < sh.NCycles {
+		sh.N++
+	}
+}
+
+// RateEst computes an exponentially-weighted estimate of the spiking
+// probability per cycle for each neuron over the recorded history,
+// with the given time constant tau in cycles, weighting the most recent
+// cycle the most.  Results are stored in Rates, which is returned.
+func (sh *SpikeHist) RateEst(tau float32) []float32 {
+	for ni := range sh.Rates {
+		sh.Rates[ni] = 0
+	}
+	if sh.N == 0 || tau <= 0 {
+		return sh.Rates
+	}
+	dt := 1 / tau
+	wsum := float32(0)
+	for k := 0; k < sh.N; k++ { // k = cycles back from most recent
+		ci := (sh.Idx - 1 - k + sh.NCycles) % sh.NCycles
+		w := mat32.FastExp(-float32(k) * dt)
+		wsum += w
+		st := ci * sh.NNeurons
+		for ni := range sh.Rates {
+			sh.Rates[ni] += w * sh.Spikes[st+ni]
+		}
+	}
+	for ni := range sh.Rates {
+		sh.Rates[ni] /= wsum
+	}
+	return sh.Rates
+}
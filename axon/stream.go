@@ -0,0 +1,81 @@
+// Copyright (c) 2023, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package axon
+
+import (
+	"log"
+
+	"github.com/emer/etable/etensor"
+)
+
+// StreamPair is one trial of streamed training data, with the input
+// pattern and the target pattern (which can be nil if there is none).
+type StreamPair struct {
+	Input  etensor.Tensor `desc:"input pattern, applied to the input layer"`
+	Target etensor.Tensor `desc:"target pattern, applied to the target layer -- can be nil"`
+}
+
+// StreamEnv is a source of streamed training data, for online training
+// on datasets that are generated on the fly or too large to fit in an
+// etable.Table, as used with StreamApplyInputs.
+type StreamEnv interface {
+	// Next returns the next input, target pair in the stream,
+	// and false if the stream is exhausted.
+	Next() (StreamPair, bool)
+}
+
+// ChanStream is a StreamEnv that pulls each trial from a Go channel,
+// which is typically fed by a generator goroutine, and closed
+// at the end of the stream.
+type ChanStream struct {
+	Ch  <-chan StreamPair `desc:"channel that the trials are pulled from"`
+	Cur StreamPair        `desc:"current trial, from the last call to Next"`
+	N   int               `desc:"number of trials pulled from the channel so far"`
+}
+
+// NewChanStream returns a new ChanStream pulling from given channel
+func NewChanStream(ch <-chan StreamPair) *ChanStream {
+	return &ChanStream{Ch: ch}
+}
+
+// Next blocks until the next trial is available on the channel,
+// and returns false when the channel is closed.
+func (cs *ChanStream) Next() (StreamPair, bool) {
+	sp, ok := <-cs.Ch
+	if !ok {
+		return StreamPair{}, false
+	}
+	cs.Cur = sp
+	cs.N++
+	return sp, true
+}
+
+// StreamApplyInputs pulls the next trial from given StreamEnv and applies
+// its Input pattern to the input layer and Target pattern (if non-nil)
+// to the target layer, of given names, after clearing any existing
+// inputs with InitExt.  Returns the trial and true if one was applied,
+// or false if the stream is exhausted or a layer is not found, in which
+// case the existing inputs are not changed.  As with EnvApplyInputs,
+// the network ApplyExts method must then be called as usual.
+func StreamApplyInputs(net *Network, st StreamEnv, inLay, trgLay string) (StreamPair, bool) {
+	in := net.AxonLayerByName(inLay)
+	trg := net.AxonLayerByName(trgLay)
+	if in == nil || trg == nil {
+		log.Printf("StreamApplyInputs: layer(s) not found in network %s: %s, %s\n", net.Nm, inLay, trgLay)
+		return StreamPair{}, false
+	}
+	sp, ok := st.Next()
+	if !ok {
+		return sp, false
+	}
+	net.InitExt()
+	if sp.Input != nil {
+		in.ApplyExt(sp.Input)
+	}
+	if sp.Target != nil {
+		trg.ApplyExt(sp.Target)
+	}
+	return sp, true
+}
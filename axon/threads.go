@@ -77,6 +77,61 @@ func (nt *NetThreads) Set(neurons, sendSpike, synCa int) error {
 	return nil
 }
 
+// RunParallel runs given function for each of the given independent
+// networks concurrently, each on its own goroutine, calling fn(i) for
+// network nets[i] with its own Context ctxs[i], and waiting for all of
+// them to finish.  This supports embarrassingly-parallel experiment
+// batches such as hyperparameter sweeps.  To avoid oversubscribing the
+// CPU, the Threads of each network are set to 1 during the run, and
+// restored afterwards.  Each network uses its own Rand random number
+// generator, and the fn must only access nets[i] and ctxs[i], storing any
+// results in per-index storage (e.g., a results slice), so the results are
+// the same as running the networks serially.  Returns an error if the
+// numbers of nets and ctxs differ, if any network or Context is shared
+// among multiple entries, or if any network is using the GPU.
+func RunParallel(nets []*Network, ctxs []*Context, fn func(i int)) error {
+	if len(nets) != len(ctxs) {
+		err := fmt.Errorf("RunParallel: number of networks: %d != number of contexts: %d", len(nets), len(ctxs))
+		log.Println(err)
+		return err
+	}
+	netSet := make(map[*Network]bool, len(nets))
+	ctxSet := make(map[*Context]bool, len(ctxs))
+	for i, nt := range nets {
+		if netSet[nt] || ctxSet[ctxs[i]] {
+			err := fmt.Errorf("RunParallel: network or context at index %d is shared with another entry", i)
+			log.Println(err)
+			return err
+		}
+		if nt.GPU.On {
+			err := fmt.Errorf("RunParallel: network %s is using the GPU", nt.Nm)
+			log.Println(err)
+			return err
+		}
+		netSet[nt] = true
+		ctxSet[ctxs[i]] = true
+	}
+	saved := make([]NetThreads, len(nets))
+	for i, nt := range nets {
+		saved[i] = nt.Threads
+		nt.Threads.Set(1, 1, 1)
+	}
+	waitGroup := sync.WaitGroup{}
+	for i := range nets {
+		i := i
+		waitGroup.Add(1)
+		go func() {
+			fn(i)
+			waitGroup.Done()
+		}()
+	}
+	waitGroup.Wait()
+	for i, nt := range nets {
+		nt.Threads = saved[i]
+	}
+	return nil
+}
+
 //////////////////////////////////////////////////////////////////////////////////////
 //  Specialized parallel map functions that respect settings in NetworkBase.Threads
 
@@ -183,6 +238,58 @@ func (nt *NetworkBase) NeuronMapSequential(fun func(ly *Layer, ni uint32, nrn *N
 	nt.FunTimerStop(funame)
 }
 
+//////////////////////////////////////////////////////////////////////////////////////
+//  Synapse visitors
+
+// ForEachSynapse calls given function on each synapse in the network,
+// sequentially, in the global Synapses order (by receiving layer,
+// receiving projection, and receiving neuron).  The function receives the
+// receiving layer, projection, synapse, and the layer-specific
+// sending (si) and receiving (ri) neuron indexes.
+// Projections that are Off are skipped.  When running on the GPU,
+// call GPU.SyncSynapsesFmGPU first to get the current values.
+func (nt *NetworkBase) ForEachSynapse(fun func(ly *Layer, pj *Prjn, sy *Synapse, si, ri int)) {
+	for _, ly := range nt.Layers {
+		if ly.IsOff() {
+			continue
+		}
+		for _, pj := range ly.RcvPrjns {
+			if pj.IsOff() {
+				continue
+			}
+			for syi := range pj.Syns {
+				sy := &pj.Syns[syi]
+				fun(ly, pj, sy, int(pj.Params.SynSendLayIdx(sy)), int(pj.Params.SynRecvLayIdx(sy)))
+			}
+		}
+	}
+}
+
+// ForEachSynapsePar is the parallel version of ForEachSynapse, using nThreads
+// goroutines to process disjoint ranges of the global Synapses list,
+// such that each synapse is visited exactly once.  The order of visiting
+// is not determined, so the function must not write to any state shared
+// across synapses -- it is intended for read-only analysis, or writing
+// to separate per-synapse locations (e.g., indexed by sy.SynIdx).
+// Runs sequentially if nThreads <= 1.
+func (nt *NetworkBase) ForEachSynapsePar(nThreads int, fun func(ly *Layer, pj *Prjn, sy *Synapse, si, ri int)) {
+	if nThreads <= 1 {
+		nt.ForEachSynapse(fun)
+		return
+	}
+	parallelRun(func(st, ed int) {
+		for syi := st; syi < ed; syi++ {
+			sy := &nt.Synapses[syi]
+			pj := nt.Prjns[sy.PrjnIdx]
+			ly := pj.Recv
+			if ly.IsOff() || pj.IsOff() {
+				continue
+			}
+			fun(ly, pj, sy, int(pj.Params.SynSendLayIdx(sy)), int(pj.Params.SynRecvLayIdx(sy)))
+		}
+	}, len(nt.Synapses), nThreads)
+}
+
 //////////////////////////////////////////////////////////////
 // Timing reports
 
@@ -374,3 +374,101 @@ func runFunEpochs(pats *etable.Table, net *Network, fun func(*Network, *Context)
 		}
 	}
 }
+
+func TestForEachSynapsePar(t *testing.T) {
+	net := buildNet(t, []int{shape1D, shape1D}, 1, 1, 1)
+
+	nsyn := len(net.Synapses)
+	serWts := make([]float32, nsyn)
+	serN := 0
+	net.ForEachSynapse(func(ly *Layer, pj *Prjn, sy *Synapse, si, ri int) {
+		assert.Same(t, ly, pj.Recv)
+		assert.Equal(t, sy.RecvIdx, uint32(ri+ly.NeurStIdx))
+		assert.Equal(t, sy.SendIdx, uint32(si+pj.Send.NeurStIdx))
+		serWts[sy.SynIdx] = sy.Wt
+		serN++
+	})
+	assert.Equal(t, nsyn, serN)
+
+	parWts := make([]float32, nsyn)
+	parN := make([]int, nsyn)
+	net.ForEachSynapsePar(16, func(ly *Layer, pj *Prjn, sy *Synapse, si, ri int) {
+		parWts[sy.SynIdx] = sy.Wt
+		parN[sy.SynIdx]++ // separate location per synapse: no write hazard
+	})
+	serSum := float64(0)
+	parSum := float64(0)
+	for i := range serWts {
+		assert.Equal(t, 1, parN[i])
+		serSum += float64(serWts[i])
+		parSum += float64(parWts[i])
+	}
+	assert.Equal(t, serSum, parSum)
+}
+
+func TestRunParallel(t *testing.T) {
+	const nNets = 4
+	pats := []*etensor.Float32{}
+	for pi := 0; pi < 3; pi++ {
+		pat := etensor.NewFloat32([]int{5, 5}, nil, nil)
+		for i := pi; i < 25; i += 4 {
+			pat.Values[i] = 1
+		}
+		pats = append(pats, pat)
+	}
+	newNets := func() ([]*Network, []*Context) {
+		nets := make([]*Network, nNets)
+		ctxs := make([]*Context, nNets)
+		for i := range nets {
+			nets[i] = newRA25Net(t)
+			nets[i].SetRndSeed(int64(i + 1))
+			nets[i].InitWts()
+			ctxs[i] = NewContext()
+		}
+		return nets, ctxs
+	}
+	// trains net for a few trials and returns its state hash
+	train := func(net *Network, ctx *Context) string {
+		for _, pat := range pats {
+			net.InitExt()
+			net.AxonLayerByName("Input").ApplyExt(pat)
+			net.AxonLayerByName("Output").ApplyExt(pat)
+			net.ApplyExts(ctx)
+			net.NewState(ctx)
+			ctx.NewState(etime.Train)
+			for cyc := 0; cyc < 200; cyc++ {
+				net.Cycle(ctx)
+				ctx.CycleInc()
+				if cyc == 149 {
+					net.MinusPhase(ctx)
+					ctx.NewPhase(true)
+					net.PlusPhaseStart(ctx)
+				}
+			}
+			net.PlusPhase(ctx)
+			net.DWt(ctx)
+			net.WtFmDWt(ctx)
+		}
+		return net.StateHash()
+	}
+
+	nets, ctxs := newNets()
+	serial := make([]string, nNets)
+	for i := range nets {
+		serial[i] = train(nets[i], ctxs[i])
+	}
+	assert.NotEqual(t, serial[0], serial[1])
+
+	nets, ctxs = newNets()
+	thr := nets[0].Threads
+	par := make([]string, nNets)
+	assert.NoError(t, RunParallel(nets, ctxs, func(i int) {
+		assert.Equal(t, 1, nets[i].Threads.Neurons)
+		par[i] = train(nets[i], ctxs[i])
+	}))
+	assert.Equal(t, serial, par)
+	assert.Equal(t, thr, nets[0].Threads)
+
+	assert.Error(t, RunParallel(nets, ctxs[:2], func(i int) {}))
+	assert.Error(t, RunParallel([]*Network{nets[0], nets[0]}, ctxs[:2], func(i int) {}))
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2023, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package axon
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/emer/etable/etensor"
+)
+
+// WtMatrix returns the weights of this projection as a dense
+// [recv][send] matrix, over the flat receiving and sending unit
+// indexes, with 0 for units that are not connected.  This can be
+// used for external analysis, e.g., SVD.  When running on the GPU,
+// call GPU.SyncSynapsesFmGPU first.
+func (pj *Prjn) WtMatrix() *etensor.Float32 {
+	nr := len(pj.Recv.Neurons)
+	ns := len(pj.Send.Neurons)
+	mat := etensor.NewFloat32([]int{nr, ns}, nil, []string{"Recv", "Send"})
+	for ri := 0; ri < nr; ri++ {
+		syns := pj.RecvSyns(ri)
+		for ci := range syns {
+			sy := &syns[ci]
+			si := int(pj.Params.SynSendLayIdx(sy))
+			mat.Values[ri*ns+si] = sy.Wt
+		}
+	}
+	return mat
+}
+
+// WtMatrixRank returns the effective rank of the dense weight matrix of
+// this projection (see WtMatrix), as the number of singular values that are
+// greater than tol times the largest singular value (e.g., 0.01).
+// This is a measure of the dimensionality of the learned representations.
+// When running on the GPU, call GPU.SyncSynapsesFmGPU first.
+func (pj *Prjn) WtMatrixRank(tol float32) int {
+	return MatrixRank(pj.WtMatrix(), tol)
+}
+
+// WtRankByClass returns the WtMatrixRank effective rank of the weights,
+// with given tol relative to the largest singular value, for all
+// projections having any of the given classes (which include the
+// projection type name, e.g., ForwardPrjn), keyed by projection name.
+// Syncs the synapses from the GPU if it is on.
+func (nt *Network) WtRankByClass(tol float32, classes ...string) map[string]int {
+	nt.GPU.SyncSynapsesFmGPU()
+	ranks := make(map[string]int)
+	for _, ly := range nt.Layers {
+		if ly.IsOff() {
+			continue
+		}
+		for _, pj := range ly.RcvPrjns {
+			if pj.IsOff() {
+				continue
+			}
+		clsLoop:
+			for _, pcl := range strings.Fields(pj.Class()) {
+				for _, cl := range classes {
+					if pcl == cl {
+						ranks[pj.Name()] = pj.WtMatrixRank(tol)
+						break clsLoop
+					}
+				}
+			}
+		}
+	}
+	return ranks
+}
+
+// MatrixRank returns the effective rank of given 2D matrix, as the
+// number of singular values (see SingularVals) that are greater than
+// tol times the largest singular value.  Returns 0 for an all-zero matrix.
+func MatrixRank(mat *etensor.Float32, tol float32) int {
+	svs := SingularVals(mat)
+	if len(svs) == 0 || svs[0] == 0 {
+		return 0
+	}
+	thr := tol * svs[0]
+	rank := 0
+	for _, sv := range svs {
+		if sv > thr {
+			rank++
+		}
+	}
+	return rank
+}
+
+// SingularVals returns the singular values of given 2D matrix,
+// in descending order, computed as the square roots of the eigenvalues
+// of the smaller of the two Gram matrices (A A^T or A^T A), using the
+// Jacobi eigenvalue algorithm in double precision.
+func SingularVals(mat *etensor.Float32) []float32 {
+	nr := mat.Dim(0)
+	nc := mat.Dim(1)
+	n, m := nr, nc // gram over the smaller dimension n
+	at := func(i, k int) float64 { return float64(mat.Values[i*nc+k]) }
+	if nc < nr {
+		n, m = nc, nr
+		at = func(i, k int) float64 { return float64(mat.Values[k*nc+i]) }
+	}
+	gram := make([][]float64, n)
+	for i := range gram {
+		gram[i] = make([]float64, n)
+		for j := 0; j <= i; j++ {
+			sum := 0.0
+			for k := 0; k < m; k++ {
+				sum += at(i, k) * at(j, k)
+			}
+			gram[i][j] = sum
+			gram[j][i] = sum
+		}
+	}
+	eigs := symEigenVals(gram)
+	svs := make([]float32, n)
+	for i, ev := range eigs {
+		if ev > 0 {
+			svs[i] = float32(math.Sqrt(ev))
+		}
+	}
+	sort.Slice(svs, func(i, j int) bool { return svs[i] > svs[j] })
+	return svs
+}
+
+// symEigenVals returns the eigenvalues of given symmetric matrix,
+// using the cyclic Jacobi algorithm, which overwrites the matrix.
+func symEigenVals(a [][]float64) []float64 {
+	n := len(a)
+	for sweep := 0; sweep < 100; sweep++ {
+		off, diag := 0.0, 0.0
+		for p := 0; p < n; p++ {
+			diag += a[p][p] * a[p][p]
+			for q := p + 1; q < n; q++ {
+				off += a[p][q] * a[p][q]
+			}
+		}
+		if off <= 1.0e-24*diag {
+			break
+		}
+		for p := 0; p < n-1; p++ {
+			for q := p + 1; q < n; q++ {
+				if math.Abs(a[p][q]) < 1.0e-300 {
+					continue
+				}
+				theta := (a[q][q] - a[p][p]) / (2 * a[p][q])
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for k := 0; k < n; k++ {
+					akp, akq := a[k][p], a[k][q]
+					a[k][p] = c*akp - s*akq
+					a[k][q] = s*akp + c*akq
+				}
+				for k := 0; k < n; k++ {
+					apk, aqk := a[p][k], a[q][k]
+					a[p][k] = c*apk - s*aqk
+					a[q][k] = s*apk + c*aqk
+				}
+			}
+		}
+	}
+	eigs := make([]float64, n)
+	for i := range eigs {
+		eigs[i] = a[i][i]
+	}
+	return eigs
+}
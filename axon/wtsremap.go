@@ -0,0 +1,326 @@
+// Copyright (c) 2023, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package axon
+
+import (
+	"fmt"
+	"log"
+	"math"
+
+	"github.com/emer/emergent/weights"
+	"github.com/goki/ki/ints"
+	"github.com/goki/mat32"
+)
+
+// SetWtsRemap sets the weights for this network from weights.Network decoded
+// values, which can come from a network with differently named and sized
+// layers.  remap maps layer names in the weights to layer names in this
+// network (layers not in the map use the same name), including the
+// sending layer names of projections.  When the number of units in a layer
+// differs from that in the weights, mode determines how weights are mapped:
+//   - "interp": bilinearly interpolates along each dimension of the layer
+//     shape, which is appropriate for topographically organized layers.
+//   - "tile": repeats the weights from the original units, wrapping around
+//     in each dimension.
+//   - "skip": leaves the weights for resized layers and their projections
+//     unchanged, only loading those with identical sizes.
+//
+// The original layer shape is not saved in the weights, so it is inferred
+// from the number of units to have the same number of dimensions and
+// closest aspect ratio as the new layer shape (for 4D layers, either the
+// pool or the unit-level dimensions are kept the same).  Sending layers
+// without any saved weights or unit values (e.g., Input layers) are
+// assumed to have their current size.  The weights are first upgraded
+// to the current format with MigrateWts.
+func (nt *NetworkBase) SetWtsRemap(nw *weights.Network, remap map[string]string, mode string) error {
+	if mode != "interp" && mode != "tile" && mode != "skip" {
+		err := fmt.Errorf("SetWtsRemap: mode %q must be one of: interp, tile, skip", mode)
+		log.Println(err)
+		return err
+	}
+	if err := MigrateWts(nw); err != nil {
+		return err // note: already logged
+	}
+	mapNm := func(nm string) string {
+		if rn, ok := remap[nm]; ok {
+			return rn
+		}
+		return nm
+	}
+	// number of units in each layer in the weights
+	srcN := make(map[string]int)
+	for li := range nw.Layers {
+		lw := &nw.Layers[li]
+		n := 0
+		for _, uv := range lw.Units {
+			n = ints.MaxInt(n, len(uv))
+		}
+		for pi := range lw.Prjns {
+			for ri := range lw.Prjns[pi].Rs {
+				n = ints.MaxInt(n, lw.Prjns[pi].Rs[ri].Ri+1)
+			}
+		}
+		if n > 0 {
+			srcN[lw.Layer] = n
+		}
+	}
+	var err error
+	for li := range nw.Layers {
+		lw := &nw.Layers[li]
+		ly, er := nt.LayByNameTry(mapNm(lw.Layer))
+		if er != nil {
+			err = er
+			continue
+		}
+		if ly.IsOff() {
+			continue
+		}
+		rn, ok := srcN[lw.Layer]
+		if !ok {
+			rn = len(ly.Neurons)
+		}
+		if rn != len(ly.Neurons) && mode == "skip" {
+			continue
+		}
+		rmap, er := newWtsRemapIdxs(ly, rn, mode)
+		if er != nil {
+			err = er
+			continue
+		}
+		lwr := &weights.Layer{Layer: ly.Nm, MetaData: lw.MetaData}
+		if lw.Units != nil {
+			lwr.Units = make(map[string][]float32, len(lw.Units))
+			for unm, uv := range lw.Units {
+				lwr.Units[unm] = rmap.remapVals(uv)
+			}
+		}
+		ly.SetWts(lwr) // only MetaData, Units
+		for pi := range lw.Prjns {
+			pw := &lw.Prjns[pi]
+			epj, er := ly.SendNameTry(mapNm(pw.From))
+			if er != nil {
+				err = er
+				continue
+			}
+			pj := epj.(*Prjn)
+			sn, ok := srcN[pw.From]
+			if !ok { // no saved size: same as current unless indexes go beyond
+				sn = len(pj.Send.Neurons)
+				for ri := range pw.Rs {
+					for _, si := range pw.Rs[ri].Si {
+						sn = ints.MaxInt(sn, si+1)
+					}
+				}
+			}
+			if sn != len(pj.Send.Neurons) && mode == "skip" {
+				continue
+			}
+			smap, er := newWtsRemapIdxs(pj.Send, sn, mode)
+			if er != nil {
+				err = er
+				continue
+			}
+			pj.setWtsRemap(pw, rmap, smap)
+		}
+	}
+	return err
+}
+
+// setWtsRemap sets the weights for this projection from given weights,
+// using given mappings of receiving and sending unit indexes.
+func (pj *Prjn) setWtsRemap(pw *weights.Prjn, rmap, smap *wtsRemapIdxs) {
+	type srcWt struct {
+		wt, swt float32
+		hasSWt  bool
+	}
+	src := make(map[[2]int]srcWt) // [ri, si]
+	for i := range pw.Rs {
+		pr := &pw.Rs[i]
+		hasWt1 := len(pr.Wt1) >= len(pr.Si)
+		for ci, si := range pr.Si {
+			sw := srcWt{wt: pr.Wt[ci], hasSWt: hasWt1}
+			if hasWt1 {
+				sw.swt = pr.Wt1[ci]
+			}
+			src[[2]int{pr.Ri, si}] = sw
+		}
+	}
+	for ri := range pj.Recv.Neurons {
+		rsrc := rmap.Idxs[ri]
+		rwts := rmap.Wts[ri]
+		syns := pj.RecvSyns(ri)
+		for ci := range syns {
+			sy := &syns[ci]
+			si := int(pj.Params.SynSendLayIdx(sy))
+			ssrc := smap.Idxs[si]
+			swts := smap.Wts[si]
+			var wt, swt, wsum, swsum float32
+			for rii, sri := range rsrc {
+				for sii, ssi := range ssrc {
+					sw, ok := src[[2]int{sri, ssi}]
+					if !ok {
+						continue
+					}
+					w := rwts[rii] * swts[sii]
+					wt += w * sw.wt
+					wsum += w
+					if sw.hasSWt {
+						swt += w * sw.swt
+						swsum += w
+					}
+				}
+			}
+			if wsum == 0 {
+				continue
+			}
+			if swsum > 0 {
+				sy.SWt = swt / swsum
+			}
+			sy.Wt = wt / wsum
+			if sy.SWt == 0 {
+				sy.SWt = sy.Wt
+			}
+			sy.LWt = pj.Params.SWt.LWtFmWts(sy.Wt, sy.SWt)
+		}
+	}
+}
+
+// wtsRemapIdxs maps each unit in a layer to a weighted set of units
+// in the original layer that the weights were saved from.
+type wtsRemapIdxs struct {
+	Idxs [][]int     `desc:"for each unit, the indexes of the original units"`
+	Wts  [][]float32 `desc:"for each unit, the weights of the original units, summing to 1"`
+}
+
+// newWtsRemapIdxs returns the mapping from units in given layer to
+// an original layer with srcN units, according to mode (interp or tile).
+func newWtsRemapIdxs(ly *Layer, srcN int, mode string) (*wtsRemapIdxs, error) {
+	nn := len(ly.Neurons)
+	rm := &wtsRemapIdxs{Idxs: make([][]int, nn), Wts: make([][]float32, nn)}
+	if srcN == nn {
+		for ni := 0; ni < nn; ni++ {
+			rm.Idxs[ni] = []int{ni}
+			rm.Wts[ni] = []float32{1}
+		}
+		return rm, nil
+	}
+	shp := ly.Shp.Shp
+	src, err := wtsRemapShape(shp, srcN)
+	if err != nil {
+		err = fmt.Errorf("SetWtsRemap: layer %s: %v", ly.Nm, err)
+		log.Println(err)
+		return nil, err
+	}
+	nd := len(shp)
+	idx := make([]int, nd)
+	for ni := 0; ni < nn; ni++ {
+		// row-major coordinates of this unit
+		ii := ni
+		for d := nd - 1; d >= 0; d-- {
+			idx[d] = ii % shp[d]
+			ii /= shp[d]
+		}
+		idxs := []int{0}
+		wts := []float32{1}
+		for d := 0; d < nd; d++ {
+			var lo, hi int
+			var frac float32
+			if mode == "tile" {
+				lo = idx[d] % src[d]
+				hi = lo
+			} else {
+				pos := (float32(idx[d])+0.5)*float32(src[d])/float32(shp[d]) - 0.5
+				pos = mat32.Clamp(pos, 0, float32(src[d]-1))
+				lo = int(pos)
+				hi = ints.MinInt(lo+1, src[d]-1)
+				frac = pos - float32(lo)
+			}
+			var nidxs []int
+			var nwts []float32
+			for i, si := range idxs {
+				nidxs = append(nidxs, si*src[d]+lo)
+				nwts = append(nwts, wts[i]*(1-frac))
+				if hi != lo && frac > 0 {
+					nidxs = append(nidxs, si*src[d]+hi)
+					nwts = append(nwts, wts[i]*frac)
+				}
+			}
+			idxs, wts = nidxs, nwts
+		}
+		rm.Idxs[ni] = idxs
+		rm.Wts[ni] = wts
+	}
+	return rm, nil
+}
+
+// remapVals returns the unit-level values from the original layer
+// mapped onto the units of the new layer.
+func (rm *wtsRemapIdxs) remapVals(vals []float32) []float32 {
+	rv := make([]float32, len(rm.Idxs))
+	for ni, idxs := range rm.Idxs {
+		wsum := float32(0)
+		for i, si := range idxs {
+			if si < len(vals) {
+				rv[ni] += rm.Wts[ni][i] * vals[si]
+				wsum += rm.Wts[ni][i]
+			}
+		}
+		if wsum > 0 {
+			rv[ni] /= wsum
+		}
+	}
+	return rv
+}
+
+// wtsRemapShape infers the shape of an original layer with n units,
+// given the current shape, with the same number of dimensions and the
+// closest aspect ratio.  For 4D shapes, either the pool or unit-level
+// dimensions are kept the same.
+func wtsRemapShape(shp []int, n int) ([]int, error) {
+	switch len(shp) {
+	case 1:
+		return []int{n}, nil
+	case 2:
+		y, x, ok := wtsRemapShape2D(shp[0], shp[1], n)
+		if !ok {
+			return nil, fmt.Errorf("could not infer original 2D shape for %d units", n)
+		}
+		return []int{y, x}, nil
+	case 4:
+		npl := shp[0] * shp[1]
+		nun := shp[2] * shp[3]
+		if n%npl == 0 {
+			if y, x, ok := wtsRemapShape2D(shp[2], shp[3], n/npl); ok {
+				return []int{shp[0], shp[1], y, x}, nil
+			}
+		}
+		if n%nun == 0 {
+			if y, x, ok := wtsRemapShape2D(shp[0], shp[1], n/nun); ok {
+				return []int{y, x, shp[2], shp[3]}, nil
+			}
+		}
+		return nil, fmt.Errorf("could not infer original 4D shape for %d units", n)
+	}
+	return nil, fmt.Errorf("layer shape with %d dimensions not supported", len(shp))
+}
+
+// wtsRemapShape2D returns the y, x factors of n with the aspect ratio
+// closest to ty, tx.
+func wtsRemapShape2D(ty, tx, n int) (y, x int, ok bool) {
+	tasp := math.Log(float64(ty) / float64(tx))
+	best := math.MaxFloat64
+	for fy := 1; fy <= n; fy++ {
+		if n%fy != 0 {
+			continue
+		}
+		fx := n / fy
+		d := math.Abs(math.Log(float64(fy)/float64(fx)) - tasp)
+		if d < best {
+			best = d
+			y, x, ok = fy, fx, true
+		}
+	}
+	return
+}
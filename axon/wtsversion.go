@@ -0,0 +1,109 @@
+// Copyright (c) 2023, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package axon
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/emer/emergent/weights"
+)
+
+// WtsVersion is the current version of the weights file format written
+// by WriteWtsJSON, recorded in the network-level MetaData under
+// WtsVersionKey.  The versions are:
+//   - 1: original format, without a version, where the structural weight
+//     (SWt) saved as Wt1 in each projection is optional.
+//   - 2: adds the version, and SWt is always saved as Wt1 when written.
+//     Migrated version 1 weights may still lack Wt1, which is handled
+//     as before by SetWts.
+//
+// Weights files of any earlier version are upgraded to the current
+// version by MigrateWts when loaded, and files with a later version than
+// WtsVersion are rejected with an error, so that newer formats are never
+// silently misloaded.  Any change to the format must increment WtsVersion
+// and add a function upgrading from the previous version in WtsMigrations.
+const WtsVersion = 2
+
+// WtsVersionKey is the network-level MetaData key for the WtsVersion
+const WtsVersionKey = "WtsVersion"
+
+// WtsMigrateFunc upgrades decoded weights from one version to the next
+type WtsMigrateFunc func(nw *weights.Network) error
+
+// WtsMigrations has the functions that upgrade decoded weights of
+// the given version to the next version -- see WtsVersion.
+var WtsMigrations = map[int]WtsMigrateFunc{
+	1: MigrateWtsV1,
+}
+
+// WtsFileVersion returns the version of the format of the given decoded
+// weights, from the WtsVersionKey MetaData, which is 1 if not present.
+func WtsFileVersion(nw *weights.Network) (int, error) {
+	vs, ok := nw.MetaData[WtsVersionKey]
+	if !ok {
+		return 1, nil
+	}
+	v, err := strconv.Atoi(vs)
+	if err != nil || v < 1 {
+		return 0, fmt.Errorf("weights for network %s have an invalid %s: %q", nw.Network, WtsVersionKey, vs)
+	}
+	return v, nil
+}
+
+// MigrateWts upgrades the given decoded weights from their version
+// (see WtsFileVersion) to the current WtsVersion, by applying each of
+// the WtsMigrations in turn, and sets their version to WtsVersion.
+// Returns an error if the version is invalid or later than WtsVersion,
+// in which case the weights are not changed.  This is called
+// automatically by SetWts and ReadWtsJSON.
+func MigrateWts(nw *weights.Network) error {
+	v, err := WtsFileVersion(nw)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	if v > WtsVersion {
+		err = fmt.Errorf("weights for network %s have version %d, which is later than the latest supported version: %d", nw.Network, v, WtsVersion)
+		log.Println(err)
+		return err
+	}
+	for ; v < WtsVersion; v++ {
+		mf, ok := WtsMigrations[v]
+		if !ok {
+			err = fmt.Errorf("MigrateWts: no migration from weights version %d", v)
+			log.Println(err)
+			return err
+		}
+		if err = mf(nw); err != nil {
+			log.Println(err)
+			return err
+		}
+	}
+	nw.SetMetaData(WtsVersionKey, strconv.Itoa(WtsVersion))
+	return nil
+}
+
+// MigrateWtsV1 upgrades version 1 weights to version 2, checking that
+// each receiving unit has a weight for each sending unit.  Any missing
+// structural weights (Wt1) are left absent, so that SetWts keeps the
+// initialized SWt and recovers the LWt from the Wt, exactly as version 1
+// weights were always loaded.
+func MigrateWtsV1(nw *weights.Network) error {
+	for li := range nw.Layers {
+		lw := &nw.Layers[li]
+		for pi := range lw.Prjns {
+			pw := &lw.Prjns[pi]
+			for ri := range pw.Rs {
+				pr := &pw.Rs[ri]
+				if len(pr.Wt) < len(pr.Si) {
+					return fmt.Errorf("MigrateWtsV1: layer %s prjn from %s recv unit %d has %d weights for %d sending units", lw.Layer, pw.From, pr.Ri, len(pr.Wt), len(pr.Si))
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -5,6 +5,7 @@
 package chans
 
 import (
+	"github.com/goki/gosl/slbool"
 	"github.com/goki/mat32"
 )
 
@@ -23,6 +24,8 @@ type GABABParams struct {
 	TauFact float32 `view:"-" desc:"time constant factor used in integration: (Decay / Rise) ^ (Rise / (Decay - Rise))"`
 
 	pad int32
+
+	Deplete GABABDepleteParams `viewif:"Gbar>0" view:"inline" desc:"use-dependent depletion of the available GABA-B conductance under sustained inhibitory drive"`
 }
 
 func (gp *GABABParams) Defaults() {
@@ -31,12 +34,14 @@ func (gp *GABABParams) Defaults() {
 	gp.DecayTau = 50
 	gp.Gbase = 0.2
 	gp.GiSpike = 10
+	gp.Deplete.Defaults()
 	gp.Update()
 }
 
 func (gp *GABABParams) Update() {
 	gp.TauFact = mat32.Pow(gp.DecayTau/gp.RiseTau, gp.RiseTau/(gp.DecayTau-gp.RiseTau))
 	gp.MaxTime = ((gp.RiseTau * gp.DecayTau) / (gp.DecayTau - gp.RiseTau)) * mat32.Log(gp.DecayTau/gp.RiseTau)
+	gp.Deplete.Update()
 }
 
 // GFmV returns the GABA-B conductance as a function of normalized membrane potential
@@ -81,4 +86,49 @@ func (gp *GABABParams) GgabaB(gabaB, vm float32) float32 {
 	return gp.Gbar * gp.GFmV(vm) * (gabaB + gp.Gbase)
 }
 
+// GABABDeplete updates the depletion factor (0 = none, 1 = fully depleted)
+// based on the gi inhibitory conductance (proxy for GABA spikes),
+// and returns the proportion of GABA-B conductance still available.
+// The drive is GFmS relative to its value at gi = 0, so that depletion
+// fully recovers in the absence of inhibition.
+// Returns 1 if Deplete is not On.
+func (gp *GABABParams) GABABDeplete(gi float32, dep *float32) float32 {
+	if gp.Deplete.On.IsFalse() {
+		return 1
+	}
+	s := gp.GFmS(gi) - gp.GFmS(0)
+	if s < 0 {
+		s = 0
+	}
+	gp.Deplete.DepFmS(s, dep)
+	return 1 - *dep
+}
+
+// GABABDepleteParams control use-dependent depletion of GABA-B conductance,
+// where sustained GABA release reduces the available conductance,
+// which then recovers with a slower time constant during silence.
+type GABABDepleteParams struct {
+	On   slbool.Bool `desc:"if On, sustained GABA-B activation depletes the available conductance"`
+	Rate float32     `viewif:"On" def:"0.005" desc:"rate of depletion per cycle as a function of the normalized GABA spiking drive (GFmS) -- multiplies the remaining available proportion"`
+	Tau  float32     `viewif:"On" def:"500" desc:"time constant in cycles (msec) for recovery from depletion"`
+
+	Dt float32 `view:"-" desc:"1/Tau rate constant"`
+}
+
+func (dp *GABABDepleteParams) Defaults() {
+	dp.Rate = 0.005
+	dp.Tau = 500
+	dp.Update()
+}
+
+func (dp *GABABDepleteParams) Update() {
+	dp.Dt = 1 / dp.Tau
+}
+
+// DepFmS updates the depletion factor dep based on
+// normalized GABA spiking drive s (0-1).
+func (dp *GABABDepleteParams) DepFmS(s float32, dep *float32) {
+	*dep += dp.Rate*s*(1-*dep) - dp.Dt**dep
+}
+
 //gosl: end chans
@@ -12,6 +12,7 @@ import (
 	"github.com/emer/axon/axon"
 	"github.com/emer/emergent/etime"
 	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
 	"github.com/goki/gi/gi"
 )
 
@@ -210,3 +211,47 @@ func BenchmarkLayerTypeAssertBaseline(b *testing.B) {
 	// avoid compiler optimization
 	fp32Result = tmp
 }
+
+// Run the DWt benchmarks with `go test -bench=".*DWt.*" .`
+func benchmarkDWt(spikeGated bool, b *testing.B) {
+	net := &axon.Network{}
+	ConfigNet(net, 1, 1, 1, 2048, false)
+	for _, pj := range net.Prjns {
+		pj.Params.Learn.SpikeGatedDWt.SetBool(spikeGated)
+	}
+	pats := &etable.Table{}
+	ConfigPats(pats, 1, 2048)
+	inLay := net.AxonLayerByName("Input")
+	outLay := net.AxonLayerByName("Output")
+	inLay.ApplyExt(pats.ColByName("Input").(*etensor.Float32).SubSpace([]int{0}))
+	outLay.ApplyExt(pats.ColByName("Output").(*etensor.Float32).SubSpace([]int{0}))
+
+	ctx := axon.NewContext()
+	net.ApplyExts(ctx)
+	net.NewState(ctx)
+	ctx.NewState(etime.Train)
+	for cyc := 0; cyc < 200; cyc++ {
+		net.Cycle(ctx)
+		ctx.CycleInc()
+		if cyc == 149 {
+			net.MinusPhase(ctx)
+			ctx.NewPhase(true)
+			net.PlusPhaseStart(ctx)
+		}
+	}
+	net.PlusPhase(ctx)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		net.DWt(ctx)
+	}
+}
+
+func BenchmarkDWtFull(b *testing.B) {
+	benchmarkDWt(false, b)
+}
+
+func BenchmarkDWtSpikeGated(b *testing.B) {
+	benchmarkDWt(true, b)
+}
@@ -79,6 +79,8 @@ type Sim struct {
 	TestInterval int              `desc:"how often to run through all the test patterns, in terms of training epochs -- can use 0 or -1 for no testing"`
 	PCAInterval  int              `desc:"how frequently (in epochs) to compute PCA on hidden representations to measure variance?"`
 
+	ConfMat axon.ConfusionMatrix `view:"-" desc:"confusion matrix of winning output unit in the minus phase vs. the target, accumulated over each testing epoch"`
+
 	GUI      egui.GUI    `view:"-" desc:"manages all the gui elements"`
 	Args     ecmd.Args   `view:"no-inline" desc:"command line args"`
 	RndSeeds erand.Seeds `view:"-" desc:"a list of random seeds to use for each run"`
@@ -272,8 +274,12 @@ func (ss *Sim) ConfigLoops() {
 	/////////////////////////////////////////////
 	// Logging
 
+	man.GetLoop(etime.Test, etime.Epoch).OnStart.Add("ResetConfMat", func() {
+		ss.ConfMat.Init(ss.Net.AxonLayerByName("Output").Shape().Len())
+	})
 	man.GetLoop(etime.Test, etime.Epoch).OnEnd.Add("LogTestErrors", func() {
 		axon.LogTestErrors(&ss.Logs)
+		ss.Logs.MiscTables["ConfusionMatrix"] = ss.ConfMat.ToTable()
 	})
 	man.GetLoop(etime.Train, etime.Epoch).OnEnd.Add("PCAStats", func() {
 		trnEpc := man.Stacks[etime.Train].Loops[etime.Epoch].Counter.Cur
@@ -424,6 +430,10 @@ func (ss *Sim) TrialStats() {
 
 	ss.Stats.SetFloat("TrlCorSim", float64(out.Vals.CorSim.Cor))
 	ss.Stats.SetFloat("TrlUnitErr", out.PctUnitErr())
+	if ss.Context.Mode == etime.Test {
+		// predicted = minus-phase winner, vs. the first active target unit
+		ss.ConfMat.Incr(out.ClassifyWinnerVar("ActM"), out.ClassifyWinnerVar("Target"))
+	}
 
 	if ss.Stats.Float("TrlUnitErr") > 0 {
 		ss.Stats.SetFloat("TrlErr", 1)
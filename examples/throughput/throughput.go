@@ -0,0 +1,69 @@
+// Copyright (c) 2023, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// throughput runs axon.Benchmark on a network with the same structure as
+// the ra25 example, reporting the neurons, synapses, and cycles per second,
+// for a quick, consistent measure of the performance impact of changes.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"runtime"
+
+	"github.com/emer/axon/axon"
+	"github.com/emer/emergent/prjn"
+	"github.com/emer/etable/etensor"
+)
+
+func init() {
+	// must lock main thread for gpu!
+	runtime.LockOSThread()
+}
+
+var gpu = flag.Bool("gpu", false, "whether to run on the gpu")
+var trials = flag.Int("trials", 100, "number of trials to run")
+var units = flag.Int("units", 10, "hidden layer size: units x units")
+var threads = flag.Int("threads", 0, "number of goroutines for neurons, send spike, and synca -- 0 = default")
+
+func main() {
+	flag.Parse()
+	net := axon.NewNetwork("Throughput")
+	inp := net.AddLayer2D("Input", 5, 5, axon.InputLayer)
+	hid1 := net.AddLayer2D("Hidden1", *units, *units, axon.SuperLayer)
+	hid2 := net.AddLayer2D("Hidden2", *units, *units, axon.SuperLayer)
+	out := net.AddLayer2D("Output", 5, 5, axon.TargetLayer)
+	full := prjn.NewFull()
+	net.ConnectLayers(inp, hid1, full, axon.ForwardPrjn)
+	net.BidirConnectLayers(hid1, hid2, full)
+	net.BidirConnectLayers(hid2, out, full)
+	if err := net.Build(); err != nil {
+		log.Fatal(err)
+	}
+	net.Defaults()
+	if *threads > 0 {
+		if err := net.Threads.Set(*threads, *threads, *threads); err != nil {
+			log.Fatal(err)
+		}
+	}
+	net.InitWts()
+
+	ctx := axon.NewContext()
+	pat := etensor.NewFloat32([]int{5, 5}, nil, nil)
+	for i := 0; i < 25; i += 4 {
+		pat.Values[i] = 1
+	}
+	net.InitExt()
+	inp.ApplyExt(pat)
+	out.ApplyExt(pat)
+	if *gpu {
+		net.ConfigGPUnoGUI(ctx)
+	}
+	net.ApplyExts(ctx)
+
+	br := axon.Benchmark(net, ctx, *trials)
+	fmt.Print(br.String())
+	net.GPU.Destroy()
+}